@@ -0,0 +1,290 @@
+// Package api serves a snapshot of the content of a ResourceParser with a read only REST interface:
+//
+//	GET /locales                 the locales with content
+//	GET /{locale}/categories     the categories of the locale, with the IDs of their subcategories
+//	GET /{locale}/category/{id}  a category with all its descendants
+//	GET /{locale}/form/{id}      a form
+//
+// Responses are JSON, categories and forms are the ones of ResourceParser.Export, made once
+// for each snapshot and locale. Every response has an ETag, the hash of its content, and a
+// request with an If-None-Match that matches it, compared as weak tags, or that is * gets
+// 304 Not Modified.
+//
+// A handler serving a History (see NewVersioned) serves its current version, or the one of
+// the version query parameter, like /en/categories?version=3, and has one more endpoint:
+//
+//	GET /versions                the versions kept, and the number of the current one
+//
+// Its responses have the number of the version served in the Content-Version header.
+//
+// The options of a handler select the locales served, like ResourceParser.Locales: with
+// ReadyLocalesOnly the locales that are not ready are neither listed nor found.
+package api
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/securityfirst/tent/component"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrMethod   = errors.New("method not allowed")
+	ErrVersion  = errors.New("version not found")
+)
+
+// Handler is the http.Handler of the API
+type Handler struct {
+	mu       sync.RWMutex
+	snapshot *component.Snapshot
+	history  *component.History
+	opts     []component.Option
+
+	exportMu sync.Mutex
+	exports  map[exportKey]*component.Tree // see export
+}
+
+type exportKey struct {
+	snapshot *component.Snapshot
+	locale   string
+}
+
+// New returns a handler for the content of s, see ResourceParser.Snapshot
+func New(s *component.Snapshot, opts ...component.Option) *Handler {
+	return &Handler{snapshot: s, opts: opts}
+}
+
+// NewVersioned returns a handler for the versions of the history
+func NewVersioned(h *component.History, opts ...component.Option) *Handler {
+	return &Handler{history: h, opts: opts}
+}
+
+// SetSnapshot replaces the content served, so the parser can go on parsing
+// and its new content is served when ready
+func (h *Handler) SetSnapshot(s *component.Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.snapshot = s
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, ErrMethod)
+		return
+	}
+	var (
+		parts = strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		obj   interface{}
+		err   error
+	)
+	if h.history != nil && len(parts) == 1 && parts[0] == "versions" {
+		obj = versions(h.history)
+	} else {
+		var s *component.Snapshot
+		if s, err = h.version(w, r); err == nil {
+			obj, err = h.route(s, parts)
+		}
+	}
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(obj); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	sum := sha1.Sum(b.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+	if matchETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(b.Bytes())
+	}
+}
+
+// version returns the snapshot to serve, and sets the header of its version
+func (h *Handler) version(w http.ResponseWriter, r *http.Request) (*component.Snapshot, error) {
+	if h.history == nil {
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+		return h.snapshot, nil
+	}
+	v := h.history.Current()
+	if q := r.URL.Query().Get("version"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil {
+			return nil, ErrVersion
+		}
+		v = h.history.Version(n)
+	}
+	if v == nil {
+		return nil, ErrVersion
+	}
+	w.Header().Set("Content-Version", strconv.Itoa(v.Number))
+	return v.Snapshot, nil
+}
+
+func versions(h *component.History) interface{} {
+	var current int
+	if v := h.Current(); v != nil {
+		current = v.Number
+	}
+	list := h.Versions()
+	if list == nil {
+		list = []*component.Version{}
+	}
+	return map[string]interface{}{"current": current, "versions": list}
+}
+
+// route returns the content for the parts of the path
+func (h *Handler) route(s *component.Snapshot, parts []string) (interface{}, error) {
+	switch {
+	case len(parts) == 1 && parts[0] == "locales":
+		return map[string]interface{}{"locales": s.Locales(h.opts...)}, nil
+	case len(parts) > 1 && h.hidden(s, parts[0]):
+		return nil, ErrNotFound
+	case len(parts) == 2 && parts[1] == "categories":
+		return categories(s, parts[0])
+	case len(parts) == 3 && parts[1] == "category":
+		return h.category(s, parts[0], parts[2])
+	case len(parts) == 3 && parts[1] == "form":
+		return h.form(s, parts[0], parts[2])
+	}
+	return nil, ErrNotFound
+}
+
+// export returns the content of the locale in the snapshot, made on the first request. The
+// trees of the snapshots no longer served are dropped when a new one is made.
+func (h *Handler) export(s *component.Snapshot, locale string) (*component.Tree, error) {
+	key := exportKey{s, locale}
+	h.exportMu.Lock()
+	defer h.exportMu.Unlock()
+	if t, ok := h.exports[key]; ok {
+		return t, nil
+	}
+	t, err := s.Export(locale)
+	if err != nil {
+		return nil, err
+	}
+	var live = map[*component.Snapshot]bool{s: true}
+	if h.history != nil {
+		for _, v := range h.history.Versions() {
+			live[v.Snapshot] = true
+		}
+	}
+	for k := range h.exports {
+		if !live[k.snapshot] {
+			delete(h.exports, k)
+		}
+	}
+	if h.exports == nil {
+		h.exports = make(map[exportKey]*component.Tree)
+	}
+	h.exports[key] = t
+	return t, nil
+}
+
+// hidden tells if the options of the handler exclude the locale
+func (h *Handler) hidden(s *component.Snapshot, locale string) bool {
+	if len(h.opts) == 0 {
+		return false
+	}
+	for _, l := range s.Locales(h.opts...) {
+		if l == locale {
+			return false
+		}
+	}
+	return true
+}
+
+func categories(s *component.Snapshot, locale string) (interface{}, error) {
+	cats := s.Categories(locale)
+	if len(cats) == 0 {
+		return nil, ErrNotFound
+	}
+	var list = make([]map[string]interface{}, 0, len(cats))
+	for _, c := range cats {
+		m := c.Fields()
+		m["id"] = c.ID
+		list = append(list, m)
+	}
+	return map[string]interface{}{"categories": list}, nil
+}
+
+func (h *Handler) category(s *component.Snapshot, locale, id string) (interface{}, error) {
+	t, err := h.export(s, locale)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	for _, c := range t.Categories {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (h *Handler) form(s *component.Snapshot, locale, id string) (interface{}, error) {
+	t, err := h.export(s, locale)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	for _, f := range t.Forms {
+		if f.ID == id {
+			return f, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// matchETag tells if the If-None-Match header matches the tag, see RFC 9110 13.1.2: the
+// header is * or a list of tags, compared ignoring the weak prefix W/. A malformed list
+// matches up to the first invalid tag.
+func matchETag(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for {
+		header = strings.TrimLeft(header, " \t,")
+		if header == "" {
+			return false
+		}
+		header = strings.TrimPrefix(header, "W/")
+		if !strings.HasPrefix(header, `"`) {
+			return false
+		}
+		end := strings.IndexByte(header[1:], '"')
+		if end < 0 {
+			return false
+		}
+		if header[:end+2] == etag {
+			return true
+		}
+		header = header[end+2:]
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
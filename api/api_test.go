@@ -0,0 +1,232 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/securityfirst/tent/component"
+)
+
+const locale = `{"locale": "en", "categories": [{"id": "cat", "name": "Category", "order": 1,
+	"subcategories": [{"id": "sub", "name": "Sub", "order": 0, "difficulties": [{"id": "beginner",
+	"description": "Easy", "items": [{"id": "item", "title": "Title", "body": "<b>Body</b>", "order": 0}],
+	"checks": [{"text": "Check", "no_check": false}]}]}]}],
+	"forms": [{"id": "form", "name": "Form", "screens": [{"name": "One", "items": [{"type": "text_input", "label": "Label"}]}]}]}`
+
+func testHandler(t *testing.T) *Handler {
+	p := component.NewResourceParser()
+	if err := p.UnmarshalLocale([]byte(locale)); err != nil {
+		t.Fatal(err)
+	}
+	return New(p.Snapshot())
+}
+
+func TestHandler(t *testing.T) {
+	h := testHandler(t)
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/locales", 200, `{"locales":["en"]}`},
+		{"GET", "/en/categories", 200, `{"categories":[{"id":"cat","name":"Category","subcategories":["sub"]}]}`},
+		{"GET", "/en/category/cat", 200, `{"id":"cat","name":"Category","order":1,"subcategories":[{"id":"sub","name":"Sub","order":0,` +
+			`"difficulties":[{"id":"beginner","description":"Easy","items":[{"id":"item","title":"Title","body":"<b>Body</b>","order":0}],` +
+			`"checks":[{"text":"Check","no_check":false}]}]}]}`},
+		{"GET", "/en/form/form", 200, `{"id":"form","name":"Form","screens":[{"name":"One","items":[{"type":"text_input","label":"Label"}]}]}`},
+		{"GET", "/en/category/missing", 404, `{"error":"not found"}`},
+		{"GET", "/fr/categories", 404, `{"error":"not found"}`},
+		{"GET", "/fr/form/form", 404, `{"error":"not found"}`},
+		{"GET", "/en", 404, `{"error":"not found"}`},
+		{"POST", "/locales", 405, `{"error":"method not allowed"}`},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.status {
+			t.Errorf("%s %s: status %d, expected %d", tc.method, tc.path, w.Code, tc.status)
+		}
+		if body := w.Body.String(); body != tc.body+"\n" {
+			t.Errorf("%s %s: body %s, expected %s", tc.method, tc.path, body, tc.body)
+		}
+	}
+}
+
+func TestHandlerReadyLocalesOnly(t *testing.T) {
+	p := component.NewResourceParser()
+	for _, doc := range []string{locale, `{"locale": "it", "categories": [{"id": "cat", "name": "Categoria", "order": 1}]}`} {
+		if err := p.UnmarshalLocale([]byte(doc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		h       *Handler
+		locales string
+		status  int
+	}{
+		{New(p.Snapshot()), `{"locales":["en","it"]}`, 200},
+		{New(p.Snapshot(), component.ReadyLocalesOnly("en", component.DefaultThresholds)), `{"locales":["en"]}`, 404},
+	} {
+		w := httptest.NewRecorder()
+		tc.h.ServeHTTP(w, httptest.NewRequest("GET", "/locales", nil))
+		if body := w.Body.String(); body != tc.locales+"\n" {
+			t.Errorf("locales %s, expected %s", body, tc.locales)
+		}
+		w = httptest.NewRecorder()
+		tc.h.ServeHTTP(w, httptest.NewRequest("GET", "/it/categories", nil))
+		if w.Code != tc.status {
+			t.Errorf("status %d, expected %d", w.Code, tc.status)
+		}
+	}
+}
+
+func TestHandlerETag(t *testing.T) {
+	h := testHandler(t)
+	get := func(etag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/en/category/cat", nil)
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if w := get(etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("matching ETag: status %d, body %q", w.Code, w.Body)
+	}
+
+	// new content has a new tag
+	var doc component.Tree
+	if err := json.Unmarshal([]byte(locale), &doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.Categories[0].Name = "Changed"
+	b, _ := json.Marshal(doc)
+	p := component.NewResourceParser()
+	if err := p.UnmarshalLocale(b); err != nil {
+		t.Fatal(err)
+	}
+	h.SetSnapshot(p.Snapshot())
+	if w := get(etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("changed content: status %d, ETag %s", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestMatchETag(t *testing.T) {
+	const etag = `"abc"`
+	for _, tc := range []struct {
+		header string
+		match  bool
+	}{
+		{``, false},
+		{`"abc"`, true},
+		{` * `, true},
+		{`W/"abc"`, true},
+		{`"x", W/"abc"`, true},
+		{`"x",,"abc" `, true},
+		{`"a,b", "abc"`, true},
+		{`"x", "y"`, false},
+		{`abc`, false},
+		{`"x", abc, "abc"`, false},
+		{`"abc`, false},
+	} {
+		if m := matchETag(tc.header, etag); m != tc.match {
+			t.Errorf("%q: match %v, expected %v", tc.header, m, tc.match)
+		}
+	}
+}
+
+func TestHandlerExportCache(t *testing.T) {
+	h := testHandler(t)
+	s := h.snapshot
+	first, err := h.export(s, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := h.export(s, "en"); again != first {
+		t.Error("export made again")
+	}
+	if _, err := h.export(s, "fr"); err == nil {
+		t.Error("missing locale exported")
+	}
+
+	// the trees of the old snapshot are dropped with the next export
+	p := component.NewResourceParser()
+	if err := p.UnmarshalLocale([]byte(locale)); err != nil {
+		t.Fatal(err)
+	}
+	h.SetSnapshot(p.Snapshot())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/en/form/form", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d", w.Code)
+	}
+	if _, ok := h.exports[exportKey{s, "en"}]; ok || len(h.exports) != 1 {
+		t.Errorf("unexpected exports %v", h.exports)
+	}
+}
+
+func TestHandlerVersions(t *testing.T) {
+	var hist = component.NewHistory(2)
+	for _, name := range []string{"One", "Two", "Three"} {
+		var doc component.Tree
+		if err := json.Unmarshal([]byte(locale), &doc); err != nil {
+			t.Fatal(err)
+		}
+		doc.Categories[0].Name = name
+		b, _ := json.Marshal(doc)
+		p := component.NewResourceParser()
+		if err := p.UnmarshalLocale(b); err != nil {
+			t.Fatal(err)
+		}
+		hist.Commit(p)
+	}
+	h := NewVersioned(hist)
+	for _, tc := range []struct {
+		path    string
+		status  int
+		version string
+		name    string
+	}{
+		{"/en/categories", 200, "3", "Three"},
+		{"/en/categories?version=2", 200, "2", "Two"},
+		{"/en/categories?version=1", 404, "", ""},
+		{"/en/categories?version=x", 404, "", ""},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.status || w.Header().Get("Content-Version") != tc.version {
+			t.Errorf("%s: status %d, version %q", tc.path, w.Code, w.Header().Get("Content-Version"))
+			continue
+		}
+		var resp struct {
+			Categories []struct{ Name string }
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if tc.name != "" && (len(resp.Categories) != 1 || resp.Categories[0].Name != tc.name) {
+			t.Errorf("%s: got %s", tc.path, w.Body)
+		}
+	}
+
+	if _, err := hist.Rollback(2); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/versions", nil))
+	var resp struct {
+		Current  int
+		Versions []struct{ Version int }
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Current != 2 || len(resp.Versions) != 2 || resp.Versions[0].Version != 2 || resp.Versions[1].Version != 3 {
+		t.Errorf("versions: got %s", w.Body)
+	}
+}
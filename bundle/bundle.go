@@ -0,0 +1,301 @@
+// Package bundle writes the content of a ResourceParser, every locale, into a SQLite database
+// that the apps can ship as an asset and query instead of parsing the JSON at startup.
+//
+// The database has a table for each component, with the locale and the ID, or slug, of the
+// component and the ones of its parents; children keep their position, from 0, in the order
+// of Export. Lists, like the audience or the options of an input, are JSON arrays and flags
+// are 0 or 1. Conditions of form screens and inputs are the show_if expressions, empty if
+// they are always shown. The schema is Schema, and its version is in the meta table, with the key
+// schema_version. Every table has an index, or its primary key, on locale and slug, and the
+// ones of children on locale and parent.
+//
+// The package uses database/sql and no driver: the program registers one, like
+// github.com/mattn/go-sqlite3, and passes its name to WriteFile.
+//
+// Sign packages the database, or the JSON of the locales with SignJSON, in a tar archive with a
+// manifest of the SHA-256 hashes of the files and of the components, signed with Ed25519, that
+// Verify checks so that the apps can detect a tampered or truncated bundle.
+package bundle
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/securityfirst/tent/component"
+)
+
+// SchemaVersion is the version of Schema, changed for every change of the tables
+const SchemaVersion = 3
+
+// Schema creates the tables and indexes of the database
+const Schema = `
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE locales (
+	locale TEXT PRIMARY KEY
+);
+CREATE TABLE categories (
+	locale   TEXT NOT NULL,
+	slug     TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	sort     REAL NOT NULL,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (locale, slug)
+);
+CREATE TABLE subcategories (
+	locale   TEXT NOT NULL,
+	category TEXT NOT NULL,
+	slug     TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	sort     REAL NOT NULL,
+	audience TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (locale, category, slug)
+);
+CREATE TABLE difficulties (
+	locale      TEXT NOT NULL,
+	category    TEXT NOT NULL,
+	subcategory TEXT NOT NULL,
+	slug        TEXT NOT NULL,
+	description TEXT NOT NULL,
+	position    INTEGER NOT NULL,
+	PRIMARY KEY (locale, category, subcategory, slug)
+);
+CREATE TABLE items (
+	locale      TEXT NOT NULL,
+	category    TEXT NOT NULL,
+	subcategory TEXT NOT NULL,
+	difficulty  TEXT NOT NULL,
+	slug        TEXT NOT NULL,
+	title       TEXT NOT NULL,
+	body        TEXT NOT NULL,
+	summary     TEXT NOT NULL,
+	sort        REAL NOT NULL,
+	audience    TEXT NOT NULL,
+	position    INTEGER NOT NULL,
+	PRIMARY KEY (locale, category, subcategory, difficulty, slug)
+);
+CREATE TABLE checks (
+	locale      TEXT NOT NULL,
+	category    TEXT NOT NULL,
+	subcategory TEXT NOT NULL,
+	difficulty  TEXT NOT NULL,
+	position    INTEGER NOT NULL,
+	text        TEXT NOT NULL,
+	no_check    INTEGER NOT NULL,
+	style       TEXT NOT NULL,
+	id          TEXT NOT NULL,
+	PRIMARY KEY (locale, category, subcategory, difficulty, position)
+);
+CREATE TABLE forms (
+	locale TEXT NOT NULL,
+	slug   TEXT NOT NULL,
+	name   TEXT NOT NULL,
+	PRIMARY KEY (locale, slug)
+);
+CREATE TABLE form_screens (
+	locale   TEXT NOT NULL,
+	form     TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	slug     TEXT NOT NULL,
+	name     TEXT NOT NULL,
+	show_if  TEXT NOT NULL,
+	PRIMARY KEY (locale, form, position)
+);
+CREATE TABLE form_inputs (
+	locale       TEXT NOT NULL,
+	form         TEXT NOT NULL,
+	screen       INTEGER NOT NULL,
+	position     INTEGER NOT NULL,
+	type         TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	label        TEXT NOT NULL,
+	value        TEXT NOT NULL,
+	options      TEXT NOT NULL,
+	hint         TEXT NOT NULL,
+	lines        INTEGER NOT NULL,
+	multi_select INTEGER NOT NULL,
+	other_option INTEGER NOT NULL,
+	required     INTEGER NOT NULL,
+	show_if      TEXT NOT NULL,
+	PRIMARY KEY (locale, form, screen, position)
+);
+CREATE TABLE quiz_questions (
+	locale      TEXT NOT NULL,
+	category    TEXT NOT NULL,
+	subcategory TEXT NOT NULL,
+	difficulty  TEXT NOT NULL,
+	position    INTEGER NOT NULL,
+	text        TEXT NOT NULL,
+	options     TEXT NOT NULL,
+	correct     TEXT NOT NULL,
+	explanation TEXT NOT NULL,
+	PRIMARY KEY (locale, category, subcategory, difficulty, position)
+);
+CREATE TABLE glossaries (
+	locale TEXT NOT NULL,
+	slug   TEXT NOT NULL,
+	PRIMARY KEY (locale, slug)
+);
+CREATE TABLE glossary_terms (
+	locale     TEXT NOT NULL,
+	glossary   TEXT NOT NULL,
+	position   INTEGER NOT NULL,
+	slug       TEXT NOT NULL,
+	term       TEXT NOT NULL,
+	definition TEXT NOT NULL,
+	PRIMARY KEY (locale, glossary, position)
+);
+CREATE INDEX subcategories_slug ON subcategories (locale, slug);
+CREATE INDEX difficulties_parent ON difficulties (locale, category, subcategory);
+CREATE INDEX items_slug ON items (locale, slug);
+CREATE INDEX items_parent ON items (locale, category, subcategory, difficulty);
+CREATE INDEX checks_parent ON checks (locale, category, subcategory, difficulty);
+CREATE INDEX form_screens_parent ON form_screens (locale, form);
+CREATE INDEX form_inputs_parent ON form_inputs (locale, form, screen);
+CREATE INDEX quiz_questions_parent ON quiz_questions (locale, category, subcategory, difficulty);
+CREATE INDEX glossary_terms_slug ON glossary_terms (locale, slug);
+CREATE INDEX glossary_terms_parent ON glossary_terms (locale, glossary);
+`
+
+// WriteFile creates the database at path, replacing the file if it exists, with the driver
+// registered with the name, and writes the content of the parser into it, see Write
+func WriteFile(driver, path string, p *component.ResourceParser, opts ...component.Option) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open(driver, path)
+	if err != nil {
+		return err
+	}
+	if err := Write(db, p, opts...); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// Write creates the tables of Schema in an empty database and writes the content of every
+// locale of the parser, archived ones excluded, in a single transaction. The options select
+// the locales, like ResourceParser.Locales: with ReadyLocalesOnly the locales that are not
+// ready are left out.
+func Write(db *sql.DB, p *component.ResourceParser, opts ...component.Option) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	w := writer{tx: tx}
+	w.exec(Schema)
+	w.insert("meta", "schema_version", strconv.Itoa(SchemaVersion))
+	for _, l := range p.Locales(opts...) {
+		tree, err := p.Export(l)
+		if err != nil {
+			w.err = err
+			break
+		}
+		w.locale(tree)
+	}
+	if w.err != nil {
+		tx.Rollback()
+		return w.err
+	}
+	return tx.Commit()
+}
+
+// writer executes statements until the first error
+type writer struct {
+	tx  *sql.Tx
+	err error
+}
+
+func (w *writer) exec(query string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	if _, err := w.tx.Exec(query, args...); err != nil {
+		w.err = fmt.Errorf("bundle: %v", err)
+	}
+}
+
+// insert adds a row to the table, the values in the order of the columns of Schema
+func (w *writer) insert(table string, values ...interface{}) {
+	var marks = make([]byte, 0, 2*len(values))
+	for i := range values {
+		if i != 0 {
+			marks = append(marks, ',')
+		}
+		marks = append(marks, '?')
+	}
+	w.exec(fmt.Sprintf("INSERT INTO %s VALUES (%s)", table, marks), values...)
+}
+
+func (w *writer) locale(t *component.Tree) {
+	l := t.Locale
+	w.insert("locales", l)
+	for i, c := range t.Categories {
+		w.insert("categories", l, c.ID, c.Name, c.Order, i)
+		for i, s := range c.Subcategories {
+			w.insert("subcategories", l, c.ID, s.ID, s.Name, s.Order, list(s.Audience), i)
+			for i, d := range s.Difficulties {
+				w.insert("difficulties", l, c.ID, s.ID, d.ID, d.Description, i)
+				for i, item := range d.Items {
+					w.insert("items", l, c.ID, s.ID, d.ID, item.ID, item.Title, item.Body, item.Summary,
+						item.Order, list(item.Audience), i)
+				}
+				for i, check := range d.Checks {
+					w.insert("checks", l, c.ID, s.ID, d.ID, i, check.Text, flag(check.NoCheck), check.Style, check.ID)
+				}
+				for i, q := range d.Quiz {
+					w.insert("quiz_questions", l, c.ID, s.ID, d.ID, i, q.Text, list(q.Options), numbers(q.Correct),
+						q.Explanation)
+				}
+			}
+		}
+	}
+	for _, f := range t.Forms {
+		w.insert("forms", l, f.ID, f.Name)
+		for i, s := range f.Screens {
+			w.insert("form_screens", l, f.ID, i, s.ID, s.Name, s.ShowIf)
+			for j, in := range s.Items {
+				w.insert("form_inputs", l, f.ID, i, j, in.Type, in.Name, in.Label, list(in.Value),
+					list(in.Options), in.Hint, in.Lines, flag(in.MultiSelect), flag(in.OtherOption), flag(in.Required), in.ShowIf)
+			}
+		}
+	}
+	for _, g := range t.Glossaries {
+		w.insert("glossaries", l, g.ID)
+		for i, term := range g.Terms {
+			w.insert("glossary_terms", l, g.ID, i, term.ID(), term.Term, term.Definition)
+		}
+	}
+}
+
+// list returns the JSON array of the strings, empty if nil
+func list(s []string) string {
+	if len(s) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// numbers returns the JSON array of the numbers, empty if nil
+func numbers(n []int) string {
+	if len(n) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+func flag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,225 @@
+package bundle
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/securityfirst/tent/component"
+)
+
+const locale = `{"locale": "en", "categories": [{"id": "cat", "name": "Category", "order": 1,
+	"subcategories": [{"id": "sub", "name": "Sub", "order": 0, "audience": ["journalist"],
+	"difficulties": [{"id": "beginner", "description": "Easy", "items": [{"id": "item", "title": "Title",
+	"body": "Body", "order": 0}], "checks": [{"text": "Check", "no_check": false},
+	{"text": "Note", "no_check": true, "style": "warning"}]}]}]}],
+	"forms": [{"id": "form", "name": "Form", "screens": [{"name": "One", "items": [{"type": "single_choice",
+	"label": "Label", "options": ["a", "b"]}]}]}]}`
+
+// recorder is a database/sql driver keeping the rows inserted, by table
+type recorder struct {
+	mu        sync.Mutex
+	rows      map[string][][]driver.Value
+	schema    string
+	committed bool
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return recorderConn{r}, nil }
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{r: c.r, query: query}, nil
+}
+func (c recorderConn) Close() error              { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) { return recorderTx{c.r}, nil }
+
+type recorderTx struct{ r *recorder }
+
+func (t recorderTx) Commit() error   { t.r.committed = true; return nil }
+func (t recorderTx) Rollback() error { return nil }
+
+type recorderStmt struct {
+	r     *recorder
+	query string
+}
+
+func (s recorderStmt) Close() error  { return nil }
+func (s recorderStmt) NumInput() int { return -1 }
+
+func (s recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	if !strings.HasPrefix(s.query, "INSERT INTO ") {
+		s.r.schema += s.query
+		return driver.RowsAffected(0), nil
+	}
+	table := strings.Fields(s.query)[2]
+	s.r.rows[table] = append(s.r.rows[table], args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s recorderStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("no queries")
+}
+
+var rec = &recorder{rows: make(map[string][][]driver.Value)}
+
+func init() { sql.Register("bundletest", rec) }
+
+func TestWrite(t *testing.T) {
+	p := component.NewResourceParser()
+	if err := p.UnmarshalLocale([]byte(locale)); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("bundletest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := Write(db, p); err != nil {
+		t.Fatal(err)
+	}
+	if !rec.committed || rec.schema != Schema {
+		t.Fatalf("committed %v, schema executed %v", rec.committed, rec.schema == Schema)
+	}
+	for table, expected := range map[string][][]driver.Value{
+		"meta":          {{"schema_version", "3"}},
+		"locales":       {{"en"}},
+		"categories":    {{"en", "cat", "Category", 1.0, int64(0)}},
+		"subcategories": {{"en", "cat", "sub", "Sub", 0.0, `["journalist"]`, int64(0)}},
+		"difficulties":  {{"en", "cat", "sub", "beginner", "Easy", int64(0)}},
+		"items":         {{"en", "cat", "sub", "beginner", "item", "Title", "Body", "", 0.0, "[]", int64(0)}},
+		"checks": {
+			{"en", "cat", "sub", "beginner", int64(0), "Check", int64(0), "", ""},
+			{"en", "cat", "sub", "beginner", int64(1), "Note", int64(1), "warning", ""},
+		},
+		"forms":        {{"en", "form", "Form"}},
+		"form_screens": {{"en", "form", int64(0), "", "One", ""}},
+		"form_inputs": {{"en", "form", int64(0), int64(0), "single_choice", "", "Label", "[]", `["a","b"]`, "",
+			int64(0), int64(0), int64(0), int64(0), ""}},
+	} {
+		if got := rec.rows[table]; !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %v, got %v", table, expected, got)
+		}
+	}
+}
+
+// fullLocale has a component of every kind that Export writes
+const fullLocale = `{"locale": "it", "categories": [{"id": "cat", "name": "Categoria", "order": 1,
+	"subcategories": [{"id": "sub", "name": "Sotto", "order": 2, "audience": ["journalist"],
+	"difficulties": [{"id": "beginner", "description": "Facile", "items": [{"id": "item", "title": "Titolo",
+	"body": "Testo", "order": 1, "summary": "Sommario", "audience": ["activist"]}],
+	"checks": [{"text": "Controllo", "no_check": false, "id": "first"}],
+	"quiz": [{"text": "Domanda", "options": ["a", "b", "c"], "correct": [0, 2], "explanation": "Perché"}]}]}]}],
+	"forms": [{"id": "form", "name": "Modulo", "screens": [{"id": "one", "name": "Uno", "items": [{"type": "single_choice",
+	"name": "kind", "label": "Tipo", "options": ["a", "b"], "required": true}]}, {"id": "two", "name": "Due",
+	"show_if": "kind == a", "items": [{"type": "text_input", "name": "more", "label": "Altro", "show_if": "kind != b"}]}]}],
+	"glossaries": [{"id": "terms", "terms": [{"term": "Two factor", "definition": "Due fattori"}]}]}`
+
+func TestWriteFile(t *testing.T) {
+	p := component.NewResourceParser()
+	if err := p.UnmarshalLocale([]byte(fullLocale)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.db")
+	if err := WriteFile("sqlite3", path, p); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for table, expected := range map[string][]string{
+		"meta":           {"schema_version 3"},
+		"locales":        {"it"},
+		"categories":     {"it cat Categoria 1 0"},
+		"subcategories":  {`it cat sub Sotto 2 ["journalist"] 0`},
+		"difficulties":   {"it cat sub beginner Facile 0"},
+		"items":          {`it cat sub beginner item Titolo Testo Sommario 1 ["activist"] 0`},
+		"checks":         {"it cat sub beginner 0 Controllo 0  first"},
+		"quiz_questions": {`it cat sub beginner 0 Domanda ["a","b","c"] [0,2] Perché`},
+		"forms":          {"it form Modulo"},
+		"form_screens":   {"it form 0 one Uno ", "it form 1 two Due kind == a"},
+		"form_inputs": {
+			`it form 0 0 single_choice kind Tipo [] ["a","b"]  0 0 0 1 `,
+			"it form 1 0 text_input more Altro [] []  0 0 0 0 kind != b",
+		},
+		"glossaries":     {"it terms"},
+		"glossary_terms": {"it terms 0 two-factor Two factor Due fattori"},
+	} {
+		rows, err := db.Query("SELECT * FROM " + table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cols, _ := rows.Columns()
+		var got []string
+		for rows.Next() {
+			var values = make([]interface{}, len(cols))
+			for i := range values {
+				values[i] = new(interface{})
+			}
+			if err := rows.Scan(values...); err != nil {
+				t.Fatal(err)
+			}
+			var fields = make([]string, len(values))
+			for i, v := range values {
+				fields[i] = fmt.Sprint(*v.(*interface{}))
+			}
+			got = append(got, strings.Join(fields, " "))
+		}
+		rows.Close()
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %q, got %q", table, expected, got)
+		}
+	}
+}
+
+func TestWriteReadyLocalesOnly(t *testing.T) {
+	p := component.NewResourceParser()
+	for _, doc := range []string{locale, `{"locale": "it", "categories": [{"id": "cat", "name": "Categoria", "order": 1}]}`} {
+		if err := p.UnmarshalLocale([]byte(doc)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ready := component.ReadyLocalesOnly("en", component.DefaultThresholds)
+	for _, tc := range []struct {
+		opts     []component.Option
+		expected []string
+	}{
+		{nil, []string{"en", "it"}},
+		{[]component.Option{ready}, []string{"en"}},
+	} {
+		path := filepath.Join(t.TempDir(), "bundle.db")
+		if err := WriteFile("sqlite3", path, p, tc.opts...); err != nil {
+			t.Fatal(err)
+		}
+		db, err := sql.Open("sqlite3", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := db.Query("SELECT locale FROM locales ORDER BY locale")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for rows.Next() {
+			var l string
+			rows.Scan(&l)
+			got = append(got, l)
+		}
+		rows.Close()
+		db.Close()
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("expected %v, got %v", tc.expected, got)
+		}
+	}
+}
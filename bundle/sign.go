@@ -0,0 +1,312 @@
+package bundle
+
+import (
+	"archive/tar"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/securityfirst/tent/component"
+)
+
+// ManifestVersion is the version of Manifest, changed for every change of its fields or hashes
+const ManifestVersion = 2
+
+// Names of the manifest and of its signature in a signed bundle
+const (
+	ManifestName  = "manifest.json"
+	SignatureName = "manifest.sig"
+)
+
+// MaxManifestSize is the largest manifest that Verify reads: it's read before its signature
+// is checked, so the limit keeps an unsigned bundle from making it allocate without bounds.
+// The signature is read up to ed25519.SignatureSize bytes.
+const MaxManifestSize = 16 << 20
+
+// Errors of Verify, a HashError is ErrTampered
+var (
+	ErrSignature = errors.New("bundle: invalid signature")
+	ErrTruncated = errors.New("bundle: truncated")
+	ErrTampered  = errors.New("bundle: tampered")
+	ErrTooLarge  = errors.New("bundle: manifest or signature too large")
+)
+
+// Manifest lists the files of a signed bundle and the components of its locales, each with
+// the hex SHA-256 of its content. The hash of a component is the one of the JSON of its value
+// in the Tree of the locale, without children: a category without subcategories, a difficulty
+// without items, but with checks and quiz, a form or an item as they are.
+type Manifest struct {
+	Version    int                          `json:"version"`
+	Files      map[string]ManifestFile      `json:"files"`
+	Components map[string]map[string]string `json:"components"` // by locale, then tree path
+}
+
+// ManifestFile is a file of a signed bundle
+type ManifestFile struct {
+	Hash   string `json:"hash"`
+	Size   int64  `json:"size"`
+	Locale string `json:"locale,omitempty"` // set for the JSON of the Tree of a locale
+}
+
+// HashError is returned by Verify for a file, or a component of a file, that doesn't match
+// its hash in the manifest
+type HashError struct {
+	File      string
+	Component string // tree path, empty for the whole file
+}
+
+func (e HashError) Error() string {
+	if e.Component != "" {
+		return fmt.Sprintf("bundle: %s: component %s does not match the manifest", e.File, e.Component)
+	}
+	return fmt.Sprintf("bundle: %s does not match the manifest", e.File)
+}
+
+// Is makes a HashError match ErrTampered
+func (e HashError) Is(target error) bool { return target == ErrTampered }
+
+// Signed is the content of a verified bundle
+type Signed struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Sign writes a signed bundle to w: a tar archive with the manifest, its Ed25519 signature,
+// then the files sorted by name. The manifest has the hashes and sizes of the files and the
+// hashes of the components of every locale of the parser, archived ones excluded, or the ones
+// selected by the options, see Write. A file named after one of those locales, like en.json,
+// is marked as the JSON of its Tree.
+func Sign(w io.Writer, files map[string][]byte, p *component.ResourceParser, key ed25519.PrivateKey, opts ...component.Option) error {
+	m := Manifest{Version: ManifestVersion, Files: make(map[string]ManifestFile), Components: make(map[string]map[string]string)}
+	var names []string
+	for name, b := range files {
+		if name == ManifestName || name == SignatureName {
+			return fmt.Errorf("bundle: reserved file name %q", name)
+		}
+		m.Files[name] = ManifestFile{Hash: hash(b), Size: int64(len(b))}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, l := range p.Locales(opts...) {
+		tree, err := p.Export(l)
+		if err != nil {
+			return err
+		}
+		m.Components[l] = componentHashes(tree)
+		if f, ok := m.Files[l+".json"]; ok {
+			f.Locale = l
+			m.Files[l+".json"] = f
+		}
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	t := tar.NewWriter(w)
+	put := func(name string, b []byte) error {
+		if err := t.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(b))}); err != nil {
+			return err
+		}
+		_, err := t.Write(b)
+		return err
+	}
+	if err := put(ManifestName, manifest); err != nil {
+		return err
+	}
+	if err := put(SignatureName, ed25519.Sign(key, manifest)); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := put(name, files[name]); err != nil {
+			return err
+		}
+	}
+	return t.Close()
+}
+
+// SignJSON writes a signed bundle to w with the JSON of every locale of the parser, archived
+// ones excluded, or the ones selected by the options, in a file named after the locale, like
+// en.json
+func SignJSON(w io.Writer, p *component.ResourceParser, key ed25519.PrivateKey, opts ...component.Option) error {
+	var files = make(map[string][]byte)
+	for _, l := range p.Locales(opts...) {
+		b, err := p.MarshalLocale(l)
+		if err != nil {
+			return err
+		}
+		files[l+".json"] = b
+	}
+	return Sign(w, files, p, key, opts...)
+}
+
+// Verify reads a bundle written by Sign and checks it with the public key: the signature of
+// the manifest, the size and hash of every file, and the ones of the components of the files
+// marked as the JSON of a locale.
+// It returns ErrSignature for a manifest not signed by the key, ErrTruncated for a bundle
+// that ends before its last file, ErrTooLarge for a manifest above MaxManifestSize and an
+// error that is ErrTampered for any other difference.
+func Verify(r io.Reader, pub ed25519.PublicKey) (*Signed, error) {
+	t := tar.NewReader(r)
+	manifest, err := next(t, ManifestName, MaxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := next(t, SignatureName, ed25519.SignatureSize)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pub, manifest, sig) {
+		return nil, ErrSignature
+	}
+	var s Signed
+	if err := json.Unmarshal(manifest, &s.Manifest); err != nil {
+		return nil, fmt.Errorf("bundle: manifest: %v", err)
+	}
+	if v := s.Manifest.Version; v != ManifestVersion {
+		return nil, fmt.Errorf("bundle: manifest version %d, %d expected", v, ManifestVersion)
+	}
+	s.Files = make(map[string][]byte)
+	for {
+		h, err := t.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, truncated(err)
+		}
+		f, ok := s.Manifest.Files[h.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s not in the manifest", ErrTampered, h.Name)
+		}
+		if _, ok := s.Files[h.Name]; ok {
+			return nil, fmt.Errorf("%w: %s repeated", ErrTampered, h.Name)
+		}
+		// checked before reading, so the size of the header cannot make it allocate more
+		if h.Size != f.Size {
+			return nil, fmt.Errorf("%w: %s has %d bytes, %d expected", ErrTampered, h.Name, h.Size, f.Size)
+		}
+		b, err := ioutil.ReadAll(t)
+		if err != nil {
+			return nil, truncated(err)
+		}
+		if hash(b) != f.Hash {
+			return nil, HashError{File: h.Name}
+		}
+		s.Files[h.Name] = b
+	}
+	if len(s.Files) != len(s.Manifest.Files) {
+		return nil, ErrTruncated
+	}
+	for name, f := range s.Manifest.Files {
+		if f.Locale == "" {
+			continue
+		}
+		if err := s.Manifest.check(name, f.Locale, s.Files[name]); err != nil {
+			return nil, err
+		}
+	}
+	return &s, nil
+}
+
+// check compares the components of the JSON of a locale with their hashes
+func (m *Manifest) check(name, locale string, b []byte) error {
+	var tree component.Tree
+	if err := json.Unmarshal(b, &tree); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrTampered, name, err)
+	}
+	if tree.Locale != locale {
+		return fmt.Errorf("%w: %s has locale %q, %q expected", ErrTampered, name, tree.Locale, locale)
+	}
+	expected := m.Components[locale]
+	got := componentHashes(&tree)
+	for path, sum := range got {
+		if expected[path] != sum {
+			return HashError{File: name, Component: path}
+		}
+	}
+	for path := range expected {
+		if _, ok := got[path]; !ok {
+			return HashError{File: name, Component: path}
+		}
+	}
+	return nil
+}
+
+// next reads the next file of the archive, that must have the name and at most max bytes
+func next(t *tar.Reader, name string, max int64) ([]byte, error) {
+	h, err := t.Next()
+	if err == io.EOF {
+		return nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, truncated(err)
+	}
+	if h.Name != name {
+		return nil, fmt.Errorf("%w: %s found, %s expected", ErrTampered, h.Name, name)
+	}
+	if h.Size > max {
+		return nil, fmt.Errorf("%w: %s has %d bytes", ErrTooLarge, name, h.Size)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(t, max+1))
+	if err != nil {
+		return nil, truncated(err)
+	}
+	if int64(len(b)) > max {
+		return nil, fmt.Errorf("%w: %s", ErrTooLarge, name)
+	}
+	return b, nil
+}
+
+// truncated returns ErrTruncated for an archive that ends too soon, and marks any other
+// error of the reader as ErrTampered
+func truncated(err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	}
+	return fmt.Errorf("%w: %v", ErrTampered, err)
+}
+
+// componentHashes returns the hashes of the components of the tree, by tree path
+func componentHashes(t *component.Tree) map[string]string {
+	var m = make(map[string]string)
+	add := func(path string, v interface{}) {
+		b, _ := json.Marshal(v)
+		m[path] = hash(b)
+	}
+	for _, c := range t.Categories {
+		subs := c.Subcategories
+		c.Subcategories = nil
+		add(c.ID, c)
+		for _, s := range subs {
+			diffs := s.Difficulties
+			s.Difficulties = nil
+			add(c.ID+"/"+s.ID, s)
+			for _, d := range diffs {
+				items := d.Items
+				d.Items = nil
+				add(c.ID+"/"+s.ID+"/"+d.ID, d)
+				for _, i := range items {
+					add(c.ID+"/"+s.ID+"/"+d.ID+"/"+i.ID, i)
+				}
+			}
+		}
+	}
+	for _, f := range t.Forms {
+		add("forms/"+f.ID, f)
+	}
+	for _, g := range t.Glossaries {
+		add("glossary/"+g.ID, g)
+	}
+	return m
+}
+
+func hash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,157 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/securityfirst/tent/component"
+)
+
+func TestSignVerify(t *testing.T) {
+	p := component.NewResourceParser()
+	if err := p.UnmarshalLocale([]byte(locale)); err != nil {
+		t.Fatal(err)
+	}
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := SignJSON(&b, p, key); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Verify(bytes.NewReader(b.Bytes()), pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if json, _ := p.MarshalLocale("en"); !bytes.Equal(s.Files["en.json"], json) || len(s.Files) != 1 {
+		t.Errorf("unexpected files %v", s.Files)
+	}
+	var paths []string
+	for path := range s.Manifest.Components["en"] {
+		paths = append(paths, path)
+	}
+	if len(paths) != 5 {
+		t.Errorf("unexpected components %v", paths)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if _, err := Verify(bytes.NewReader(b.Bytes()), other); err != ErrSignature {
+		t.Errorf("other key: %v", err)
+	}
+	if _, err := Verify(bytes.NewReader(b.Bytes()[:b.Len()-1500]), pub); err != ErrTruncated {
+		t.Errorf("truncated: %v", err)
+	}
+	tampered := bytes.Replace(b.Bytes(), []byte(`"Body"`), []byte(`"Evil"`), 1)
+	if _, err := Verify(bytes.NewReader(tampered), pub); !errors.Is(err, ErrTampered) || err != (HashError{File: "en.json"}) {
+		t.Errorf("tampered: %v", err)
+	}
+
+	// a file added after the signed ones
+	var extra bytes.Buffer
+	r, w := tar.NewReader(bytes.NewReader(b.Bytes())), tar.NewWriter(&extra)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		w.WriteHeader(h)
+		io.Copy(w, r)
+	}
+	w.WriteHeader(&tar.Header{Name: "extra.json", Mode: 0644, Size: 2})
+	w.Write([]byte("{}"))
+	w.Close()
+	if _, err := Verify(&extra, pub); !errors.Is(err, ErrTampered) || !strings.Contains(err.Error(), "extra.json") {
+		t.Errorf("extra file: %v", err)
+	}
+
+	// a manifest whose components don't match the JSON
+	var m Manifest
+	m.Components = map[string]map[string]string{"en": {"cat": "00"}}
+	if err := m.check("en.json", "en", s.Files["en.json"]); err == nil || !errors.Is(err, ErrTampered) {
+		t.Errorf("component: %v", err)
+	}
+	if err := s.Manifest.check("en.json", "it", s.Files["en.json"]); err == nil || !errors.Is(err, ErrTampered) {
+		t.Errorf("locale: %v", err)
+	}
+
+	// a file with a size other than the one in the manifest
+	var resized bytes.Buffer
+	r, w = tar.NewReader(bytes.NewReader(b.Bytes())), tar.NewWriter(&resized)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		body, _ := ioutil.ReadAll(r)
+		if h.Name == "en.json" {
+			body = append(body, ' ')
+			h.Size++
+		}
+		w.WriteHeader(h)
+		w.Write(body)
+	}
+	w.Close()
+	if _, err := Verify(&resized, pub); !errors.Is(err, ErrTampered) || !strings.Contains(err.Error(), "bytes") {
+		t.Errorf("size: %v", err)
+	}
+
+	// only the JSON of the locales is checked as a tree
+	files := map[string][]byte{"en.json": s.Files["en.json"], "config.json": []byte("[]")}
+	b.Reset()
+	if err := Sign(&b, files, p, key); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Verify(bytes.NewReader(b.Bytes()), pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := s.Manifest.Files; f["en.json"].Locale != "en" || f["config.json"].Locale != "" || f["config.json"].Size != 2 {
+		t.Errorf("unexpected manifest files %v", f)
+	}
+
+	if err := Sign(&b, map[string][]byte{ManifestName: nil}, p, key); err == nil {
+		t.Error("reserved name: no error")
+	}
+
+	// a locale that is not ready is left out
+	if err := p.UnmarshalLocale([]byte(`{"locale": "it", "categories": [{"id": "cat", "name": "Categoria", "order": 1}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	if err := SignJSON(&b, p, key, component.ReadyLocalesOnly("en", component.DefaultThresholds)); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Verify(bytes.NewReader(b.Bytes()), pub); err != nil || len(s.Files) != 1 || len(s.Manifest.Components) != 1 {
+		t.Errorf("ready locales: %v %v", s, err)
+	}
+}
+
+func TestVerifyTooLarge(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the headers are written, the sizes are rejected before reading the content
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	w.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0644, Size: MaxManifestSize + 1})
+	if _, err := Verify(bytes.NewReader(b.Bytes()), pub); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("manifest: %v", err)
+	}
+
+	b.Reset()
+	w = tar.NewWriter(&b)
+	w.WriteHeader(&tar.Header{Name: ManifestName, Mode: 0644, Size: 2})
+	w.Write([]byte("{}"))
+	w.WriteHeader(&tar.Header{Name: SignatureName, Mode: 0644, Size: ed25519.SignatureSize + 1})
+	if _, err := Verify(bytes.NewReader(b.Bytes()), pub); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("signature: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package component
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// assetScheme is the prefix of the links to an asset by ID, like [the guide](asset:guide.pdf)
+const assetScheme = "asset:"
+
+var assetLink = regexp.MustCompile(`(!?)\[[^\]]*\]\(\s*([^)\s]+)`)
+
+// assetRefs adds to list the IDs of the assets referenced by text that are not in seen: images,
+// by the file name of their path, and links to asset:ID, in order of appearance
+func assetRefs(text string, seen map[string]bool, list []string) []string {
+	for _, m := range assetLink.FindAllStringSubmatch(text, -1) {
+		ref := m[2]
+		if !strings.HasPrefix(ref, assetScheme) && (m[1] == "" || isExternal(ref)) {
+			continue
+		}
+		if id := path.Base(strings.TrimPrefix(ref, assetScheme)); !seen[id] {
+			seen[id] = true
+			list = append(list, id)
+		}
+	}
+	return list
+}
+
+// AssetRefs returns the IDs of the assets referenced by the body, images and asset: links,
+// without repetitions (notes excluded)
+func (i *Item) AssetRefs() []string {
+	return assetRefs(stripBodyNotes(i.Body), make(map[string]bool), nil)
+}
+
+// AssetRefs returns the IDs of the assets referenced by the checks, see Item.AssetRefs
+func (c *Checklist) AssetRefs() []string {
+	var (
+		seen = make(map[string]bool)
+		list []string
+	)
+	for _, v := range c.Checks {
+		list = assetRefs(stripNotes(v.Text), seen, list)
+	}
+	return list
+}
+
+// Assets returns the IDs of the assets referenced by the items and checks of the category,
+// in tree order without repetitions, so a client can fetch them before showing it
+func (c *Category) Assets() []string {
+	var (
+		seen = make(map[string]bool)
+		list []string
+	)
+	walkCategory(c, func(cmp Component) {
+		switch v := cmp.(type) {
+		case *Item:
+			list = assetRefs(stripBodyNotes(v.Body), seen, list)
+		case *Checklist:
+			for _, check := range v.Checks {
+				list = assetRefs(stripNotes(check.Text), seen, list)
+			}
+		}
+	})
+	return list
+}
+
+// AssetRoot sets the directory of the file system containing the assets
+func AssetRoot(dir string) Option { return func(o *options) { o.assetRoot = dir } }
+
+// ValidateAssets checks the images and asset: links of the items of a locale against the files
+// of fsys, an asset: link referring to the file with its ID in the asset root, reporting
+// missing files, files found only with a different case and empty files.
+// Files under the asset root that no item of any locale references are reported too, with
+// their path under assets/. References outside the asset root are reported, external URLs
+// are ignored.
+func (r *ResourceParser) ValidateAssets(fsys fs.FS, locale string, opts ...Option) []Problem {
+	var (
+		o          = newOptions(opts)
+		root       = path.Clean("./" + o.assetRoot)
+		referenced = make(map[string]bool)
+		problems   []Problem
+	)
+	for l, cats := range r.categories {
+		for _, cat := range cats {
+			walkCategory(cat, func(c Component) {
+				item, ok := c.(*Item)
+				if !ok {
+					return
+				}
+				for _, ref := range itemAssetPaths(item) {
+					// the other locales only count for the files that are not referenced
+					if msg := checkAsset(fsys, root, ref, referenced); msg != "" && l == locale {
+						problems = append(problems, Problem{Path: treePath(item), Locale: locale, Message: msg})
+					}
+				}
+			})
+		}
+	}
+	fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || referenced[p] {
+			return nil
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		problems = append(problems, Problem{Path: "assets/" + rel, Locale: locale, Message: fmt.Sprintf("asset %q is not referenced", rel)})
+		return nil
+	})
+	return problems
+}
+
+// itemAssetPaths returns the paths of the local images of the item and the IDs of its asset: links
+func itemAssetPaths(item *Item) []string {
+	var refs []string
+	for _, img := range item.Images() {
+		if !isExternal(img.Path) && !strings.HasPrefix(img.Path, assetScheme) {
+			refs = append(refs, img.Path)
+		}
+	}
+	for _, m := range assetLink.FindAllStringSubmatch(stripBodyNotes(item.Body), -1) {
+		if strings.HasPrefix(m[2], assetScheme) {
+			refs = append(refs, strings.TrimPrefix(m[2], assetScheme))
+		}
+	}
+	return refs
+}
+
+func isExternal(ref string) bool {
+	return strings.Contains(ref, "://") || strings.HasPrefix(ref, "data:")
+}
+
+// checkAsset resolves a reference, marks the file found as referenced and describes its problem
+func checkAsset(fsys fs.FS, root, ref string, referenced map[string]bool) string {
+	name := path.Join(root, strings.TrimPrefix(ref, "/"))
+	if !fs.ValidPath(name) || root != "." && name != root && !strings.HasPrefix(name, root+"/") {
+		return fmt.Sprintf("asset %q is outside the asset root", ref)
+	}
+	found := resolveFold(fsys, name)
+	if found == "" {
+		return fmt.Sprintf("asset %q not found", ref)
+	}
+	referenced[found] = true
+	if found != name {
+		return fmt.Sprintf("asset %q found as %q, case differs", ref, strings.TrimPrefix(strings.TrimPrefix(found, root), "/"))
+	}
+	info, err := fs.Stat(fsys, name)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("asset %q: %v", ref, err)
+	case info.IsDir():
+		return fmt.Sprintf("asset %q is a directory", ref)
+	case info.Size() == 0:
+		return fmt.Sprintf("asset %q is empty", ref)
+	}
+	return ""
+}
+
+// resolveFold returns the path of the file matching name ignoring case, preferring the exact
+// case of each element, or an empty string if there's none
+func resolveFold(fsys fs.FS, name string) string {
+	if _, err := fs.Stat(fsys, name); err == nil {
+		return name
+	}
+	return foldMatch(fsys, ".", strings.Split(name, "/"))
+}
+
+func foldMatch(fsys fs.FS, dir string, elems []string) string {
+	if len(elems) == 0 {
+		return dir
+	}
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return ""
+	}
+	var candidates []string
+	for _, e := range entries {
+		switch {
+		case e.Name() == elems[0]:
+			candidates = append([]string{e.Name()}, candidates...)
+		case strings.EqualFold(e.Name(), elems[0]):
+			candidates = append(candidates, e.Name())
+		}
+	}
+	for _, name := range candidates {
+		if found := foldMatch(fsys, path.Join(dir, name), elems[1:]); found != "" {
+			return found
+		}
+	}
+	return ""
+}
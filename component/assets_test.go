@@ -0,0 +1,91 @@
+package component
+
+import (
+	"testing/fstest"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestValidateAssets(c *C) {
+	p := NewResourceParser()
+	cat := testCategory("en", "")
+	cat.Sub("sub").Difficulty("beginner").AddItem(
+		&Item{ID: "one", Title: "One", Body: "![Logo](img/logo.png)\n\n![Map](img/Map.png)"},
+		&Item{ID: "two", Title: "Two", Body: "![Empty](/img/empty.png) ![Gone](img/gone.png) ![Web](https://example.com/a.png)"},
+		&Item{ID: "three", Title: "Three", Body: "![Up](../secret.png)"},
+	)
+	p.categories["en"] = []*Category{cat}
+
+	fsys := fstest.MapFS{
+		"content/img/logo.png":   {Data: []byte("png")},
+		"content/IMG/map.png":    {Data: []byte("png")},
+		"content/img/empty.png":  {Data: []byte{}},
+		"content/img/unused.png": {Data: []byte("png")},
+		"other.png":              {Data: []byte("png")},
+	}
+	var messages []string
+	for _, pr := range p.ValidateAssets(fsys, "en", AssetRoot("content")) {
+		c.Assert(pr.Locale, Equals, "en")
+		messages = append(messages, pr.Path+": "+pr.Message)
+	}
+	c.Assert(messages, DeepEquals, []string{
+		`cat/sub/beginner/one: asset "img/Map.png" found as "IMG/map.png", case differs`,
+		`cat/sub/beginner/two: asset "/img/empty.png" is empty`,
+		`cat/sub/beginner/two: asset "img/gone.png" not found`,
+		`cat/sub/beginner/three: asset "../secret.png" is outside the asset root`,
+		`assets/img/unused.png: asset "img/unused.png" is not referenced`,
+	})
+
+	// without a root the references leave the file system
+	messages = nil
+	for _, pr := range p.ValidateAssets(fsys, "en") {
+		messages = append(messages, pr.Message)
+	}
+	c.Assert(messages, HasLen, 10)
+	c.Assert(messages[4], Equals, `asset "../secret.png" is outside the asset root`)
+	c.Assert(p.ValidateAssets(fsys, "it"), HasLen, 5)
+
+	// the files used by another locale are referenced
+	c.Assert(p.ValidateAssets(fsys, "it", AssetRoot("content")), DeepEquals, []Problem{
+		{Path: "assets/img/unused.png", Locale: "it", Message: `asset "img/unused.png" is not referenced`},
+	})
+}
+
+func (CmpSuite) TestAssetRefs(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.AddItem(
+		&Item{ID: "one", Title: "One", Body: "![Logo](img/logo.png) [the guide](asset:guide.pdf)\n\n" +
+			"![Web](https://example.com/a.png) [site](https://example.com) [[note: ![Old](old.png)]]"},
+		&Item{ID: "two", Title: "Two", Body: "![Logo](../assets/logo.png) [again]( asset:guide.pdf ) ![Map](asset:map.png)"},
+	)
+	diff.AddChecks(Check{Text: "Print [the form](asset:form.pdf)"}, Check{Text: "Read [the guide](asset:guide.pdf)"})
+
+	c.Assert(diff.Item("one").AssetRefs(), DeepEquals, []string{"logo.png", "guide.pdf"})
+	c.Assert(diff.Item("two").AssetRefs(), DeepEquals, []string{"logo.png", "guide.pdf", "map.png"})
+	c.Assert(diff.Checks().AssetRefs(), DeepEquals, []string{"form.pdf", "guide.pdf"})
+	c.Assert(cat.Assets(), DeepEquals, []string{"logo.png", "guide.pdf", "map.png", "form.pdf"})
+	c.Assert(testCategory("en", "").Assets(), HasLen, 0)
+
+	// asset: links refer to the asset root
+	p := NewResourceParser()
+	p.categories["en"] = []*Category{cat}
+	fsys := fstest.MapFS{
+		"assets/img/logo.png": {Data: []byte("png")},
+		"assets/logo.png":     {Data: []byte("png")},
+		"assets/guide.pdf":    {Data: []byte("pdf")},
+	}
+	var messages []string
+	for _, pr := range p.ValidateAssets(fsys, "en", AssetRoot("assets")) {
+		messages = append(messages, pr.Path+": "+pr.Message)
+	}
+	c.Assert(messages, DeepEquals, []string{`cat/sub/beginner/two: asset "map.png" not found`})
+
+	for _, tc := range []struct{ id, content, expected string }{
+		{"logo.png", "", "image/png"},
+		{"guide.pdf", "", "application/pdf"},
+		{"notes", "plain text", "text/plain; charset=utf-8"},
+	} {
+		c.Assert((&Asset{ID: tc.id, Content: tc.content}).ContentType(), Equals, tc.expected)
+	}
+}
@@ -0,0 +1,106 @@
+package component
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Audience includes untagged content and content tagged with any of the tags
+func Audience(tags ...string) Option {
+	return func(o *options) { o.audience = append(o.audience, tags...) }
+}
+
+// allows tells if content with the tags is visible to the requested audience
+func (o options) allows(tags []string) bool {
+	if len(o.audience) == 0 || len(tags) == 0 {
+		return true
+	}
+	for _, a := range o.audience {
+		for _, t := range tags {
+			if a == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Filter returns a copy of the category without the content excluded by the options.
+// Difficulties, subcategories and the category itself are pruned when filtering leaves
+// them empty; it returns nil if the whole category is pruned.
+func (c *Category) Filter(opts ...Option) *Category {
+	o := newOptions(opts)
+	if len(o.audience) == 0 {
+		return c
+	}
+	var cat = *c
+	cat.subcategories = nil
+	cat.subIndex.reset()
+	for _, s := range c.subcategories {
+		if !o.allows(s.Audience) {
+			continue
+		}
+		var sub = *s
+		sub.difficulties = nil
+		sub.diffIndex.reset()
+		for _, d := range s.difficulties {
+			var diff = *d
+			diff.items = nil
+			diff.itemIndex.reset()
+			for _, i := range d.items {
+				if o.allows(i.Audience) {
+					var item = *i
+					diff.AddItem(&item)
+				}
+			}
+			if len(d.items) != 0 && len(diff.items) == 0 {
+				continue
+			}
+			sub.AddDifficulty(&diff)
+		}
+		if len(s.difficulties) != 0 && len(sub.difficulties) == 0 {
+			continue
+		}
+		cat.Add(&sub)
+	}
+	if len(c.subcategories) != 0 && len(cat.subcategories) == 0 {
+		return nil
+	}
+	return &cat
+}
+
+// CheckAudience returns a problem for each tag that is not in the allowed ones
+func (r *ResourceParser) CheckAudience(allowed ...string) []Problem {
+	var (
+		valid    = make(map[string]bool, len(allowed))
+		problems []Problem
+	)
+	for _, t := range allowed {
+		valid[t] = true
+	}
+	check := func(c Component, locale string, tags []string) {
+		for _, t := range tags {
+			if !valid[t] {
+				problems = append(problems, Problem{Path: treePath(c), Locale: locale, Message: fmt.Sprintf("unknown audience %q", t)})
+			}
+		}
+	}
+	var locales []string
+	for l := range r.categories {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	for _, l := range locales {
+		for _, cat := range r.categories[l] {
+			walkCategory(cat, func(c Component) {
+				switch v := c.(type) {
+				case *Subcategory:
+					check(v, l, v.Audience)
+				case *Item:
+					check(v, l, v.Audience)
+				}
+			})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,78 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func audienceCategory() *Category {
+	cat := testCategory("en", "")
+	cat.Add(&Subcategory{ID: "local", Name: "Local", Audience: []string{"kenya"}})
+	cat.Add(&Subcategory{ID: "mixed", Name: "Mixed"})
+	cat.Sub("local").AddDifficulty(&Difficulty{ID: "beginner"})
+	cat.Sub("mixed").AddDifficulty(&Difficulty{ID: "beginner"}, &Difficulty{ID: "expert"})
+	cat.Sub("sub").Difficulty("beginner").AddItem(
+		&Item{ID: "all", Title: "All"},
+		&Item{ID: "both", Title: "Both", Audience: []string{"kenya", "uganda"}},
+	)
+	cat.Sub("local").Difficulty("beginner").AddItem(&Item{ID: "item", Title: "Item"})
+	cat.Sub("mixed").Difficulty("beginner").AddItem(&Item{ID: "uganda", Title: "Uganda", Audience: []string{"uganda"}})
+	cat.Sub("mixed").Difficulty("expert").AddItem(&Item{ID: "kenya", Title: "Kenya", Audience: []string{"kenya"}})
+	return cat
+}
+
+func paths(cat *Category) []string {
+	var list []string
+	walkCategory(cat, func(c Component) {
+		if _, ok := c.(*Item); ok {
+			list = append(list, treePath(c))
+		}
+	})
+	return list
+}
+
+func (CmpSuite) TestAudienceFilter(c *C) {
+	cat := audienceCategory()
+	c.Assert(cat.Filter(), Equals, cat)
+	c.Assert(paths(cat.Filter(Audience("kenya"))), DeepEquals, []string{
+		"cat/sub/beginner/all", "cat/sub/beginner/both", "cat/local/beginner/item", "cat/mixed/expert/kenya",
+	})
+	uganda := cat.Filter(Audience("uganda"))
+	c.Assert(paths(uganda), DeepEquals, []string{
+		"cat/sub/beginner/all", "cat/sub/beginner/both", "cat/mixed/beginner/uganda",
+	})
+	c.Assert(uganda.Subcategories(), DeepEquals, []string{"sub", "mixed"})
+	c.Assert(uganda.Sub("mixed").DifficultyNames(), DeepEquals, []string{"beginner"})
+	c.Assert(paths(cat.Filter(Audience("kenya", "uganda"))), HasLen, 5)
+	c.Assert(paths(cat), HasLen, 5)
+
+	var empty = &Category{ID: "empty"}
+	empty.Add(&Subcategory{ID: "sub", Audience: []string{"kenya"}})
+	c.Assert(empty.Filter(Audience("uganda")), IsNil)
+}
+
+func (CmpSuite) TestAudienceMeta(c *C) {
+	var item Item
+	c.Assert(item.SetContents("[Title]: # (Title)\n[Order]: # (1)\n[Audience]: # (kenya;uganda)\n\nBody"), IsNil)
+	c.Assert(item.Audience, DeepEquals, []string{"kenya", "uganda"})
+	c.Assert(item.Contents(), Equals, "[Title]: # (Title)\n[Order]: # (1)\n[Audience]: # (kenya;uganda)\n\nBody")
+
+	cat := audienceCategory()
+	p := NewResourceParser()
+	sub, both := cat.Sub("local"), cat.Sub("sub").Difficulty("beginner").Item("both")
+	c.Assert(p.Parse(sub, &Resource{Content: []map[string]string{{"name": "Locale", "audience": "uganda"}}}, "it"), IsNil)
+	c.Assert(p.Parse(both, &Resource{Content: []map[string]string{{"title": "Entrambi", "body": "Testo"}}}, "it"), IsNil)
+	c.Assert(p.category("cat", "it").Sub("local").Audience, DeepEquals, []string{"kenya"})
+	c.Assert(p.category("cat", "it").Sub("sub").Difficulty("beginner").Item("both").Audience, DeepEquals, []string{"kenya", "uganda"})
+	c.Assert(both.Resource().Content[0]["audience"], Equals, "kenya;uganda")
+	local := p.category("cat", "it").Sub("local")
+	local.Audience[0] = "changed"
+	c.Assert(sub.Audience, DeepEquals, []string{"kenya"})
+	local.Audience[0] = "kenya"
+
+	p.categories["en"] = []*Category{cat}
+	c.Assert(p.CheckAudience("kenya"), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/both", Locale: "en", Message: `unknown audience "uganda"`},
+		{Path: "cat/mixed/beginner/uganda", Locale: "en", Message: `unknown audience "uganda"`},
+		{Path: "cat/sub/beginner/both", Locale: "it", Message: `unknown audience "uganda"`},
+	})
+}
@@ -0,0 +1,328 @@
+package component
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/securityfirst/tent/kvfile"
+)
+
+// BatchKeys is the number of batch keys remembered by ParseAllIdempotent
+const BatchKeys = 64
+
+var (
+	ErrKeyReplayMismatch = errors.New("Batch key used with a different batch")
+	ErrParentFailed      = errors.New("Parent failed")
+	ErrNoResource        = errors.New("No resource")
+)
+
+// ImportSummary is the outcome of a batch applied by ParseAllIdempotent
+type ImportSummary struct {
+	Fingerprint string `json:"fingerprint"`
+	Applied     int    `json:"applied"`
+	Failed      int    `json:"failed"`
+	Quarantined int    `json:"quarantined"` // skipped, see WithQuarantine
+	Skipped     int    `json:"skipped"`     // not parsed because a parent failed
+	Replayed    bool   `json:"replayed"`    // the batch had already been applied
+}
+
+// ImportError is a request of a batch that failed, see ParseAll
+type ImportError struct {
+	Type   string `json:"type"` // type of the component
+	Path   string `json:"path"` // IDs of the component and its parents
+	Locale string `json:"locale"`
+	Parent string `json:"parent,omitempty"` // path of the failed parent, if skipped
+	Err    error  `json:"-"`                // the cause, ErrParentFailed if skipped
+}
+
+func (e *ImportError) Error() string {
+	if e.Parent != "" {
+		return fmt.Sprintf("%s (%s): skipped, %s failed", e.Path, e.Locale, e.Parent)
+	}
+	return fmt.Sprintf("%s (%s): %v", e.Path, e.Locale, e.Err)
+}
+
+func (e *ImportError) Unwrap() error { return e.Err }
+
+// MarshalJSON adds the message of the cause to the fields, empty without a cause, and its row
+// for a *ParseError, so a list of errors is a complete report
+func (e *ImportError) MarshalJSON() ([]byte, error) {
+	var m = map[string]interface{}{
+		"type":    e.Type,
+		"path":    e.Path,
+		"locale":  e.Locale,
+		"message": "",
+	}
+	if e.Err != nil {
+		m["message"] = e.Err.Error()
+	}
+	if e.Parent != "" {
+		m["parent"] = e.Parent
+	}
+	var p *ParseError
+	if errors.As(e.Err, &p) {
+		m["row"] = p.Row
+	}
+	return json.Marshal(m)
+}
+
+// ParseAll parses every request of the batch, in order, returning an *ImportError for each
+// one that fails. Requests of a component whose parent failed for the same locale earlier in
+// the batch are not parsed, and are reported once each as skipped with ErrParentFailed.
+func (r *ResourceParser) ParseAll(batch []ParseRequest, opts ...Option) (ImportSummary, []error) {
+	b := newBatchState(opts)
+	for _, req := range batch {
+		b.parse(r, req)
+	}
+	r.sortParsed(b.locales)
+	r.notifyComplete()
+	b.summary.Fingerprint = batchFingerprint(batch)
+	return b.summary, b.errs
+}
+
+// batchState is the progress of ParseAll or ParseStream
+type batchState struct {
+	o       options
+	summary ImportSummary
+	errs    []error
+	failed  map[[2]string]bool
+	locales map[string]bool // locales of the requests, see SetSorted
+}
+
+func newBatchState(opts []Option) *batchState {
+	return &batchState{o: newOptions(opts), failed: make(map[[2]string]bool), locales: make(map[string]bool)}
+}
+
+// parse parses a request of the batch, and reports it to the progress callback
+func (b *batchState) parse(r *ResourceParser, req ParseRequest) {
+	b.locales[req.Locale] = true
+	err := b.apply(r, req)
+	if b.o.progress == nil {
+		return
+	}
+	s := b.summary
+	b.o.progress(StreamProgress{
+		Parsed: s.Applied + s.Failed + s.Skipped + s.Quarantined,
+		Path:   treePath(req.Component), Locale: req.Locale, Err: err,
+	})
+}
+
+// apply parses a request of the batch, it returns its *ImportError, if any
+func (b *batchState) apply(r *ResourceParser, req ParseRequest) error {
+	path := treePath(req.Component)
+	if parent := failedParent(b.failed, path, req.Locale); parent != "" {
+		err := &ImportError{
+			Type: cmpType(req.Component), Path: path, Locale: req.Locale,
+			Parent: parent, Err: ErrParentFailed,
+		}
+		b.errs = append(b.errs, err)
+		b.summary.Skipped++
+		return err
+	}
+	if req.Resource == nil {
+		err := &ImportError{Type: cmpType(req.Component), Path: path, Locale: req.Locale, Err: ErrNoResource}
+		b.errs = append(b.errs, err)
+		b.failed[[2]string{path, req.Locale}] = true
+		b.summary.Failed++
+		return err
+	}
+	var hash string
+	if b.o.quarantine != nil {
+		hash = batchFingerprint([]ParseRequest{req})
+		if e, ok := b.o.quarantine.Get(path, req.Locale); ok && e.Hash == hash && e.Failures >= QuarantineAfter {
+			r.warn(req.Component, req.Locale, "quarantined after %d failures: %s", e.Failures, e.Error)
+			b.summary.Quarantined++
+			b.failed[[2]string{path, req.Locale}] = true
+			return nil
+		}
+	}
+	var err, result error
+	if b.o.roundTrip {
+		err = r.checkRoundTrip(req)
+	}
+	if err == nil {
+		err = r.Parse(req.Component, req.Resource, req.Locale)
+	}
+	if err == nil {
+		b.summary.Applied++
+	} else {
+		result = &ImportError{Type: cmpType(req.Component), Path: path, Locale: req.Locale, Err: err}
+		b.errs = append(b.errs, result)
+		b.failed[[2]string{path, req.Locale}] = true
+		b.summary.Failed++
+	}
+	if b.o.quarantine != nil {
+		if qerr := r.quarantine(b.o.quarantine, path, req.Locale, hash, err); qerr != nil {
+			b.errs = append(b.errs, qerr)
+		}
+	}
+	return result
+}
+
+// failedParent returns the path of the nearest parent of the path that failed for the locale
+func failedParent(failed map[[2]string]bool, path, locale string) string {
+	for i := strings.LastIndex(path, "/"); i > 0; i = strings.LastIndex(path, "/") {
+		path = path[:i]
+		if failed[[2]string{path, locale}] {
+			return path
+		}
+	}
+	return ""
+}
+
+// quarantine records the outcome of parsing a component: a failure with the same hash
+// increases the count, a success clears it
+func (r *ResourceParser) quarantine(q QuarantineStore, path, locale, hash string, err error) error {
+	if err == nil {
+		return q.Delete(path, locale)
+	}
+	e, ok := q.Get(path, locale)
+	if !ok || e.Hash != hash {
+		e = QuarantineEntry{Path: path, Locale: locale, Hash: hash}
+	}
+	e.Failures++
+	e.Error = err.Error()
+	return q.Put(e)
+}
+
+// ParseAllIdempotent parses the batch like ParseAll, unless a batch with the same key was already
+// applied: then it returns the summary of the first attempt without parsing again, or
+// ErrKeyReplayMismatch if the batch is different. The parser remembers the last BatchKeys keys,
+// see SetBatchStore to keep them across restarts.
+func (r *ResourceParser) ParseAllIdempotent(key string, batch []ParseRequest, opts ...Option) (ImportSummary, []error) {
+	if s, ok := r.batch(key); ok {
+		if s.Fingerprint != batchFingerprint(batch) {
+			return ImportSummary{}, []error{ErrKeyReplayMismatch}
+		}
+		var errs []error
+		if err := r.saveBatch(key); err != nil {
+			errs = append(errs, err)
+		}
+		s.Replayed = true
+		return s, errs
+	}
+	s, errs := r.ParseAll(batch, opts...)
+	if err := r.addBatch(key, s); err != nil {
+		errs = append(errs, err)
+	}
+	return s, errs
+}
+
+// batchRecord is a batch key saved in the store of SetBatchStore
+type batchRecord struct {
+	Seq     uint64        `json:"seq"` // higher is more recent
+	Summary ImportSummary `json:"summary"`
+}
+
+// SetBatchStore keeps the batch keys of ParseAllIdempotent in db, so a restarted service still
+// recognizes the batches it applied. The keys in db replace the ones of the parser.
+func (r *ResourceParser) SetBatchStore(db *kvfile.DB) error {
+	var (
+		keys    []string
+		records = make(map[string]batchRecord)
+		err     error
+	)
+	db.Range(func(k string, b []byte) bool {
+		var rec batchRecord
+		if err = json.Unmarshal(b, &rec); err != nil {
+			err = fmt.Errorf("Batch %q: %v", k, err)
+			return false
+		}
+		keys, records[k] = append(keys, k), rec
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(keys, func(i, j int) bool { return records[keys[i]].Seq < records[keys[j]].Seq })
+	for len(keys) > BatchKeys {
+		if err := db.Delete(keys[0]); err != nil {
+			return err
+		}
+		keys = keys[1:]
+	}
+	r.batchDB, r.batchSeq = db, 0
+	r.batches, r.batchKeys = make(map[string]ImportSummary), keys
+	for _, k := range keys {
+		r.batches[k] = records[k].Summary
+		r.batchSeq = records[k].Seq
+	}
+	return nil
+}
+
+// saveBatch writes the key to the batch store, as the most recent
+func (r *ResourceParser) saveBatch(key string) error {
+	if r.batchDB == nil {
+		return nil
+	}
+	r.batchSeq++
+	b, err := json.Marshal(batchRecord{Seq: r.batchSeq, Summary: r.batches[key]})
+	if err != nil {
+		return err
+	}
+	return r.batchDB.Put(key, b)
+}
+
+// batch returns the summary of the key, marking it as the most recent
+func (r *ResourceParser) batch(key string) (ImportSummary, bool) {
+	for i, k := range r.batchKeys {
+		if k == key {
+			r.batchKeys = append(append(r.batchKeys[:i:i], r.batchKeys[i+1:]...), key)
+			return r.batches[key], true
+		}
+	}
+	return ImportSummary{}, false
+}
+
+func (r *ResourceParser) addBatch(key string, s ImportSummary) error {
+	if r.batches == nil {
+		r.batches = make(map[string]ImportSummary)
+	}
+	if len(r.batchKeys) == BatchKeys {
+		delete(r.batches, r.batchKeys[0])
+		if r.batchDB != nil {
+			if err := r.batchDB.Delete(r.batchKeys[0]); err != nil {
+				return err
+			}
+		}
+		r.batchKeys = r.batchKeys[1:]
+	}
+	r.batchKeys = append(r.batchKeys, key)
+	r.batches[key] = s
+	return r.saveBatch(key)
+}
+
+// batchFingerprint hashes the requests of the batch, in order
+func batchFingerprint(batch []ParseRequest) string {
+	h := sha1.New()
+	for _, req := range batch {
+		writeFingerprint(h, req)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// writeFingerprint writes the request to the hash of batchFingerprint
+func writeFingerprint(h io.Writer, req ParseRequest) {
+	fmt.Fprintf(h, "%s\x00%s\x00%T\x00", req.Locale, treePath(req.Component), req.Component)
+	if req.Resource == nil {
+		io.WriteString(h, "\x01")
+		return
+	}
+	for _, row := range req.Resource.Content {
+		var keys = make([]string, 0, len(row))
+		for k := range row {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(h, "%q=%q\x00", k, row[k])
+		}
+		io.WriteString(h, "\x02")
+	}
+	io.WriteString(h, "\x03")
+}
@@ -0,0 +1,191 @@
+package component
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/securityfirst/tent/kvfile"
+	. "gopkg.in/check.v1"
+)
+
+func itemBatch(title string) []ParseRequest {
+	cat := testCategory("en", "")
+	item := &Item{ID: "item", Title: "Item", Body: "a"}
+	cat.Sub("sub").Difficulty("beginner").AddItem(item)
+	return []ParseRequest{
+		{Component: cat, Resource: &Resource{Content: []map[string]string{{"name": "Categoria"}}}, Locale: "it"},
+		{Component: item, Resource: &Resource{Content: []map[string]string{{"title": title}, {"body": "uno"}}}, Locale: "it"},
+	}
+}
+
+func (CmpSuite) TestParseAllIdempotent(c *C) {
+	p := NewResourceParser()
+	batch := itemBatch("Voce")
+	s, errs := p.ParseAllIdempotent("k1", batch)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s.Applied, Equals, 2)
+	c.Assert(s.Replayed, Equals, false)
+	item := p.lookup("it", "cat/sub/beginner/item").(*Item)
+	c.Assert(item.Title, Equals, "Voce")
+
+	// a replay does not parse again
+	item.Title = "Edited"
+	again, errs := p.ParseAllIdempotent("k1", itemBatch("Voce"))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(again.Replayed, Equals, true)
+	again.Replayed = false
+	c.Assert(again, Equals, s)
+	c.Assert(item.Title, Equals, "Edited")
+
+	// same key, different batch
+	_, errs = p.ParseAllIdempotent("k1", itemBatch("Altro"))
+	c.Assert(errs, DeepEquals, []error{ErrKeyReplayMismatch})
+	c.Assert(item.Title, Equals, "Edited")
+
+	// a new key is applied, failures are counted
+	bad := itemBatch("Voce")
+	bad[1].Resource = &Resource{}
+	s, errs = p.ParseAllIdempotent("k2", bad)
+	c.Assert(s.Applied, Equals, 1)
+	c.Assert(s.Failed, Equals, 1)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `cat/sub/beginner/item \(it\): Invalid content`)
+	c.Assert(s.Fingerprint, Not(Equals), again.Fingerprint)
+}
+
+func (CmpSuite) TestParseAllIdempotentEviction(c *C) {
+	p := NewResourceParser()
+	for i := 0; i < BatchKeys; i++ {
+		p.ParseAllIdempotent(fmt.Sprint(i), itemBatch(fmt.Sprint(i)))
+	}
+	// using "0" makes "1" the least recent
+	s, _ := p.ParseAllIdempotent("0", itemBatch("0"))
+	c.Assert(s.Replayed, Equals, true)
+	p.ParseAllIdempotent("new", itemBatch("new"))
+	c.Assert(p.batchKeys, HasLen, BatchKeys)
+
+	s, errs := p.ParseAllIdempotent("1", itemBatch("other"))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s.Replayed, Equals, false)
+	s, errs = p.ParseAllIdempotent("0", itemBatch("0"))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s.Replayed, Equals, true)
+}
+
+func (CmpSuite) TestParseAllSkipsChildren(c *C) {
+	var (
+		cat  = testCategory("en", "")
+		sub  = cat.Sub("sub")
+		diff = sub.Difficulty("beginner")
+		item = &Item{ID: "item", Title: "Item", Body: "a"}
+		row  = func(k, v string) *Resource { return &Resource{Content: []map[string]string{{k: v}}} }
+	)
+	diff.AddItem(item)
+	diff.AddChecks(Check{Text: "Check"})
+	var batch []ParseRequest
+	for _, l := range []string{"it", "es"} {
+		batch = append(batch,
+			ParseRequest{cat, row("name", "Categoria"), l},
+			ParseRequest{sub, row("name", "Sotto"), l},
+			ParseRequest{diff, row("description", "Facile"), l},
+			ParseRequest{item, &Resource{Content: []map[string]string{{"title": "Voce"}, {"body": "uno"}}}, l},
+			ParseRequest{diff.Checks(), row("text", "Controllo"), l},
+		)
+	}
+	batch[0].Resource = &Resource{Content: []map[string]string{{"name": "A"}, {"name": "B"}}} // it
+	batch[8].Resource = &Resource{}                                                           // es item
+	batch = append(batch, ParseRequest{errorForm(), row("form", "Modulo"), "it"})
+
+	s, errs := NewResourceParser().ParseAll(batch)
+	c.Assert(s.Applied, Equals, 4)
+	c.Assert(s.Failed, Equals, 3)
+	c.Assert(s.Skipped, Equals, 4)
+	var messages []string
+	for _, err := range errs {
+		e, ok := err.(*ImportError)
+		c.Assert(ok, Equals, true)
+		messages = append(messages, e.Type+" "+e.Error())
+	}
+	c.Assert(messages, DeepEquals, []string{
+		"category cat (it): Invalid content",
+		"subcategory cat/sub (it): skipped, cat failed",
+		"difficulty cat/sub/beginner (it): skipped, cat failed",
+		"item cat/sub/beginner/item (it): skipped, cat failed",
+		"checklist cat/sub/beginner/.checks (it): skipped, cat failed",
+		"item cat/sub/beginner/item (es): Invalid content",
+		"form forms/form (it): forms/form (it) row 2: No more at screen 1/2",
+	})
+	c.Assert(errors.Is(errs[0], ErrContent), Equals, true)
+	c.Assert(errors.Is(errs[1], ErrParentFailed), Equals, true)
+	c.Assert(errs[6], FitsTypeOf, &ImportError{})
+	var pe *ParseError
+	c.Assert(errors.As(errs[6], &pe), Equals, true)
+	c.Assert(pe.Row, Equals, 2)
+
+	// the errors are a report
+	b, err := json.Marshal(errs[:2:2])
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `[{"locale":"it","message":"Invalid content","path":"cat","type":"category"},`+
+		`{"locale":"it","message":"Parent failed","parent":"cat","path":"cat/sub","type":"subcategory"}]`)
+	b, err = json.Marshal(errs[6])
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Matches, `\{"locale":"it","message":"forms/form \(it\) row 2: .*","path":"forms/form","row":2,"type":"form"\}`)
+	b, err = json.Marshal(&ImportError{Type: "item", Path: "cat/sub/beginner/item", Locale: "it"})
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"locale":"it","message":"","path":"cat/sub/beginner/item","type":"item"}`)
+
+	// a request without a resource fails, the rest of the batch goes on
+	batch = []ParseRequest{{cat, nil, "it"}, {sub, row("name", "Sotto"), "it"}, {cat, row("name", "Categoría"), "es"}}
+	for _, opts := range [][]Option{nil, {RoundTrip()}} {
+		s, errs = NewResourceParser().ParseAll(batch, opts...)
+		c.Assert([]int{s.Applied, s.Failed, s.Skipped}, DeepEquals, []int{1, 1, 1})
+		c.Assert(errs, HasLen, 2)
+		c.Assert(errors.Is(errs[0], ErrNoResource), Equals, true)
+		c.Assert(errs[0], ErrorMatches, `cat \(it\): No resource`)
+	}
+}
+
+func (CmpSuite) TestBatchStore(c *C) {
+	file := filepath.Join(c.MkDir(), "batches")
+	db, err := kvfile.Open(file)
+	c.Assert(err, IsNil)
+	p := NewResourceParser()
+	c.Assert(p.SetBatchStore(db), IsNil)
+	s, errs := p.ParseAllIdempotent("k1", itemBatch("Voce"))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(db.Close(), IsNil)
+
+	// a new parser knows the key
+	db, err = kvfile.Open(file)
+	c.Assert(err, IsNil)
+	p = NewResourceParser()
+	c.Assert(p.SetBatchStore(db), IsNil)
+	again, errs := p.ParseAllIdempotent("k1", itemBatch("Voce"))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(again.Replayed, Equals, true)
+	c.Assert(again.Fingerprint, Equals, s.Fingerprint)
+	c.Assert(p.lookup("it", "cat/sub/beginner/item"), IsNil)
+	_, errs = p.ParseAllIdempotent("k1", itemBatch("Altro"))
+	c.Assert(errs, DeepEquals, []error{ErrKeyReplayMismatch})
+
+	// evicted keys are removed from the store, "k1" was used last
+	for i := 0; i < BatchKeys; i++ {
+		p.ParseAllIdempotent(fmt.Sprint(i), itemBatch(fmt.Sprint(i)))
+	}
+	c.Assert(db.Len(), Equals, BatchKeys)
+	_, ok := db.Get("k1")
+	c.Assert(ok, Equals, false)
+	c.Assert(db.Close(), IsNil)
+
+	db, err = kvfile.Open(file)
+	c.Assert(err, IsNil)
+	defer db.Close()
+	p = NewResourceParser()
+	c.Assert(p.SetBatchStore(db), IsNil)
+	c.Assert(p.batchKeys, HasLen, BatchKeys)
+	c.Assert(p.batchKeys[0], Equals, "0")
+	again, _ = p.ParseAllIdempotent("0", itemBatch("0"))
+	c.Assert(again.Replayed, Equals, true)
+}
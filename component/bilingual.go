@@ -0,0 +1,200 @@
+package component
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+)
+
+const (
+	statusMissing    = "missing"
+	statusMisaligned = "misaligned"
+	statusPending    = "needs translation"
+)
+
+// Changes of the source units since an older parser, see Since
+const (
+	changeNew     = "new"
+	changeSource  = "source changed"
+	changeNone    = "unchanged"
+	changeRemoved = "removed pending"
+)
+
+type bilingualRow struct {
+	Path, Field    string
+	Index          int
+	Source, Target string
+	Status         string
+	Change         string // see Since
+	OldSource      string // with changeSource and changeRemoved
+}
+
+// Since makes ExportBilingual add the change of each source unit compared to the old parser:
+// new, source changed, with the old source text, or unchanged. Components removed from the
+// source since are added at the end as removed pending, with the old source text and their
+// translation if any, so it can be removed too.
+func Since(old *ResourceParser) Option { return func(o *options) { o.since = old } }
+
+// ExportBilingual writes the category in the two locales side by side, one row per translatable unit.
+// Units missing in the target locale are marked as missing, fields with a different number of
+// units (i.e. paragraphs) are marked as misaligned, units copied by BootstrapLocale as needing translation.
+func (r *ResourceParser) ExportBilingual(w io.Writer, sourceLocale, targetLocale, categoryID string, format Format, opts ...Option) error {
+	if format != FormatCSV && format != FormatHTML {
+		return ErrFormat
+	}
+	cat := r.category(categoryID, sourceLocale)
+	if cat == nil {
+		return fmt.Errorf("No cat %q (%s)", categoryID, sourceLocale)
+	}
+	var (
+		rows  []bilingualRow
+		o     = newOptions(opts)
+		paths = make(map[string]bool)
+	)
+	if cat = cat.Filter(opts...); cat != nil {
+		walkCategory(cat, func(c Component) {
+			from := len(rows)
+			paths[treePath(c)] = true
+			rows = alignRows(rows, c, r.lookup(targetLocale, treePath(c)))
+			if r.NeedsTranslation(targetLocale, treePath(c)) {
+				for i := range rows[from:] {
+					rows[from+i].Status = statusPending
+				}
+			}
+		})
+	}
+	if o.since != nil {
+		rows = o.since.changedRows(rows, paths, r, sourceLocale, targetLocale, categoryID, opts)
+	}
+	if format == FormatHTML {
+		return writeBilingualHTML(w, sourceLocale, targetLocale, rows, o.since != nil)
+	}
+	return writeBilingualCSV(w, sourceLocale, targetLocale, rows, o.since != nil)
+}
+
+// changedRows sets the change of the rows compared to the old parser r, and appends the units
+// of r missing in the current parser cur, whose paths are given.
+func (r *ResourceParser) changedRows(rows []bilingualRow, paths map[string]bool, cur *ResourceParser, source, target, categoryID string, opts []Option) []bilingualRow {
+	var old = make(map[[3]string]string)
+	if cat := r.category(categoryID, source); cat != nil {
+		cat = cat.Filter(opts...)
+		if cat != nil {
+			walkCategory(cat, func(c Component) {
+				path := treePath(c)
+				for _, f := range textFields(c) {
+					for i, s := range f.Texts {
+						old[[3]string{path, f.Name, strconv.Itoa(i + 1)}] = s
+					}
+				}
+				if !paths[path] {
+					from := len(rows)
+					rows = alignRows(rows, c, cur.lookup(target, path))
+					for i := range rows[from:] {
+						row := &rows[from+i]
+						row.Change, row.OldSource, row.Source = changeRemoved, row.Source, ""
+					}
+				}
+			})
+		}
+	}
+	for i := range rows {
+		row := &rows[i]
+		s, ok := old[[3]string{row.Path, row.Field, strconv.Itoa(row.Index)}]
+		switch {
+		case row.Change != "":
+		case !ok:
+			row.Change = changeNew
+		case s != row.Source:
+			row.Change, row.OldSource = changeSource, s
+		default:
+			row.Change = changeNone
+		}
+	}
+	return rows
+}
+
+// alignRows appends a row for each unit of src, paired with the same unit of dst (that can be nil).
+func alignRows(rows []bilingualRow, src, dst Component) []bilingualRow {
+	path := treePath(src)
+	var targets = make(map[string][]string)
+	if dst != nil {
+		for _, f := range textFields(dst) {
+			targets[f.Name] = f.Texts
+		}
+	}
+	for _, f := range textFields(src) {
+		var status string
+		t, ok := targets[f.Name]
+		switch {
+		case !ok:
+			status = statusMissing
+		case len(t) != len(f.Texts):
+			status = statusMisaligned
+		}
+		n := len(f.Texts)
+		if len(t) > n {
+			n = len(t)
+		}
+		for i := 0; i < n; i++ {
+			row := bilingualRow{Path: path, Field: f.Name, Index: i + 1, Status: status}
+			if i < len(f.Texts) {
+				row.Source = f.Texts[i]
+			}
+			if i < len(t) {
+				row.Target = t[i]
+			}
+			if row.Status == "" && row.Target == "" && row.Source != "" {
+				row.Status = statusMissing
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func writeBilingualCSV(w io.Writer, source, target string, rows []bilingualRow, changes bool) error {
+	c := csv.NewWriter(w)
+	header := []string{"path", "field", "index", source, target, "status"}
+	if changes {
+		header = append(header, "change", "old "+source)
+	}
+	c.Write(header)
+	for _, r := range rows {
+		record := []string{r.Path, r.Field, strconv.Itoa(r.Index), r.Source, r.Target, r.Status}
+		if changes {
+			record = append(record, r.Change, r.OldSource)
+		}
+		c.Write(record)
+	}
+	c.Flush()
+	return c.Error()
+}
+
+func writeBilingualHTML(w io.Writer, source, target string, rows []bilingualRow, changes bool) error {
+	e := html.EscapeString
+	var extra string
+	if changes {
+		extra = fmt.Sprintf("<th>change</th><th>old %s</th>", e(source))
+	}
+	if _, err := fmt.Fprintf(w, "<table>\n<tr><th>path</th><th>field</th><th>%s</th><th>%s</th><th>status</th>%s</tr>\n", e(source), e(target), extra); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		var class string
+		if r.Status != "" {
+			class = fmt.Sprintf(" class=%q", r.Status)
+		}
+		var extra string
+		if changes {
+			extra = fmt.Sprintf("<td>%s</td><td>%s</td>", r.Change, e(r.OldSource))
+		}
+		if _, err := fmt.Fprintf(w, "<tr%s><td>%s</td><td>%s %d</td><td>%s</td><td>%s</td><td>%s</td>%s</tr>\n",
+			class, e(r.Path), e(r.Field), r.Index, e(r.Source), e(r.Target), r.Status, extra); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, "</table>\n")
+	return err
+}
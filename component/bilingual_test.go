@@ -0,0 +1,109 @@
+package component
+
+import (
+	"bytes"
+
+	. "gopkg.in/check.v1"
+)
+
+// testCategory returns a category with a single subcategory and difficulty, names are prefixed.
+func testCategory(locale, prefix string) *Category {
+	cat := &Category{ID: "cat", Name: prefix + "Category", Locale: locale}
+	sub := &Subcategory{ID: "sub", Name: prefix + "Sub"}
+	cat.Add(sub)
+	sub.AddDifficulty(&Difficulty{ID: "beginner", Descr: prefix + "Easy & safe"})
+	return cat
+}
+
+func bilingualParser() *ResourceParser {
+	p := NewResourceParser()
+	en, it := testCategory("en", ""), testCategory("it", "IT ")
+	p.categories["en"] = []*Category{en}
+	p.categories["it"] = []*Category{it}
+
+	diff := en.Sub("sub").Difficulty("beginner")
+	diff.AddItem(
+		&Item{ID: "item", Title: "Title", Body: "One\n\nTwo"},
+		&Item{ID: "other", Title: "Other", Body: "<b>Three</b>"},
+	)
+	diff.AddChecks(Check{Text: "Check"})
+
+	diff = it.Sub("sub").Difficulty("beginner")
+	diff.AddItem(&Item{ID: "item", Title: "Titolo", Body: "Uno"})
+	diff.AddChecks(Check{Text: "Controllo"})
+	return p
+}
+
+func (CmpSuite) TestBilingualCSV(c *C) {
+	var b bytes.Buffer
+	c.Assert(bilingualParser().ExportBilingual(&b, "en", "it", "cat", FormatCSV), IsNil)
+	c.Assert(b.String(), Equals, `path,field,index,en,it,status
+cat,name,1,Category,IT Category,
+cat/sub,name,1,Sub,IT Sub,
+cat/sub/beginner,description,1,Easy & safe,IT Easy & safe,
+cat/sub/beginner/item,title,1,Title,Titolo,
+cat/sub/beginner/item,body,1,One,Uno,misaligned
+cat/sub/beginner/item,body,2,Two,,misaligned
+cat/sub/beginner/other,title,1,Other,,missing
+cat/sub/beginner/other,body,1,<b>Three</b>,,missing
+cat/sub/beginner/.checks,text,1,Check,Controllo,
+`)
+}
+
+func (CmpSuite) TestBilingualHTML(c *C) {
+	var b bytes.Buffer
+	c.Assert(bilingualParser().ExportBilingual(&b, "en", "it", "cat", FormatHTML), IsNil)
+	c.Assert(b.String(), Equals, `<table>
+<tr><th>path</th><th>field</th><th>en</th><th>it</th><th>status</th></tr>
+<tr><td>cat</td><td>name 1</td><td>Category</td><td>IT Category</td><td></td></tr>
+<tr><td>cat/sub</td><td>name 1</td><td>Sub</td><td>IT Sub</td><td></td></tr>
+<tr><td>cat/sub/beginner</td><td>description 1</td><td>Easy &amp; safe</td><td>IT Easy &amp; safe</td><td></td></tr>
+<tr><td>cat/sub/beginner/item</td><td>title 1</td><td>Title</td><td>Titolo</td><td></td></tr>
+<tr class="misaligned"><td>cat/sub/beginner/item</td><td>body 1</td><td>One</td><td>Uno</td><td>misaligned</td></tr>
+<tr class="misaligned"><td>cat/sub/beginner/item</td><td>body 2</td><td>Two</td><td></td><td>misaligned</td></tr>
+<tr class="missing"><td>cat/sub/beginner/other</td><td>title 1</td><td>Other</td><td></td><td>missing</td></tr>
+<tr class="missing"><td>cat/sub/beginner/other</td><td>body 1</td><td>&lt;b&gt;Three&lt;/b&gt;</td><td></td><td>missing</td></tr>
+<tr><td>cat/sub/beginner/.checks</td><td>text 1</td><td>Check</td><td>Controllo</td><td></td></tr>
+</table>
+`)
+}
+
+func (CmpSuite) TestBilingualErrors(c *C) {
+	var b bytes.Buffer
+	p := bilingualParser()
+	c.Assert(p.ExportBilingual(&b, "en", "it", "cat", Format("pdf")), Equals, ErrFormat)
+	c.Assert(p.ExportBilingual(&b, "en", "it", "missing", FormatCSV), NotNil)
+}
+
+func (CmpSuite) TestBilingualSince(c *C) {
+	old, p := bilingualParser(), bilingualParser()
+	diff := p.category("cat", "en").Sub("sub").Difficulty("beginner")
+	diff.Item("item").Body = "One\n\nTwo, changed"
+	p.remove("en", diff.Item("other"))
+	diff.AddItem(&Item{ID: "new", Title: "New", Body: "Four"})
+	p.lookup("it", "cat/sub/beginner").(*Difficulty).AddItem(&Item{ID: "other", Title: "Altro", Body: "Tre"})
+
+	var b bytes.Buffer
+	c.Assert(p.ExportBilingual(&b, "en", "it", "cat", FormatCSV, Since(old)), IsNil)
+	c.Assert(b.String(), Equals, `path,field,index,en,it,status,change,old en
+cat,name,1,Category,IT Category,,unchanged,
+cat/sub,name,1,Sub,IT Sub,,unchanged,
+cat/sub/beginner,description,1,Easy & safe,IT Easy & safe,,unchanged,
+cat/sub/beginner/item,title,1,Title,Titolo,,unchanged,
+cat/sub/beginner/item,body,1,One,Uno,misaligned,unchanged,
+cat/sub/beginner/item,body,2,"Two, changed",,misaligned,source changed,Two
+cat/sub/beginner/new,title,1,New,,missing,new,
+cat/sub/beginner/new,body,1,Four,,missing,new,
+cat/sub/beginner/.checks,text,1,Check,Controllo,,unchanged,
+cat/sub/beginner/other,title,1,,Altro,,removed pending,Other
+cat/sub/beginner/other,body,1,,Tre,,removed pending,<b>Three</b>
+`)
+
+	b.Reset()
+	c.Assert(p.ExportBilingual(&b, "en", "it", "cat", FormatHTML, Since(old)), IsNil)
+	c.Assert(b.String(), Matches, `(?s)<table>
+<tr><th>path</th><th>field</th><th>en</th><th>it</th><th>status</th><th>change</th><th>old en</th></tr>
+.*<td>removed pending</td><td>&lt;b&gt;Three&lt;/b&gt;</td></tr>
+</table>
+`)
+}
@@ -0,0 +1,68 @@
+package component
+
+import "fmt"
+
+// BootstrapLocale copies every component of the source locale into the target one, so it can be
+// previewed before it's translated. Copied components need translation until a resource for them
+// is parsed, and they never count as translated.
+func (r *ResourceParser) BootstrapLocale(source, target string) error {
+	if len(r.categories[target]) != 0 || len(r.forms[target]) != 0 {
+		return fmt.Errorf("Locale %s is not empty", target)
+	}
+	if r.pending == nil {
+		r.pending = make(map[string]map[string]bool)
+	}
+	pending := make(map[string]bool)
+	r.pending[target] = pending
+	for _, cat := range r.categories[source] {
+		clone := cloneCategory(cat, target)
+		walkCategory(clone, func(c Component) { pending[treePath(c)] = true })
+		r.categories[target] = append(r.categories[target], clone)
+	}
+	for _, f := range r.forms[source] {
+		clone := *f.Tree(RawMarkdown).(*Form)
+		clone.Hash, clone.Locale = "", target
+		for i, s := range f.Screens {
+			for j, v := range s.Items {
+				clone.Screens[i].Items[j].Value = append([]string(nil), v.Value...)
+				clone.Screens[i].Items[j].Options = append([]string(nil), v.Options...)
+			}
+		}
+		pending[treePath(&clone)] = true
+		r.forms[target] = append(r.forms[target], &clone)
+	}
+	return nil
+}
+
+// NeedsTranslation tells if the component at the path has been copied by BootstrapLocale
+// and not translated yet.
+func (r *ResourceParser) NeedsTranslation(locale, path string) bool { return r.pending[locale][path] }
+
+// translation returns the component of locale found at the tree path, nil if missing or not translated
+func (r *ResourceParser) translation(locale, path string) Component {
+	if r.NeedsTranslation(locale, path) {
+		return nil
+	}
+	return r.lookup(locale, path)
+}
+
+func cloneCategory(c *Category, locale string) *Category {
+	cat := &Category{ID: c.ID, Name: c.Name, Locale: locale, Order: c.Order}
+	for _, s := range c.subcategories {
+		sub := &Subcategory{ID: s.ID, Name: s.Name, Order: s.Order, Audience: s.Audience}
+		for _, d := range s.difficulties {
+			diff := &Difficulty{ID: d.ID, Descr: d.Descr}
+			for _, i := range d.items {
+				item := *i
+				item.Hash, item.summary = "", summaryCache{}
+				diff.AddItem(&item)
+			}
+			if d.checklist != nil {
+				diff.SetChecks(&Checklist{Checks: append([]Check(nil), d.checklist.Checks...)})
+			}
+			sub.AddDifficulty(diff)
+		}
+		cat.Add(sub)
+	}
+	return cat
+}
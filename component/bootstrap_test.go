@@ -0,0 +1,52 @@
+package component
+
+import (
+	"bytes"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestBootstrapLocale(c *C) {
+	p := readinessParser(map[string]int{"en": 4})
+	en := p.category("cat", "en")
+	en.Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "Check"})
+	p.forms["en"] = []*Form{{ID: "form", Name: "Form", Screens: []FormScreen{{Name: "One", Items: []FormInput{{Label: "Label", Options: []string{"a"}}}}}}}
+
+	c.Assert(p.BootstrapLocale("en", "sw"), IsNil)
+	c.Assert(p.BootstrapLocale("en", "sw"), NotNil)
+	sw := p.category("cat", "sw")
+	c.Assert(sw != en, Equals, true)
+	c.Assert(sw.Locale, Equals, "sw")
+	c.Assert(sw.Sub("sub").Difficulty("beginner").Items(), HasLen, 4)
+	c.Assert(p.forms["sw"][0].Locale, Equals, "sw")
+	c.Assert(p.NeedsTranslation("sw", "cat/sub/beginner/item0"), Equals, true)
+	c.Assert(p.NeedsTranslation("sw", "forms/form"), Equals, true)
+	c.Assert(p.LocaleReadiness("en")["sw"], DeepEquals, Readiness{MissingNames: 2})
+
+	sw.Sub("sub").Difficulty("beginner").Item("item0").Title = "changed"
+	c.Assert(en.Sub("sub").Difficulty("beginner").Item("item0").Title, Equals, "Title")
+
+	item := en.Sub("sub").Difficulty("beginner").Item("item1")
+	res := Resource{Content: []map[string]string{{"title": "Kichwa", "body": "moja mbili\n\ntatu nne"}}}
+	c.Assert(p.Parse(item, &res, "sw"), IsNil)
+	c.Assert(p.NeedsTranslation("sw", "cat/sub/beginner/item1"), Equals, false)
+	c.Assert(sw.Sub("sub").Difficulty("beginner").Items(), HasLen, 4)
+	c.Assert(sw.Sub("sub").Difficulty("beginner").Item("item1").Title, Equals, "Kichwa")
+	r := p.LocaleReadiness("en")["sw"]
+	c.Assert(r.Components, Equals, 100.0/9)
+	c.Assert(r.Ready, Equals, false)
+
+	var b bytes.Buffer
+	c.Assert(p.ExportBilingual(&b, "en", "sw", "cat", FormatCSV), IsNil)
+	lines := strings.Split(b.String(), "\n")
+	c.Assert(lines[1], Equals, "cat,name,1,Category,Category,needs translation")
+	c.Assert(b.String(), Matches, "(?s).*cat/sub/beginner/item1,title,1,Title,Kichwa,\n.*")
+
+	// removing a component drops the pending translations of its descendants too
+	c.Assert(p.Remove(en.Sub("sub"), "sw"), IsNil)
+	for path := range p.pending["sw"] {
+		c.Assert(strings.HasPrefix(path, "cat/sub"), Equals, false, Commentf("%s still pending", path))
+	}
+	c.Assert(p.NeedsTranslation("sw", "forms/form"), Equals, true)
+}
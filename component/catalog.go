@@ -0,0 +1,181 @@
+package component
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+type catalogUnit struct {
+	ID         uint32          `json:"id,omitempty"` // see NumericIDs
+	Path       string          `json:"path"`
+	Field      string          `json:"field"`
+	Index      int             `json:"index"`
+	Text       string          `json:"text"`
+	Chars      int             `json:"chars"`
+	Words      int             `json:"words"`
+	Translated map[string]bool `json:"translated"`
+}
+
+// catalogTotal counts the units of a component type, Locale is empty for the source
+// and set for the units still to translate in a target locale.
+type catalogTotal struct {
+	Type    string `json:"type"`
+	Locale  string `json:"locale,omitempty"`
+	Strings int    `json:"strings"`
+	Chars   int    `json:"chars"`
+	Words   int    `json:"words"`
+}
+
+type stringCatalog struct {
+	Source  string         `json:"source"`
+	Targets []string       `json:"targets"`
+	Units   []catalogUnit  `json:"units"`
+	Totals  []catalogTotal `json:"totals"`
+	ids     bool           // units have an ID
+}
+
+// ExportStringCatalog writes every non empty translatable unit of the source locale, with its
+// character and word count and whether each other locale already translates it, followed
+// by totals per component type. Units are the same used by the other exports.
+func (r *ResourceParser) ExportStringCatalog(w io.Writer, sourceLocale string, format Format, opts ...Option) error {
+	if format != FormatCSV && format != FormatJSON {
+		return ErrFormat
+	}
+	var (
+		o       = newOptions(opts)
+		catalog = stringCatalog{Source: sourceLocale, ids: o.ids != nil}
+	)
+	for _, l := range r.Locales(opts...) {
+		if l != sourceLocale {
+			catalog.Targets = append(catalog.Targets, l)
+		}
+	}
+	var (
+		totals = make(map[[2]string]*catalogTotal)
+		types  []string
+	)
+	count := func(typ, locale string, u catalogUnit) {
+		k := [2]string{typ, locale}
+		t, ok := totals[k]
+		if !ok {
+			t = &catalogTotal{Type: typ, Locale: locale}
+			totals[k] = t
+			if locale == "" {
+				types = append(types, typ)
+			}
+		}
+		t.Strings++
+		t.Chars += u.Chars
+		t.Words += u.Words
+	}
+	add := func(c Component) {
+		var targets = make(map[string]map[string][]string)
+		for _, l := range catalog.Targets {
+			targets[l] = make(map[string][]string)
+			if dst := r.translation(l, treePath(c)); dst != nil {
+				for _, f := range textFields(dst) {
+					targets[l][f.Name] = f.Texts
+				}
+			}
+		}
+		typ := cmpType(c)
+		for _, f := range textFields(c) {
+			for i, s := range f.Texts {
+				if s == "" {
+					continue
+				}
+				u := catalogUnit{
+					Path: treePath(c), Field: f.Name, Index: i + 1, Text: s,
+					Chars: utf8.RuneCountInString(s), Words: countWords(sourceLocale, s),
+					Translated: make(map[string]bool),
+				}
+				if o.ids != nil {
+					u.ID = o.ids.IDs[u.Path]
+				}
+				count(typ, "", u)
+				for _, l := range catalog.Targets {
+					t := targets[l][f.Name]
+					u.Translated[l] = i < len(t) && t[i] != ""
+					if !u.Translated[l] {
+						count(typ, l, u)
+					}
+				}
+				catalog.Units = append(catalog.Units, u)
+			}
+		}
+	}
+	for _, cat := range r.categories[sourceLocale] {
+		if cat = cat.Filter(opts...); cat != nil {
+			walkCategory(cat, add)
+		}
+	}
+	for _, f := range r.forms[sourceLocale] {
+		add(f)
+	}
+	for _, typ := range types {
+		catalog.Totals = append(catalog.Totals, *totals[[2]string{typ, ""}])
+		for _, l := range catalog.Targets {
+			if t, ok := totals[[2]string{typ, l}]; ok {
+				catalog.Totals = append(catalog.Totals, *t)
+			}
+		}
+	}
+	if format == FormatJSON {
+		return json.NewEncoder(w).Encode(catalog)
+	}
+	return writeCatalogCSV(w, &catalog)
+}
+
+func writeCatalogCSV(w io.Writer, catalog *stringCatalog) error {
+	c := csv.NewWriter(w)
+	header := append([]string{"path", "field", "index", "text", "chars", "words"}, catalog.Targets...)
+	if catalog.ids {
+		header = append([]string{"id"}, header...)
+	}
+	c.Write(header)
+	for _, u := range catalog.Units {
+		row := []string{u.Path, u.Field, strconv.Itoa(u.Index), u.Text, strconv.Itoa(u.Chars), strconv.Itoa(u.Words)}
+		if catalog.ids {
+			row = append([]string{strconv.FormatUint(uint64(u.ID), 10)}, row...)
+		}
+		for _, l := range catalog.Targets {
+			row = append(row, strconv.FormatBool(u.Translated[l]))
+		}
+		c.Write(row)
+	}
+	c.Write(nil)
+	c.Write([]string{"type", "locale", "strings", "chars", "words"})
+	for _, t := range catalog.Totals {
+		c.Write([]string{t.Type, t.Locale, strconv.Itoa(t.Strings), strconv.Itoa(t.Chars), strconv.Itoa(t.Words)})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// cmpType returns the name of the type of the component
+func cmpType(c Component) string {
+	switch c.(type) {
+	case *Category:
+		return "category"
+	case *Subcategory:
+		return "subcategory"
+	case *Difficulty:
+		return "difficulty"
+	case *Item:
+		return "item"
+	case *Checklist:
+		return "checklist"
+	case *Quiz:
+		return "quiz"
+	case *Form:
+		return "form"
+	case *Glossary:
+		return "glossary"
+	case *Asset:
+		return "asset"
+	}
+	return ""
+}
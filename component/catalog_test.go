@@ -0,0 +1,66 @@
+package component
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestStringCatalogCSV(c *C) {
+	var b bytes.Buffer
+	c.Assert(bilingualParser().ExportStringCatalog(&b, "en", FormatCSV), IsNil)
+	c.Assert(b.String(), Equals, `path,field,index,text,chars,words,it
+cat,name,1,Category,8,1,true
+cat/sub,name,1,Sub,3,1,true
+cat/sub/beginner,description,1,Easy & safe,11,2,true
+cat/sub/beginner/item,title,1,Title,5,1,true
+cat/sub/beginner/item,body,1,One,3,1,true
+cat/sub/beginner/item,body,2,Two,3,1,false
+cat/sub/beginner/other,title,1,Other,5,1,false
+cat/sub/beginner/other,body,1,<b>Three</b>,12,1,false
+cat/sub/beginner/.checks,text,1,Check,5,1,true
+
+type,locale,strings,chars,words
+category,,1,8,1
+subcategory,,1,3,1
+difficulty,,1,11,2
+item,,5,28,5
+item,it,3,20,3
+checklist,,1,5,1
+`)
+}
+
+func (CmpSuite) TestStringCatalogJSON(c *C) {
+	var b bytes.Buffer
+	c.Assert(bilingualParser().ExportStringCatalog(&b, "en", FormatJSON), IsNil)
+	var catalog stringCatalog
+	c.Assert(json.Unmarshal(b.Bytes(), &catalog), IsNil)
+	c.Assert(catalog.Targets, DeepEquals, []string{"it"})
+	c.Assert(catalog.Units, HasLen, 9)
+	c.Assert(catalog.Units[5].Translated, DeepEquals, map[string]bool{"it": false})
+	c.Assert(catalog.Totals[4], DeepEquals, catalogTotal{Type: "item", Locale: "it", Strings: 3, Chars: 20, Words: 3})
+	c.Assert(bilingualParser().ExportStringCatalog(&b, "en", FormatHTML), Equals, ErrFormat)
+}
+
+// TestStringCatalogUnits checks that the catalog and the bilingual export agree on the units.
+func (CmpSuite) TestStringCatalogUnits(c *C) {
+	p := bilingualParser()
+	var b bytes.Buffer
+	c.Assert(p.ExportStringCatalog(&b, "en", FormatJSON), IsNil)
+	var catalog stringCatalog
+	c.Assert(json.Unmarshal(b.Bytes(), &catalog), IsNil)
+
+	b.Reset()
+	c.Assert(p.ExportBilingual(&b, "en", "it", "cat", FormatCSV), IsNil)
+	rows, err := csv.NewReader(&b).ReadAll()
+	c.Assert(err, IsNil)
+	var units int
+	for _, row := range rows[1:] {
+		if row[3] != "" {
+			units++
+		}
+	}
+	c.Assert(catalog.Units, HasLen, units)
+}
@@ -0,0 +1,218 @@
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type changelogEntry struct {
+	Path    string   `json:"path"`
+	From    string   `json:"from,omitempty"` // old path of a renamed component
+	Title   string   `json:"title"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// changelogSection groups the changes of a category, or of the forms
+type changelogSection struct {
+	ID      string           `json:"id"`
+	Name    string           `json:"name"`
+	Added   []changelogEntry `json:"added,omitempty"`
+	Renamed []changelogEntry `json:"renamed,omitempty"`
+	Updated []changelogEntry `json:"updated,omitempty"`
+	Removed []changelogEntry `json:"removed,omitempty"`
+}
+
+type changelog struct {
+	Locale     string              `json:"locale"`
+	Removed    bool                `json:"locale_removed,omitempty"` // the locale has no content anymore
+	Categories []*changelogSection `json:"categories"`
+	Forms      *changelogSection   `json:"forms,omitempty"`
+}
+
+// GenerateChangelog describes the changes of a locale from the old content to the new one, grouped
+// by category: added, renamed, updated and removed items and checklists, followed by the changed
+// forms. Renames are the ones made with Rename on the new parser. A locale that has no content
+// anymore is reported as removed, besides its components. The format is Markdown or JSON.
+func GenerateChangelog(old, new *ResourceParser, locale string, format Format) ([]byte, error) {
+	if format != FormatMarkdown && format != FormatJSON {
+		return nil, ErrFormat
+	}
+	log := changelog{
+		Locale:     locale,
+		Categories: []*changelogSection{},
+		Removed:    !old.emptyLocale(locale) && new.emptyLocale(locale),
+	}
+	var (
+		before   = changelogComponents(old, locale)
+		after    = changelogComponents(new, locale)
+		renamed  = make(map[string]string)
+		sections = make(map[string]*changelogSection)
+	)
+	for p := range before {
+		if _, ok := after[p]; ok {
+			continue
+		}
+		if n := new.renamedPath(p); n != p {
+			if _, ok := before[n]; !ok && after[n] != nil {
+				renamed[n] = p
+			}
+		}
+	}
+	section := func(p string) *changelogSection {
+		id := strings.SplitN(p, "/", 2)[0]
+		if s, ok := sections[id]; ok {
+			return s
+		}
+		s := &changelogSection{ID: id, Name: "Forms"}
+		switch cat := new.category(id, locale); {
+		case id == "forms":
+			log.Forms = s
+		case cat != nil:
+			s.Name = cat.Name
+		default:
+			if cat = old.category(id, locale); cat != nil {
+				s.Name = cat.Name
+			}
+		}
+		if id != "forms" {
+			log.Categories = append(log.Categories, s)
+		}
+		sections[id] = s
+		return s
+	}
+	for _, p := range sortedComponentPaths(after) {
+		c := after[p]
+		e := changelogEntry{Path: p, Title: changelogTitle(c)}
+		switch from, ok := renamed[p]; {
+		case ok:
+			e.From, e.Changed = from, changedFields(before[from], c)
+			s := section(p)
+			s.Renamed = append(s.Renamed, e)
+		case before[p] == nil:
+			s := section(p)
+			s.Added = append(s.Added, e)
+		default:
+			if e.Changed = changedFields(before[p], c); len(e.Changed) != 0 {
+				s := section(p)
+				s.Updated = append(s.Updated, e)
+			}
+		}
+	}
+	moved := make(map[string]bool, len(renamed))
+	for _, from := range renamed {
+		moved[from] = true
+	}
+	for _, p := range sortedComponentPaths(before) {
+		if after[p] == nil && !moved[p] {
+			s := section(p)
+			s.Removed = append(s.Removed, changelogEntry{Path: p, Title: changelogTitle(before[p])})
+		}
+	}
+	sort.Slice(log.Categories, func(i, j int) bool { return log.Categories[i].ID < log.Categories[j].ID })
+	if format == FormatJSON {
+		return json.Marshal(log)
+	}
+	return log.markdown(), nil
+}
+
+// changelogComponents returns the items, checklists and forms of a locale by tree path
+func changelogComponents(r *ResourceParser, locale string) map[string]Component {
+	var m = make(map[string]Component)
+	for _, cat := range r.categories[locale] {
+		walkCategory(cat, func(c Component) {
+			switch c.(type) {
+			case *Item, *Checklist:
+				m[treePath(c)] = c
+			}
+		})
+	}
+	for _, f := range r.forms[locale] {
+		m[treePath(f)] = f
+	}
+	return m
+}
+
+func sortedComponentPaths(m map[string]Component) []string {
+	var paths = make([]string, 0, len(m))
+	for p := range m {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func changelogTitle(c Component) string {
+	switch v := c.(type) {
+	case *Item:
+		return v.Title
+	case *Checklist:
+		return "Checks"
+	case *Quiz:
+		return "Quiz"
+	case *Form:
+		return v.Name
+	}
+	return ""
+}
+
+// changedFields returns the names of the translatable fields that differ, checks for checklists
+func changedFields(a, b Component) []string {
+	var (
+		before  = textFields(a)
+		after   = textFields(b)
+		changed []string
+	)
+	for i := range after {
+		if reflect.DeepEqual(before[i].Texts, after[i].Texts) {
+			continue
+		}
+		if _, ok := b.(*Checklist); ok {
+			return []string{"checks"}
+		}
+		changed = append(changed, after[i].Name)
+	}
+	return changed
+}
+
+func (l *changelog) markdown() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Changes (%s)\n", l.Locale)
+	sections := l.Categories
+	if l.Forms != nil {
+		sections = append(sections[:len(sections):len(sections)], l.Forms)
+	}
+	if l.Removed {
+		b.WriteString("\nThe locale was removed, it has no content anymore.\n")
+	}
+	if len(sections) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n## %s\n", s.Name)
+		for _, group := range []struct {
+			name    string
+			entries []changelogEntry
+		}{{"Added", s.Added}, {"Renamed", s.Renamed}, {"Updated", s.Updated}, {"Removed", s.Removed}} {
+			if len(group.entries) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "\n### %s\n\n", group.name)
+			for _, e := range group.entries {
+				fmt.Fprintf(&b, "- %s (`%s`", e.Title, e.Path)
+				if e.From != "" {
+					fmt.Fprintf(&b, ", was `%s`", e.From)
+				}
+				b.WriteString(")")
+				if len(e.Changed) != 0 {
+					fmt.Fprintf(&b, ": %s changed", strings.Join(e.Changed, ", "))
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.Bytes()
+}
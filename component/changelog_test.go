@@ -0,0 +1,83 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func changelogParsers(c *C) (old, new *ResourceParser) {
+	old, new = bilingualParser(), bilingualParser()
+	old.categories["en"][0].Sub("sub").Difficulty("beginner").AddItem(&Item{ID: "gone", Title: "Gone", Body: "Old"})
+
+	_, err := new.Rename("cat/sub/beginner/other", "third")
+	c.Assert(err, IsNil)
+	diff := new.categories["en"][0].Sub("sub").Difficulty("beginner")
+	diff.Item("third").Body = "<b>Three</b>\n\nFour"
+	diff.Item("item").Title = "New title"
+	diff.AddItem(&Item{ID: "added", Title: "Added", Body: "New"})
+	diff.checklist.Checks[0].Text = "Changed check"
+	new.forms["en"] = []*Form{{ID: "form", Name: "Form", Locale: "en"}}
+	return old, new
+}
+
+func (CmpSuite) TestChangelogMarkdown(c *C) {
+	old, new := changelogParsers(c)
+	b, err := GenerateChangelog(old, new, "en", FormatMarkdown)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "# Changes (en)\n"+`
+## Category
+
+### Added
+
+- Added (`+"`cat/sub/beginner/added`"+`)
+
+### Renamed
+
+- Other (`+"`cat/sub/beginner/third`, was `cat/sub/beginner/other`"+`): body changed
+
+### Updated
+
+- Checks (`+"`cat/sub/beginner/.checks`"+`): checks changed
+- New title (`+"`cat/sub/beginner/item`"+`): title changed
+
+### Removed
+
+- Gone (`+"`cat/sub/beginner/gone`"+`)
+
+## Forms
+
+### Added
+
+- Form (`+"`forms/form`"+`)
+`)
+
+	b, err = GenerateChangelog(old, new, "it", FormatMarkdown)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "# Changes (it)\n\nNo changes.\n")
+}
+
+func (CmpSuite) TestChangelogJSON(c *C) {
+	old, new := changelogParsers(c)
+	b, err := GenerateChangelog(old, new, "en", FormatJSON)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"locale":"en","categories":[{"id":"cat","name":"Category",`+
+		`"added":[{"path":"cat/sub/beginner/added","title":"Added"}],`+
+		`"renamed":[{"path":"cat/sub/beginner/third","from":"cat/sub/beginner/other","title":"Other","changed":["body"]}],`+
+		`"updated":[{"path":"cat/sub/beginner/.checks","title":"Checks","changed":["checks"]},{"path":"cat/sub/beginner/item","title":"New title","changed":["title"]}],`+
+		`"removed":[{"path":"cat/sub/beginner/gone","title":"Gone"}]}],`+
+		`"forms":{"id":"forms","name":"Forms","added":[{"path":"forms/form","title":"Form"}]}}`)
+
+	b, err = GenerateChangelog(old, new, "it", FormatJSON)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"locale":"it","categories":[]}`)
+
+	// the output does not depend on map order
+	for i := 0; i < 10; i++ {
+		again, err := GenerateChangelog(old, new, "en", FormatMarkdown)
+		c.Assert(err, IsNil)
+		first, _ := GenerateChangelog(old, new, "en", FormatMarkdown)
+		c.Assert(string(again), Equals, string(first))
+	}
+
+	_, err = GenerateChangelog(old, new, "en", FormatCSV)
+	c.Assert(err, Equals, ErrFormat)
+}
@@ -2,6 +2,7 @@ package component
 
 import (
 	"testing"
+	"testing/fstest"
 
 	. "gopkg.in/check.v1"
 	git "gopkg.in/src-d/go-git.v4"
@@ -42,6 +43,31 @@ func (*CmpSuite) TestParse(c *C) {
 	}
 }
 
+func (CmpSuite) TestParseFS(c *C) {
+	fsys := fstest.MapFS{
+		"contents_en/cat/.metadata.md":                {Data: []byte("[Name]: # (Category)\n[Order]: # (1)")},
+		"contents_en/cat/sub/.metadata.md":            {Data: []byte("[Name]: # (Sub)\n[Order]: # (1)")},
+		"contents_en/cat/sub/beginner/.metadata.md":   {Data: []byte("[Description]: # (Easy)")},
+		"contents_en/cat/sub/beginner/.checks.md":     {Data: []byte("[Text]: # (Check)\n[NoCheck]: # (false)")},
+		"contents_en/cat/sub/beginner/second.md":      {Data: []byte("[Title]: # (Second)\n[Order]: # (2)\n\nBody")},
+		"contents_en/cat/sub/beginner/first.md":       {Data: []byte("[Title]: # (First)\n[Order]: # (1)\n\nBody")},
+		"forms_en/form.md":                            {Data: []byte("[Name]: # (Form)")},
+		"README.md":                                   {Data: []byte("# Readme")},
+		"contents_en/cat/sub/beginner/bad.md/note.md": {Data: []byte("ignored")},
+	}
+	var p Parser
+	c.Assert(p.ParseFS(fsys), NotNil)
+	delete(fsys, "contents_en/cat/sub/beginner/bad.md/note.md")
+	delete(fsys, "README.md")
+	c.Assert(p.ParseFS(fsys), IsNil)
+	cats := p.Categories()["en"]
+	c.Assert(cats, HasLen, 1)
+	diff := cats[0].Sub("sub").Difficulty("beginner")
+	c.Assert(diff.ItemNames(), DeepEquals, []string{"first", "second"})
+	c.Assert(diff.Checks().Checks, DeepEquals, []Check{{Text: "Check"}})
+	c.Assert(p.Forms(), HasLen, 1)
+}
+
 func (CmpSuite) TestForm(c *C) {
 	var f Form
 
@@ -78,3 +104,46 @@ func (CmpSuite) TestForm(c *C) {
 
 	c.Logf("%v", f)
 }
+
+func (CmpSuite) TestParseFSCorruptLocale(c *C) {
+	fsys := fstest.MapFS{
+		"contents_en/cat/.metadata.md":              {Data: []byte("[Name]: # (Category)\n[Order]: # (1)")},
+		"contents_en/cat/sub/.metadata.md":          {Data: []byte("[Name]: # (Sub)\n[Order]: # (1)")},
+		"contents_en/cat/sub/beginner/.metadata.md": {Data: []byte("[Description]: # (Easy)")},
+		"contents_en/cat/sub/beginner/item.md":      {Data: []byte("[Title]: # (Item)\n[Order]: # (1)\n\nBody")},
+		"contents_it/cat/.metadata.md":              {Data: []byte("[Name]: # (Categoria)\n[Order]: # (1)")},
+		"contents_it/cat/sub/.metadata.md":          {Data: []byte("[Name]: # (Sotto)\n[Order]: # (1)")},
+		"contents_it/cat/sub/beginner/.metadata.md": {Data: []byte("[Description]: # (Facile)")},
+		"contents_it/cat/sub/beginner/item.md":      {Data: []byte("[Title]: # (Voce)\n[Order]: # (uno)\n\nTesto")},
+		"forms_en/form.md":                          {Data: []byte("[Name]: # (Form)")},
+		"forms_it/form.md":                          {Data: []byte("[Name]: # (Modulo)")},
+	}
+	// strict, the first error fails the parse
+	var p Parser
+	c.Assert(p.ParseFS(fsys), ErrorMatches, `\[contents\]contents_it/cat/sub/beginner/item.md - .*`)
+
+	r, err := p.ParseFSPartial(fsys)
+	c.Assert(err, IsNil)
+	cats := p.Categories()
+	c.Assert(cats["it"], HasLen, 0)
+	c.Assert(cats["en"], HasLen, 1)
+	c.Assert(cats["en"][0].Sub("sub").Difficulty("beginner").ItemNames(), DeepEquals, []string{"item"})
+	c.Assert(p.Forms(), HasLen, 1)
+	c.Assert(p.Forms()[0].Locale, Equals, "en")
+	c.Assert(r.Loaded, DeepEquals, []string{"en"})
+	c.Assert(r.Failed, HasLen, 1)
+	c.Assert(r.Failed["it"], ErrorMatches, `\[contents\]contents_it/cat/sub/beginner/item.md - .*`)
+
+	// a file without locale still fails the whole parse
+	fsys["README.md"] = &fstest.MapFile{Data: []byte("# Readme")}
+	_, err = p.ParseFSPartial(fsys)
+	c.Assert(err, NotNil)
+	delete(fsys, "README.md")
+
+	// nothing left to load
+	fsys["contents_en/cat/sub/beginner/item.md"] = &fstest.MapFile{Data: []byte("no body")}
+	r, err = p.ParseFSPartial(fsys)
+	c.Assert(err, ErrorMatches, `\[contents\]contents_en/.*`)
+	c.Assert(r.Loaded, HasLen, 0)
+	c.Assert(r.Failed, HasLen, 2)
+}
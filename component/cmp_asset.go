@@ -2,12 +2,15 @@ package component
 
 import (
 	"fmt"
+	"mime"
+	"net/http"
+	"path"
 	"regexp"
 )
 
 type Asset struct {
 	ID      string `json:"id"`
-	Hash    string `json:"hash,omAssetpty"`
+	Hash    string `json:"hash,omitempty"`
 	Content string `json:"content"`
 }
 
@@ -19,6 +22,15 @@ func (*Asset) HasChildren() bool {
 	return false
 }
 
+// ContentType returns the media type of the asset, from the extension of its ID or,
+// if it's unknown, from its content
+func (a *Asset) ContentType() string {
+	if t := mime.TypeByExtension(path.Ext(a.ID)); t != "" {
+		return t
+	}
+	return http.DetectContentType([]byte(a.Content))
+}
+
 func (a *Asset) SHA() string {
 	return a.Hash
 }
@@ -12,14 +12,16 @@ type Category struct {
 	Hash          string  `json:"hash"`
 	Locale        string  `json:"-"`
 	Order         float64 `json:"-"`
+	SourceLocale  string  `json:"source_locale,omitempty"` // locale of the content, if a fallback, see Resolve
 	subcategories []*Subcategory
+	subIndex      idIndex
 }
 
 func (c *Category) Resource() Resource {
 	return Resource{
-		Slug: c.ID,
+		Slug: EncodeSlug(c.ID),
 		Content: []map[string]string{
-			map[string]string{"name": c.Name},
+			map[string]string{KeyName: c.Name},
 		},
 	}
 }
@@ -32,19 +34,23 @@ func (c *Category) SHA() string {
 	return c.Hash
 }
 
-func (c *Category) Tree(html bool) interface{} {
+// Tree returns the category and its descendants as shown to users, with the texts encoded
+func (c *Category) Tree(enc Encoding) interface{} {
 	var subs = make([]interface{}, 0, len(c.subcategories))
 	for i := range c.subcategories {
-		subs = append(subs, c.subcategories[i].Tree(html))
+		subs = append(subs, c.subcategories[i].Tree(enc))
 	}
 	return map[string]interface{}{
 		"id":            c.ID,
-		"name":          c.Name,
+		"name":          enc.text(c.Name),
 		"subcategories": subs,
 	}
 }
 
-func (c *Category) MarshalJSON() ([]byte, error) {
+func (c *Category) MarshalJSON() ([]byte, error) { return json.Marshal(c.Fields()) }
+
+// Fields returns the fields of the category shown by the API, in any encoding
+func (c *Category) Fields() map[string]interface{} {
 	var m = map[string]interface{}{
 		"name":          c.Name,
 		"subcategories": c.Subcategories(),
@@ -52,18 +58,21 @@ func (c *Category) MarshalJSON() ([]byte, error) {
 	if c.Hash != "" {
 		m["hash"] = c.Hash
 	}
-	return json.Marshal(m)
+	if c.SourceLocale != "" {
+		m["source_locale"] = c.SourceLocale
+	}
+	return m
 }
 
 func (c *Category) Sub(ID string) *Subcategory {
-	for _, v := range c.subcategories {
-		if v.ID == ID {
-			return v
-		}
+	if i := c.subIndex.find(len(c.subcategories), c.subID, ID); i >= 0 {
+		return c.subcategories[i]
 	}
 	return nil
 }
 
+func (c *Category) subID(i int) string { return c.subcategories[i].ID }
+
 func (c *Category) Subcategories() []string {
 	var r = make([]string, 0, len(c.subcategories))
 	for _, v := range c.subcategories {
@@ -72,11 +81,16 @@ func (c *Category) Subcategories() []string {
 	return r
 }
 
-func (c *Category) Add(subs ...*Subcategory) {
+func (c *Category) Add(subs ...*Subcategory) error {
 	for _, v := range subs {
+		if c.Sub(v.ID) != nil {
+			return fmt.Errorf("Subcategory %s exists in %s", v.ID, c.ID)
+		}
 		v.parent = c
+		c.subIndex.add(len(c.subcategories), c.subID, v.ID)
+		c.subcategories = append(c.subcategories, v)
 	}
-	c.subcategories = append(c.subcategories, subs...)
+	return nil
 }
 
 func (c *Category) basePath() string {
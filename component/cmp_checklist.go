@@ -8,33 +8,65 @@ import (
 )
 
 type Checklist struct {
-	parent *Difficulty
-	Hash   string  `json:"hash"`
-	Checks []Check `json:"checks"`
+	parent       *Difficulty
+	Hash         string  `json:"hash"`
+	Checks       []Check `json:"checks"`
+	SourceLocale string  `json:"source_locale,omitempty"` // see Category.SourceLocale
 }
 
 func (c *Checklist) Resource() Resource {
 	var content = make([]map[string]string, 0, len(c.Checks))
 	for _, c := range c.Checks {
-		content = append(content, map[string]string{
-			"text": c.Text,
-		})
+		row := map[string]string{KeyText: stripNotes(c.Text)}
+		if c.NoCheck && c.Style != "" {
+			row[KeyStyle] = c.Style
+		}
+		if c.ID != "" {
+			row[KeyID] = c.ID
+		}
+		content = append(content, row)
 	}
 	return Resource{
-		Slug:    c.parent.Resource().Slug + "_" + "_checks",
+		Slug:    appendSlug(c.parent.Resource().Slug, "", "checks"),
 		Content: content,
 	}
 }
 
+// Styles of the informational steps of a checklist, the ones with NoCheck
+const (
+	StyleInfo    = "info"
+	StyleWarning = "warning"
+	StyleTip     = "tip"
+)
+
+func validStyle(s string) bool { return s == StyleInfo || s == StyleWarning || s == StyleTip }
+
 type Check struct {
 	Text    string `json:"text"`
 	NoCheck bool   `json:"no_check"`
+	Style   string `json:"style,omitempty"` // only for NoCheck, empty is StyleInfo
+	ID      string `json:"id,omitempty"`    // optional, translated rows with an ID match it, not the position
+
+	// Untranslated marks a check that kept the base text, see SetStrictChecklists
+	Untranslated bool `json:"untranslated,omitempty"`
 }
 
-func (*Check) order() []string     { return []string{"Text", "NoCheck"} }
-func (*Check) optionals() []string { return nil }
-func (c *Check) pointers() args    { return args{&c.Text, &c.NoCheck} }
-func (c *Check) values() args      { return args{c.Text, c.NoCheck} }
+// DisplayStyle returns the style of an informational step, with the default applied,
+// and an empty string for the others
+func (c Check) DisplayStyle() string {
+	switch {
+	case !c.NoCheck:
+		return ""
+	case c.Style == "":
+		return StyleInfo
+	}
+	return c.Style
+}
+
+func (*Check) order() []string     { return []string{"Text", "NoCheck", "Style", "ID"} }
+func (*Check) optionals() []string { return []string{"Style", "ID"} }
+func (c *Check) pointers() args    { return args{&c.Text, &c.NoCheck, &c.Style, &c.ID} }
+func (c *Check) values() args      { return args{c.Text, c.NoCheck, c.Style, c.ID} }
 
 func (c *Checklist) SetParent(d *Difficulty) {
 	c.parent = d
@@ -81,14 +113,60 @@ func (c *Checklist) SetContents(contents string) error {
 		return nil
 	}
 	parts := strings.Split(contents, bodySeparator)
-	var checks = make([]Check, len(parts))
+	var (
+		checks = make([]Check, len(parts))
+		ids    = make(map[string]bool)
+	)
 	for i, v := range parts {
 		if err := setMeta(v, &checks[i]); err != nil {
 			return err
 		}
+		if s := checks[i].Style; s != "" && (!checks[i].NoCheck || !validStyle(s)) {
+			return fmt.Errorf("Invalid style %q", s)
+		}
+		if id := checks[i].ID; id != "" {
+			if ids[id] {
+				return fmt.Errorf("Repeated check ID %q", id)
+			}
+			ids[id] = true
+		}
 	}
 	c.Checks = checks
 	return nil
 }
 
 func (c *Checklist) Add(v ...Check) { c.Checks = append(c.Checks, v...) }
+
+// orderRows returns the rows of a translation in the order of the checks. Rows are matched by
+// position or, if any has one, by ID: then every row needs the ID of a check.
+func (c *Checklist) orderRows(rows []map[string]string, locale string) ([]map[string]string, error) {
+	var ids bool
+	for _, row := range rows {
+		if row[KeyID] != "" {
+			ids = true
+		}
+	}
+	if !ids {
+		return rows, nil
+	}
+	var ordered = make([]map[string]string, len(c.Checks))
+	for i, row := range rows {
+		id, j := strings.TrimSpace(row[KeyID]), -1
+		for k := range c.Checks {
+			if id != "" && c.Checks[k].ID == id {
+				j = k
+				break
+			}
+		}
+		switch {
+		case id == "":
+			return nil, rowError(c, locale, i+1, ErrInvalidValue, "missing check ID")
+		case j < 0:
+			return nil, rowError(c, locale, i+1, ErrInvalidValue, "unknown check ID %q", id)
+		case ordered[j] != nil:
+			return nil, rowError(c, locale, i+1, ErrInvalidValue, "repeated check ID %q", id)
+		}
+		ordered[j] = row
+	}
+	return ordered, nil
+}
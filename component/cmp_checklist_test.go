@@ -0,0 +1,97 @@
+package component
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func styledChecklist() *Checklist {
+	diff := testCategory("en", "").Sub("sub").Difficulty("beginner")
+	diff.AddChecks(
+		Check{Text: "Check"},
+		Check{Text: "Note", NoCheck: true},
+		Check{Text: "Careful", NoCheck: true, Style: StyleWarning},
+		Check{Text: "Hint", NoCheck: true, Style: StyleTip},
+	)
+	return diff.checklist
+}
+
+func TestCheckStyleContents(t *testing.T) {
+	list := styledChecklist()
+	var c Checklist
+	if err := c.SetContents(list.Contents()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Checks, list.Checks) {
+		t.Fatalf("expected %v, got %v", list.Checks, c.Checks)
+	}
+	if strings.Contains(list.Contents(), "(info)") {
+		t.Fatalf("default style written: %s", list.Contents())
+	}
+	for _, contents := range []string{
+		"[Text]: # (Check)\n[NoCheck]: # (false)\n[Style]: # (tip)",
+		"[Text]: # (Note)\n[NoCheck]: # (true)\n[Style]: # (loud)",
+	} {
+		if err := c.SetContents(contents); err == nil {
+			t.Errorf("%q: expected error", contents)
+		}
+	}
+}
+
+func TestCheckStyleResource(t *testing.T) {
+	list := styledChecklist()
+	var styles []string
+	for _, row := range list.Resource().Content {
+		styles = append(styles, row[KeyStyle])
+	}
+	if expected := []string{"", "", StyleWarning, StyleTip}; !reflect.DeepEqual(styles, expected) {
+		t.Fatalf("expected %q, got %q", expected, styles)
+	}
+
+	styles = nil
+	for _, c := range list.parent.Tree(RawMarkdown).(map[string]interface{})["checks"].([]Check) {
+		styles = append(styles, c.Style)
+	}
+	if expected := []string{"", StyleInfo, StyleWarning, StyleTip}; !reflect.DeepEqual(styles, expected) {
+		t.Fatalf("expected %q, got %q", expected, styles)
+	}
+}
+
+func TestParseCheckStyle(t *testing.T) {
+	list := styledChecklist()
+	rows := func(styles ...string) *Resource {
+		var res Resource
+		for i, s := range styles {
+			res.Content = append(res.Content, map[string]string{KeyText: list.Checks[i].Text + " (it)", KeyStyle: s})
+		}
+		return &res
+	}
+	// the styles are the ones of the source, different ones are reported
+	base := []string{"", "", StyleWarning, StyleTip}
+	for _, tc := range []struct {
+		res      *Resource
+		problems int
+	}{
+		{rows("", "", "", ""), 0},
+		{rows("", "", StyleWarning, StyleTip), 0},
+		{rows("", StyleTip, StyleInfo, " warning "), 3},
+		{rows(StyleTip, "", "", ""), 1},
+		{rows("", "loud", "", ""), 1},
+	} {
+		p := NewResourceParser()
+		if err := p.Parse(list, tc.res, "it"); err != nil {
+			t.Fatal(err)
+		}
+		var styles []string
+		for _, c := range p.lookup("it", "cat/sub/beginner/.checks").(*Checklist).Checks {
+			styles = append(styles, c.Style)
+		}
+		if !reflect.DeepEqual(styles, base) {
+			t.Errorf("expected %q, got %q", base, styles)
+		}
+		if len(p.Problems()) != tc.problems {
+			t.Errorf("expected %d problems, got %v", tc.problems, p.Problems())
+		}
+	}
+}
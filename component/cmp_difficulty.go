@@ -7,19 +7,22 @@ import (
 )
 
 type Difficulty struct {
-	parent    *Subcategory
-	ID        string `json:"id"`
-	Descr     string `json:"description"`
-	Hash      string `json:"hash"`
-	items     []*Item
-	checklist *Checklist
+	parent       *Subcategory
+	ID           string `json:"id"`
+	Descr        string `json:"description"`
+	Hash         string `json:"hash"`
+	SourceLocale string `json:"source_locale,omitempty"` // see Category.SourceLocale
+	items        []*Item
+	itemIndex    idIndex
+	checklist    *Checklist
+	quiz         *Quiz
 }
 
 func (d *Difficulty) Resource() Resource {
 	return Resource{
-		Slug: d.parent.Resource().Slug + "_" + d.ID,
+		Slug: appendSlug(d.parent.Resource().Slug, d.ID),
 		Content: []map[string]string{
-			map[string]string{"description": d.Descr},
+			map[string]string{KeyDescription: d.Descr},
 		},
 	}
 }
@@ -28,20 +31,38 @@ func (d *Difficulty) HasChildren() bool {
 	return len(d.items) != 0
 }
 
-func (d *Difficulty) Tree(html bool) interface{} {
+func (d *Difficulty) Tree(enc Encoding) interface{} {
 	var items = make([]Item, len(d.items))
 	for i, v := range d.items {
 		items[i] = *v
-		if html {
-			items[i].Body = items[i].htmlBody
+		items[i].Title = enc.text(v.Title)
+		items[i].Abstract = enc.text(items[i].Summary())
+		if c := v.rendered; enc == SafeHTML && c.body == v.Body {
+			items[i].Body = c.html
+		} else {
+			items[i].Body = enc.body(v.Body)
 		}
+		items[i].Paragraphs = enc.paragraphs(v.Body)
+		items[i].Snippets = v.CodeSnippets()
 	}
-	return map[string]interface{}{
+	var checks = make([]Check, 0)
+	if d.checklist != nil {
+		for _, c := range d.checklist.Checks {
+			c.Text = enc.text(c.Text)
+			c.Style = c.DisplayStyle()
+			checks = append(checks, c)
+		}
+	}
+	var m = map[string]interface{}{
 		"id":          d.ID,
-		"description": d.Descr,
+		"description": enc.text(d.Descr),
 		"items":       items,
-		"checks":      d.checklist.Checks,
+		"checks":      checks,
+	}
+	if d.quiz != nil && len(d.quiz.Questions) != 0 {
+		m["quiz"] = d.quiz.tree(enc)
 	}
+	return m
 }
 
 func (d *Difficulty) SHA() string {
@@ -52,7 +73,10 @@ func (d *Difficulty) SetParent(s *Subcategory) {
 	d.parent = s
 }
 
-func (d *Difficulty) MarshalJSON() ([]byte, error) {
+func (d *Difficulty) MarshalJSON() ([]byte, error) { return json.Marshal(d.Fields()) }
+
+// Fields returns the fields of the difficulty shown by the API, in any encoding
+func (d *Difficulty) Fields() map[string]interface{} {
 	var m = map[string]interface{}{
 		"description": d.Descr,
 		"items":       d.ItemNames(),
@@ -61,7 +85,10 @@ func (d *Difficulty) MarshalJSON() ([]byte, error) {
 	if d.Hash != "" {
 		m["hash"] = d.Hash
 	}
-	return json.Marshal(m)
+	if d.SourceLocale != "" {
+		m["source_locale"] = d.SourceLocale
+	}
+	return m
 }
 
 func (d *Difficulty) Items() []Item {
@@ -108,26 +135,42 @@ func (d *Difficulty) SetChecks(c *Checklist) {
 	c.parent = d
 }
 
+// Quiz returns a copy of the quiz of the difficulty, nil if it has none
+func (d *Difficulty) Quiz() *Quiz {
+	if d.quiz == nil {
+		return nil
+	}
+	q := d.quiz.Copy()
+	q.parent = d
+	return q
+}
+
+func (d *Difficulty) SetQuiz(q *Quiz) {
+	d.quiz = q
+	q.parent = d
+}
+
 func (d *Difficulty) AddItem(items ...*Item) error {
 	for _, v := range items {
 		if d.Item(v.ID) != nil {
 			return fmt.Errorf("item %s exists in %s/%s", v.ID, d.parent.ID, d.ID)
 		}
 		v.parent = d
+		d.itemIndex.add(len(d.items), d.itemID, v.ID)
+		d.items = append(d.items, v)
 	}
-	d.items = append(d.items, items...)
 	return nil
 }
 
 func (d *Difficulty) Item(id string) *Item {
-	for _, v := range d.items {
-		if v.ID == id {
-			return v
-		}
+	if i := d.itemIndex.find(len(d.items), d.itemID, id); i >= 0 {
+		return d.items[i]
 	}
 	return nil
 }
 
+func (d *Difficulty) itemID(i int) string { return d.items[i].ID }
+
 func (d *Difficulty) basePath() string {
 	return fmt.Sprintf("%s/%s", d.parent.basePath(), d.ID)
 }
@@ -156,7 +199,7 @@ func (d *Difficulty) Contents() string { return getMeta(d) }
 
 func (d *Difficulty) SetContents(contents string) error {
 	if d.checklist == nil {
-		d.checklist = new(Checklist)
+		d.SetChecks(new(Checklist))
 	}
 	return setMeta(contents, d)
 }
@@ -8,35 +8,61 @@ import (
 )
 
 type Form struct {
-	ID      string       `json:"id"`
-	Name    string       `json:"name"`
-	Hash    string       `json:"hash,omitempty"`
-	Locale  string       `json:"-"`
-	Screens []FormScreen `json:"screens,omitempty"`
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Hash         string       `json:"hash,omitempty"`
+	Locale       string       `json:"-"`
+	Screens      []FormScreen `json:"screens,omitempty"`
+	SourceLocale string       `json:"source_locale,omitempty"` // see Category.SourceLocale
 }
 
 func (f *Form) Resource() Resource {
 	contents := []map[string]string{
-		map[string]string{"form": f.Name},
+		map[string]string{KeyForm: f.Name},
 	}
 	for _, s := range f.Screens {
-		contents = append(contents, map[string]string{"screen": s.Name})
+		row := map[string]string{KeyScreen: s.Name}
+		if s.ID != "" {
+			row[KeyID] = s.ID
+		}
+		contents = append(contents, row)
 		for _, i := range s.Items {
 			contents = append(contents, map[string]string{
-				"label":   i.Label,
-				"hint":    i.Hint,
-				"options": strings.Join(i.Options, ";"),
+				KeyLabel:   stripNotes(i.Label),
+				KeyHint:    stripNotes(i.Hint),
+				KeyOptions: strings.Join(i.Options, ";"),
 			})
 		}
 	}
 	return Resource{
-		Slug:    "forms___" + f.ID,
+		Slug:    EncodeSlug("forms", "_", f.ID),
 		Content: contents,
 	}
 }
 
 func (f *Form) HasChildren() bool { return false }
 
+// Tree returns a copy of the form as shown to users
+func (f *Form) Tree(enc Encoding) interface{} {
+	var v = *f
+	v.Name = enc.text(f.Name)
+	v.Screens = make([]FormScreen, len(f.Screens))
+	for i, s := range f.Screens {
+		v.Screens[i] = FormScreen{ID: s.ID, Name: enc.text(s.Name), Items: make([]FormInput, len(s.Items)), ShowIf: s.ShowIf}
+		for j, item := range s.Items {
+			item.Label, item.Hint = enc.text(item.Label), enc.text(item.Hint)
+			if item.Options != nil {
+				item.Options = make([]string, len(s.Items[j].Options))
+				for k, o := range s.Items[j].Options {
+					item.Options[k] = enc.text(o)
+				}
+			}
+			v.Screens[i].Items[j] = item
+		}
+	}
+	return &v
+}
+
 func (f *Form) SHA() string { return f.Hash }
 
 func (f *Form) Path() string {
@@ -104,39 +130,127 @@ func (f *Form) SetContents(contents string) error {
 		}
 
 	}
-	return nil
+	return f.checkConditions()
+}
+
+// ScreenIDs returns the ID of each screen: the explicit one if set, otherwise
+// the slug of the screen name, de-duplicated with a numeric suffix.
+func (f *Form) ScreenIDs() []string {
+	var (
+		ids  = make([]string, len(f.Screens))
+		used = make(map[string]bool)
+	)
+	for i, s := range f.Screens {
+		if s.ID != "" {
+			ids[i], used[s.ID] = s.ID, true
+		}
+	}
+	for i, s := range f.Screens {
+		if ids[i] != "" {
+			continue
+		}
+		base := makeID(s.Name)
+		if base == "" {
+			base = "screen"
+		}
+		id := base
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		ids[i], used[id] = id, true
+	}
+	return ids
+}
+
+var (
+	idClean  = regexp.MustCompile(`[^[:alpha:]\s\d_]`)
+	idSpaces = regexp.MustCompile(`[\s-]+`)
+)
+
+// makeID returns a lowercase slug of s
+func makeID(s string) string {
+	s = idClean.ReplaceAllString(s, " ")
+	s = idSpaces.ReplaceAllString(strings.TrimSpace(s), "-")
+	return strings.ToLower(s)
 }
 
 type FormScreen struct {
-	Name  string      `json:"name"`
-	Items []FormInput `json:"items,omitempty"`
+	ID     string      `json:"id,omitempty"`
+	Name   string      `json:"name"`
+	Items  []FormInput `json:"items,omitempty"`
+	ShowIf string      `json:"show_if,omitempty"` // the screen is shown only if it holds, see FormCondition
+}
+
+func (*FormScreen) order() []string     { return []string{"Type", "Name", "ID", "ShowIf"} }
+func (*FormScreen) optionals() []string { return []string{"ID", "ShowIf"} }
+func (f *FormScreen) pointers() args    { var s string; return args{&s, &f.Name, &f.ID, &f.ShowIf} }
+func (f *FormScreen) values() args      { return args{"screen", f.Name, f.ID, f.ShowIf} }
+
+// Types of FormInput, choices have options
+const (
+	InputText           = "text_input"
+	InputTextArea       = "text_area"
+	InputSingleChoice   = "single_choice"
+	InputMultipleChoice = "multiple_choice"
+	InputCheckbox       = "checkbox"
+	InputDate           = "date"
+	InputNumber         = "number"
+	InputFile           = "file"
+)
+
+// inputTypes tells which types are known, and if they have options
+var inputTypes = map[string]bool{
+	InputText: false, InputTextArea: false, InputSingleChoice: true, InputMultipleChoice: true,
+	InputCheckbox: false, InputDate: false, InputNumber: false, InputFile: false,
 }
 
-func (*FormScreen) order() []string     { return []string{"Type", "Name"} }
-func (*FormScreen) optionals() []string { return nil }
-func (f *FormScreen) pointers() args    { var s string; return args{&s, &f.Name} }
-func (f *FormScreen) values() args      { return args{"screen", f.Name} }
+// checkOptions returns what is wrong with the translated options of the input, an empty string
+// if nothing. Inputs of unknown type are checked only for the number of options.
+func (f *FormInput) checkOptions(options []string) string {
+	choice, known := inputTypes[f.Type]
+	switch {
+	case known && !choice && len(options) != 0:
+		return fmt.Sprintf("%d options, %s has none", len(options), f.Type)
+	case f.Options != nil && len(options) != len(f.Options):
+		return fmt.Sprintf("%d options, %d expected", len(options), len(f.Options))
+	case choice:
+		var n int
+		for _, o := range options {
+			if strings.TrimSpace(o) != "" {
+				n++
+			}
+		}
+		if n < 2 {
+			return fmt.Sprintf("%d non-empty options, %s needs at least 2", n, f.Type)
+		}
+	}
+	return ""
+}
 
 type FormInput struct {
-	Type    string   `json:"type"`
-	Name    string   `json:"name,omitempty"`
-	Label   string   `json:"label,omitempty"`
-	Value   []string `json:"value,omitempty"`
-	Options []string `json:"options,omitempty"`
-	Hint    string   `json:"hint,omitempty"`
-	Lines   int      `json:"lines,omitempty"`
+	Type        string   `json:"type"`
+	Name        string   `json:"name,omitempty"`
+	Label       string   `json:"label,omitempty"`
+	Value       []string `json:"value,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	Hint        string   `json:"hint,omitempty"`
+	Lines       int      `json:"lines,omitempty"`
+	MultiSelect bool     `json:"multi_select,omitempty"`
+	OtherOption bool     `json:"other_option,omitempty"`
+	Required    bool     `json:"required,omitempty"` // must be answered, see FormResponse.Validate
+	ShowIf      string   `json:"show_if,omitempty"`  // the input is shown only if it holds, see FormCondition
 }
 
 func (*FormInput) order() []string {
-	return []string{"Type", "Name", "Label", "Value", "Options", "Hint", "Lines"}
+	return []string{"Type", "Name", "Label", "Value", "Options", "Hint", "Lines", "MultiSelect", "OtherOption", "Required", "ShowIf"}
 }
 func (*FormInput) optionals() []string {
-	return []string{"Value", "Options", "Hint", "Lines"}
+	return []string{"Value", "Options", "Hint", "Lines", "MultiSelect", "OtherOption", "Required", "ShowIf"}
 }
 
 func (f *FormInput) pointers() args {
-	return args{&f.Type, &f.Name, &f.Label, &f.Value, &f.Options, &f.Hint, &f.Lines}
+	return args{&f.Type, &f.Name, &f.Label, &f.Value, &f.Options, &f.Hint, &f.Lines, &f.MultiSelect, &f.OtherOption, &f.Required, &f.ShowIf}
 }
 func (f *FormInput) values() args {
-	return args{f.Type, f.Name, f.Label, f.Value, f.Options, f.Hint, f.Lines}
+	return args{f.Type, f.Name, f.Label, f.Value, f.Options, f.Hint, f.Lines, f.MultiSelect, f.OtherOption, f.Required, f.ShowIf}
 }
@@ -0,0 +1,50 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScreenIDs(t *testing.T) {
+	var form = Form{ID: "form", Screens: []FormScreen{
+		{Name: "Personal details"}, {Name: "Contacts", ID: "people"}, {Name: "Notes"}, {Name: "Notes"}, {Name: "?!"},
+	}}
+	expected := []string{"personal-details", "people", "notes", "notes-2", "screen"}
+	if ids := form.ScreenIDs(); !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+	form.Screens = append(form.Screens[:1], append([]FormScreen{{Name: "Location"}}, form.Screens[1:]...)...)
+	expected = []string{"personal-details", "location", "people", "notes", "notes-2", "screen"}
+	if ids := form.ScreenIDs(); !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestScreenIDRoundtrip(t *testing.T) {
+	var form = Form{ID: "form", Name: "Form", Screens: []FormScreen{
+		{Name: "One", ID: "first", Items: []FormInput{{Type: "text_input", Name: "a", Label: "A"}}},
+		{Name: "Two", Items: []FormInput{{Type: "text_input", Name: "b", Label: "B"}}},
+	}}
+	var f Form
+	if err := f.SetContents(form.Contents()); err != nil {
+		t.Fatal(err)
+	}
+	if f.Screens[0].ID != "first" || f.Screens[1].ID != "" {
+		t.Fatalf("unexpected screens %+v", f.Screens)
+	}
+
+	res := form.Resource()
+	res.Content[1]["screen"], res.Content[2]["label"] = "Uno", "A"
+	res.Content[3]["screen"], res.Content[4]["label"] = "Due", "B"
+	res.Content[3]["id"] = "due"
+	p := NewResourceParser()
+	if err := p.Parse(&form, &res, "it"); err != nil {
+		t.Fatal(err)
+	}
+	if ids := p.forms["it"][0].ScreenIDs(); !reflect.DeepEqual(ids, []string{"first", "two"}) {
+		t.Fatalf("translated screens should keep base IDs, got %v", ids)
+	}
+	if problems := p.Problems(); len(problems) != 1 || problems[0].Message != `screen "Two": ID "due" ignored, the source has "two"` {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+}
@@ -0,0 +1,112 @@
+package component
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Glossary is a list of terms with their definitions, for a locale. It's a file of the
+// glossary_xx folder, like glossary_en/terms.md, with a block for each term:
+//
+//	[Term]: # (Phishing)
+//	[Definition]: # (A message that pretends to come from someone you trust)
+type Glossary struct {
+	ID           string         `json:"id"`
+	Hash         string         `json:"hash,omitempty"`
+	Locale       string         `json:"-"`
+	Terms        []GlossaryTerm `json:"terms"`
+	SourceLocale string         `json:"source_locale,omitempty"` // see Category.SourceLocale
+}
+
+// GlossaryTerm is a term of a Glossary
+type GlossaryTerm struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition"`
+}
+
+// ID returns the slug of the term, its words in lower case joined by "-", used to link it,
+// see TermAnnotator
+func (t GlossaryTerm) ID() string {
+	notWord := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	return strings.Join(strings.FieldsFunc(strings.ToLower(t.Term), notWord), "-")
+}
+
+func (*GlossaryTerm) order() []string     { return []string{"Term", "Definition"} }
+func (*GlossaryTerm) optionals() []string { return nil }
+func (t *GlossaryTerm) pointers() args    { return args{&t.Term, &t.Definition} }
+func (t *GlossaryTerm) values() args      { return args{t.Term, t.Definition} }
+
+func (g *Glossary) Resource() Resource {
+	var content = make([]map[string]string, 0, len(g.Terms))
+	for _, t := range g.Terms {
+		content = append(content, map[string]string{
+			KeyTerm:       stripNotes(t.Term),
+			KeyDefinition: stripNotes(t.Definition),
+		})
+	}
+	return Resource{
+		Slug:    EncodeSlug("glossary", "_", g.ID),
+		Content: content,
+	}
+}
+
+func (g *Glossary) HasChildren() bool { return false }
+
+func (g *Glossary) SHA() string { return g.Hash }
+
+func (g *Glossary) Path() string {
+	var loc string
+	if g.Locale != "" {
+		loc = "_" + g.Locale
+	}
+	return fmt.Sprintf("glossary%s/%s%s", loc, g.ID, fileExt)
+}
+
+var glossaryPath = regexp.MustCompile("glossary_([a-z]{2})/([^/]+).md")
+
+func (g *Glossary) SetPath(filepath string) error {
+	p := glossaryPath.FindStringSubmatch(filepath)
+	if len(p) == 0 {
+		return ErrContent
+	}
+	g.Locale = p[1]
+	g.ID = p[2]
+	return nil
+}
+
+func (g *Glossary) Contents() string {
+	b := bytes.NewBuffer(nil)
+	for i := range g.Terms {
+		if i != 0 {
+			fmt.Fprint(b, bodySeparator)
+		}
+		fmt.Fprint(b, getMeta(&g.Terms[i]))
+	}
+	return b.String()
+}
+
+func (g *Glossary) SetContents(contents string) error {
+	if contents == "" {
+		g.Terms = nil
+		return nil
+	}
+	parts := strings.Split(contents, bodySeparator)
+	var terms = make([]GlossaryTerm, len(parts))
+	for i, v := range parts {
+		if err := setMeta(v, &terms[i]); err != nil {
+			return err
+		}
+	}
+	g.Terms = terms
+	return nil
+}
+
+// Copy returns a deep copy of the glossary
+func (g *Glossary) Copy() *Glossary {
+	v := *g
+	v.Terms = append([]GlossaryTerm(nil), g.Terms...)
+	return &v
+}
@@ -4,32 +4,60 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
-
-	"github.com/russross/blackfriday"
 )
 
 const paragraphSep = "\n\n"
 
+// SplitBody returns the paragraphs of a body, trimmed and without empty ones.
+// It's the inverse of JoinBody, clients should use it instead of splitting bodies themselves.
+func SplitBody(body string) []string {
+	var list []string
+	for _, p := range strings.Split(body, paragraphSep) {
+		if p = strings.TrimSpace(p); p != "" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// JoinBody returns the body made of the paragraphs, the inverse of SplitBody
+func JoinBody(paragraphs []string) string { return strings.Join(paragraphs, paragraphSep) }
+
 type Item struct {
 	parent   *Difficulty
 	ID       string `json:"id"`
 	Hash     string `json:"hash,omitempty"`
 	Title    string `json:"title"`
 	Body     string `json:"body"`
-	htmlBody string
-	Order    float64 `json:"-"`
+	rendered htmlCache
+	Order    float64  `json:"-"`
+	Audience []string `json:"audience,omitempty"`
+	Abstract string   `json:"summary,omitempty"` // explicit summary, see Summary
+	// Locale of the content, if a fallback, see Category.SourceLocale
+	SourceLocale string `json:"source_locale,omitempty"`
+	// Paragraphs of the body, encoded like it; only set by Tree
+	Paragraphs []string `json:"paragraphs,omitempty"`
+	// Code of the body, see CodeSnippets; only set by Tree
+	Snippets []Snippet `json:"snippets,omitempty"`
+	summary  summaryCache
+	snippets snippetCache
 }
 
 func (i *Item) Resource() Resource {
 	parts := strings.Split(i.Body, paragraphSep)
 	content := make([]map[string]string, len(parts)+1)
-	content[0] = map[string]string{"title": i.Title}
+	content[0] = map[string]string{KeyTitle: i.Title}
 	for i := range parts {
-		content[i+1] = map[string]string{"body": parts[i]}
+		content[i+1] = map[string]string{KeyBody: parts[i]}
+	}
+	row := map[string]string{KeyTitle: i.Title, KeyBody: stripBodyNotes(i.Body)}
+	if len(i.Audience) != 0 {
+		row[KeyAudience] = strings.Join(i.Audience, ";")
+	}
+	if i.Abstract != "" {
+		row[KeySummary] = i.Abstract
 	}
-	return Resource{Slug: i.parent.Resource().Slug + "_" + i.ID, Content: []map[string]string{
-		map[string]string{"title": i.Title, "body": i.Body},
-	}}
+	return Resource{Slug: appendSlug(i.parent.Resource().Slug, i.ID), Content: []map[string]string{row}}
 }
 
 func (i *Item) SetParent(d *Difficulty) {
@@ -59,10 +87,10 @@ func (i *Item) SetPath(filepath string) error {
 	return nil
 }
 
-func (*Item) order() []string     { return []string{"Title", "Order"} }
-func (*Item) optionals() []string { return nil }
-func (i *Item) pointers() args    { return args{&i.Title, &i.Order} }
-func (i *Item) values() args      { return args{i.Title, i.Order} }
+func (*Item) order() []string     { return []string{"Title", "Order", "Audience", "Summary"} }
+func (*Item) optionals() []string { return []string{"Audience", "Summary"} }
+func (i *Item) pointers() args    { return args{&i.Title, &i.Order, &i.Audience, &i.Abstract} }
+func (i *Item) values() args      { return args{i.Title, i.Order, i.Audience, i.Abstract} }
 
 func (i *Item) Contents() string {
 	return fmt.Sprint(getMeta(i), bodySeparator, i.Body)
@@ -77,6 +105,6 @@ func (i *Item) SetContents(contents string) error {
 		return err
 	}
 	i.Body = parts[1]
-	i.htmlBody = string(blackfriday.Run([]byte(i.Body)))
+	i.rendered = htmlCache{body: i.Body, html: SafeHTML.body(i.Body)}
 	return nil
 }
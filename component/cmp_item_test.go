@@ -0,0 +1,69 @@
+package component
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func TestSplitBody(t *testing.T) {
+	for _, tc := range []struct {
+		body       string
+		paragraphs []string
+	}{
+		{"", nil},
+		{"\n\n", nil},
+		{"One", []string{"One"}},
+		{"One\n\nTwo", []string{"One", "Two"}},
+		{"One\n\nTwo\n\n", []string{"One", "Two"}},
+		{"\n\nOne\n\n\n\n\n\nTwo", []string{"One", "Two"}},
+		{"One\n\n\nTwo", []string{"One", "Two"}},
+		{" One \nline\n\n\t Two ", []string{"One \nline", "Two"}},
+		{"One\n \nTwo", []string{"One\n \nTwo"}},
+	} {
+		if p := SplitBody(tc.body); !reflect.DeepEqual(p, tc.paragraphs) {
+			t.Errorf("%q: expected %q, got %q", tc.body, tc.paragraphs, p)
+		}
+	}
+}
+
+// bodyText is a random text made of few characters, so separators are frequent
+type bodyText string
+
+func (bodyText) Generate(r *rand.Rand, size int) reflect.Value {
+	const chars = "ab \n\n\t"
+	b := make([]byte, r.Intn(size+1))
+	for i := range b {
+		b[i] = chars[r.Intn(len(chars))]
+	}
+	return reflect.ValueOf(bodyText(b))
+}
+
+func TestSplitJoinBody(t *testing.T) {
+	// splitting a joined body gives the same paragraphs
+	split := func(x bodyText) bool {
+		p := SplitBody(string(x))
+		for _, s := range p {
+			if s == "" || s != strings.TrimSpace(s) {
+				return false
+			}
+		}
+		return reflect.DeepEqual(SplitBody(JoinBody(p)), p)
+	}
+	// a joined body is canonical: splitting and joining it again gives the same body
+	join := func(xs []bodyText) bool {
+		var p []string
+		for _, x := range xs {
+			p = append(p, SplitBody(string(x))...)
+		}
+		body := JoinBody(p)
+		return JoinBody(SplitBody(body)) == body && reflect.DeepEqual(SplitBody(body), p)
+	}
+	for _, fn := range []interface{}{split, join} {
+		if err := quick.Check(fn, &quick.Config{MaxCount: 2000}); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package component
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Quiz is a list of multiple-choice questions of a difficulty, stored in its .quiz.md file
+type Quiz struct {
+	parent       *Difficulty
+	Hash         string     `json:"hash"`
+	Questions    []Question `json:"questions"`
+	SourceLocale string     `json:"source_locale,omitempty"` // see Category.SourceLocale
+}
+
+// Question is a question of a Quiz. Correct has the positions in Options, from 0, of the right
+// answers: they are the same in every locale.
+type Question struct {
+	Text        string   `json:"text"`
+	Options     []string `json:"options"`
+	Correct     []int    `json:"correct"`
+	Explanation string   `json:"explanation,omitempty"`
+}
+
+func (q *Question) order() []string     { return []string{"Question", "Options", "Correct", "Explanation"} }
+func (q *Question) optionals() []string { return []string{"Explanation"} }
+func (q *Question) pointers() args      { return args{&q.Text, &q.Options, &q.Correct, &q.Explanation} }
+func (q *Question) values() args        { return args{q.Text, q.Options, q.Correct, q.Explanation} }
+
+// validate checks that the question has a text, at least two options and valid correct ones
+func (q *Question) validate() error {
+	switch {
+	case strings.TrimSpace(q.Text) == "":
+		return fmt.Errorf("Empty question")
+	case len(q.Options) < 2:
+		return fmt.Errorf("Question %q has %d options, at least 2 expected", q.Text, len(q.Options))
+	case len(q.Correct) == 0:
+		return fmt.Errorf("Question %q has no correct option", q.Text)
+	}
+	var seen = make(map[int]bool)
+	for _, n := range q.Correct {
+		if n < 0 || n >= len(q.Options) || seen[n] {
+			return fmt.Errorf("Question %q has an invalid correct option %d", q.Text, n)
+		}
+		seen[n] = true
+	}
+	return nil
+}
+
+func (q *Quiz) Resource() Resource {
+	var content = make([]map[string]string, 0, len(q.Questions))
+	for _, v := range q.Questions {
+		row := map[string]string{KeyQuestion: stripNotes(v.Text), KeyOptions: strings.Join(v.Options, ";")}
+		if v.Explanation != "" {
+			row[KeyExplanation] = stripNotes(v.Explanation)
+		}
+		content = append(content, row)
+	}
+	return Resource{
+		Slug:    appendSlug(q.parent.Resource().Slug, "", "quiz"),
+		Content: content,
+	}
+}
+
+func (q *Quiz) HasChildren() bool {
+	return len(q.Questions) != 0
+}
+
+func (q *Quiz) SHA() string {
+	return q.Hash
+}
+
+func (q *Quiz) Path() string {
+	return fmt.Sprintf("%s/%s%s", q.parent.basePath(), suffixQuiz, fileExt)
+}
+
+var quizPath = regexp.MustCompile("contents(?:_[a-z]{2})?/[^/]+/[^/]+/[^/]+/.quiz.md")
+
+func (*Quiz) SetPath(filepath string) error {
+	p := quizPath.FindString(filepath)
+	if len(p) == 0 {
+		return ErrContent
+	}
+	return nil
+}
+
+func (q *Quiz) Contents() string {
+	b := bytes.NewBuffer(nil)
+	for i := range q.Questions {
+		if i != 0 {
+			fmt.Fprint(b, bodySeparator)
+		}
+		fmt.Fprint(b, getMeta(&q.Questions[i]))
+	}
+	return b.String()
+}
+
+func (q *Quiz) SetContents(contents string) error {
+	if contents == "" {
+		q.Questions = nil
+		return nil
+	}
+	parts := strings.Split(contents, bodySeparator)
+	var questions = make([]Question, len(parts))
+	for i, v := range parts {
+		if err := setMeta(v, &questions[i]); err != nil {
+			return err
+		}
+		if err := questions[i].validate(); err != nil {
+			return err
+		}
+	}
+	q.Questions = questions
+	return nil
+}
+
+// tree returns a copy of the questions with the texts encoded
+func (q *Quiz) tree(enc Encoding) []Question {
+	var list = make([]Question, len(q.Questions))
+	for i, v := range q.Questions {
+		v.Text, v.Explanation = enc.text(v.Text), enc.text(v.Explanation)
+		var options = make([]string, len(v.Options))
+		for j, o := range v.Options {
+			options[j] = enc.text(o)
+		}
+		v.Options, v.Correct = options, append([]int(nil), v.Correct...)
+		list[i] = v
+	}
+	return list
+}
+
+// Copy returns a deep copy of the quiz, without a parent
+func (q *Quiz) Copy() *Quiz {
+	quiz := *q
+	quiz.parent, quiz.Questions = nil, nil
+	for _, v := range q.Questions {
+		v.Options = copyStrings(v.Options)
+		v.Correct = append([]int(nil), v.Correct...)
+		quiz.Questions = append(quiz.Questions, v)
+	}
+	return &quiz
+}
@@ -4,23 +4,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 type Subcategory struct {
 	parent       *Category
-	ID           string  `json:"id"`
-	Name         string  `json:"name"`
-	Hash         string  `json:"hash"`
-	Order        float64 `json:"-"`
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Hash         string   `json:"hash"`
+	Order        float64  `json:"-"`
+	Audience     []string `json:"audience,omitempty"`
+	SourceLocale string   `json:"source_locale,omitempty"` // see Category.SourceLocale
 	difficulties []*Difficulty
+	diffIndex    idIndex
 }
 
 func (s *Subcategory) Resource() Resource {
+	row := map[string]string{KeyName: s.Name}
+	if len(s.Audience) != 0 {
+		row[KeyAudience] = strings.Join(s.Audience, ";")
+	}
 	return Resource{
-		Slug: s.parent.Resource().Slug + "_" + s.ID,
-		Content: []map[string]string{
-			map[string]string{"name": s.Name},
-		},
+		Slug:    appendSlug(s.parent.Resource().Slug, s.ID),
+		Content: []map[string]string{row},
 	}
 }
 
@@ -28,16 +34,20 @@ func (s *Subcategory) HasChildren() bool {
 	return len(s.difficulties) != 0
 }
 
-func (s *Subcategory) Tree(html bool) interface{} {
+func (s *Subcategory) Tree(enc Encoding) interface{} {
 	var difficulties = make([]interface{}, len(s.difficulties))
 	for i, v := range s.difficulties {
-		difficulties[i] = v.Tree(html)
+		difficulties[i] = v.Tree(enc)
 	}
-	return map[string]interface{}{
+	var m = map[string]interface{}{
 		"id":           s.ID,
-		"name":         s.Name,
+		"name":         enc.text(s.Name),
 		"difficulties": difficulties,
 	}
+	if len(s.Audience) != 0 {
+		m["audience"] = s.Audience
+	}
+	return m
 }
 
 func (s *Subcategory) SHA() string {
@@ -48,7 +58,10 @@ func (s *Subcategory) SetParent(c *Category) {
 	s.parent = c
 }
 
-func (s *Subcategory) MarshalJSON() ([]byte, error) {
+func (s *Subcategory) MarshalJSON() ([]byte, error) { return json.Marshal(s.Fields()) }
+
+// Fields returns the fields of the subcategory shown by the API, in any encoding
+func (s *Subcategory) Fields() map[string]interface{} {
 	var m = map[string]interface{}{
 		"name":         s.Name,
 		"difficulties": s.DifficultyNames(),
@@ -56,13 +69,17 @@ func (s *Subcategory) MarshalJSON() ([]byte, error) {
 	if s.Hash != "" {
 		m["hash"] = s.Hash
 	}
-	return json.Marshal(m)
+	if s.SourceLocale != "" {
+		m["source_locale"] = s.SourceLocale
+	}
+	return m
 }
 
 func (s *Subcategory) Difficulties() []Difficulty {
 	var dst = make([]Difficulty, len(s.difficulties))
 	for i, v := range s.difficulties {
 		dst[i] = *v
+		dst[i].itemIndex.reset()
 	}
 	return dst
 }
@@ -81,20 +98,21 @@ func (s *Subcategory) AddDifficulty(difficulties ...*Difficulty) error {
 			return fmt.Errorf("Difficulty %s exists in %s/%s", v.ID, s.parent.ID, s.ID)
 		}
 		v.parent = s
+		s.diffIndex.add(len(s.difficulties), s.diffID, v.ID)
+		s.difficulties = append(s.difficulties, v)
 	}
-	s.difficulties = append(s.difficulties, difficulties...)
 	return nil
 }
 
 func (s *Subcategory) Difficulty(ID string) *Difficulty {
-	for _, v := range s.difficulties {
-		if v.ID == ID {
-			return v
-		}
+	if i := s.diffIndex.find(len(s.difficulties), s.diffID, ID); i >= 0 {
+		return s.difficulties[i]
 	}
 	return nil
 }
 
+func (s *Subcategory) diffID(i int) string { return s.difficulties[i].ID }
+
 func (s *Subcategory) basePath() string {
 	return fmt.Sprintf("%s/%s", s.parent.basePath(), s.ID)
 }
@@ -114,10 +132,10 @@ func (s *Subcategory) SetPath(filepath string) error {
 	return nil
 }
 
-func (*Subcategory) order() []string     { return []string{"Name", "Order"} }
-func (*Subcategory) optionals() []string { return nil }
-func (s *Subcategory) pointers() args    { return args{&s.Name, &s.Order} }
-func (s *Subcategory) values() args      { return args{s.Name, s.Order} }
+func (*Subcategory) order() []string     { return []string{"Name", "Order", "Audience"} }
+func (*Subcategory) optionals() []string { return []string{"Audience"} }
+func (s *Subcategory) pointers() args    { return args{&s.Name, &s.Order, &s.Audience} }
+func (s *Subcategory) values() args      { return args{s.Name, s.Order, s.Audience} }
 
 func (s *Subcategory) Contents() string { return getMeta(s) }
 
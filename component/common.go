@@ -28,6 +28,7 @@ const (
 	bodySeparator = "\n\n"
 	suffixMeta    = ".metadata"
 	suffixChecks  = ".checks"
+	suffixQuiz    = ".quiz"
 	fileExt       = ".md"
 )
 
@@ -47,6 +48,51 @@ type Resource struct {
 	Content []map[string]string
 }
 
+// Keys of the rows of a Resource
+const (
+	KeyName        = "name"
+	KeyDescription = "description"
+	KeyTitle       = "title"
+	KeyBody        = "body"
+	KeySummary     = "summary"
+	KeyAudience    = "audience"
+	KeyText        = "text"
+	KeyStyle       = "style"
+	KeyForm        = "form"
+	KeyScreen      = "screen"
+	KeyID          = "id"
+	KeyLabel       = "label"
+	KeyHint        = "hint"
+	KeyOptions     = "options"
+	KeyTerm        = "term"
+	KeyDefinition  = "definition"
+	KeyQuestion    = "question"
+	KeyExplanation = "explanation"
+)
+
+// ExpectedKeys returns the keys that can be found in the resource rows of the component
+func ExpectedKeys(c Component) []string {
+	switch c.(type) {
+	case *Category:
+		return []string{KeyName}
+	case *Subcategory:
+		return []string{KeyName, KeyAudience}
+	case *Difficulty:
+		return []string{KeyDescription}
+	case *Item:
+		return []string{KeyTitle, KeyBody, KeySummary, KeyAudience}
+	case *Checklist:
+		return []string{KeyText, KeyStyle, KeyID}
+	case *Form:
+		return []string{KeyForm, KeyScreen, KeyID, KeyLabel, KeyHint, KeyOptions}
+	case *Glossary:
+		return []string{KeyTerm, KeyDefinition}
+	case *Quiz:
+		return []string{KeyQuestion, KeyOptions, KeyExplanation}
+	}
+	return nil
+}
+
 func newCmp(path string) (Component, error) {
 	p := strings.Split(path, "/")
 	switch l := len(p); l {
@@ -54,6 +100,9 @@ func newCmp(path string) (Component, error) {
 		if formPath.MatchString(path) {
 			return new(Form), nil
 		}
+		if glossaryPath.MatchString(path) {
+			return new(Glossary), nil
+		}
 		if p[0] == "assets" && isImage(p[1]) {
 			return new(Asset), nil
 		}
@@ -71,6 +120,8 @@ func newCmp(path string) (Component, error) {
 			return new(Difficulty), nil
 		case suffixChecks:
 			return new(Checklist), nil
+		case suffixQuiz:
+			return new(Quiz), nil
 		default:
 			if isMd(p[4]) {
 				return new(Item), nil
@@ -111,6 +162,16 @@ type parseError struct {
 
 func (p parseError) Error() string { return fmt.Sprintf("[%s]%s - %v", p.phase, p.file, p.err) }
 
+// A Problem is an issue in the content that does not stop parsing
+type Problem struct {
+	Path    string `json:"path"`
+	Locale  string `json:"locale"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"` // corrected text, see CheckStyle
+}
+
+func (p Problem) String() string { return fmt.Sprintf("%s (%s): %s", p.Path, p.Locale, p.Message) }
+
 func strPtr(s string) *string { return &s }
 
 func repoAddress(owner, name string) string {
@@ -0,0 +1,93 @@
+package component
+
+import "fmt"
+
+// Config has the settings of a ResourceParser, for services that read them from a file.
+// Each field is equivalent to a setter; zero values keep the default of the setting.
+type Config struct {
+	Strict            bool                // see SetStrict
+	NameLength        int                 // see SetNameLength; 0 is DefaultNameLength, negative is no limit
+	DescriptionLength int                 // see SetDescriptionLength; 0 is DefaultDescriptionLength, negative is no limit
+	Resync            int                 // see SetResync
+	Thresholds        Thresholds          // see SetThresholds; zero fields are taken from DefaultThresholds
+	Archived          []string            // see ArchiveLocale
+	FallbackLocale    string              // see SetFallbackLocale
+	LenientChecklists bool                // see SetStrictChecklists
+	FallbackChains    map[string][]string // see SetFallbackChain
+	Sorted            bool                // see SetSorted
+}
+
+// Validate checks that every field has a valid value
+func (c *Config) Validate() error {
+	if c.Resync < 0 {
+		return fmt.Errorf("Invalid resync %d", c.Resync)
+	}
+	for name, v := range map[string]float64{"components": c.Thresholds.Components, "words": c.Thresholds.Words} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("Invalid %s threshold %v", name, v)
+		}
+	}
+	if c.Thresholds.CriticalGaps < 0 {
+		return fmt.Errorf("Invalid critical gaps threshold %d", c.Thresholds.CriticalGaps)
+	}
+	for l, chain := range c.FallbackChains {
+		var seen = map[string]bool{l: true}
+		for _, f := range chain {
+			if f == "" || seen[f] {
+				return fmt.Errorf("Invalid fallback chain of %q", l)
+			}
+			seen[f] = true
+		}
+	}
+	var seen = make(map[string]bool)
+	for _, l := range c.Archived {
+		if l == "" || seen[l] {
+			return fmt.Errorf("Invalid archived locale %q", l)
+		}
+		seen[l] = true
+	}
+	return nil
+}
+
+// NewResourceParserFromConfig returns a parser with the settings of the config
+func NewResourceParserFromConfig(cfg Config) (*ResourceParser, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	r := NewResourceParser()
+	r.SetStrict(cfg.Strict)
+	switch {
+	case cfg.NameLength > 0:
+		r.SetNameLength(cfg.NameLength)
+	case cfg.NameLength < 0:
+		r.SetNameLength(0)
+	}
+	switch {
+	case cfg.DescriptionLength > 0:
+		r.SetDescriptionLength(cfg.DescriptionLength)
+	case cfg.DescriptionLength < 0:
+		r.SetDescriptionLength(0)
+	}
+	r.SetResync(cfg.Resync)
+	t := cfg.Thresholds
+	if t.Components == 0 {
+		t.Components = DefaultThresholds.Components
+	}
+	if t.Words == 0 {
+		t.Words = DefaultThresholds.Words
+	}
+	if t.CriticalGaps == 0 {
+		t.CriticalGaps = DefaultThresholds.CriticalGaps
+	}
+	r.SetThresholds(t)
+	for _, l := range cfg.Archived {
+		r.ArchiveLocale(l)
+	}
+	r.SetFallbackLocale(cfg.FallbackLocale)
+	r.SetStrictChecklists(!cfg.LenientChecklists)
+	for l, chain := range cfg.FallbackChains {
+		r.SetFallbackChain(l, chain...)
+	}
+	r.SetSorted(cfg.Sorted)
+	return r, nil
+}
@@ -0,0 +1,99 @@
+package component
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestConfigValidate(c *C) {
+	c.Assert((&Config{}).Validate(), IsNil)
+	for _, tc := range []struct {
+		cfg Config
+		err string
+	}{
+		{Config{Resync: -1}, "Invalid resync -1"},
+		{Config{Thresholds: Thresholds{Words: 101}}, "Invalid words threshold 101"},
+		{Config{Thresholds: Thresholds{Components: -5}}, "Invalid components threshold -5"},
+		{Config{Thresholds: Thresholds{CriticalGaps: -1}}, "Invalid critical gaps threshold -1"},
+		{Config{Archived: []string{"it", "it"}}, `Invalid archived locale "it"`},
+		{Config{Archived: []string{""}}, `Invalid archived locale ""`},
+	} {
+		c.Assert(tc.cfg.Validate(), ErrorMatches, tc.err)
+		_, err := NewResourceParserFromConfig(tc.cfg)
+		c.Assert(err, ErrorMatches, tc.err)
+	}
+}
+
+func (CmpSuite) TestConfigDefaults(c *C) {
+	p, err := NewResourceParserFromConfig(Config{})
+	c.Assert(err, IsNil)
+	c.Assert(p, DeepEquals, NewResourceParser())
+
+	p, err = NewResourceParserFromConfig(Config{NameLength: -1, DescriptionLength: -1, Thresholds: Thresholds{Words: 50}})
+	c.Assert(err, IsNil)
+	c.Assert(p.nameLength, Equals, 0)
+	c.Assert(p.descrLength, Equals, 0)
+	c.Assert(p.thresholds, Equals, Thresholds{Components: 80, Words: 50})
+
+	p, err = NewResourceParserFromConfig(Config{FallbackLocale: "en"})
+	c.Assert(err, IsNil)
+	c.Assert(p.fallback, Equals, "en")
+}
+
+// TestConfigSetters checks that a config and the equivalent setters make parsers that behave the same
+func (CmpSuite) TestConfigSetters(c *C) {
+	cfg := Config{
+		Strict:     true,
+		NameLength: 10,
+		Resync:     2,
+		Thresholds: Thresholds{Components: 50, Words: 40, CriticalGaps: 1},
+		Archived:   []string{"sw"},
+
+		LenientChecklists: true,
+		DescriptionLength: 20,
+	}
+	fromConfig, err := NewResourceParserFromConfig(cfg)
+	c.Assert(err, IsNil)
+	bySetters := NewResourceParser()
+	bySetters.SetStrict(true)
+	bySetters.SetNameLength(10)
+	bySetters.SetDescriptionLength(20)
+	bySetters.SetResync(2)
+	bySetters.SetThresholds(Thresholds{Components: 50, Words: 40, CriticalGaps: 1})
+	bySetters.ArchiveLocale("sw")
+	bySetters.SetStrictChecklists(false)
+
+	type result struct {
+		errs      []string
+		problems  []Problem
+		locales   []string
+		readiness map[string]Readiness
+	}
+	run := func(p *ResourceParser) result {
+		batch := append(itemBatch("Voce"), roundTripForm("")...)
+		batch = append(batch, ParseRequest{
+			Component: batch[0].Component, Locale: "sw",
+			Resource: &Resource{Content: []map[string]string{{"name": "Jamii"}}},
+		}, ParseRequest{
+			Component: batch[0].Component, Locale: "fr",
+			Resource: &Resource{Content: []map[string]string{{"name": strings.Repeat("x", 11)}}},
+		})
+		batch[2].Resource.Content = append(batch[2].Resource.Content, map[string]string{"label": "E"})
+		p.categories["en"] = []*Category{batch[0].Component.(*Category)}
+		_, errs := p.ParseAll(batch)
+		var res = result{problems: p.Problems(), locales: p.Locales(), readiness: p.LocaleReadiness("en")}
+		for _, err := range errs {
+			res.errs = append(res.errs, err.Error())
+		}
+		return res
+	}
+	expected := run(fromConfig)
+	c.Assert(expected.errs, DeepEquals, []string{"cat (fr): cat (fr) row 1: name longer than 10 characters"})
+	c.Assert(expected.problems, DeepEquals, []Problem{
+		{Path: "forms/form", Locale: "it", Message: "row 8 skipped: expected end of the form"},
+		{Path: "cat", Locale: "sw", Message: "locale is archived"},
+	})
+	c.Assert(expected.locales, DeepEquals, []string{"en", "it"})
+	c.Assert(run(bySetters), DeepEquals, expected)
+}
@@ -0,0 +1,184 @@
+package component
+
+import "sort"
+
+// Copy returns a deep copy of the category and its descendants
+func (c *Category) Copy() *Category {
+	cat := *c
+	cat.subcategories = nil
+	cat.subIndex.reset()
+	for _, s := range c.subcategories {
+		cat.Add(s.Copy())
+	}
+	return &cat
+}
+
+// Copy returns a deep copy of the subcategory and its descendants, without a parent
+func (s *Subcategory) Copy() *Subcategory {
+	sub := *s
+	sub.parent = nil
+	sub.Audience = copyStrings(s.Audience)
+	sub.difficulties = nil
+	sub.diffIndex.reset()
+	for _, d := range s.difficulties {
+		sub.AddDifficulty(d.Copy())
+	}
+	return &sub
+}
+
+// Copy returns a deep copy of the difficulty, its items, checklist and quiz, without a parent
+func (d *Difficulty) Copy() *Difficulty {
+	diff := *d
+	diff.parent = nil
+	diff.items, diff.checklist, diff.quiz = nil, nil, nil
+	diff.itemIndex.reset()
+	for _, i := range d.items {
+		diff.AddItem(i.Copy())
+	}
+	if d.checklist != nil {
+		diff.SetChecks(d.checklist.Copy())
+	}
+	if d.quiz != nil {
+		diff.SetQuiz(d.quiz.Copy())
+	}
+	return &diff
+}
+
+// Copy returns a deep copy of the item, without a parent
+func (i *Item) Copy() *Item {
+	item := *i
+	item.parent = nil
+	item.Audience = copyStrings(i.Audience)
+	item.Paragraphs = copyStrings(i.Paragraphs)
+	item.Snippets = append([]Snippet(nil), i.Snippets...)
+	return &item
+}
+
+// Copy returns a deep copy of the checklist, without a parent
+func (c *Checklist) Copy() *Checklist {
+	list := *c
+	list.parent = nil
+	list.Checks = append([]Check(nil), c.Checks...)
+	return &list
+}
+
+// Copy returns a copy of the asset
+func (a *Asset) Copy() *Asset {
+	v := *a
+	return &v
+}
+
+// Copy returns a deep copy of the form
+func (f *Form) Copy() *Form {
+	form := *f
+	form.Screens = make([]FormScreen, len(f.Screens))
+	for i, s := range f.Screens {
+		s.Items = append([]FormInput(nil), s.Items...)
+		for j, input := range s.Items {
+			s.Items[j].Value = copyStrings(input.Value)
+			s.Items[j].Options = copyStrings(input.Options)
+		}
+		form.Screens[i] = s
+	}
+	if f.Screens == nil {
+		form.Screens = nil
+	}
+	return &form
+}
+
+// Glossary returns a copy of the parsed glossary of a locale, nil if missing
+func (r *ResourceParser) Glossary(id, locale string) *Glossary {
+	if g := r.glossary(id, locale); g != nil {
+		return g.Copy()
+	}
+	return nil
+}
+
+// Glossaries returns a copy of the parsed glossaries of a locale, sorted by ID
+func (r *ResourceParser) Glossaries(locale string) []*Glossary {
+	var list = make([]*Glossary, len(r.glossaries[locale]))
+	for i, g := range r.glossaries[locale] {
+		list[i] = g.Copy()
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func (r *ResourceParser) glossary(id, locale string) *Glossary {
+	for _, g := range r.glossaries[locale] {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
+
+// copyStrings returns a copy of s, nil if s is nil
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
+
+// Categories returns a deep copy of the parsed categories by locale, changing it does not
+// change the parser. See CategoriesUnsafe.
+func (r *ResourceParser) Categories() map[string][]*Category {
+	var res = make(map[string][]*Category, len(r.categories))
+	for l, cats := range r.categories {
+		list := make([]*Category, len(cats))
+		for i, c := range cats {
+			list[i] = c.Copy()
+		}
+		res[l] = list
+	}
+	return res
+}
+
+// CategoriesUnsafe returns the categories of the parser: changing them changes the parser, and
+// they change with the next parse.
+func (r *ResourceParser) CategoriesUnsafe() map[string][]*Category { return r.categories }
+
+// Forms returns a deep copy of the parsed forms by locale, sorted by ID. See FormsUnsafe.
+func (r *ResourceParser) Forms() map[string][]*Form {
+	res := r.FormsUnsafe()
+	for _, list := range res {
+		for i, f := range list {
+			list[i] = f.Copy()
+		}
+	}
+	return res
+}
+
+// FormsUnsafe returns the parsed forms by locale, sorted by ID. The slices are new but the forms
+// are shared with the parser: changing one changes the parsed form.
+func (r *ResourceParser) FormsUnsafe() map[string][]*Form {
+	var res = make(map[string][]*Form, len(r.forms))
+	for l, forms := range r.forms {
+		if len(forms) == 0 {
+			continue
+		}
+		list := append([]*Form(nil), forms...)
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+		res[l] = list
+	}
+	return res
+}
+
+// Form returns a copy of the parsed form of a locale, nil if missing. See FormUnsafe.
+func (r *ResourceParser) Form(id, locale string) *Form {
+	if f := r.FormUnsafe(id, locale); f != nil {
+		return f.Copy()
+	}
+	return nil
+}
+
+// FormUnsafe returns the parsed form of a locale, shared with the parser, nil if missing
+func (r *ResourceParser) FormUnsafe(id, locale string) *Form {
+	for _, f := range r.forms[locale] {
+		if f.ID == id {
+			return f
+		}
+	}
+	return nil
+}
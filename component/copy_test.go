@@ -0,0 +1,102 @@
+package component
+
+import (
+	"encoding/json"
+	"math/rand"
+
+	. "gopkg.in/check.v1"
+)
+
+// treeHash returns the resources of all the locales of the parser, as JSON
+func treeHash(c *C, p *ResourceParser) string {
+	var all = make(map[string]map[string]*Resource)
+	for _, l := range p.Locales(IncludeEmptyLocales()) {
+		res, err := p.ExportResources(l)
+		c.Assert(err, IsNil)
+		all[l] = res
+	}
+	b, err := json.Marshal(all)
+	c.Assert(err, IsNil)
+	return string(b)
+}
+
+// mutateCategory changes every field of the category and its descendants
+func mutateCategory(cat *Category) {
+	cat.Walk(func(_ []Component, cmp Component) error {
+		switch v := cmp.(type) {
+		case *Category:
+			v.Name += "!"
+		case *Subcategory:
+			v.Name += "!"
+		case *Difficulty:
+			v.Descr += "!"
+			v.AddItem(&Item{ID: "added", Title: "Added"})
+		case *Item:
+			v.Title += "!"
+			v.Body += "!"
+		case *Checklist:
+			v.Checks[0].Text += "!"
+			v.Checks = append(v.Checks, Check{Text: "Added"})
+		}
+		return nil
+	})
+}
+
+func mutateForm(f *Form) {
+	f.Name += "!"
+	for i := range f.Screens {
+		f.Screens[i].Name += "!"
+		for j := range f.Screens[i].Items {
+			input := &f.Screens[i].Items[j]
+			input.Label += "!"
+			if len(input.Options) != 0 {
+				input.Options[0] += "!"
+			}
+		}
+	}
+}
+
+func (CmpSuite) TestAccessorCopies(c *C) {
+	rnd := rand.New(rand.NewSource(5))
+	p := randomTree(rnd, "en")
+	for len(p.forms["en"]) == 0 || len(p.categories["en"]) == 0 {
+		p = randomTree(rnd, "en")
+	}
+	hash := treeHash(c, p)
+
+	for _, cat := range p.Categories()["en"] {
+		mutateCategory(cat)
+	}
+	c.Assert(p.Walk("en", func(_ []Component, cmp Component) error {
+		if cat, ok := cmp.(*Category); ok {
+			mutateCategory(cat)
+		}
+		return nil
+	}), IsNil)
+	for _, f := range p.Forms()["en"] {
+		mutateForm(f)
+	}
+	mutateForm(p.Form(p.forms["en"][0].ID, "en"))
+	c.Assert(p.WalkForms("en", func(f *Form) error { mutateForm(f); return nil }), IsNil)
+	c.Assert(treeHash(c, p), Equals, hash)
+
+	// the unsafe variants are live
+	mutateForm(p.FormUnsafe(p.forms["en"][0].ID, "en"))
+	c.Assert(treeHash(c, p), Not(Equals), hash)
+	hash = treeHash(c, p)
+	mutateForm(p.FormsUnsafe()["en"][0])
+	c.Assert(treeHash(c, p), Not(Equals), hash)
+	hash = treeHash(c, p)
+	mutateCategory(p.CategoriesUnsafe()["en"][0])
+	c.Assert(treeHash(c, p), Not(Equals), hash)
+}
+
+func (CmpSuite) TestCopy(c *C) {
+	p := randomTree(rand.New(rand.NewSource(6)), "en")
+	for _, cat := range p.categories["en"] {
+		c.Assert(cat.Copy(), DeepEquals, cat)
+	}
+	for _, f := range p.forms["en"] {
+		c.Assert(f.Copy(), DeepEquals, f)
+	}
+}
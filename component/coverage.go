@@ -0,0 +1,98 @@
+package component
+
+import (
+	"sort"
+	"strconv"
+)
+
+// CoverageReport lists what a locale misses of the base locale and what it has more.
+// Checks are counted one by one, with the path of the checklist followed by their number.
+type CoverageReport struct {
+	Missing []string                `json:"missing"` // paths of the base locale without translation
+	Extra   []string                `json:"extra"`   // paths missing in the base locale
+	Kinds   map[string]KindCoverage `json:"kinds"`   // by component type, "check" for checklists
+}
+
+// KindCoverage counts the translated components of a type
+type KindCoverage struct {
+	Total      int     `json:"total"`
+	Translated int     `json:"translated"`
+	Percent    float64 `json:"percent"`
+}
+
+// Coverage returns the report of every locale, except the base one. Bootstrapped components
+// that still need translation are missing.
+func (r *ResourceParser) Coverage(baseLocale string, opts ...Option) map[string]CoverageReport {
+	var res = make(map[string]CoverageReport)
+	for _, l := range r.Locales(opts...) {
+		if l == baseLocale {
+			continue
+		}
+		res[l] = r.coverage(baseLocale, l)
+	}
+	return res
+}
+
+func (r *ResourceParser) coverage(base, locale string) CoverageReport {
+	var (
+		rep   = CoverageReport{Missing: []string{}, Extra: []string{}, Kinds: make(map[string]KindCoverage)}
+		count = func(kind string, translated bool) {
+			k := rep.Kinds[kind]
+			k.Total++
+			if translated {
+				k.Translated++
+			}
+			rep.Kinds[kind] = k
+		}
+	)
+	for _, c := range r.localeComponents(base) {
+		path := treePath(c)
+		target := r.translation(locale, path)
+		list, ok := c.(*Checklist)
+		if !ok {
+			count(cmpType(c), target != nil)
+			if target == nil {
+				rep.Missing = append(rep.Missing, path)
+			}
+			continue
+		}
+		var checks []Check
+		if target != nil {
+			checks = target.(*Checklist).Checks
+		}
+		for i := range list.Checks {
+			ok := i < len(checks) && checks[i].Text != "" && !checks[i].Untranslated
+			count("check", ok)
+			if !ok {
+				rep.Missing = append(rep.Missing, checkPath(path, i))
+			}
+		}
+	}
+	for _, c := range r.localeComponents(locale) {
+		path := treePath(c)
+		b := r.lookup(base, path)
+		if list, ok := c.(*Checklist); ok {
+			var n int
+			if b != nil {
+				n = len(b.(*Checklist).Checks)
+			}
+			for i := n; i < len(list.Checks); i++ {
+				rep.Extra = append(rep.Extra, checkPath(path, i))
+			}
+			continue
+		}
+		if b == nil {
+			rep.Extra = append(rep.Extra, path)
+		}
+	}
+	for kind, k := range rep.Kinds {
+		k.Percent = percent(k.Translated, k.Total)
+		rep.Kinds[kind] = k
+	}
+	sort.Strings(rep.Missing)
+	sort.Strings(rep.Extra)
+	return rep
+}
+
+// checkPath returns the path of the i-th check of the checklist at path
+func checkPath(path string, i int) string { return path + "/" + strconv.Itoa(i+1) }
@@ -0,0 +1,46 @@
+package component
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestCoverage(c *C) {
+	p := readinessParser(map[string]int{"en": 4, "es": 2, "it": 5})
+	p.category("cat", "en").Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "One"}, Check{Text: "Two"}, Check{Text: "Three"})
+	p.category("cat", "es").Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "Uno"}, Check{Text: ""})
+	p.category("cat", "it").Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "1"}, Check{Text: "2"}, Check{Text: "3"}, Check{Text: "4"})
+	p.categories["es"][0].Add(&Subcategory{ID: "extra", Name: "Extra"})
+	p.forms["en"] = []*Form{{ID: "form", Name: "Form"}}
+	p.forms["it"] = []*Form{{ID: "form", Name: "Modulo"}, {ID: "other", Name: "Altro"}}
+
+	r := p.Coverage("en")
+	c.Assert(r, HasLen, 2)
+	c.Assert(r["es"], DeepEquals, CoverageReport{
+		Missing: []string{
+			"cat/sub/beginner/.checks/2", "cat/sub/beginner/.checks/3",
+			"cat/sub/beginner/item2", "cat/sub/beginner/item3", "forms/form",
+		},
+		Extra: []string{"cat/extra"},
+		Kinds: map[string]KindCoverage{
+			"category":    {1, 1, 100},
+			"subcategory": {1, 1, 100},
+			"difficulty":  {1, 1, 100},
+			"item":        {4, 2, 50},
+			"check":       {3, 1, percent(1, 3)},
+			"form":        {1, 0, 0},
+		},
+	})
+	c.Assert(r["it"].Missing, HasLen, 0)
+	c.Assert(r["it"].Extra, DeepEquals, []string{"cat/sub/beginner/.checks/4", "cat/sub/beginner/item4", "forms/other"})
+	c.Assert(r["it"].Kinds["check"], DeepEquals, KindCoverage{3, 3, 100})
+
+	// bootstrapped components are missing
+	c.Assert(p.BootstrapLocale("en", "fr"), IsNil)
+	c.Assert(p.Coverage("en")["fr"].Kinds["item"], DeepEquals, KindCoverage{4, 0, 0})
+
+	b, err := json.Marshal(r["it"].Kinds["form"])
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"total":1,"translated":1,"percent":100}`)
+}
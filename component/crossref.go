@@ -0,0 +1,179 @@
+package component
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var crossRef = regexp.MustCompile(`\]\(\s*item:([^)\s]+)\s*\)`)
+
+// CrossRefs returns the targets of the item: links of the body, in order of appearance (notes
+// excluded). A target is an item ID, or the tree path of an item when the ID is not unique.
+func (i *Item) CrossRefs() []string {
+	var list []string
+	for _, m := range crossRef.FindAllStringSubmatch(stripBodyNotes(i.Body), -1) {
+		list = append(list, m[1])
+	}
+	return list
+}
+
+// CrossRefChecker collects the item: links of the parsed items and reports, when finalized,
+// the ones without a target item in the same locale. Forward references are allowed, since
+// the targets are resolved only by Finalize. Use it with:
+//
+//	var x CrossRefChecker
+//	r.AddItemHook(x.Hook)
+//	r.AddFinalizer(&x)
+type CrossRefChecker struct {
+	mu   sync.Mutex
+	refs map[[2]string][]string // targets by locale and item path
+}
+
+// Hook records the links of the item, it's an ItemHook
+func (x *CrossRefChecker) Hook(_ []Component, item *Item, locale string) error {
+	refs := item.CrossRefs()
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.refs == nil {
+		x.refs = make(map[[2]string][]string)
+	}
+	key := [2]string{locale, treePath(item)}
+	if len(refs) == 0 {
+		delete(x.refs, key)
+		return nil
+	}
+	x.refs[key] = refs
+	return nil
+}
+
+// Finalize returns a problem for each link without a target, telling the locales that have it
+func (x *CrossRefChecker) Finalize(r *ResourceParser) []Problem {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	var keys [][2]string
+	for k := range x.refs {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	var (
+		locales = r.Locales(IncludeArchived())
+		ids     = make(map[string]map[string][]string, len(locales)) // item paths by locale and ID
+		list    []Problem
+	)
+	for _, l := range locales {
+		ids[l] = r.itemPaths(l)
+	}
+	for _, k := range keys {
+		locale, path := k[0], k[1]
+		if _, ok := r.lookup(locale, path).(*Item); !ok {
+			continue // removed after parsing
+		}
+		for _, target := range x.refs[k] {
+			if r.resolveLink(locale, target, ids[locale]) != "" {
+				continue
+			}
+			if len(ids[locale][target]) > 1 {
+				list = append(list, Problem{Path: path, Locale: locale,
+					Message: fmt.Sprintf("ambiguous link to item:%s, use the path of the item", target)})
+				continue
+			}
+			var found []string
+			for _, l := range locales {
+				if l != locale && (r.resolveLink(l, target, ids[l]) != "" || len(ids[l][target]) > 1) {
+					found = append(found, l)
+				}
+			}
+			msg := fmt.Sprintf("dangling link to item:%s", target)
+			if len(found) != 0 {
+				msg += ", found in " + strings.Join(found, ", ")
+			}
+			list = append(list, Problem{Path: path, Locale: locale, Message: msg})
+		}
+	}
+	return list
+}
+
+// itemPaths returns the paths of the items of the locale by ID
+func (r *ResourceParser) itemPaths(locale string) map[string][]string {
+	var m = make(map[string][]string)
+	for _, cat := range r.categories[locale] {
+		walkCategory(cat, func(c Component) {
+			if item, ok := c.(*Item); ok {
+				m[item.ID] = append(m[item.ID], treePath(item))
+			}
+		})
+	}
+	return m
+}
+
+// resolveLink returns the path of the item of the locale that is the target of a link, an
+// empty string if there is none or the ID is ambiguous; ids are the paths of itemPaths
+func (r *ResourceParser) resolveLink(locale, target string, ids map[string][]string) string {
+	if strings.Contains(target, "/") {
+		if _, ok := r.lookup(locale, target).(*Item); ok {
+			return target
+		}
+		return ""
+	}
+	if paths := ids[target]; len(paths) == 1 {
+		return paths[0]
+	}
+	return ""
+}
+
+// Links returns the paths of the items that the item of the locale at the path links to, in
+// order of appearance and without repetitions. Links without a single target are left out,
+// CrossRefChecker reports them.
+func (r *ResourceParser) Links(locale, path string) ([]string, error) {
+	item, ok := r.lookup(locale, path).(*Item)
+	if !ok {
+		return nil, &NotFoundError{Path: path, Locale: locale}
+	}
+	var (
+		ids  = r.itemPaths(locale)
+		seen = make(map[string]bool)
+		list []string
+	)
+	for _, target := range item.CrossRefs() {
+		if p := r.resolveLink(locale, target, ids); p != "" && !seen[p] {
+			seen[p] = true
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+// Backlinks returns the sorted paths of the items of the locale linking to the item at the path
+func (r *ResourceParser) Backlinks(locale, path string) ([]string, error) {
+	if _, ok := r.lookup(locale, path).(*Item); !ok {
+		return nil, &NotFoundError{Path: path, Locale: locale}
+	}
+	var (
+		ids  = r.itemPaths(locale)
+		list []string
+	)
+	for _, cat := range r.categories[locale] {
+		walkCategory(cat, func(c Component) {
+			item, ok := c.(*Item)
+			if !ok {
+				return
+			}
+			for _, target := range item.CrossRefs() {
+				if r.resolveLink(locale, target, ids) == path {
+					list = append(list, treePath(item))
+					return
+				}
+			}
+		})
+	}
+	sort.Strings(list)
+	return list, nil
+}
@@ -0,0 +1,99 @@
+package component
+
+import (
+	"errors"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestItemHooks(c *C) {
+	batch := itemBatch("Voce")
+	p := NewResourceParser()
+	var seen []string
+	p.AddItemHook(func(path []Component, item *Item, locale string) error {
+		seen = append(seen, treePath(path[len(path)-1])+" "+item.ID+" "+locale+" "+item.Body)
+		return nil
+	})
+	p.AddItemHook(func(_ []Component, item *Item, _ string) error {
+		if strings.Contains(item.Body, "```") {
+			return errors.New("unclosed code fence")
+		}
+		return nil
+	})
+	_, errs := p.ParseAll(batch)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(seen, DeepEquals, []string{"cat/sub/beginner item it uno"})
+
+	// a hook error fails the item, that is not changed
+	batch[1].Resource.Content[1]["body"] = "```go"
+	_, errs = p.ParseAll(batch)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, ".*unclosed code fence")
+	c.Assert(p.lookup("it", "cat/sub/beginner/item").(*Item).Body, Equals, "uno")
+}
+
+func (CmpSuite) TestCrossRefChecker(c *C) {
+	base := testCategory("en", "")
+	diff := base.Sub("sub").Difficulty("beginner")
+	base.Add(&Subcategory{ID: "other"})
+	base.Sub("other").AddDifficulty(&Difficulty{ID: "beginner"})
+	items := map[string]*Item{}
+	for _, id := range []string{"first", "second", "only_en", "twin"} {
+		items[id] = &Item{ID: id, Title: id}
+		diff.AddItem(items[id])
+	}
+	twin := &Item{ID: "twin", Title: "Twin"}
+	base.Sub("other").Difficulty("beginner").AddItem(twin)
+
+	var x CrossRefChecker
+	p := NewResourceParser()
+	p.AddItemHook(x.Hook)
+	p.AddFinalizer(&x)
+	parse := func(item *Item, locale, body string) {
+		res := &Resource{Content: []map[string]string{{"title": item.Title}, {"body": body}}}
+		c.Assert(p.Parse(item, res, locale), IsNil)
+	}
+	for _, l := range []string{"en", "it"} {
+		// forward reference to second
+		parse(items["first"], l, "See [the second](item:second) and [the path](item:cat/sub/beginner/second).")
+		parse(items["second"], l, "Back to [first](item:first). [[note: link [gone](item:gone) later]]")
+		parse(twin, l, "Twin")
+	}
+	parse(items["only_en"], "en", "Only")
+	parse(items["twin"], "it", "See [missing](item:missing), [only](item:only_en) and [twin](item:twin).")
+	parse(items["twin"], "en", "See [twin](item:cat/other/beginner/twin).")
+
+	c.Assert(items["first"].CrossRefs(), HasLen, 0)
+	problems := p.Finalize()
+	c.Assert(problems, DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/twin", Locale: "it", Message: "dangling link to item:missing"},
+		{Path: "cat/sub/beginner/twin", Locale: "it", Message: "dangling link to item:only_en, found in en"},
+		{Path: "cat/sub/beginner/twin", Locale: "it", Message: "ambiguous link to item:twin, use the path of the item"},
+	})
+	c.Assert(p.Problems(), DeepEquals, problems)
+	c.Assert(p.Finalize(), DeepEquals, problems)
+	c.Assert(p.Problems(), DeepEquals, problems)
+
+	// parsing an item again replaces its links
+	parse(items["twin"], "it", "No links")
+	c.Assert(p.Finalize(), HasLen, 0)
+	c.Assert(p.Problems(), HasLen, 0)
+
+	links, err := p.Links("en", "cat/sub/beginner/first")
+	c.Assert(err, IsNil)
+	c.Assert(links, DeepEquals, []string{"cat/sub/beginner/second"})
+	links, err = p.Links("it", "cat/sub/beginner/twin")
+	c.Assert(err, IsNil)
+	c.Assert(links, HasLen, 0)
+	backlinks, err := p.Backlinks("en", "cat/other/beginner/twin")
+	c.Assert(err, IsNil)
+	c.Assert(backlinks, DeepEquals, []string{"cat/sub/beginner/twin"})
+	backlinks, err = p.Backlinks("it", "cat/sub/beginner/first")
+	c.Assert(err, IsNil)
+	c.Assert(backlinks, DeepEquals, []string{"cat/sub/beginner/second"})
+	_, err = p.Links("en", "cat/sub/beginner/gone")
+	c.Assert(err, ErrorMatches, "cat/sub/beginner/gone not found \\(en\\)")
+	_, err = p.Backlinks("en", "cat/sub")
+	c.Assert(err, NotNil)
+}
@@ -0,0 +1,268 @@
+package component
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	toml "github.com/pelletier/go-toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A Decoder reads a Resource stored in some format, so it can be fed to the parser
+type Decoder interface {
+	Decode(r io.Reader) (*Resource, error)
+}
+
+// CSVDecoder reads a header with the keys of the rows, followed by a record for each row:
+// empty cells are missing keys. It's the format written by WriteResourcesCSV.
+type CSVDecoder struct{}
+
+// Decode implements Decoder
+func (CSVDecoder) Decode(r io.Reader) (*Resource, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var res Resource
+	if len(records) == 0 {
+		return &res, nil
+	}
+	header := records[0]
+	for _, record := range records[1:] {
+		var row = make(map[string]string)
+		for i, v := range record {
+			if v != "" {
+				row[header[i]] = v
+			}
+		}
+		res.Content = append(res.Content, row)
+	}
+	return &res, nil
+}
+
+// TOMLDecoder reads a document with an optional slug key and a [[content]] table for each row.
+type TOMLDecoder struct{}
+
+// Decode implements Decoder
+func (TOMLDecoder) Decode(r io.Reader) (*Resource, error) {
+	tree, err := toml.LoadReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocument(tree.ToMap())
+}
+
+// frontMatterSep delimits the YAML front matter
+const frontMatterSep = "---"
+
+// FrontMatterDecoder reads a Markdown file that starts with a YAML front matter, with an
+// optional slug and a content list with the rows. The paragraphs of the text that follows
+// the front matter are appended as body rows.
+type FrontMatterDecoder struct{}
+
+// Decode implements Decoder
+func (FrontMatterDecoder) Decode(r io.Reader) (*Resource, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		head, body bytes.Buffer
+		scanner    = bufio.NewScanner(bytes.NewReader(b))
+		line       int
+		closed     bool
+	)
+	for scanner.Scan() {
+		line++
+		switch text := scanner.Text(); {
+		case line == 1:
+			if strings.TrimSpace(text) != frontMatterSep {
+				return nil, fmt.Errorf("Expected front matter, got %q", text)
+			}
+		case !closed && strings.TrimSpace(text) == frontMatterSep:
+			closed = true
+		case !closed:
+			head.WriteString(text + "\n")
+		default:
+			body.WriteString(text + "\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, fmt.Errorf("Unterminated front matter")
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(head.Bytes(), &doc); err != nil {
+		return nil, err
+	}
+	res, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range SplitBody(body.String()) {
+		if p = strings.TrimSpace(p); p != "" {
+			res.Content = append(res.Content, map[string]string{KeyBody: p})
+		}
+	}
+	return res, nil
+}
+
+// decodeDocument converts a decoded document, with a slug and a list of content rows, into a
+// Resource. Row values must be scalars.
+func decodeDocument(doc map[string]interface{}) (*Resource, error) {
+	var res Resource
+	for k, v := range doc {
+		switch k {
+		case "slug":
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("Invalid slug %v", v)
+			}
+			res.Slug = s
+		case "content":
+			rows, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("Invalid content, expected a list of rows")
+			}
+			for i, r := range rows {
+				row, err := decodeRow(r)
+				if err != nil {
+					return nil, fmt.Errorf("Row %d: %s", i+1, err)
+				}
+				res.Content = append(res.Content, row)
+			}
+		default:
+			return nil, fmt.Errorf("Unknown key %q", k)
+		}
+	}
+	return &res, nil
+}
+
+func decodeRow(v interface{}) (map[string]string, error) {
+	var row = make(map[string]string)
+	switch m := v.(type) {
+	case map[string]interface{}:
+		for k, v := range m {
+			s, err := decodeScalar(k, v)
+			if err != nil {
+				return nil, err
+			}
+			row[k] = s
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("Invalid key %v", k)
+			}
+			s, err := decodeScalar(key, v)
+			if err != nil {
+				return nil, err
+			}
+			row[key] = s
+		}
+	default:
+		return nil, fmt.Errorf("Expected a table, got %v", v)
+	}
+	return row, nil
+}
+
+func decodeScalar(key string, v interface{}) (string, error) {
+	switch v.(type) {
+	case nil:
+		return "", nil
+	case string, bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("Invalid value for %q: %v", key, v)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		".csv":  CSVDecoder{},
+		".toml": TOMLDecoder{},
+		".md":   FrontMatterDecoder{},
+	}
+)
+
+// RegisterDecoder sets the decoder used for files with the extension, including the dot,
+// replacing the one already registered, if any. It's safe to call while files are decoded.
+func RegisterDecoder(ext string, d Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[strings.ToLower(ext)] = d
+}
+
+// DecoderFor returns the decoder registered for the extension of the file name
+func DecoderFor(name string) (Decoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	d, ok := decoders[strings.ToLower(filepath.Ext(name))]
+	return d, ok
+}
+
+// DecodeFile reads the resource in the file, using the decoder of its extension
+func DecodeFile(name string) (*Resource, error) {
+	d, ok := DecoderFor(name)
+	if !ok {
+		return nil, fmt.Errorf("No decoder for %q", name)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	res, err := d.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return res, nil
+}
+
+// ReadResources reads the resources of the locale in dir/locale, keyed by path without extension,
+// using the registered decoders. Files without a decoder are ignored and two files with the same
+// path are an error.
+func ReadResources(dir, locale string) (map[string]*Resource, error) {
+	var (
+		m     = make(map[string]*Resource)
+		names = make(map[string]string)
+		root  = filepath.Join(dir, locale)
+	)
+	err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if _, ok := DecoderFor(name); !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		path := filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+		if prev, ok := names[path]; ok {
+			return fmt.Errorf("Both %q and %q decode %s", prev, name, path)
+		}
+		res, err := DecodeFile(name)
+		if err != nil {
+			return err
+		}
+		m[path], names[path] = res, name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
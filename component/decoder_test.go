@@ -0,0 +1,87 @@
+package component
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestDecoders(c *C) {
+	expected := &Resource{Slug: "item", Content: []map[string]string{{"title": "Voce", "order": "2"}, {"body": "Uno"}, {"body": "Due"}}}
+
+	res, err := TOMLDecoder{}.Decode(strings.NewReader(`slug = "item"
+
+[[content]]
+title = "Voce"
+order = 2
+
+[[content]]
+body = "Uno"
+
+[[content]]
+body = "Due"
+`))
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, expected)
+
+	res, err = FrontMatterDecoder{}.Decode(strings.NewReader(`---
+slug: item
+content:
+  - title: Voce
+    order: 2
+---
+Uno
+
+Due
+`))
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, expected)
+
+	res, err = CSVDecoder{}.Decode(strings.NewReader("title,order,body\nVoce,2,\n,,Uno\n,,Due\n"))
+	c.Assert(err, IsNil)
+	c.Assert(res.Content, DeepEquals, expected.Content)
+
+	_, err = FrontMatterDecoder{}.Decode(strings.NewReader("---\ncontent: []\n"))
+	c.Assert(err, ErrorMatches, "Unterminated front matter")
+	_, err = TOMLDecoder{}.Decode(strings.NewReader("[[content]]\ntitle = [1, 2]\n"))
+	c.Assert(err, ErrorMatches, `Row 1: Invalid value for "title".*`)
+	_, err = TOMLDecoder{}.Decode(strings.NewReader("title = \"Voce\"\n"))
+	c.Assert(err, ErrorMatches, `Unknown key "title"`)
+}
+
+func (CmpSuite) TestReadResources(c *C) {
+	dir := c.MkDir()
+	files := map[string]string{
+		"cat.csv":                  "name\nCategoria\n",
+		"cat/sub.toml":             "[[content]]\nname = \"Sotto\"\n",
+		"cat/sub/beginner.md":      "---\ncontent:\n  - description: Facile\n---\n",
+		"cat/sub/beginner/item.md": "---\ncontent:\n  - title: Voce\n---\nUno\n",
+		"cat/notes.txt":            "ignored",
+	}
+	for name, data := range files {
+		name = filepath.Join(dir, "it", filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(name), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(name, []byte(data), 0644), IsNil)
+	}
+	resources, err := ReadResources(dir, "it")
+	c.Assert(err, IsNil)
+	c.Assert(resources, DeepEquals, map[string]*Resource{
+		"cat":                   {Content: []map[string]string{{"name": "Categoria"}}},
+		"cat/sub":               {Content: []map[string]string{{"name": "Sotto"}}},
+		"cat/sub/beginner":      {Content: []map[string]string{{"description": "Facile"}}},
+		"cat/sub/beginner/item": {Content: []map[string]string{{"title": "Voce"}, {"body": "Uno"}}},
+	})
+
+	c.Assert(ioutil.WriteFile(filepath.Join(dir, "it", "cat.toml"), []byte("[[content]]\nname = \"Altra\"\n"), 0644), IsNil)
+	_, err = ReadResources(dir, "it")
+	c.Assert(err, ErrorMatches, "Both .* decode cat")
+
+	RegisterDecoder(".TXT", CSVDecoder{})
+	defer delete(decoders, ".txt")
+	d, ok := DecoderFor("notes.txt")
+	c.Assert(ok, Equals, true)
+	c.Assert(d, Equals, CSVDecoder{})
+}
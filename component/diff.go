@@ -0,0 +1,262 @@
+package component
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is what happened to a component, see Change
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a component added, removed or modified between two versions of the content.
+// Path is the tree path of the component; checks are numbered from 1 after the path of their
+// checklist, screens after the path of their form and inputs after the one of their screen,
+// like cat/sub/beginner/.checks/2 or forms/form/1/3.
+type Change struct {
+	Kind   ChangeKind `json:"kind"`
+	Path   string     `json:"path"`
+	Fields []string   `json:"fields,omitempty"` // changed fields, JSON names, of a modified component
+}
+
+// Changeset is the difference between two versions of the content, by locale
+type Changeset struct {
+	Locales map[string][]Change `json:"locales"`
+}
+
+// Diff returns the changes from the content of old to the one of new, for every locale,
+// archived included. Categories, subcategories, difficulties and items are matched by ID,
+// checks, screens and inputs by position. Only the topmost component added or removed is
+// reported, not its descendants, and changes are in tree order, the new one first, followed
+// by what was removed at the same level.
+func Diff(old, new *ResourceParser) *Changeset {
+	var (
+		cs      = &Changeset{Locales: make(map[string][]Change)}
+		locales = make(map[string]bool)
+	)
+	for _, r := range []*ResourceParser{old, new} {
+		for l := range r.categories {
+			locales[l] = true
+		}
+		for l := range r.forms {
+			locales[l] = true
+		}
+	}
+	for l := range locales {
+		var d differ
+		d.categories(old.categories[l], new.categories[l])
+		d.forms(old.forms[l], new.forms[l])
+		if len(d.changes) != 0 {
+			cs.Locales[l] = d.changes
+		}
+	}
+	return cs
+}
+
+// Empty tells if there are no changes
+func (c *Changeset) Empty() bool { return len(c.Locales) == 0 }
+
+// Affected returns the paths of the locale that changed and their ancestors, sorted, like the
+// content that must be invalidated in a cache. Checks, screens and inputs are reported as
+// their checklist or form.
+func (c *Changeset) Affected(locale string) []string {
+	var m = make(map[string]bool)
+	for _, ch := range c.Locales[locale] {
+		p := ch.Path
+		if i := strings.Index(p, "/"+suffixChecks); i >= 0 {
+			p = p[:i+len(suffixChecks)+1]
+		}
+		if strings.HasPrefix(p, "forms/") {
+			p = strings.Join(strings.SplitN(p, "/", 3)[:2], "/")
+		}
+		for m[p] = true; strings.Contains(p, "/") && !strings.HasPrefix(p, "forms/"); {
+			p = p[:strings.LastIndex(p, "/")]
+			m[p] = true
+		}
+	}
+	var list = make([]string, 0, len(m))
+	for p := range m {
+		list = append(list, p)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// differ collects the changes of a locale
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(kind ChangeKind, path string, fields ...string) {
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, Fields: fields})
+}
+
+// modified adds a change if some of the fields differ
+func (d *differ) modified(path string, before, after []diffField) {
+	var changed []string
+	for i := range after {
+		if !diffEqual(before[i].value, after[i].value) {
+			changed = append(changed, after[i].name)
+		}
+	}
+	if len(changed) != 0 {
+		d.add(ChangeModified, path, changed...)
+	}
+}
+
+// children compares two lists of children by ID, calling fn for the ones in both
+func (d *differ) children(prefix string, before, after []string, fn func(i, j int)) {
+	var pos = make(map[string]int, len(before))
+	for i, id := range before {
+		pos[id] = i
+	}
+	found := make(map[string]bool, len(after))
+	for j, id := range after {
+		found[id] = true
+		if i, ok := pos[id]; ok {
+			fn(i, j)
+		} else {
+			d.add(ChangeAdded, prefix+id)
+		}
+	}
+	for _, id := range before {
+		if !found[id] {
+			d.add(ChangeRemoved, prefix+id)
+		}
+	}
+}
+
+// positions compares two lists by position, calling fn for the ones in both
+func (d *differ) positions(prefix string, before, after int, fn func(i int)) {
+	for i := 0; i < after || i < before; i++ {
+		switch path := fmt.Sprintf("%s%d", prefix, i+1); {
+		case i >= before:
+			d.add(ChangeAdded, path)
+		case i >= after:
+			d.add(ChangeRemoved, path)
+		default:
+			fn(i)
+		}
+	}
+}
+
+func (d *differ) categories(before, after []*Category) {
+	var a, b = make([]string, len(before)), make([]string, len(after))
+	for i, c := range before {
+		a[i] = c.ID
+	}
+	for i, c := range after {
+		b[i] = c.ID
+	}
+	d.children("", a, b, func(i, j int) {
+		old, cat := before[i], after[j]
+		d.modified(cat.ID, diffFields(old), diffFields(cat))
+		d.children(cat.ID+"/", old.Subcategories(), cat.Subcategories(), func(i, j int) {
+			d.subcategory(old.subcategories[i], cat.subcategories[j])
+		})
+	})
+}
+
+func (d *differ) subcategory(old, sub *Subcategory) {
+	path := treePath(sub)
+	d.modified(path, diffFields(old), diffFields(sub))
+	d.children(path+"/", old.DifficultyNames(), sub.DifficultyNames(), func(i, j int) {
+		d.difficulty(old.difficulties[i], sub.difficulties[j])
+	})
+}
+
+func (d *differ) difficulty(old, diff *Difficulty) {
+	path := treePath(diff)
+	d.modified(path, diffFields(old), diffFields(diff))
+	d.children(path+"/", old.ItemNames(), diff.ItemNames(), func(i, j int) {
+		d.modified(treePath(diff.items[j]), diffFields(old.items[i]), diffFields(diff.items[j]))
+	})
+	var before, after []Check
+	if old.checklist != nil {
+		before = old.checklist.Checks
+	}
+	if diff.checklist != nil {
+		after = diff.checklist.Checks
+	}
+	prefix := path + "/" + suffixChecks + "/"
+	d.positions(prefix, len(before), len(after), func(i int) {
+		d.modified(fmt.Sprintf("%s%d", prefix, i+1), checkFields(before[i]), checkFields(after[i]))
+	})
+}
+
+func (d *differ) forms(before, after []*Form) {
+	var a, b = make([]string, len(before)), make([]string, len(after))
+	for i, f := range before {
+		a[i] = f.ID
+	}
+	for i, f := range after {
+		b[i] = f.ID
+	}
+	d.children("forms/", a, b, func(i, j int) {
+		old, f := before[i], after[j]
+		path := treePath(f)
+		d.modified(path, diffFields(old), diffFields(f))
+		d.positions(path+"/", len(old.Screens), len(f.Screens), func(i int) {
+			s, n := old.Screens[i], f.Screens[i]
+			prefix := fmt.Sprintf("%s/%d/", path, i+1)
+			d.modified(prefix[:len(prefix)-1], screenFields(s), screenFields(n))
+			d.positions(prefix, len(s.Items), len(n.Items), func(i int) {
+				d.modified(fmt.Sprintf("%s%d", prefix, i+1), inputFields(s.Items[i]), inputFields(n.Items[i]))
+			})
+		})
+	})
+}
+
+// diffField is a field compared by Diff, with its JSON name
+type diffField struct {
+	name  string
+	value interface{}
+}
+
+// diffEqual compares two values of a field, empty lists are equal
+func diffEqual(a, b interface{}) bool {
+	if x, ok := a.([]string); ok && len(x) == 0 && len(b.([]string)) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// diffFields returns the fields of a component compared by Diff, children excluded
+func diffFields(c Component) []diffField {
+	switch v := c.(type) {
+	case *Category:
+		return []diffField{{"name", v.Name}, {"order", v.Order}}
+	case *Subcategory:
+		return []diffField{{"name", v.Name}, {"order", v.Order}, {"audience", v.Audience}}
+	case *Difficulty:
+		return []diffField{{"description", v.Descr}}
+	case *Item:
+		return []diffField{{"title", v.Title}, {"body", v.Body}, {"summary", v.Abstract},
+			{"order", v.Order}, {"audience", v.Audience}}
+	case *Form:
+		return []diffField{{"name", v.Name}}
+	}
+	return nil
+}
+
+func checkFields(c Check) []diffField {
+	return []diffField{{"text", c.Text}, {"no_check", c.NoCheck}, {"style", c.Style}}
+}
+
+func screenFields(s FormScreen) []diffField {
+	return []diffField{{"id", s.ID}, {"name", s.Name}, {"show_if", s.ShowIf}}
+}
+
+func inputFields(i FormInput) []diffField {
+	return []diffField{{"type", i.Type}, {"name", i.Name}, {"label", i.Label}, {"value", i.Value},
+		{"options", i.Options}, {"hint", i.Hint}, {"lines", i.Lines},
+		{"multi_select", i.MultiSelect}, {"other_option", i.OtherOption}, {"required", i.Required},
+		{"show_if", i.ShowIf}}
+}
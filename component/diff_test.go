@@ -0,0 +1,59 @@
+package component
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestDiff(c *C) {
+	old, p := bilingualParser(), bilingualParser()
+	c.Assert(Diff(old, p).Empty(), Equals, true)
+
+	f := errorForm()
+	old.forms["en"] = []*Form{f}
+	f = errorForm()
+	f.Screens[0].Items[0].Options = []string{"x", "z"}
+	f.Screens[1].Items = f.Screens[1].Items[:1]
+	f.Screens = append(f.Screens, FormScreen{Name: "Three"})
+	p.forms["en"] = []*Form{f}
+
+	en := p.categories["en"][0]
+	en.Sub("sub").Name = "Section"
+	diff := en.Sub("sub").Difficulty("beginner")
+	diff.Item("item").Title = "New title"
+	diff.Item("item").Body = "One\n\nTwo"
+	diff.AddItem(&Item{ID: "new", Title: "New"})
+	diff.AddChecks(Check{Text: "Another"})
+	en.Add(&Subcategory{ID: "extra", Name: "Extra"})
+	p.remove("en", diff.Item("other"))
+	old.categories["it"][0].Sub("sub").Difficulty("beginner").checklist.Checks[0].NoCheck = true
+	p.categories["fr"] = []*Category{testCategory("fr", "FR ")}
+
+	cs := Diff(old, p)
+	c.Assert(cs.Locales, DeepEquals, map[string][]Change{
+		"en": {
+			{Kind: ChangeModified, Path: "cat/sub", Fields: []string{"name"}},
+			{Kind: ChangeModified, Path: "cat/sub/beginner/item", Fields: []string{"title"}},
+			{Kind: ChangeAdded, Path: "cat/sub/beginner/new"},
+			{Kind: ChangeRemoved, Path: "cat/sub/beginner/other"},
+			{Kind: ChangeAdded, Path: "cat/sub/beginner/.checks/2"},
+			{Kind: ChangeAdded, Path: "cat/extra"},
+			{Kind: ChangeModified, Path: "forms/form/1/1", Fields: []string{"options"}},
+			{Kind: ChangeRemoved, Path: "forms/form/2/2"},
+			{Kind: ChangeAdded, Path: "forms/form/3"},
+		},
+		"it": {{Kind: ChangeModified, Path: "cat/sub/beginner/.checks/1", Fields: []string{"no_check"}}},
+		"fr": {{Kind: ChangeAdded, Path: "cat"}},
+	})
+	c.Assert(cs.Affected("en"), DeepEquals, []string{
+		"cat", "cat/extra", "cat/sub", "cat/sub/beginner", "cat/sub/beginner/.checks",
+		"cat/sub/beginner/item", "cat/sub/beginner/new", "cat/sub/beginner/other", "forms/form",
+	})
+	c.Assert(cs.Affected("it"), DeepEquals, []string{"cat", "cat/sub", "cat/sub/beginner", "cat/sub/beginner/.checks"})
+	c.Assert(cs.Affected("es"), HasLen, 0)
+
+	b, err := json.Marshal(Diff(p, old).Locales["fr"])
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `[{"kind":"removed","path":"cat"}]`)
+}
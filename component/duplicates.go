@@ -0,0 +1,207 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DuplicateError is a component added twice to a locale of the Parser, or whose slug is the
+// same of another component of the locale
+type DuplicateError struct {
+	Path   string
+	Locale string
+	Other  string // component with the same slug, empty if Path was added twice
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Other != "" {
+		return fmt.Sprintf("%s has the slug of %s (%s)", e.Path, e.Other, e.Locale)
+	}
+	return fmt.Sprintf("%s is a duplicate (%s)", e.Path, e.Locale)
+}
+
+// checkSlugs marks as failed the locales with two components with the same slug, so they
+// cannot be told apart by the resources, and the ones with a category whose ID starts with
+// slugMarker, so its legacy slugs cannot be told from the ones of EncodeSlug
+func (p *Parser) checkSlugs() {
+	var (
+		seen  = make(map[string]map[string]string) // path by locale and slug
+		check = func(c Component, locale string) {
+			if _, ok := p.failed[locale]; ok {
+				return
+			}
+			if seen[locale] == nil {
+				seen[locale] = make(map[string]string)
+			}
+			key, path := c.Resource().Slug, treePath(c)
+			if cat, ok := c.(*Category); ok && strings.HasPrefix(cat.ID, slugMarker) {
+				p.failed[locale] = parseError{path, "slug", fmt.Sprintf("ID starts with %q", slugMarker)}
+				return
+			}
+			if other, ok := seen[locale][key]; ok {
+				p.failed[locale] = parseError{path, "slug", &DuplicateError{Path: path, Locale: locale, Other: other}}
+				return
+			}
+			seen[locale][key] = path
+		}
+	)
+	for _, cat := range p.categories {
+		walkCategory(cat, func(c Component) { check(c, cat.Locale) })
+	}
+	for _, f := range p.forms {
+		check(f, f.Locale)
+	}
+	for _, g := range p.glossaries {
+		check(g, g.Locale)
+	}
+}
+
+type checkRef struct {
+	checklist *Checklist
+	index     int
+	text      string
+}
+
+func (c checkRef) String() string { return fmt.Sprintf("%s[%d]", treePath(c.checklist), c.index+1) }
+
+// CheckDuplicateChecks looks for checks of the locale repeated in other difficulties, with the
+// same text or one whose similarity (0 to 1) is at least the given one. It reports groups of
+// near duplicates whose texts differ, and groups of exact duplicates whose translations differ
+// in other locales. Groups fully in sync are not reported.
+func (r *ResourceParser) CheckDuplicateChecks(locale string, similarity float64) []Problem {
+	var refs []checkRef
+	for _, cat := range r.categories[locale] {
+		walkCategory(cat, func(c Component) {
+			if list, ok := c.(*Checklist); ok {
+				for i := range list.Checks {
+					refs = append(refs, checkRef{list, i, normalizeCheck(list.Checks[i].Text)})
+				}
+			}
+		})
+	}
+	// union find on the checks of different checklists
+	var parent = make([]int, len(refs))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range refs {
+		for j := i + 1; j < len(refs); j++ {
+			if refs[i].checklist == refs[j].checklist || refs[i].text == "" {
+				continue
+			}
+			if refs[i].text == refs[j].text || textSimilarity(refs[i].text, refs[j].text) >= similarity {
+				if a, b := root(i), root(j); a != b {
+					parent[b] = a
+				}
+			}
+		}
+	}
+	var (
+		groups = make(map[int][]checkRef)
+		roots  []int // in order of first check
+	)
+	for i := range refs {
+		k := root(i)
+		if _, ok := groups[k]; !ok {
+			roots = append(roots, k)
+		}
+		groups[k] = append(groups[k], refs[i])
+	}
+
+	var problems []Problem
+	for _, k := range roots {
+		g := groups[k]
+		if len(g) < 2 {
+			continue
+		}
+		if !sameTexts(g, func(c checkRef) string { return c.text }) {
+			problems = append(problems, duplicateProblem(g, locale, "near duplicates differ"))
+			continue
+		}
+		for _, l := range r.Locales(IncludeArchived()) {
+			if l == locale {
+				continue
+			}
+			translation := func(c checkRef) string {
+				if dst, ok := r.lookup(l, treePath(c.checklist)).(*Checklist); ok && c.index < len(dst.Checks) {
+					return normalizeCheck(dst.Checks[c.index].Text)
+				}
+				return ""
+			}
+			if !sameTexts(g, translation) {
+				problems = append(problems, duplicateProblem(g, l, "translations differ"))
+			}
+		}
+	}
+	return problems
+}
+
+func duplicateProblem(g []checkRef, locale, reason string) Problem {
+	var others = make([]string, len(g)-1)
+	for i := range others {
+		others[i] = g[i+1].String()
+	}
+	return Problem{
+		Path:    treePath(g[0].checklist),
+		Locale:  locale,
+		Message: fmt.Sprintf("check %d is repeated in %s, %s: consider inheriting a shared checklist", g[0].index+1, strings.Join(others, ", "), reason),
+	}
+}
+
+func sameTexts(g []checkRef, text func(checkRef) string) bool {
+	first := text(g[0])
+	for _, c := range g[1:] {
+		if text(c) != first {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeCheck removes notes, case and spacing differences from the text of a check
+func normalizeCheck(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(stripNotes(s)), " "))
+	return strings.TrimRightFunc(s, unicode.IsPunct)
+}
+
+// textSimilarity returns 1 minus the edit distance of the two strings, relative to the longest one
+func textSimilarity(a, b string) float64 {
+	x, y := []rune(a), []rune(b)
+	if len(x) < len(y) {
+		x, y = y, x
+	}
+	if len(x) == 0 {
+		return 1
+	}
+	var row = make([]int, len(y)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(x); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			v := prev + cost
+			if row[j]+1 < v {
+				v = row[j] + 1
+			}
+			if row[j-1]+1 < v {
+				v = row[j-1] + 1
+			}
+			prev, row[j] = row[j], v
+		}
+	}
+	return 1 - float64(row[len(y)])/float64(len(x))
+}
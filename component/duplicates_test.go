@@ -0,0 +1,97 @@
+package component
+
+import (
+	"testing/fstest"
+
+	. "gopkg.in/check.v1"
+)
+
+func duplicatesParser() *ResourceParser {
+	p := NewResourceParser()
+	for _, l := range []string{"en", "it"} {
+		cat := testCategory(l, "")
+		cat.Sub("sub").AddDifficulty(&Difficulty{ID: "expert"})
+		other := &Subcategory{ID: "other"}
+		cat.Add(other)
+		other.AddDifficulty(&Difficulty{ID: "beginner"})
+		p.categories[l] = []*Category{cat}
+	}
+	en, it := p.category("cat", "en"), p.category("cat", "it")
+	en.Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "Memorize an emergency contact"}, Check{Text: "Lock your phone"})
+	en.Sub("sub").Difficulty("expert").AddChecks(Check{Text: "memorize an emergency contact."}, Check{Text: "Use a password manager"})
+	en.Sub("other").Difficulty("beginner").AddChecks(Check{Text: "Lock your phones"}, Check{Text: "Use a password manager"})
+	it.Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "Memorizza un contatto di emergenza"}, Check{Text: "Blocca il telefono"})
+	it.Sub("sub").Difficulty("expert").AddChecks(Check{Text: "Memorizza un contatto di emergenza"}, Check{Text: "Usa un password manager"})
+	it.Sub("other").Difficulty("beginner").AddChecks(Check{Text: "Blocca i telefoni"}, Check{Text: "Usa un gestore di password"})
+	return p
+}
+
+func (CmpSuite) TestCheckDuplicateChecks(c *C) {
+	p := duplicatesParser()
+	c.Assert(p.CheckDuplicateChecks("en", 1), DeepEquals, []Problem{{
+		Path: "cat/sub/expert/.checks", Locale: "it",
+		Message: "check 2 is repeated in cat/other/beginner/.checks[2], translations differ: consider inheriting a shared checklist",
+	}})
+	c.Assert(p.CheckDuplicateChecks("en", 0.9), DeepEquals, []Problem{{
+		Path: "cat/sub/beginner/.checks", Locale: "en",
+		Message: "check 2 is repeated in cat/other/beginner/.checks[1], near duplicates differ: consider inheriting a shared checklist",
+	}, {
+		Path: "cat/sub/expert/.checks", Locale: "it",
+		Message: "check 2 is repeated in cat/other/beginner/.checks[2], translations differ: consider inheriting a shared checklist",
+	}})
+	c.Assert(p.CheckDuplicateChecks("en", 0.9), DeepEquals, p.CheckDuplicateChecks("en", 0.9))
+
+	p.category("cat", "it").Sub("other").Difficulty("beginner").checklist.Checks[1].Text = "Usa un password manager"
+	c.Assert(p.CheckDuplicateChecks("en", 1), HasLen, 0)
+}
+
+func (CmpSuite) TestTextSimilarity(c *C) {
+	c.Assert(textSimilarity("", ""), Equals, 1.0)
+	c.Assert(textSimilarity("abcd", "abcd"), Equals, 1.0)
+	c.Assert(textSimilarity("abcd", "abce"), Equals, 0.75)
+	c.Assert(textSimilarity("abcd", ""), Equals, 0.0)
+}
+
+func (CmpSuite) TestParseDuplicates(c *C) {
+	meta := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+	fsys := fstest.MapFS{
+		"contents_en/cat/.metadata.md":          meta("[Name]: # (Category)\n[Order]: # (1)"),
+		"contents_en/cat/sub/.metadata.md":      meta("[Name]: # (Sub)\n[Order]: # (1)"),
+		"contents_en/cat/sub/-beg/.metadata.md": meta("[Description]: # (Easy)"),
+		"contents_en/a_b/.metadata.md":          meta("[Name]: # (AB)\n[Order]: # (2)"),
+		"contents_en/a_b/c/.metadata.md":        meta("[Name]: # (C)\n[Order]: # (1)"),
+		"contents_en/a/.metadata.md":            meta("[Name]: # (A)\n[Order]: # (3)"),
+		"contents_en/a/b_c/.metadata.md":        meta("[Name]: # (BC)\n[Order]: # (1)"),
+		"contents_it/forms/.metadata.md":        meta("[Name]: # (Forms)\n[Order]: # (1)"),
+		"contents_it/forms/_/.metadata.md":      meta("[Name]: # (Sub)\n[Order]: # (1)"),
+		"contents_it/forms/_/f/.metadata.md":    meta("[Description]: # (Easy)"),
+		"forms_it/f.md":                         meta("[Name]: # (Form)"),
+		"contents_fr/_v2_cat/.metadata.md":      meta("[Name]: # (Cat)\n[Order]: # (1)"),
+	}
+	var p Parser
+	c.Assert(p.ParseFS(fsys), ErrorMatches, `\[slug\]_v2_cat - .*`)
+	r, err := p.ParseFSPartial(fsys)
+	c.Assert(err, IsNil)
+	c.Assert(p.CheckInvariants(), IsNil)
+
+	// the subcategory file comes after its difficulty and replaces the one created for it
+	sub := p.Categories()["en"][0].Sub("sub")
+	c.Assert(sub.Name, Equals, "Sub")
+	c.Assert(sub.Difficulty("-beg"), NotNil)
+
+	c.Assert(r.Loaded, DeepEquals, []string{"en"})
+	dup, ok := r.Failed["it"].(parseError)
+	c.Assert(ok, Equals, true)
+	c.Assert(dup.err, DeepEquals, &DuplicateError{Path: "forms/f", Locale: "it", Other: "forms/_/f"})
+	c.Assert(dup, ErrorMatches, `\[slug\]forms/f - forms/f has the slug of forms/_/f \(it\)`)
+	c.Assert(r.Failed["fr"], ErrorMatches, `\[slug\]_v2_cat - ID starts with "_v2_"`)
+
+	// the same item added twice
+	p.reset(false)
+	cat := &Category{ID: "cat", Locale: "en"}
+	p.addCat(cat)
+	name := "contents_en/cat/sub/beginner/item.md"
+	c.Assert(p.setPath(name, new(Item)), IsNil)
+	c.Assert(p.setPath(name, new(Item)), ErrorMatches, `\[path\].* - cat/sub/beginner/item is a duplicate \(en\)`)
+	c.Assert(p.setPath("contents_en/cat/.metadata.md", &Category{ID: "cat", Locale: "en"}), ErrorMatches, `.* cat is a duplicate \(en\)`)
+}
@@ -0,0 +1,79 @@
+package component
+
+import "strings"
+
+// Encode returns the resource of the component of the locale at the path of cmp, in the layout
+// expected by Parse: parsing it with cmp as base gives back the same component. Items always
+// use a row for the title followed by a row for each paragraph, even if they were parsed from
+// the legacy single body row, and forms have rows only for what cmp expects.
+func (r *ResourceParser) Encode(cmp Component, locale string) (*Resource, error) {
+	path := treePath(cmp)
+	c := r.lookup(locale, path)
+	if c == nil {
+		return nil, &NotFoundError{Path: path, Locale: locale}
+	}
+	return encode(cmp, c), nil
+}
+
+// EncodeComponent returns the resource of a component that is not in a parser, like a tree
+// built in memory, in the layout of Encode with the component as its own base.
+func EncodeComponent(c Component) *Resource { return encode(c, c) }
+
+// EncodeCategory returns the resources of the category and its descendants, by tree path,
+// see EncodeComponent
+func EncodeCategory(cat *Category) map[string]*Resource {
+	var m = make(map[string]*Resource)
+	walkCategory(cat, func(c Component) { m[treePath(c)] = encode(c, c) })
+	return m
+}
+
+// encode returns the resource of c, the component at the path of cmp
+func encode(cmp, c Component) *Resource {
+	res := c.Resource()
+	switch v := c.(type) {
+	case *Item:
+		row := res.Content[0]
+		delete(row, KeyBody)
+		res.Content = []map[string]string{row}
+		for _, p := range SplitBody(stripBodyNotes(v.Body)) {
+			res.Content = append(res.Content, map[string]string{KeyBody: p})
+		}
+	case *Form:
+		res.Content = encodeForm(cmp.(*Form), v)
+	}
+	return &res
+}
+
+// encodeForm returns the rows of the form f for the shape of the base form
+func encodeForm(base, f *Form) []map[string]string {
+	var rows = []map[string]string{{KeyForm: stripNotes(f.Name)}}
+	for i, s := range f.Screens {
+		if i >= len(base.Screens) {
+			break
+		}
+		if base.Screens[i].Name != "" {
+			row := map[string]string{KeyScreen: stripNotes(s.Name)}
+			if s.ID != "" {
+				row[KeyID] = s.ID
+			}
+			rows = append(rows, row)
+		}
+		for j, input := range s.Items {
+			if j >= len(base.Screens[i].Items) {
+				break
+			}
+			if b := base.Screens[i].Items[j]; b.Label == "" && b.Hint == "" && b.Options == nil {
+				continue
+			}
+			row := map[string]string{KeyLabel: stripNotes(input.Label)}
+			if input.Hint != "" {
+				row[KeyHint] = stripNotes(input.Hint)
+			}
+			if input.Options != nil {
+				row[KeyOptions] = strings.Join(input.Options, ";")
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,177 @@
+package component
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// randomTree returns a parser with a random tree in the locale, with items in the legacy
+// format, multi-paragraph bodies, styled checklists and forms.
+func randomTree(rnd *rand.Rand, locale string) *ResourceParser {
+	text := func(words int) string {
+		var s = make([]string, 1+rnd.Intn(words))
+		for i := range s {
+			s[i] = fmt.Sprintf("w%d", rnd.Intn(1000))
+		}
+		return strings.Join(s, " ")
+	}
+	p := NewResourceParser()
+	for i := 0; i < 1+rnd.Intn(3); i++ {
+		cat := &Category{ID: fmt.Sprint("cat", i), Name: text(3), Locale: locale}
+		for j := 0; j < 1+rnd.Intn(3); j++ {
+			sub := &Subcategory{ID: fmt.Sprint("sub", j), Name: text(3)}
+			cat.Add(sub)
+			diff := &Difficulty{ID: "beginner", Descr: text(5)}
+			sub.AddDifficulty(diff)
+			for k := 0; k < rnd.Intn(4); k++ {
+				var body = make([]string, 1+rnd.Intn(4))
+				for n := range body {
+					body[n] = text(10)
+				}
+				diff.AddItem(&Item{ID: fmt.Sprint("item", k), Title: text(4), Body: JoinBody(body)})
+			}
+			for k := 0; k < rnd.Intn(4); k++ {
+				check := Check{Text: text(6)}
+				if rnd.Intn(3) == 0 {
+					check.NoCheck, check.Style = true, []string{"", StyleInfo, StyleTip, StyleWarning}[rnd.Intn(4)]
+				}
+				diff.AddChecks(check)
+			}
+		}
+		p.categories[locale] = append(p.categories[locale], cat)
+	}
+	for i := 0; i < rnd.Intn(3); i++ {
+		form := &Form{ID: fmt.Sprint("form", i), Name: text(3), Locale: locale}
+		for j := 0; j < 1+rnd.Intn(3); j++ {
+			var screen FormScreen
+			if rnd.Intn(4) != 0 {
+				screen.Name = text(3)
+			}
+			for k := 0; k < rnd.Intn(4); k++ {
+				var input FormInput
+				switch rnd.Intn(4) {
+				case 0:
+					input.Label = text(3)
+				case 1:
+					input.Label, input.Hint = text(3), text(5)
+				case 2:
+					input.Label, input.Options = text(3), strings.Split(text(5), " ")
+				}
+				screen.Items = append(screen.Items, input)
+			}
+			form.Screens = append(form.Screens, screen)
+		}
+		// parsed forms have the IDs of the screens
+		for j, id := range form.ScreenIDs() {
+			form.Screens[j].ID = id
+		}
+		p.forms[locale] = append(p.forms[locale], form)
+	}
+	return p
+}
+
+// encodeAll encodes all the components of the locale of src and parses them in dst
+func encodeAll(c *C, src, dst *ResourceParser, locale string) {
+	for _, cmp := range src.localeComponents(locale) {
+		res, err := src.Encode(cmp, locale)
+		c.Assert(err, IsNil)
+		c.Assert(dst.Parse(cmp, res, locale), IsNil, Commentf("%s: %v", treePath(cmp), res.Content))
+	}
+}
+
+func (CmpSuite) TestEncodeRoundTrip(c *C) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		src := randomTree(rnd, "en")
+		dst := NewResourceParser()
+		encodeAll(c, src, dst, "en")
+		for _, cmp := range src.localeComponents("en") {
+			path := treePath(cmp)
+			got := dst.lookup("en", path)
+			c.Assert(got, NotNil, Commentf(path))
+			c.Assert(textFields(got), DeepEquals, textFields(cmp), Commentf(path))
+			a, err := src.Encode(cmp, "en")
+			c.Assert(err, IsNil)
+			b, err := dst.Encode(cmp, "en")
+			c.Assert(err, IsNil)
+			c.Assert(b.Content, DeepEquals, a.Content, Commentf(path))
+		}
+		c.Assert(dst.Problems(), HasLen, 0)
+	}
+}
+
+func (CmpSuite) TestEncodeComponent(c *C) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 20; i++ {
+		src := randomTree(rnd, "en")
+		dst := NewResourceParser()
+		for _, cat := range src.categories["en"] {
+			resources := EncodeCategory(cat)
+			walkCategory(cat, func(cmp Component) {
+				res := resources[treePath(cmp)]
+				c.Assert(res, NotNil)
+				c.Assert(dst.Parse(cmp, res, "it"), IsNil)
+			})
+		}
+		for _, f := range src.forms["en"] {
+			c.Assert(dst.Parse(f, EncodeComponent(f), "it"), IsNil)
+		}
+		for _, cmp := range src.localeComponents("en") {
+			expected, err := src.Encode(cmp, "en")
+			c.Assert(err, IsNil)
+			got, err := dst.Encode(cmp, "it")
+			c.Assert(err, IsNil)
+			c.Assert(got.Content, DeepEquals, expected.Content)
+			c.Assert(EncodeComponent(cmp).Content, DeepEquals, expected.Content)
+		}
+	}
+}
+
+func (CmpSuite) TestEncodeLegacyItem(c *C) {
+	p := NewResourceParser()
+	batch := itemBatch("Voce")
+	batch[1].Resource.Content = []map[string]string{{"title": "Voce", "body": "Uno\n\nDue [[note: check]]"}}
+	_, errs := p.ParseAll(batch)
+	c.Assert(errs, HasLen, 0)
+
+	item := batch[1].Component
+	res, err := p.Encode(item, "it")
+	c.Assert(err, IsNil)
+	c.Assert(res.Content, DeepEquals, []map[string]string{{"title": "Voce"}, {"body": "Uno"}, {"body": "Due"}})
+
+	_, err = p.Encode(item, "fr")
+	c.Assert(err, DeepEquals, &NotFoundError{Path: "cat/sub/beginner/item", Locale: "fr"})
+}
+
+func (CmpSuite) TestWriteResourcesCSV(c *C) {
+	rnd := rand.New(rand.NewSource(2))
+	dir := c.MkDir()
+	for i := 0; i < 20; i++ {
+		locale := fmt.Sprint("l", i)
+		src := randomTree(rnd, locale)
+		c.Assert(src.WriteResourcesCSV(dir, locale), IsNil)
+
+		resources, err := ReadResourcesCSV(dir, locale)
+		c.Assert(err, IsNil)
+		expected, err := src.ExportResources(locale)
+		c.Assert(err, IsNil)
+		c.Assert(resources, HasLen, len(expected))
+
+		// the source components are the base, parents come first
+		var batch []ParseRequest
+		for _, cmp := range src.localeComponents(locale) {
+			res := resources[treePath(cmp)]
+			c.Assert(res, NotNil, Commentf(treePath(cmp)))
+			batch = append(batch, ParseRequest{Component: cmp, Resource: res, Locale: locale})
+		}
+		p := NewResourceParser()
+		_, errs := p.ParseAll(batch)
+		c.Assert(errs, HasLen, 0)
+		got, err := p.ExportResources(locale)
+		c.Assert(err, IsNil)
+		c.Assert(got, DeepEquals, expected)
+	}
+}
@@ -0,0 +1,112 @@
+package component
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/russross/blackfriday"
+)
+
+// Encoding is how Tree writes the translatable texts. Every text is encoded exactly once,
+// in the package: consumers must not escape or render it again.
+type Encoding int
+
+const (
+	// RawMarkdown writes the texts as parsed, only without editorial notes
+	RawMarkdown Encoding = iota
+	// PlainText writes item bodies without Markdown formatting, other texts as parsed
+	PlainText
+	// SafeHTML renders item bodies to HTML, dropping raw HTML blocks, and escapes the other texts
+	SafeHTML
+)
+
+var encodings = map[string]Encoding{"markdown": RawMarkdown, "text": PlainText, "html": SafeHTML}
+
+// ParseEncoding returns the encoding with the name, RawMarkdown if empty
+func ParseEncoding(s string) (Encoding, error) {
+	if s == "" {
+		return RawMarkdown, nil
+	}
+	e, ok := encodings[s]
+	if !ok {
+		return 0, fmt.Errorf("Invalid encoding %q", s)
+	}
+	return e, nil
+}
+
+func (e Encoding) String() string {
+	for k, v := range encodings {
+		if v == e {
+			return k
+		}
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// text encodes a text that is not Markdown: names, titles, checks and form texts
+func (e Encoding) text(s string) string {
+	s = stripNotes(s)
+	if e == SafeHTML {
+		return html.EscapeString(s)
+	}
+	return s
+}
+
+// body encodes the Markdown body of an item
+func (e Encoding) body(s string) string {
+	s = stripBodyNotes(s)
+	switch e {
+	case PlainText:
+		var parts []string
+		for _, p := range strings.Split(s, paragraphSep) {
+			if p = stripMarkdown(p); p != "" {
+				parts = append(parts, p)
+			}
+		}
+		return strings.Join(parts, paragraphSep)
+	case SafeHTML:
+		return renderHTML(s)
+	}
+	return s
+}
+
+// paragraphs encodes each paragraph of the Markdown body of an item, dropping empty ones
+func (e Encoding) paragraphs(s string) []string {
+	var list []string
+	for _, p := range SplitBody(stripBodyNotes(s)) {
+		if p = strings.TrimSpace(e.body(p)); p != "" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// renderHTML renders Markdown, the final newline makes blackfriday recognize an HTML block at the end.
+// Links to other protocols than http, https, ftp and mailto, or item: for a cross reference,
+// and relative paths are rendered as text, so javascript: and data: links are dropped.
+func renderHTML(s string) string {
+	r := safeLinks{blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+		Flags: blackfriday.CommonHTMLFlags | blackfriday.SkipHTML | blackfriday.Safelink |
+			blackfriday.NofollowLinks | blackfriday.NoreferrerLinks,
+	})}
+	return string(blackfriday.Run([]byte(s+"\n"), blackfriday.WithRenderer(r)))
+}
+
+// safeLinks is an HTML renderer with Safelink that keeps the links of cross references, without rel
+type safeLinks struct{ *blackfriday.HTMLRenderer }
+
+func (r safeLinks) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	if node.Type == blackfriday.Link && strings.HasPrefix(string(node.LinkData.Destination), "item:") {
+		flags := r.Flags
+		r.Flags &^= blackfriday.Safelink | blackfriday.NofollowLinks | blackfriday.NoreferrerLinks
+		defer func() { r.Flags = flags }()
+	}
+	return r.HTMLRenderer.RenderNode(w, node, entering)
+}
+
+// htmlCache keeps the SafeHTML body of an item, rendered when the item is read
+type htmlCache struct {
+	body, html string
+}
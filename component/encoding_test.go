@@ -0,0 +1,133 @@
+package component
+
+import (
+	"html"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	encName = `Tom & Jerry's <"Guide">`
+	encBody = "Use **bold** & <script>x</script> \"quotes\" [[note: check]]\n\n<div>block</div>\n\nSecond <b>para</b> 1 < 2"
+)
+
+func encodingCategory() (*Category, *Form) {
+	cat := testCategory("en", "")
+	cat.Name = encName
+	cat.Sub("sub").Name = encName
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.Descr = encName
+	var item Item
+	if err := item.SetContents("[Title]: # (" + encName + ")\n[Order]: # (1)\n\n" + encBody); err != nil {
+		panic(err)
+	}
+	item.ID = "item"
+	diff.AddItem(&item)
+	diff.AddChecks(Check{Text: encName + " [[note: check]]"})
+	form := &Form{ID: "form", Name: encName, Screens: []FormScreen{
+		{Name: encName, Items: []FormInput{{Label: encName, Hint: encName, Options: []string{"a & b", "<c>"}}}},
+	}}
+	return cat, form
+}
+
+func (CmpSuite) TestEncodingParagraphs(c *C) {
+	cat, _ := encodingCategory()
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.Item("item").Body += "\n\n\n\n  Last  \n\n"
+	for enc, paragraphs := range map[Encoding][]string{
+		RawMarkdown: {"Use **bold** & <script>x</script> \"quotes\"", "<div>block</div>", "Second <b>para</b> 1 < 2", "Last"},
+		PlainText:   {"Use bold & x \"quotes\"", "block", "Second para 1 < 2", "Last"},
+		SafeHTML:    {"<p>Use <strong>bold</strong> &amp; x &ldquo;quotes&rdquo;</p>", "<p>Second para 1 &lt; 2</p>", "<p>Last</p>"},
+	} {
+		item := diff.Tree(enc).(map[string]interface{})["items"].([]Item)[0]
+		c.Assert(item.Paragraphs, DeepEquals, paragraphs, Commentf("%s", enc))
+	}
+}
+
+func (CmpSuite) TestEncodingSnippets(c *C) {
+	cat, _ := encodingCategory()
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.Item("item").Body = "Run `a <b>`:\n\n```sh\nx & y\n```"
+	for _, enc := range []Encoding{RawMarkdown, PlainText, SafeHTML} {
+		item := diff.Tree(enc).(map[string]interface{})["items"].([]Item)[0]
+		c.Assert(item.Snippets, DeepEquals, []Snippet{{Text: "a <b>", Offset: 4}, {Language: "sh", Text: "x & y", Offset: 14}})
+	}
+}
+
+// encodedTexts returns every text of the trees, with the item bodies last
+func encodedTexts(cat *Category, form *Form, enc Encoding) (texts []string, body string) {
+	tc := cat.Tree(enc).(map[string]interface{})
+	sub := tc["subcategories"].([]interface{})[0].(map[string]interface{})
+	diff := sub["difficulties"].([]interface{})[0].(map[string]interface{})
+	item := diff["items"].([]Item)[0]
+	f := form.Tree(enc).(*Form)
+	in := f.Screens[0].Items[0]
+	texts = []string{
+		tc["name"].(string), sub["name"].(string), diff["description"].(string), item.Title,
+		diff["checks"].([]Check)[0].Text, f.Name, f.Screens[0].Name, in.Label, in.Hint,
+	}
+	return texts, item.Body
+}
+
+func (CmpSuite) TestEncoding(c *C) {
+	cat, form := encodingCategory()
+
+	texts, body := encodedTexts(cat, form, RawMarkdown)
+	for _, t := range texts {
+		c.Assert(t, Equals, encName)
+	}
+	c.Assert(body, Equals, "Use **bold** & <script>x</script> \"quotes\"\n\n<div>block</div>\n\nSecond <b>para</b> 1 < 2")
+	c.Assert(form.Tree(RawMarkdown).(*Form).Screens[0].Items[0].Options, DeepEquals, []string{"a & b", "<c>"})
+
+	texts, body = encodedTexts(cat, form, PlainText)
+	for _, t := range texts {
+		c.Assert(t, Equals, encName)
+	}
+	c.Assert(body, Equals, "Use bold & x \"quotes\"\n\nblock\n\nSecond para 1 < 2")
+
+	texts, body = encodedTexts(cat, form, SafeHTML)
+	for _, t := range texts {
+		c.Assert(t, Equals, "Tom &amp; Jerry&#39;s &lt;&#34;Guide&#34;&gt;")
+		c.Assert(html.UnescapeString(t), Equals, encName)
+	}
+	c.Assert(body, Equals, "<p>Use <strong>bold</strong> &amp; x &ldquo;quotes&rdquo;</p>\n\n<p>Second para 1 &lt; 2</p>\n")
+	c.Assert(form.Tree(SafeHTML).(*Form).Screens[0].Items[0].Options, DeepEquals, []string{"a &amp; b", "&lt;c&gt;"})
+
+	// the trees are copies
+	c.Assert(form.Name, Equals, encName)
+	c.Assert(form.Screens[0].Items[0].Options[0], Equals, "a & b")
+	c.Assert(cat.Sub("sub").Difficulty("beginner").Item("item").Title, Equals, encName)
+}
+
+func (CmpSuite) TestEncodingBodyChange(c *C) {
+	cat, form := encodingCategory()
+	item := cat.Sub("sub").Difficulty("beginner").Item("item")
+	item.Body = "Changed & *new*"
+	_, body := encodedTexts(cat, form, SafeHTML)
+	c.Assert(body, Equals, "<p>Changed &amp; <em>new</em></p>\n")
+}
+
+func (CmpSuite) TestParseEncoding(c *C) {
+	for s, e := range map[string]Encoding{"": RawMarkdown, "markdown": RawMarkdown, "text": PlainText, "html": SafeHTML} {
+		enc, err := ParseEncoding(s)
+		c.Assert(err, IsNil)
+		c.Assert(enc, Equals, e)
+		if s != "" {
+			c.Assert(enc.String(), Equals, s)
+		}
+	}
+	_, err := ParseEncoding("xml")
+	c.Assert(err, ErrorMatches, `Invalid encoding "xml"`)
+}
+
+func (CmpSuite) TestEncodingLinks(c *C) {
+	for md, expected := range map[string]string{
+		"[x](javascript:alert`1`)":        "<p><tt>x</tt></p>\n",
+		"[x](data:text/html;base64,PHNj)": "<p><tt>x</tt></p>\n",
+		"[x](JavaScript:void)":            "<p><tt>x</tt></p>\n",
+		"[x](https://example.org)":        "<p><a href=\"https://example.org\" rel=\"nofollow noreferrer\">x</a></p>\n",
+		"[x](item:other)":                 "<p><a href=\"item:other\">x</a></p>\n",
+	} {
+		c.Assert(renderHTML(md), Equals, expected, Commentf(md))
+	}
+}
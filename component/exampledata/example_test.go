@@ -0,0 +1,117 @@
+package exampledata_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/securityfirst/tent/component/exampledata"
+)
+
+func ExampleLoadExample() {
+	r, err := exampledata.LoadExample()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, l := range r.Locales() {
+		for _, cat := range r.Categories()[l] {
+			fmt.Println(l, cat.ID, cat.Name, cat.Subcategories())
+		}
+	}
+	// Output:
+	// en travel Travel [borders]
+	// en devices Devices [phones]
+	// it travel Viaggi [borders]
+	// it devices Dispositivi [phones]
+}
+
+// Translations are parsed using the base component and the translated resource.
+func ExampleBase_parse() {
+	cmps, err := exampledata.Base()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	r := component.NewResourceParser()
+	for _, c := range cmps {
+		if item, ok := c.(*component.Item); ok && item.ID == "lock" {
+			res := item.Resource()
+			res.Content = []map[string]string{
+				{"title": "Blocca il telefono"},
+				{"body": "Usa un PIN di almeno sei cifre."},
+				{"body": "Disattiva le notifiche sulla schermata di blocco."},
+			}
+			if err := r.Parse(item, &res, "it"); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+	}
+	item := r.Categories()["it"][0].Sub("phones").Difficulty("beginner").Item("lock")
+	fmt.Println(item.Title)
+	fmt.Println(item.Summary())
+	// Output:
+	// Blocca il telefono
+	// Usa un PIN di almeno sei cifre.
+}
+
+func ExampleLoadExample_readiness() {
+	r, err := exampledata.LoadExample()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	it := r.LocaleReadiness(exampledata.BaseLocale)["it"]
+	fmt.Printf("components %.0f%%, words %.0f%%, ready %v\n", it.Components, it.Words, it.Ready)
+	// Output:
+	// components 67%, words 67%, ready false
+}
+
+func ExampleLoadExample_exportBilingual() {
+	r, err := exampledata.LoadExample()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := r.ExportBilingual(os.Stdout, "en", "it", "travel", component.FormatCSV); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// path,field,index,en,it,status
+	// travel,name,1,Travel,Viaggi,
+	// travel/borders,name,1,Borders,Frontiere,
+	// travel/borders/advanced,description,1,Travelling with sensitive data,Viaggiare con dati sensibili,
+	// travel/borders/advanced/travel-device,title,1,Use a travel device,,missing
+	// travel/borders/advanced/travel-device,body,1,Carry a phone with only the apps you need for the trip.,,missing
+	// travel/borders/advanced/.checks,text,1,Memorize an emergency contact,,missing
+	// travel/borders/beginner,description,1,Crossing a border with your devices,Attraversare una frontiera con i propri dispositivi,
+	// travel/borders/beginner/prepare,title,1,Before you leave,Prima di partire,
+	// travel/borders/beginner/prepare,body,1,Back up your phone and remove the data you do not need.,Fai il backup del telefono e rimuovi i dati che non ti servono.,
+	// travel/borders/beginner/prepare,body,2,Know your rights at the border of the country you are visiting.,Conosci i tuoi diritti alla frontiera del paese che visiti.,
+	// travel/borders/beginner/search,title,1,If your devices are searched,Se i dispositivi vengono perquisiti,
+	// travel/borders/beginner/search,body,1,## Stay calm,## Mantieni la calma,
+	// travel/borders/beginner/search,body,2,Do not lie to officials.,Non mentire ai funzionari.,
+	// travel/borders/beginner/.checks,text,1,Back up your phone,Fai il backup del telefono,
+	// travel/borders/beginner/.checks,text,2,Things to remember at the border,Cose da ricordare alla frontiera,
+	// travel/borders/beginner/.checks,text,3,Memorize an emergency contact,Memorizza un contatto di emergenza,
+}
+
+func ExampleBase_validateAnswers() {
+	cmps, err := exampledata.Base()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	form := cmps[len(cmps)-1].(*component.Form)
+	v, err := form.CompileValidator()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(v.VisibleScreens(nil))
+	fmt.Println(v.Validate(map[string]string{"kind": "Theft", "help": "Legal;other"}))
+	// Output:
+	// [what-happened follow-up]
+	// [help.other: missing text]
+}
@@ -0,0 +1,105 @@
+// Package exampledata contains a small dataset in two locales, English and Italian, with the same
+// layout of a content repository and the Italian translations as downloaded from Transifex.
+package exampledata
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/securityfirst/tent/component"
+)
+
+// BaseLocale is the locale of the repository content
+const BaseLocale = "en"
+
+//go:embed all:data
+var data embed.FS
+
+// FS contains the repository content (contents_en and forms_en), and the translations
+// (translations/<locale>.json) as resource contents keyed by slug.
+var FS, _ = fs.Sub(data, "data")
+
+// Base returns the components of the base locale, in tree order
+func Base() ([]component.Component, error) {
+	var p component.Parser
+	if err := p.ParseFS(FS); err != nil {
+		return nil, err
+	}
+	var list []component.Component
+	for _, cat := range p.Categories()[BaseLocale] {
+		list = append(list, cat)
+		for _, s := range cat.Subcategories() {
+			sub := cat.Sub(s)
+			list = append(list, sub)
+			for _, d := range sub.DifficultyNames() {
+				diff := sub.Difficulty(d)
+				list = append(list, diff)
+				for _, i := range diff.ItemNames() {
+					list = append(list, diff.Item(i))
+				}
+				if checks := diff.Checks(); checks.HasChildren() {
+					list = append(list, checks)
+				}
+			}
+		}
+	}
+	for _, f := range p.Forms() {
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// LoadExample returns a ResourceParser with the base locale and all the translations
+func LoadExample() (*component.ResourceParser, error) {
+	cmps, err := Base()
+	if err != nil {
+		return nil, err
+	}
+	r := component.NewResourceParser()
+	for _, c := range cmps {
+		res := c.Resource()
+		if err := r.Parse(c, &res, BaseLocale); err != nil {
+			return nil, fmt.Errorf("%s: %s", c.Path(), err)
+		}
+	}
+	files, err := fs.Glob(FS, "translations/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		b, err := fs.ReadFile(FS, name)
+		if err != nil {
+			return nil, err
+		}
+		var legacy map[string][]map[string]string
+		if err := json.Unmarshal(b, &legacy); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		// the translations are keyed by legacy slugs
+		var resources = make(map[string][]map[string]string, len(legacy))
+		for slug, content := range legacy {
+			parts, err := component.SplitSlug(slug)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			resources[component.EncodeSlug(parts...)] = content
+		}
+		locale := strings.TrimSuffix(path.Base(name), ".json")
+		for _, c := range cmps {
+			res := c.Resource()
+			content, ok := resources[res.Slug]
+			if !ok {
+				continue
+			}
+			res.Content = content
+			if err := r.Parse(c, &res, locale); err != nil {
+				return nil, fmt.Errorf("%s (%s): %s", res.Slug, locale, err)
+			}
+		}
+	}
+	return r, nil
+}
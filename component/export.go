@@ -0,0 +1,125 @@
+package component
+
+import (
+	"errors"
+	"strings"
+)
+
+// Format is the output format of an export
+type Format string
+
+const (
+	FormatCSV      Format = "csv"
+	FormatHTML     Format = "html"
+	FormatJSON     Format = "json"
+	FormatMarkdown Format = "md"
+)
+
+var ErrFormat = errors.New("Invalid format")
+
+// textField is a group of translatable units of a component sharing the same field
+type textField struct {
+	Name  string
+	Texts []string
+}
+
+// textFields splits a component into its translatable units, in canonical order.
+// Every export that works per unit must use it, so counts always match.
+func textFields(c Component) []textField {
+	switch v := c.(type) {
+	case *Category:
+		return []textField{{KeyName, []string{v.Name}}}
+	case *Subcategory:
+		return []textField{{KeyName, []string{v.Name}}}
+	case *Difficulty:
+		return []textField{{KeyDescription, []string{v.Descr}}}
+	case *Item:
+		return []textField{
+			{KeyTitle, []string{v.Title}},
+			{KeyBody, strings.Split(stripBodyNotes(v.Body), paragraphSep)},
+		}
+	case *Checklist:
+		var texts = make([]string, len(v.Checks))
+		for i := range v.Checks {
+			texts[i] = stripNotes(v.Checks[i].Text)
+		}
+		return []textField{{KeyText, texts}}
+	case *Form:
+		var screens, labels, hints, options []string
+		for _, s := range v.Screens {
+			screens = append(screens, s.Name)
+			for _, i := range s.Items {
+				labels = append(labels, stripNotes(i.Label))
+				hints = append(hints, stripNotes(i.Hint))
+				options = append(options, strings.Join(i.Options, ";"))
+			}
+		}
+		return []textField{
+			{KeyForm, []string{v.Name}},
+			{KeyScreen, screens},
+			{KeyLabel, labels},
+			{KeyHint, hints},
+			{KeyOptions, options},
+		}
+	case *Glossary:
+		var terms, definitions = make([]string, len(v.Terms)), make([]string, len(v.Terms))
+		for i, t := range v.Terms {
+			terms[i], definitions[i] = stripNotes(t.Term), stripNotes(t.Definition)
+		}
+		return []textField{{KeyTerm, terms}, {KeyDefinition, definitions}}
+	case *Quiz:
+		var questions, options, explanations []string
+		for _, q := range v.Questions {
+			questions = append(questions, stripNotes(q.Text))
+			options = append(options, strings.Join(q.Options, ";"))
+			explanations = append(explanations, stripNotes(q.Explanation))
+		}
+		return []textField{{KeyQuestion, questions}, {KeyOptions, options}, {KeyExplanation, explanations}}
+	}
+	return nil
+}
+
+// treePath returns the locale independent path of a component in the tree
+func treePath(c Component) string {
+	switch v := c.(type) {
+	case *Category:
+		return v.ID
+	case *Subcategory:
+		return treePath(v.parent) + "/" + v.ID
+	case *Difficulty:
+		return treePath(v.parent) + "/" + v.ID
+	case *Item:
+		return treePath(v.parent) + "/" + v.ID
+	case *Checklist:
+		return treePath(v.parent) + "/" + suffixChecks
+	case *Quiz:
+		return treePath(v.parent) + "/" + suffixQuiz
+	case *Form:
+		return "forms/" + v.ID
+	case *Glossary:
+		return "glossary/" + v.ID
+	case *Asset:
+		return "assets/" + v.ID
+	}
+	return ""
+}
+
+// walkCategory calls fn for the category and every descendant, in canonical order
+func walkCategory(cat *Category, fn func(c Component)) {
+	fn(cat)
+	for _, sub := range cat.subcategories {
+		fn(sub)
+		for _, diff := range sub.difficulties {
+			fn(diff)
+			for _, item := range diff.items {
+				fn(item)
+			}
+			if diff.checklist != nil && len(diff.checklist.Checks) != 0 {
+				fn(diff.checklist)
+			}
+			if diff.quiz != nil && len(diff.quiz.Questions) != 0 {
+				fn(diff.quiz)
+			}
+		}
+	}
+}
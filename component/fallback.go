@@ -0,0 +1,123 @@
+package component
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrMissingTranslation is returned, with a fallback locale, for a component whose category
+// exists in neither its locale nor the fallback one
+var ErrMissingTranslation = errors.New("Missing translation")
+
+// SetFallbackLocale makes the parser check the category of subcategories, difficulties, items
+// and checklists. If it was not parsed for the locale, it is created with the name it has in
+// the fallback locale and a problem is recorded; if the fallback locale is missing it too, the
+// component is rejected with ErrMissingTranslation. Without a fallback locale, the default,
+// missing categories are created without a name. It's Config.FallbackLocale.
+func (r *ResourceParser) SetFallbackLocale(locale string) { r.fallback = locale }
+
+// SetFallbackChain sets the locales used, in order, for the missing content of locale, instead
+// of the fallback locale: the first one that has a missing parent gives its name, like with
+// SetFallbackLocale, and Resolve reads from them. An empty chain restores the fallback locale.
+// It's Config.FallbackChains.
+func (r *ResourceParser) SetFallbackChain(locale string, chain ...string) {
+	if len(chain) == 0 {
+		delete(r.chains, locale)
+		return
+	}
+	if r.chains == nil {
+		r.chains = make(map[string][]string)
+	}
+	r.chains[locale] = append([]string(nil), chain...)
+}
+
+// fallbackChain returns the locales used for the missing content of locale, in order
+func (r *ResourceParser) fallbackChain(locale string) []string {
+	if chain, ok := r.chains[locale]; ok {
+		return chain
+	}
+	if r.fallback == "" || r.fallback == locale {
+		return nil
+	}
+	return []string{r.fallback}
+}
+
+// parentCategory returns the category of the locale for a component of cat
+func (r *ResourceParser) parentCategory(cat *Category, locale string) (*Category, error) {
+	chain := r.fallbackChain(locale)
+	if r.fallback == "" && len(chain) == 0 || r.category(cat.ID, locale) != nil {
+		return r.getCategory(cat, locale), nil
+	}
+	for _, l := range chain {
+		base := r.category(cat.ID, l)
+		if base == nil {
+			continue
+		}
+		c := r.getCategory(cat, locale)
+		c.Name, c.SourceLocale = base.Name, l
+		r.warn(c, locale, "category missing, name of %s used", l)
+		return c, nil
+	}
+	return nil, &NotFoundError{Path: cat.ID, Locale: locale, Cause: ErrMissingTranslation, Parent: true}
+}
+
+// fallbackParent returns the component at the tree path in the first locale of the fallback
+// chain of locale that has it, with that locale
+func (r *ResourceParser) fallbackParent(locale, path string) (Component, string) {
+	for _, l := range r.fallbackChain(locale) {
+		if c := r.lookup(l, path); c != nil {
+			return c, l
+		}
+	}
+	return nil, ""
+}
+
+// Resolve returns a copy of the component of the locale at the tree path. If it's missing, or
+// still to be translated (see BootstrapLocale), the component of the first locale of the
+// fallback chain that has it is returned, with SourceLocale set on it and its descendants, so
+// the content can be shown as untranslated. It fails with ErrMissingTranslation if no locale
+// has the component.
+func (r *ResourceParser) Resolve(locale, path string) (Component, error) {
+	for _, l := range append([]string{locale}, r.fallbackChain(locale)...) {
+		if r.translation(l, path) == nil {
+			continue
+		}
+		source := ""
+		if l != locale {
+			source = l
+		}
+		p := strings.Split(path, "/")
+		if p[0] == "forms" {
+			f := r.FormUnsafe(p[1], l).Copy()
+			if source != "" {
+				f.SourceLocale = source
+			}
+			return f, nil
+		}
+		cat := r.category(p[0], l).Copy()
+		if source != "" {
+			walkCategory(cat, func(c Component) { setSourceLocale(c, source) })
+		}
+		return lookupCategory(cat, p), nil
+	}
+	return nil, &NotFoundError{Path: path, Locale: locale, Cause: ErrMissingTranslation}
+}
+
+func setSourceLocale(c Component, locale string) {
+	switch v := c.(type) {
+	case *Category:
+		v.SourceLocale = locale
+	case *Subcategory:
+		v.SourceLocale = locale
+	case *Difficulty:
+		v.SourceLocale = locale
+	case *Item:
+		v.SourceLocale = locale
+	case *Checklist:
+		v.SourceLocale = locale
+	case *Quiz:
+		v.SourceLocale = locale
+	case *Form:
+		v.SourceLocale = locale
+	}
+}
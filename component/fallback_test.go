@@ -0,0 +1,124 @@
+package component
+
+import (
+	"context"
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestFallbackLocale(c *C) {
+	var (
+		cat  = testCategory("en", "")
+		sub  = cat.Sub("sub")
+		diff = sub.Difficulty("beginner")
+		item = &Item{ID: "item", Title: "Title", Body: "Body"}
+		name = &Resource{Content: []map[string]string{{"name": "Sottocategoria"}}}
+		body = &Resource{Content: []map[string]string{{"title": "Titolo", "body": "Testo"}}}
+	)
+	diff.AddItem(item)
+
+	// without a fallback the category is created without a name
+	p := NewResourceParser()
+	c.Assert(p.Parse(sub, name, "it"), IsNil)
+	c.Assert(p.categories["it"], HasLen, 1)
+	c.Assert(p.categories["it"][0].Name, Equals, "")
+	c.Assert(p.Problems(), HasLen, 0)
+
+	// the category of the fallback locale is used
+	p = NewResourceParser()
+	p.SetFallbackLocale("en")
+	c.Assert(p.Parse(cat, &Resource{Content: []map[string]string{{"name": "Category"}}}, "en"), IsNil)
+	c.Assert(p.Parse(item, body, "it"), IsNil)
+	c.Assert(p.categories["it"], HasLen, 1)
+	c.Assert(p.categories["it"][0].Name, Equals, "Category")
+	c.Assert(p.categories["it"][0].Sub("sub").Difficulty("beginner").Item("item").Title, Equals, "Titolo")
+	c.Assert(p.Problems(), DeepEquals, []Problem{{Path: "cat", Locale: "it", Message: "category missing, name of en used"}})
+	c.Assert(p.Parse(sub, name, "it"), IsNil)
+	c.Assert(p.Problems(), HasLen, 1)
+
+	// no locale has the category
+	p = NewResourceParser()
+	p.SetFallbackLocale("en")
+	for _, tc := range []struct {
+		cmp    Component
+		res    *Resource
+		locale string
+	}{
+		{sub, name, "it"},
+		{diff, &Resource{Content: []map[string]string{{"description": "Facile"}}}, "it"},
+		{item, body, "it"},
+		{diff.Checks(), &Resource{}, "it"},
+		{item, body, "en"},
+	} {
+		err := p.Parse(tc.cmp, tc.res, tc.locale)
+		c.Assert(errors.Is(err, ErrMissingTranslation), Equals, true)
+		c.Assert(err, ErrorMatches, `Missing translation: cat not found \(`+tc.locale+`\)`)
+		c.Assert(err, DeepEquals, &NotFoundError{Path: "cat", Locale: tc.locale, Cause: ErrMissingTranslation, Parent: true})
+		c.Assert(errors.Is(err, ErrMissingParent), Equals, true)
+	}
+	c.Assert(p.categories, HasLen, 0)
+}
+
+func (CmpSuite) TestFallbackChain(c *C) {
+	var (
+		cat   = testCategory("en", "")
+		sub   = cat.Sub("sub")
+		diff  = sub.Difficulty("beginner")
+		item  = &Item{ID: "item", Title: "Title", Body: "Body"}
+		other = &Item{ID: "other", Title: "Other", Body: "Other body"}
+		row   = func(k, v string) *Resource { return &Resource{Content: []map[string]string{{k: v}}} }
+		body  = &Resource{Content: []map[string]string{{"title": "Título", "body": "Texto"}}}
+	)
+	diff.AddItem(item, other)
+	sub.Audience = []string{"kenya"}
+	work := map[string][]ParseRequest{
+		"en": {{Component: cat, Resource: row("name", "Category")}, {Component: sub, Resource: row("name", "Sub")},
+			{Component: diff, Resource: row("description", "Easy")},
+			{Component: item, Resource: &Resource{Content: []map[string]string{{"title": "Title", "body": "Body"}}}},
+			{Component: other, Resource: &Resource{Content: []map[string]string{{"title": "Other", "body": "Other body"}}}}},
+		"pt":    {{Component: cat, Resource: row("name", "Categoria")}, {Component: sub, Resource: row("name", "Subcategoria")}},
+		"pt-BR": {{Component: item, Resource: body}},
+	}
+	p := NewResourceParser()
+	p.SetFallbackChain("pt-BR", "pt", "en")
+	p.SetFallbackChain("pt", "en")
+	c.Assert(p.ParseLocales(context.Background(), work, 4), IsNil)
+
+	// the missing parents are taken from the first locale that has them
+	br := p.category("cat", "pt-BR")
+	c.Assert([]string{br.Name, br.SourceLocale}, DeepEquals, []string{"Categoria", "pt"})
+	c.Assert([]string{br.Sub("sub").Name, br.Sub("sub").SourceLocale}, DeepEquals, []string{"Subcategoria", "pt"})
+	br.Sub("sub").Audience[0] = "uganda"
+	c.Assert(p.category("cat", "pt").Sub("sub").Audience, DeepEquals, []string{"kenya"})
+	d := br.Sub("sub").Difficulty("beginner")
+	c.Assert([]string{d.Descr, d.SourceLocale}, DeepEquals, []string{"Easy", "en"})
+	c.Assert(p.Problems(), DeepEquals, []Problem{{Path: "cat", Locale: "pt-BR", Message: "category missing, name of pt used"}})
+
+	// resolved components tell where they come from
+	for _, tc := range []struct {
+		path, title, source string
+	}{
+		{"cat/sub/beginner/item", "Título", ""},
+		{"cat/sub/beginner/other", "Other", "en"},
+	} {
+		cmp, err := p.Resolve("pt-BR", tc.path)
+		c.Assert(err, IsNil)
+		c.Assert(cmp.(*Item).Title, Equals, tc.title)
+		c.Assert(cmp.(*Item).SourceLocale, Equals, tc.source)
+	}
+	cmp, err := p.Resolve("pt", "cat/sub/beginner")
+	c.Assert(err, IsNil)
+	c.Assert(cmp.(*Difficulty).SourceLocale, Equals, "en")
+	c.Assert(cmp.(*Difficulty).Item("other").SourceLocale, Equals, "en")
+	c.Assert(p.category("cat", "en").Sub("sub").SourceLocale, Equals, "")
+	_, err = p.Resolve("pt-BR", "cat/sub/beginner/missing")
+	c.Assert(err, DeepEquals, &NotFoundError{Path: "cat/sub/beginner/missing", Locale: "pt-BR", Cause: ErrMissingTranslation})
+
+	// a parsed translation is not a fallback anymore
+	c.Assert(p.Parse(sub, row("name", "Subcategoria BR"), "pt-BR"), IsNil)
+	c.Assert(br.Sub("sub").SourceLocale, Equals, "")
+
+	c.Assert((&Config{FallbackChains: map[string][]string{"pt": {"en", "en"}}}).Validate(), ErrorMatches, `Invalid fallback chain of "pt"`)
+	c.Assert((&Config{FallbackChains: map[string][]string{"pt": {"pt"}}}).Validate(), ErrorMatches, `Invalid fallback chain of "pt"`)
+}
@@ -0,0 +1,192 @@
+package component
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// OtherValue is the answer that selects the free text option of an input
+	OtherValue = "other"
+	// OtherSuffix is appended to the input name to get the key of the free text answer
+	OtherSuffix = ".other"
+	// answerSeparator separates the values of a multi select answer
+	answerSeparator = ";"
+)
+
+// AnswerError is a problem with the answer to a form input
+type AnswerError struct {
+	Input  string `json:"input"`
+	Reason string `json:"reason"`
+}
+
+func (a AnswerError) Error() string { return fmt.Sprintf("%s: %s", a.Input, a.Reason) }
+
+// ValidateAnswers checks the answers, keyed by input name, against the form.
+// Multi select inputs accept values joined by semicolons; inputs with an other option accept
+// OtherValue, with the free text in the answer named after the input plus OtherSuffix.
+func (f *Form) ValidateAnswers(answers map[string]string) []AnswerError {
+	return f.compile().Validate(answers)
+}
+
+// FormValidator validates answers against a form. It does not change after creation
+// and it's safe to use from multiple goroutines.
+type FormValidator struct {
+	inputs  map[string]*compiledInput
+	order   []*compiledInput // inputs in the order of the form
+	screens []string
+	showIf  [][]FormCondition // conditions of the screens
+}
+
+type compiledInput struct {
+	FormInput
+	options map[string]bool
+	screen  int // position of the screen
+	showIf  []FormCondition
+}
+
+// CompileValidator returns a validator for the current state of the form.
+// It fails if two inputs share the same name.
+func (f *Form) CompileValidator() (*FormValidator, error) {
+	var seen = make(map[string]bool)
+	for _, s := range f.Screens {
+		for _, i := range s.Items {
+			for _, n := range []string{i.Name, i.Name + OtherSuffix} {
+				if seen[n] {
+					return nil, fmt.Errorf("Duplicate input %q in form %s", n, f.ID)
+				}
+				seen[n] = true
+			}
+		}
+	}
+	return f.compile(), nil
+}
+
+func (f *Form) compile() *FormValidator {
+	var v = FormValidator{
+		inputs:  make(map[string]*compiledInput),
+		screens: f.ScreenIDs(),
+		showIf:  make([][]FormCondition, len(f.Screens)),
+	}
+	for i := range f.Screens {
+		v.showIf[i] = f.Screens[i].Conditions()
+		for j := range f.Screens[i].Items {
+			input := &compiledInput{FormInput: f.Screens[i].Items[j], screen: i}
+			input.showIf = input.Conditions()
+			v.order = append(v.order, input)
+			input.Options = append([]string(nil), input.Options...)
+			input.options = make(map[string]bool, len(input.Options))
+			for _, o := range input.Options {
+				input.options[o] = true
+			}
+			v.inputs[input.Name] = input
+			if input.OtherOption {
+				v.inputs[input.Name+OtherSuffix] = input
+			}
+		}
+	}
+	return &v
+}
+
+// Validate checks the answers, keyed by input name, see Form.ValidateAnswers.
+func (v *FormValidator) Validate(answers map[string]string) []AnswerError {
+	var errs []AnswerError
+	var keys = make([]string, 0, len(answers))
+	for k := range answers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		input, ok := v.inputs[k]
+		switch {
+		case !ok:
+			errs = append(errs, AnswerError{k, "unknown input"})
+		case k == input.Name:
+			errs = append(errs, input.validate(answers[k], answers[k+OtherSuffix])...)
+		case !input.selects(answers[input.Name], OtherValue):
+			errs = append(errs, AnswerError{k, "other option not selected"})
+		}
+	}
+	return errs
+}
+
+func (f *compiledInput) validate(answer, other string) []AnswerError {
+	if answer == "" || len(f.options) == 0 {
+		return nil
+	}
+	values := []string{answer}
+	if f.MultiSelect {
+		values = strings.Split(answer, answerSeparator)
+	}
+	var errs []AnswerError
+	for _, v := range values {
+		switch {
+		case f.OtherOption && v == OtherValue:
+			if strings.TrimSpace(other) == "" {
+				errs = append(errs, AnswerError{f.Name + OtherSuffix, "missing text"})
+			}
+		case !f.options[v]:
+			errs = append(errs, AnswerError{f.Name, fmt.Sprintf("invalid option %q", v)})
+		}
+	}
+	return errs
+}
+
+// selects tells if the answer contains the value
+func (f *compiledInput) selects(answer, v string) bool {
+	if !f.MultiSelect {
+		return answer == v
+	}
+	for _, a := range strings.Split(answer, answerSeparator) {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint identifies the contents of the form
+func (f *Form) Fingerprint() string {
+	h := sha1.Sum([]byte(f.Locale + "\n" + f.Contents()))
+	return hex.EncodeToString(h[:])
+}
+
+// ValidatorCache keeps the compiled validators of forms, keyed by fingerprint.
+// It's safe to use from multiple goroutines.
+type ValidatorCache struct {
+	mu         sync.RWMutex
+	validators map[string]*FormValidator
+}
+
+// Validator returns the validator of the form, compiling it if needed
+func (c *ValidatorCache) Validator(f *Form) (*FormValidator, error) {
+	key := f.Fingerprint()
+	c.mu.RLock()
+	v, ok := c.validators[key]
+	c.mu.RUnlock()
+	if ok {
+		return v, nil
+	}
+	v, err := f.CompileValidator()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.validators == nil {
+		c.validators = make(map[string]*FormValidator)
+	}
+	c.validators[key] = v
+	c.mu.Unlock()
+	return v, nil
+}
+
+// Reset drops all the validators, it should be called when the content is replaced
+func (c *ValidatorCache) Reset() {
+	c.mu.Lock()
+	c.validators = nil
+	c.mu.Unlock()
+}
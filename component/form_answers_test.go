@@ -0,0 +1,147 @@
+package component
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestValidateAnswers(t *testing.T) {
+	var form = Form{ID: "form", Screens: []FormScreen{{Name: "Screen", Items: []FormInput{
+		{Type: "single_choice", Name: "single", Options: []string{"a", "b"}},
+		{Type: "multiple_choice", Name: "multi", Options: []string{"a", "b"}, MultiSelect: true},
+		{Type: "single_choice", Name: "single_other", Options: []string{"a", "b"}, OtherOption: true},
+		{Type: "multiple_choice", Name: "multi_other", Options: []string{"a", "b"}, MultiSelect: true, OtherOption: true},
+		{Type: "text_input", Name: "text"},
+	}}}}
+
+	var testCases = []struct {
+		answers map[string]string
+		errs    []AnswerError
+	}{
+		{map[string]string{"single": "a", "multi": "a", "text": "anything;really"}, nil},
+		{map[string]string{"single": "", "multi": ""}, nil},
+		{map[string]string{"single": "c"}, []AnswerError{{"single", `invalid option "c"`}}},
+		{map[string]string{"single": "a;b"}, []AnswerError{{"single", `invalid option "a;b"`}}},
+		{map[string]string{"single": "other", "single.other": "x"}, []AnswerError{
+			{"single", `invalid option "other"`}, {"single.other", "unknown input"},
+		}},
+		{map[string]string{"multi": "a;b"}, nil},
+		{map[string]string{"multi": "a;c"}, []AnswerError{{"multi", `invalid option "c"`}}},
+		{map[string]string{"single_other": "b"}, nil},
+		{map[string]string{"single_other": "other", "single_other.other": "mine"}, nil},
+		{map[string]string{"single_other": "other"}, []AnswerError{{"single_other.other", "missing text"}}},
+		{map[string]string{"single_other": "c"}, []AnswerError{{"single_other", `invalid option "c"`}}},
+		{map[string]string{"single_other": "a", "single_other.other": "mine"}, []AnswerError{
+			{"single_other.other", "other option not selected"},
+		}},
+		{map[string]string{"multi_other": "a;other", "multi_other.other": "mine"}, nil},
+		{map[string]string{"multi_other": "b;other", "multi_other.other": " "}, []AnswerError{
+			{"multi_other.other", "missing text"},
+		}},
+		{map[string]string{"multi_other": "a;c"}, []AnswerError{{"multi_other", `invalid option "c"`}}},
+		{map[string]string{"missing": "a"}, []AnswerError{{"missing", "unknown input"}}},
+	}
+	for _, tc := range testCases {
+		if errs := form.ValidateAnswers(tc.answers); !reflect.DeepEqual(errs, tc.errs) {
+			t.Errorf("%v: expected %v, got %v", tc.answers, tc.errs, errs)
+		}
+	}
+}
+
+func TestFormInputMeta(t *testing.T) {
+	const meta = "[Type]: # (multiple_choice)\n[Name]: # (name)\n[Label]: # (Label)\n[Options]: # (a;b)\n[MultiSelect]: # (true)\n[OtherOption]: # (true)"
+	var f FormInput
+	if err := setMeta(meta, &f); err != nil {
+		t.Fatal(err)
+	}
+	if !f.MultiSelect || !f.OtherOption {
+		t.Errorf("expected multi select with other option, got %+v", f)
+	}
+	if m := getMeta(&f); m != meta {
+		t.Errorf("expected \n%q, got \n%q", meta, m)
+	}
+	f.MultiSelect, f.OtherOption = false, false
+	if m := getMeta(&f); m != "[Type]: # (multiple_choice)\n[Name]: # (name)\n[Label]: # (Label)\n[Options]: # (a;b)" {
+		t.Errorf("unexpected %q", m)
+	}
+}
+
+func validatorForm() *Form {
+	var items []FormInput
+	for i := 0; i < 20; i++ {
+		items = append(items, FormInput{Type: "single_choice", Name: fmt.Sprint("input", i), Options: []string{"a", "b", "c"}, OtherOption: true})
+	}
+	return &Form{ID: "form", Screens: []FormScreen{{Name: "One", Items: items[:10]}, {Name: "Two", Items: items[10:]}}}
+}
+
+func TestCompileValidator(t *testing.T) {
+	form := validatorForm()
+	v, err := form.CompileValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	answers := map[string]string{"input0": "a", "input1": "d", "input2": OtherValue}
+	if errs, expected := v.Validate(answers), form.ValidateAnswers(answers); !reflect.DeepEqual(errs, expected) || len(errs) != 2 {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+	if s := v.VisibleScreens(answers); !reflect.DeepEqual(s, []string{"one", "two"}) {
+		t.Errorf("unexpected screens %v", s)
+	}
+	form.Screens[0].Items[0].Options = nil
+	if errs := v.Validate(map[string]string{"input0": "x"}); len(errs) != 1 {
+		t.Errorf("validator should not change with the form, got %v", errs)
+	}
+	form.Screens[1].Items[0].Name = "input0"
+	if _, err := form.CompileValidator(); err == nil {
+		t.Error("expected duplicate input error")
+	}
+}
+
+func TestValidatorCacheConcurrent(t *testing.T) {
+	var (
+		cache ValidatorCache
+		wg    sync.WaitGroup
+		forms = []*Form{validatorForm(), validatorForm()}
+	)
+	forms[1].Screens[0].Items[0].Options = []string{"z"}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				form := forms[(i+j)%2]
+				v, err := cache.Validator(form)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if errs := v.Validate(map[string]string{"input0": "z"}); len(errs) != (i+j+1)%2 {
+					t.Errorf("form %d: unexpected %v", (i+j)%2, errs)
+				}
+				if j%10 == 0 {
+					cache.Reset()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkValidateAnswers(b *testing.B) {
+	form, answers := validatorForm(), map[string]string{"input0": "a", "input5": "b", "input10": OtherValue, "input10.other": "x"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		form.ValidateAnswers(answers)
+	}
+}
+
+func BenchmarkFormValidator(b *testing.B) {
+	v, _ := validatorForm().CompileValidator()
+	answers := map[string]string{"input0": "a", "input5": "b", "input10": OtherValue, "input10.other": "x"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v.Validate(answers)
+	}
+}
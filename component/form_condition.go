@@ -0,0 +1,224 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormCondition is a rule of the ShowIf of a screen or an input, like "travel == yes" or
+// "travel != no": the answer to the input is, or is not, the value. The answer to a multi
+// select input is the value if one of its choices is, and the one of a checkbox is true or
+// false. Rules joined by && must all hold, an empty ShowIf always holds.
+type FormCondition struct {
+	Input  string `json:"input"`
+	Value  string `json:"value"`
+	Negate bool   `json:"negate,omitempty"` // the rule is !=
+}
+
+// ParseConditions returns the rules of a ShowIf
+func ParseConditions(s string) ([]FormCondition, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var list []FormCondition
+	for _, part := range strings.Split(s, "&&") {
+		var c FormCondition
+		op := "=="
+		if strings.Contains(part, "!=") {
+			op, c.Negate = "!=", true
+		}
+		kv := strings.SplitN(part, op, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Invalid condition %q", strings.TrimSpace(part))
+		}
+		c.Input, c.Value = strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if c.Input == "" || strings.Contains(c.Value, "==") || strings.Contains(c.Value, "!=") {
+			return nil, fmt.Errorf("Invalid condition %q", strings.TrimSpace(part))
+		}
+		list = append(list, c)
+	}
+	return list, nil
+}
+
+// formatConditions returns the ShowIf of the rules, the inverse of ParseConditions
+func formatConditions(list []FormCondition) string {
+	var parts = make([]string, len(list))
+	for i, c := range list {
+		op := "=="
+		if c.Negate {
+			op = "!="
+		}
+		parts[i] = c.Input + " " + op + " " + c.Value
+	}
+	return strings.Join(parts, " && ")
+}
+
+// Conditions returns the rules of the ShowIf of the screen, nil if invalid
+func (s *FormScreen) Conditions() []FormCondition {
+	list, _ := ParseConditions(s.ShowIf)
+	return list
+}
+
+// Conditions returns the rules of the ShowIf of the input, nil if invalid
+func (f *FormInput) Conditions() []FormCondition {
+	list, _ := ParseConditions(f.ShowIf)
+	return list
+}
+
+// checkConditions checks that the conditions are valid and refer to inputs that come before:
+// in a previous screen for a screen, and also in the same one for an input. The value of a
+// condition on a checkbox is true or false, the one on an input with options is an option.
+func (f *Form) checkConditions() error {
+	var seen = make(map[string]*FormInput)
+	check := func(showIf, what string) error {
+		list, err := ParseConditions(showIf)
+		if err != nil {
+			return fmt.Errorf("Form %s, %s: %v", f.ID, what, err)
+		}
+		for _, c := range list {
+			in := seen[c.Input]
+			if in == nil {
+				return fmt.Errorf("Form %s, %s: condition on %q, not an input before it", f.ID, what, c.Input)
+			}
+			if !in.conditionValue(c.Value) {
+				return fmt.Errorf("Form %s, %s: condition on %q, %q is not one of its values", f.ID, what, c.Input, c.Value)
+			}
+		}
+		return nil
+	}
+	for i, s := range f.Screens {
+		if err := check(s.ShowIf, fmt.Sprintf("screen %d", i+1)); err != nil {
+			return err
+		}
+		for j := range s.Items {
+			in := &s.Items[j]
+			if err := check(in.ShowIf, fmt.Sprintf("screen %d, input %d", i+1, j+1)); err != nil {
+				return err
+			}
+			seen[in.Name] = in
+		}
+	}
+	return nil
+}
+
+// conditionValue tells if a condition on the input can have the value
+func (f *FormInput) conditionValue(v string) bool {
+	switch {
+	case f.Type == InputCheckbox:
+		return v == "true" || v == "false"
+	case len(f.Options) != 0:
+		if f.OtherOption && v == OtherValue {
+			return true
+		}
+		for _, o := range f.Options {
+			if o == v {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// translateConditions replaces the values of the conditions on inputs with options, copied
+// from the base form, with the options at the same position in the translation, since the
+// answers are translated options. It returns the conditions it cannot translate.
+func (f *Form) translateConditions(base *Form) []string {
+	var (
+		baseOptions = make(map[string][]string)
+		options     = make(map[string][]string)
+		failed      []string
+	)
+	for i, s := range base.Screens {
+		for j, in := range s.Items {
+			if len(in.Options) != 0 && j < len(f.Screens[i].Items) {
+				baseOptions[in.Name], options[in.Name] = in.Options, f.Screens[i].Items[j].Options
+			}
+		}
+	}
+	translate := func(showIf string) string {
+		list, err := ParseConditions(showIf)
+		if err != nil {
+			return showIf
+		}
+		changed := false
+		for i, c := range list {
+			for k, o := range baseOptions[c.Input] {
+				if o != c.Value || k >= len(options[c.Input]) {
+					continue
+				}
+				v := options[c.Input][k]
+				if strings.Contains(v, "==") || strings.Contains(v, "!=") || strings.Contains(v, "&&") {
+					failed = append(failed, fmt.Sprintf("condition on %q: option %q cannot be a value", c.Input, v))
+					break
+				}
+				changed = changed || v != c.Value
+				list[i].Value = v
+				break
+			}
+		}
+		if !changed {
+			return showIf
+		}
+		return formatConditions(list)
+	}
+	for i := range f.Screens {
+		s := &f.Screens[i]
+		if s.ShowIf != "" {
+			s.ShowIf = translate(s.ShowIf)
+		}
+		for j := range s.Items {
+			if in := &s.Items[j]; in.ShowIf != "" {
+				in.ShowIf = translate(in.ShowIf)
+			}
+		}
+	}
+	return failed
+}
+
+// VisibleScreens returns the IDs of the screens shown for the answers, keyed by input name as
+// in Validate: the ones whose ShowIf holds.
+func (v *FormValidator) VisibleScreens(answers map[string]string) []string {
+	var list []string
+	for i, id := range v.screens {
+		if v.holds(v.showIf[i], answers) {
+			list = append(list, id)
+		}
+	}
+	return list
+}
+
+// VisibleInputs returns the names of the inputs shown for the answers, the ones of the visible
+// screens whose ShowIf holds, in the order of the form
+func (v *FormValidator) VisibleInputs(answers map[string]string) []string {
+	var list []string
+	for _, in := range v.order {
+		if v.visible(in, answers) {
+			list = append(list, in.Name)
+		}
+	}
+	return list
+}
+
+// visible tells if the input and its screen are shown for the answers
+func (v *FormValidator) visible(in *compiledInput, answers map[string]string) bool {
+	return v.holds(v.showIf[in.screen], answers) && v.holds(in.showIf, answers)
+}
+
+// holds tells if the conditions hold for the answers, keyed by input name as in Validate
+func (v *FormValidator) holds(conditions []FormCondition, answers map[string]string) bool {
+	for _, c := range conditions {
+		var is bool
+		if input, ok := v.inputs[c.Input]; ok && input.Name == c.Input {
+			answer := answers[c.Input]
+			if input.Type == InputCheckbox && answer == "" {
+				answer = "false"
+			}
+			is = input.selects(answer, c.Value)
+		}
+		if is == c.Negate {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+const conditionalForm = `[Name]: # (Travel)
+
+[Type]: # (screen)
+[Name]: # (Trip)
+
+[Type]: # (checkbox)
+[Name]: # (abroad)
+[Label]: # (Going abroad?)
+
+[Type]: # (multiple_choice)
+[Name]: # (devices)
+[Label]: # (Devices)
+[Options]: # (phone;laptop)
+[MultiSelect]: # (true)
+
+[Type]: # (text_input)
+[Name]: # (model)
+[Label]: # (Laptop model)
+[Required]: # (true)
+[ShowIf]: # (devices == laptop)
+
+[Type]: # (screen)
+[Name]: # (Border)
+[ShowIf]: # (abroad == true && devices != phone)
+
+[Type]: # (text_input)
+[Name]: # (country)
+[Label]: # (Country)
+[Required]: # (true)`
+
+func TestParseConditions(t *testing.T) {
+	list, err := ParseConditions(" a == yes && b!=no ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []FormCondition{{Input: "a", Value: "yes"}, {Input: "b", Value: "no", Negate: true}}; !reflect.DeepEqual(list, expected) {
+		t.Errorf("expected %v, got %v", expected, list)
+	}
+	if list, err := ParseConditions(" "); list != nil || err != nil {
+		t.Errorf("empty: %v %v", list, err)
+	}
+	for _, s := range []string{"a", "== yes", "a = yes", "a == b == c", "a == yes &&"} {
+		if _, err := ParseConditions(s); err == nil {
+			t.Errorf("%q: no error", s)
+		}
+	}
+}
+
+func TestFormConditions(t *testing.T) {
+	var f = Form{ID: "travel"}
+	if err := f.SetContents(conditionalForm); err != nil {
+		t.Fatal(err)
+	}
+	if f.Contents() != conditionalForm {
+		t.Errorf("contents changed:\n%s", f.Contents())
+	}
+	if c := f.Screens[1].Conditions(); len(c) != 2 || c[1] != (FormCondition{Input: "devices", Value: "phone", Negate: true}) {
+		t.Errorf("unexpected conditions %v", c)
+	}
+
+	v := f.compile()
+	for _, tc := range []struct {
+		answers map[string]string
+		screens []string
+		inputs  []string
+	}{
+		{nil, []string{"trip"}, []string{"abroad", "devices"}},
+		{map[string]string{"abroad": "true"}, []string{"trip", "border"}, []string{"abroad", "devices", "country"}},
+		{map[string]string{"abroad": "true", "devices": "phone;laptop"}, []string{"trip"}, []string{"abroad", "devices", "model"}},
+		{map[string]string{"abroad": "true", "devices": "laptop"}, []string{"trip", "border"}, []string{"abroad", "devices", "model", "country"}},
+	} {
+		if s := v.VisibleScreens(tc.answers); !reflect.DeepEqual(s, tc.screens) {
+			t.Errorf("%v: expected screens %v, got %v", tc.answers, tc.screens, s)
+		}
+		if i := v.VisibleInputs(tc.answers); !reflect.DeepEqual(i, tc.inputs) {
+			t.Errorf("%v: expected inputs %v, got %v", tc.answers, tc.inputs, i)
+		}
+	}
+
+	// hidden screens can be left out, hidden inputs are not required
+	r := FormResponse{Form: "travel", Screens: []ScreenResponse{{ID: "trip", Answers: map[string]FormValue{"devices": ChoicesValue("phone")}}}}
+	if errs := r.Validate(&f); errs != nil {
+		t.Errorf("hidden: %v", errs)
+	}
+	r.Screens[0].Answers = map[string]FormValue{"abroad": CheckedValue(true), "devices": ChoicesValue("laptop")}
+	expected := []error{AnswerError{"model", "required"}, ScreenError{"border", "missing"}}
+	if errs := r.Validate(&f); !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+
+	// translations keep the conditions of the base
+	p := NewResourceParser()
+	if err := p.Parse(&f, encode(&f, &f), "it"); err != nil {
+		t.Fatal(err)
+	}
+	if it := p.FormUnsafe("travel", "it"); it.Screens[1].ShowIf != f.Screens[1].ShowIf || it.Screens[0].Items[2].ShowIf != "devices == laptop" {
+		t.Errorf("conditions lost: %+v", it.Screens)
+	}
+
+	// in a translation the conditions compare the translated options
+	res := encode(&f, &f)
+	for _, row := range res.Content {
+		if row[KeyOptions] == "phone;laptop" {
+			row[KeyOptions] = "telefono;portatile!"
+		}
+	}
+	if err := p.Parse(&f, res, "it"); err != nil {
+		t.Fatal(err)
+	}
+	it := p.FormUnsafe("travel", "it")
+	if s, in := it.Screens[1].ShowIf, it.Screens[0].Items[2].ShowIf; s != "abroad == true && devices != telefono" || in != "devices == portatile!" {
+		t.Errorf("conditions not translated: %q, %q", s, in)
+	}
+	v = it.compile()
+	if s := v.VisibleScreens(map[string]string{"abroad": "true", "devices": "portatile!"}); !reflect.DeepEqual(s, []string{"trip", "border"}) {
+		t.Errorf("translated screens %v", s)
+	}
+	if i := v.VisibleInputs(map[string]string{"devices": "portatile!"}); !reflect.DeepEqual(i, []string{"abroad", "devices", "model"}) {
+		t.Errorf("translated inputs %v", i)
+	}
+
+	for _, s := range []string{
+		"[Name]: # (F)\n\n[Type]: # (screen)\n[Name]: # (S)\n\n[Type]: # (checkbox)\n[Name]: # (a)\n[Label]: # (L)\n[ShowIf]: # ()\n\n[Type]: # (text_input)\n[Name]: # (b)\n[Label]: # (L)\n[ShowIf]: # (a == yes)",
+		"[Name]: # (F)\n\n[Type]: # (screen)\n[Name]: # (S)\n\n[Type]: # (single_choice)\n[Name]: # (a)\n[Label]: # (L)\n[Options]: # (x;y)\n\n[Type]: # (text_input)\n[Name]: # (b)\n[Label]: # (L)\n[ShowIf]: # (a == z)",
+		"[Name]: # (F)\n\n[Type]: # (screen)\n[Name]: # (S)\n[ShowIf]: # (later == yes)\n\n[Type]: # (text_input)\n[Name]: # (later)\n[Label]: # (L)",
+		"[Name]: # (F)\n\n[Type]: # (screen)\n[Name]: # (S)\n\n[Type]: # (text_input)\n[Name]: # (a)\n[Label]: # (L)\n[ShowIf]: # (a)",
+	} {
+		if err := new(Form).SetContents(s); err == nil {
+			t.Errorf("no error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateLayout is the layout of the answers to date inputs
+const DateLayout = "2006-01-02"
+
+// FormResponse is a submission of a form: the answers of each screen, by input name. The free
+// text of an other option is the answer named after the input plus OtherSuffix.
+type FormResponse struct {
+	Form    string           `json:"form"`
+	Locale  string           `json:"locale,omitempty"`
+	Screens []ScreenResponse `json:"screens"`
+}
+
+// ScreenResponse are the answers of a screen of a FormResponse
+type ScreenResponse struct {
+	ID      string               `json:"id"` // see Form.ScreenIDs
+	Answers map[string]FormValue `json:"answers"`
+}
+
+// FormValue is the answer to an input, with at most one of the fields set, a JSON string,
+// number, boolean or list of strings. Text inputs, file references and dates, in DateLayout,
+// are texts, numbers are numbers, checkboxes are booleans and choices are lists; a choice can
+// also be a text.
+type FormValue struct {
+	Text    *string
+	Number  *float64
+	Checked *bool
+	Choices []string
+}
+
+// TextValue returns an answer with the text
+func TextValue(s string) FormValue { return FormValue{Text: &s} }
+
+// NumberValue returns an answer with the number
+func NumberValue(n float64) FormValue { return FormValue{Number: &n} }
+
+// CheckedValue returns the answer of a checkbox
+func CheckedValue(b bool) FormValue { return FormValue{Checked: &b} }
+
+// ChoicesValue returns an answer with the options chosen
+func ChoicesValue(options ...string) FormValue { return FormValue{Choices: options} }
+
+func (v FormValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case v.Text != nil:
+		return json.Marshal(*v.Text)
+	case v.Number != nil:
+		return json.Marshal(*v.Number)
+	case v.Checked != nil:
+		return json.Marshal(*v.Checked)
+	case v.Choices != nil:
+		return json.Marshal(v.Choices)
+	}
+	return []byte("null"), nil
+}
+
+func (v *FormValue) UnmarshalJSON(b []byte) error {
+	*v = FormValue{}
+	switch b = bytes.TrimSpace(b); {
+	case bytes.Equal(b, []byte("null")):
+		return nil
+	case b[0] == '"':
+		return json.Unmarshal(b, &v.Text)
+	case b[0] == '[':
+		v.Choices = []string{}
+		return json.Unmarshal(b, &v.Choices)
+	case b[0] == 't' || b[0] == 'f':
+		return json.Unmarshal(b, &v.Checked)
+	}
+	return json.Unmarshal(b, &v.Number)
+}
+
+// empty tells if the value is not an answer: nothing set, a blank text or no choices
+func (v FormValue) empty() bool {
+	switch {
+	case v.Text != nil:
+		return strings.TrimSpace(*v.Text) == ""
+	case v.Number != nil:
+		return false
+	case v.Checked != nil:
+		return !*v.Checked
+	}
+	return len(v.Choices) == 0
+}
+
+// String returns the value in the format of ValidateAnswers
+func (v FormValue) String() string {
+	switch {
+	case v.Text != nil:
+		return *v.Text
+	case v.Number != nil:
+		return strconv.FormatFloat(*v.Number, 'f', -1, 64)
+	case v.Checked != nil:
+		return strconv.FormatBool(*v.Checked)
+	}
+	return strings.Join(v.Choices, answerSeparator)
+}
+
+// ScreenError is a screen of a FormResponse that is missing or not in the form
+type ScreenError struct {
+	Screen string `json:"screen"`
+	Reason string `json:"reason"`
+}
+
+func (s ScreenError) Error() string { return fmt.Sprintf("screen %s: %s", s.Screen, s.Reason) }
+
+// Validate checks the response against the form: every screen answered once and no other,
+// answers only to inputs of their screen with a value of the right kind, required inputs
+// answered, and checked if a checkbox, options among the ones of the input, one unless multi
+// select, see ValidateAnswers. Screens and inputs hidden by their ShowIf, for the answers of
+// the response, can be left out and are never required. Errors are ScreenError and
+// AnswerError, in order of screen.
+func (r *FormResponse) Validate(form *Form) []error {
+	if r.Form != form.ID {
+		return []error{fmt.Errorf("Response to form %q, not %q", r.Form, form.ID)}
+	}
+	var (
+		errs    []error
+		ids     = form.ScreenIDs()
+		screens = make(map[string]int, len(r.Screens))
+		flat    = make(map[string]string)
+		v       = form.compile()
+		all     = make(map[string]string) // every answer, for the conditions
+	)
+	for i, s := range r.Screens {
+		if _, ok := screens[s.ID]; ok {
+			errs = append(errs, ScreenError{s.ID, "answered twice"})
+			continue
+		}
+		screens[s.ID] = i
+		for k, a := range s.Answers {
+			if !a.empty() {
+				all[k] = a.String()
+			}
+		}
+	}
+	shown := func(name string) bool { return v.visible(v.inputs[name], all) }
+	for i, id := range ids {
+		n, ok := screens[id]
+		switch {
+		case !ok && v.holds(v.showIf[i], all):
+			errs = append(errs, ScreenError{id, "missing"})
+		case ok:
+			delete(screens, id)
+			errs = append(errs, validateScreen(&form.Screens[i], r.Screens[n].Answers, flat, shown)...)
+		}
+	}
+	var unknown []string
+	for id := range screens {
+		unknown = append(unknown, id)
+	}
+	sort.Strings(unknown)
+	for _, id := range unknown {
+		errs = append(errs, ScreenError{id, "not in the form"})
+	}
+	for _, err := range v.Validate(flat) {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// validateScreen checks the answers of a screen, adding them to flat for ValidateAnswers. Only
+// the inputs shown can be required.
+func validateScreen(s *FormScreen, answers map[string]FormValue, flat map[string]string, shown func(name string) bool) []error {
+	var (
+		errs   []error
+		inputs = make(map[string]*FormInput, len(s.Items))
+	)
+	for i := range s.Items {
+		in := &s.Items[i]
+		inputs[in.Name] = in
+		if in.OtherOption {
+			inputs[in.Name+OtherSuffix] = nil
+		}
+		if v, ok := answers[in.Name]; in.Required && (!ok || v.empty()) && shown(in.Name) {
+			errs = append(errs, AnswerError{in.Name, "required"})
+		}
+	}
+	for _, k := range sortedAnswers(answers) {
+		v := answers[k]
+		in, ok := inputs[k]
+		switch {
+		case !ok:
+			errs = append(errs, AnswerError{k, "unknown input"})
+			continue
+		case in == nil:
+			if v.Text == nil && !v.empty() {
+				errs = append(errs, AnswerError{k, "text expected"})
+				continue
+			}
+		default:
+			if msg := in.checkValue(v); msg != "" {
+				errs = append(errs, AnswerError{k, msg})
+				continue
+			}
+		}
+		if !v.empty() {
+			flat[k] = v.String()
+		}
+	}
+	return errs
+}
+
+// checkValue returns what is wrong with the kind of the value, an empty string if nothing
+func (f *FormInput) checkValue(v FormValue) string {
+	if v.empty() {
+		return ""
+	}
+	switch f.Type {
+	case InputText, InputTextArea, InputFile:
+		if v.Text == nil {
+			return "text expected"
+		}
+	case InputDate:
+		if v.Text == nil {
+			return "date expected"
+		}
+		if _, err := time.Parse(DateLayout, *v.Text); err != nil {
+			return fmt.Sprintf("invalid date %q", *v.Text)
+		}
+	case InputNumber:
+		if v.Number == nil {
+			return "number expected"
+		}
+	case InputCheckbox:
+		if v.Checked == nil {
+			return "boolean expected"
+		}
+	case InputSingleChoice, InputMultipleChoice:
+		switch {
+		case v.Text == nil && v.Choices == nil:
+			return "choices expected"
+		case len(v.Choices) > 1 && !f.MultiSelect:
+			return fmt.Sprintf("%d choices, only one allowed", len(v.Choices))
+		}
+	}
+	return ""
+}
+
+func sortedAnswers(m map[string]FormValue) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
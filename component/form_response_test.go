@@ -0,0 +1,112 @@
+package component
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFormResponse(t *testing.T) {
+	var form = Form{ID: "form", Screens: []FormScreen{
+		{Name: "One", Items: []FormInput{
+			{Type: InputText, Name: "name", Required: true},
+			{Type: InputNumber, Name: "age"},
+			{Type: InputDate, Name: "when"},
+		}},
+		{Name: "Two", Items: []FormInput{
+			{Type: InputSingleChoice, Name: "kind", Options: []string{"a", "b"}, OtherOption: true},
+			{Type: InputMultipleChoice, Name: "help", Options: []string{"a", "b"}, MultiSelect: true},
+			{Type: InputCheckbox, Name: "consent", Required: true},
+		}},
+	}}
+	const valid = `{"form": "form", "screens": [
+		{"id": "one", "answers": {"name": "Jo", "age": 30, "when": "2019-02-01"}},
+		{"id": "two", "answers": {"kind": "other", "kind.other": "mine", "help": ["a", "b"], "consent": true}}]}`
+
+	var testCases = []struct {
+		name   string
+		change func(r *FormResponse)
+		errs   []error
+	}{
+		{"valid", func(*FormResponse) {}, nil},
+		{"form", func(r *FormResponse) { r.Form = "other" }, []error{
+			errorString(`Response to form "other", not "form"`),
+		}},
+		{"screens", func(r *FormResponse) {
+			r.Screens = []ScreenResponse{r.Screens[1], {ID: "three"}, r.Screens[1]}
+		}, []error{
+			ScreenError{"two", "answered twice"}, ScreenError{"one", "missing"}, ScreenError{"three", "not in the form"},
+		}},
+		{"required", func(r *FormResponse) {
+			r.Screens[0].Answers["name"] = TextValue(" ")
+			r.Screens[1].Answers["consent"] = CheckedValue(false)
+		}, []error{AnswerError{"name", "required"}, AnswerError{"consent", "required"}}},
+		{"kinds", func(r *FormResponse) {
+			r.Screens[0].Answers["age"] = TextValue("30")
+			r.Screens[0].Answers["when"] = TextValue("yesterday")
+			r.Screens[1].Answers["consent"] = TextValue("yes")
+			r.Screens[1].Answers["kind.other"] = NumberValue(1)
+		}, []error{
+			AnswerError{"age", "number expected"}, AnswerError{"when", `invalid date "yesterday"`},
+			AnswerError{"consent", "boolean expected"},
+			AnswerError{"kind.other", "text expected"}, AnswerError{"kind.other", "missing text"},
+		}},
+		{"options", func(r *FormResponse) {
+			r.Screens[1].Answers["kind"] = ChoicesValue("a", "b")
+			r.Screens[1].Answers["help"] = ChoicesValue("a", "c")
+		}, []error{
+			AnswerError{"kind", "2 choices, only one allowed"},
+			AnswerError{"help", `invalid option "c"`}, AnswerError{"kind.other", "other option not selected"},
+		}},
+		{"inputs", func(r *FormResponse) {
+			r.Screens[1].Answers["name"] = TextValue("Jo")
+		}, []error{AnswerError{"name", "unknown input"}}},
+	}
+	for _, tc := range testCases {
+		var r FormResponse
+		if err := json.Unmarshal([]byte(valid), &r); err != nil {
+			t.Fatal(err)
+		}
+		tc.change(&r)
+		errs := r.Validate(&form)
+		if len(errs) != len(tc.errs) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.errs, errs)
+			continue
+		}
+		for i := range errs {
+			if errs[i].Error() != tc.errs[i].Error() {
+				t.Errorf("%s: expected %v, got %v", tc.name, tc.errs, errs)
+				break
+			}
+		}
+	}
+}
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
+
+func TestFormValueJSON(t *testing.T) {
+	var values = map[string]FormValue{
+		"text":    TextValue("a"),
+		"number":  NumberValue(1.5),
+		"checked": CheckedValue(false),
+		"choices": ChoicesValue("a", "b"),
+		"none":    {},
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `{"checked":false,"choices":["a","b"],"none":null,"number":1.5,"text":"a"}`
+	if string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+	var decoded map[string]FormValue
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, values) {
+		t.Errorf("expected %v, got %v", values, decoded)
+	}
+}
@@ -0,0 +1,145 @@
+package component
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// glossaryScheme is the prefix of the links to a term of the glossary, like [VPN](glossary:vpn)
+const glossaryScheme = "glossary:"
+
+// TermMarkup returns the annotation of an occurrence of a term in a body, match is the text
+// found, that can differ from the term in case
+type TermMarkup func(t GlossaryTerm, match string) string
+
+// LinkTerm is the default TermMarkup, a link to the ID of the term with the glossary scheme
+func LinkTerm(t GlossaryTerm, match string) string {
+	return fmt.Sprintf("[%s](%s%s)", match, glossaryScheme, t.ID())
+}
+
+var (
+	// termProtected are the parts of a body where terms are not annotated
+	termProtected = regexp.MustCompile("(?s)```.*?```|`[^`]*`|!?\\[[^\\]]*\\]\\([^)]*\\)|\\[\\[note:.*?\\]\\]|<[^>]*>")
+	termLink      = regexp.MustCompile(`\]\(\s*` + glossaryScheme + `([^)\s]+)`)
+)
+
+// TermAnnotator is a Finalizer that annotates the terms of the glossaries of each locale in the
+// bodies of its items, changing them: the first occurrence of each term in a body, a whole
+// word in any case, is replaced by Markup, LinkTerm if nil. Longer terms are matched first, and
+// code, links, images, HTML tags and notes are left alone. Terms already linked with the
+// glossary scheme are skipped, so with LinkTerm running it again changes nothing.
+type TermAnnotator struct {
+	Markup TermMarkup
+}
+
+func (a TermAnnotator) Finalize(r *ResourceParser) []Problem {
+	for locale, glossaries := range r.glossaries {
+		var terms []GlossaryTerm
+		for _, g := range glossaries {
+			terms = append(terms, g.Terms...)
+		}
+		m := newTermMatcher(terms, a.Markup)
+		if m == nil {
+			continue
+		}
+		for _, cat := range r.categories[locale] {
+			walkCategory(cat, func(c Component) {
+				if item, ok := c.(*Item); ok {
+					item.Body = m.annotate(item.Body)
+				}
+			})
+		}
+	}
+	return nil
+}
+
+// AnnotateTerms returns the body with the terms annotated, see TermAnnotator
+func AnnotateTerms(body string, terms []GlossaryTerm, markup TermMarkup) string {
+	m := newTermMatcher(terms, markup)
+	if m == nil {
+		return body
+	}
+	return m.annotate(body)
+}
+
+// termMatcher finds the terms of a locale
+type termMatcher struct {
+	re     *regexp.Regexp
+	terms  map[string]GlossaryTerm // by lower case term
+	markup TermMarkup
+}
+
+// newTermMatcher returns a matcher for the terms, nil if there are none
+func newTermMatcher(terms []GlossaryTerm, markup TermMarkup) *termMatcher {
+	if markup == nil {
+		markup = LinkTerm
+	}
+	sorted := append([]GlossaryTerm(nil), terms...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Term) > len(sorted[j].Term) })
+	var (
+		m   = termMatcher{terms: make(map[string]GlossaryTerm), markup: markup}
+		alt []string
+	)
+	for _, t := range sorted {
+		k := strings.ToLower(strings.TrimSpace(t.Term))
+		if _, ok := m.terms[k]; ok || k == "" {
+			continue
+		}
+		m.terms[k] = t
+		alt = append(alt, regexp.QuoteMeta(k))
+	}
+	if len(alt) == 0 {
+		return nil
+	}
+	m.re = regexp.MustCompile("(?i)" + strings.Join(alt, "|"))
+	return &m
+}
+
+func (m *termMatcher) annotate(body string) string {
+	var (
+		b    strings.Builder
+		done = make(map[string]bool)
+		last int
+	)
+	for _, l := range termLink.FindAllStringSubmatch(body, -1) {
+		done[l[1]] = true
+	}
+	text := func(s string) {
+		var pos int
+		for _, loc := range m.re.FindAllStringIndex(s, -1) {
+			word := s[loc[0]:loc[1]]
+			t, ok := m.terms[strings.ToLower(word)]
+			if !ok || done[t.ID()] || !wholeWord(s, loc[0], loc[1]) {
+				continue
+			}
+			done[t.ID()] = true
+			b.WriteString(s[pos:loc[0]])
+			b.WriteString(m.markup(t, word))
+			pos = loc[1]
+		}
+		b.WriteString(s[pos:])
+	}
+	for _, loc := range termProtected.FindAllStringIndex(body, -1) {
+		text(body[last:loc[0]])
+		b.WriteString(body[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	text(body[last:])
+	return b.String()
+}
+
+// wholeWord tells if s[i:j] is not part of a longer word
+func wholeWord(s string, i, j int) bool {
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' }
+	if r, _ := utf8.DecodeLastRuneInString(s[:i]); i > 0 && isWord(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(s[j:]); j < len(s) && isWord(r) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	. "gopkg.in/check.v1"
+)
+
+const glossaryContents = "[Term]: # (VPN)\n[Definition]: # (A private network)\n\n" +
+	"[Term]: # (Two-factor authentication)\n[Definition]: # (A second proof of identity)"
+
+func (CmpSuite) TestGlossary(c *C) {
+	var fp Parser
+	c.Assert(fp.ParseFS(fstest.MapFS{"glossary_en/terms.md": {Data: []byte(glossaryContents)}}), IsNil)
+	c.Assert(fp.Glossaries(), HasLen, 1)
+	g := fp.Glossaries()[0]
+	c.Assert(g.ID, Equals, "terms")
+	c.Assert(g.Locale, Equals, "en")
+	c.Assert(g.Path(), Equals, "glossary_en/terms.md")
+	c.Assert(g.Contents(), Equals, glossaryContents)
+	c.Assert(g.Terms[1], Equals, GlossaryTerm{Term: "Two-factor authentication", Definition: "A second proof of identity"})
+	c.Assert(g.Terms[1].ID(), Equals, "two-factor-authentication")
+
+	p := NewResourceParser()
+	c.Assert(p.Parse(g, &Resource{Content: []map[string]string{
+		{KeyTerm: "VPN", KeyDefinition: "A private network"},
+		{KeyTerm: "Two-factor authentication", KeyDefinition: "A second proof of identity"},
+	}}, "en"), IsNil)
+	c.Assert(p.Locales(), DeepEquals, []string{"en"})
+	res, err := p.Encode(g, "en")
+	c.Assert(err, IsNil)
+	c.Assert(Validate(g, res), HasLen, 0)
+	res.Content[0][KeyTerm] = "RPV"
+	c.Assert(p.Parse(g, res, "fr"), IsNil)
+	c.Assert(p.Glossary("terms", "fr").Terms[0].Term, Equals, "RPV")
+
+	err = p.Parse(g, &Resource{Content: res.Content[:1]}, "it")
+	c.Assert(err, ErrorMatches, ErrContent.Error())
+	err = p.Parse(g, &Resource{Content: []map[string]string{{KeyTerm: "VPN"}, {KeyTerm: "2FA", KeyDefinition: "x"}}}, "it")
+	c.Assert(err, FitsTypeOf, &ParseError{})
+	c.Assert(Validate(g, &Resource{Content: res.Content[:1]}), HasLen, 1)
+
+	b, err := p.MarshalLocale("fr")
+	c.Assert(err, IsNil)
+	copy := NewResourceParser()
+	c.Assert(copy.UnmarshalLocale(b), IsNil)
+	c.Assert(copy.Glossaries("fr"), DeepEquals, p.Glossaries("fr"))
+	c.Assert(p.Snapshot().r.Glossary("terms", "fr"), DeepEquals, p.Glossary("terms", "fr"))
+
+	res.Content[0][KeyTerm] = "VPN (de)"
+	c.Assert(p.ParseLocales(context.Background(), map[string][]ParseRequest{"de": {{Component: g, Resource: res}}}, 2), IsNil)
+	c.Assert(p.Glossary("terms", "de").Terms[0].Term, Equals, "VPN (de)")
+}
+
+func (CmpSuite) TestTermAnnotator(c *C) {
+	p := bilingualParser()
+	p.glossaries["en"] = []*Glossary{{ID: "terms", Locale: "en", Terms: []GlossaryTerm{{Term: "One", Definition: "1"}}}}
+	p.AddFinalizer(TermAnnotator{})
+	p.Finalize()
+	item := p.categories["en"][0].Sub("sub").Difficulty("beginner").Item("item")
+	c.Assert(item.Body, Equals, "[One](glossary:one)\n\nTwo")
+	p.Finalize()
+	c.Assert(item.Body, Equals, "[One](glossary:one)\n\nTwo")
+	c.Assert(p.categories["it"][0].Sub("sub").Difficulty("beginner").Item("item").Body, Equals, "Uno")
+}
+
+func TestAnnotateTerms(t *testing.T) {
+	terms := []GlossaryTerm{{Term: "VPN"}, {Term: "authentication"}, {Term: "Two-factor authentication"}, {Term: "città"}}
+	abbr := func(t GlossaryTerm, match string) string { return fmt.Sprintf("<abbr>%s</abbr>", match) }
+	for _, tc := range []struct {
+		body, expected string
+		markup         TermMarkup
+	}{
+		{"Use a vpn, a VPN.", "Use a [vpn](glossary:vpn), a VPN.", nil},
+		{"Turn on two-factor authentication, authentication matters",
+			"Turn on [two-factor authentication](glossary:two-factor-authentication), [authentication](glossary:authentication) matters", nil},
+		{"VPNs and `VPN` and [VPN](https://vpn) and [[note: VPN]]", "VPNs and `VPN` and [VPN](https://vpn) and [[note: VPN]]", nil},
+		{"```\nVPN\n```\n<b class=\"VPN\">VPN</b>", "```\nVPN\n```\n<b class=\"VPN\"><abbr>VPN</abbr></b>", abbr},
+		{"Una città, cittàdina", "Una [città](glossary:città), cittàdina", nil},
+		{"[VPN](glossary:vpn) then VPN", "[VPN](glossary:vpn) then VPN", nil},
+	} {
+		if got := AnnotateTerms(tc.body, terms, tc.markup); got != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.body, tc.expected, got)
+		}
+	}
+	if got := AnnotateTerms("VPN", nil, nil); got != "VPN" {
+		t.Errorf("no terms: got %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package component
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Hash returns a hash of the content of the snapshot, archived locales included, that changes
+// only when the content does
+func (s *Snapshot) Hash() string {
+	h := sha1.New()
+	e := json.NewEncoder(h)
+	for _, l := range s.r.Locales(IncludeArchived()) {
+		io.WriteString(h, l)
+		e.Encode(s.r.tree(l))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Version is a snapshot of the content kept by a History
+type Version struct {
+	Number   int       `json:"version"`
+	Hash     string    `json:"hash"`
+	Time     time.Time `json:"time"`
+	Snapshot *Snapshot `json:"-"`
+}
+
+// History keeps the last versions of the content, one for each content committed, and the
+// current one, that is the last committed unless a previous one was restored with Rollback.
+// It's safe for concurrent use.
+type History struct {
+	mu       sync.RWMutex
+	size     int
+	versions []*Version // oldest first
+	current  *Version
+	last     int // number of the last version
+}
+
+// NewHistory returns a history keeping the last size versions, at least 1
+func NewHistory(size int) *History {
+	if size < 1 {
+		size = 1
+	}
+	return &History{size: size}
+}
+
+// Commit takes a snapshot of the parser, after a complete parse, and makes it the current
+// version, with a number greater than any before. If the content is the one of the current
+// version, that is returned and nothing changes.
+func (h *History) Commit(r *ResourceParser) *Version {
+	s := r.Snapshot()
+	hash := s.Hash()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.current != nil && h.current.Hash == hash {
+		return h.current
+	}
+	h.last++
+	v := &Version{Number: h.last, Hash: hash, Time: time.Now(), Snapshot: s}
+	h.versions = append(h.versions, v)
+	h.current = v
+	if n := len(h.versions); n > h.size {
+		h.versions = append([]*Version(nil), h.versions[n-h.size:]...)
+	}
+	return v
+}
+
+// Current returns the current version, nil if nothing was committed
+func (h *History) Current() *Version {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.current
+}
+
+// Version returns the version with the number, nil if it's not kept
+func (h *History) Version(n int) *Version {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, v := range h.versions {
+		if v.Number == n {
+			return v
+		}
+	}
+	return nil
+}
+
+// Versions returns the versions kept, oldest first
+func (h *History) Versions() []*Version {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]*Version(nil), h.versions...)
+}
+
+// Rollback makes the version with the number the current one. Later versions are kept, so
+// the rollback can be undone restoring one of them.
+func (h *History) Rollback(n int) (*Version, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, v := range h.versions {
+		if v.Number == n {
+			h.current = v
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("Version %d not found", n)
+}
@@ -0,0 +1,50 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestHistory(c *C) {
+	h := NewHistory(2)
+	c.Assert(h.Current() == nil, Equals, true)
+	_, err := h.Rollback(1)
+	c.Assert(err, ErrorMatches, "Version 1 not found")
+
+	p := bilingualParser()
+	v1 := h.Commit(p)
+	c.Assert(v1.Number, Equals, 1)
+	c.Assert(v1.Hash, Equals, p.Snapshot().Hash())
+	c.Assert(h.Commit(p) == v1, Equals, true) // same content, same version
+
+	item := p.categories["en"][0].Sub("sub").Difficulty("beginner").Item("item")
+	item.Title = "Broken"
+	v2 := h.Commit(p)
+	c.Assert(v2.Number, Equals, 2)
+	c.Assert(v2.Hash != v1.Hash, Equals, true)
+	c.Assert(h.Current() == v2, Equals, true)
+
+	// versions do not change with the parser
+	item.Title = "Fixed"
+	c.Assert(v1.Snapshot.Category("cat", "en").Sub("sub").Difficulty("beginner").Item("item").Title, Equals, "Title")
+	c.Assert(v2.Snapshot.Category("cat", "en").Sub("sub").Difficulty("beginner").Item("item").Title, Equals, "Broken")
+
+	// a rollback keeps the later versions
+	v, err := h.Rollback(1)
+	c.Assert(err, IsNil)
+	c.Assert(v == v1 && h.Current() == v1, Equals, true)
+	c.Assert(h.Version(2) == v2, Equals, true)
+
+	// only the last versions are kept
+	v3 := h.Commit(p)
+	c.Assert(v3.Number, Equals, 3)
+	c.Assert(h.Current() == v3, Equals, true)
+	c.Assert(h.Version(1) == nil, Equals, true)
+	c.Assert(h.Versions(), HasLen, 2)
+	c.Assert(h.Versions()[0] == v2 && h.Versions()[1] == v3, Equals, true)
+
+	// archived locales are part of the content
+	p.ArchiveLocale("it")
+	c.Assert(p.Snapshot().Hash(), Equals, v3.Hash)
+	p.categories["it"][0].Name = "Archiviata"
+	c.Assert(p.Snapshot().Hash() != v3.Hash, Equals, true)
+}
@@ -0,0 +1,144 @@
+package component
+
+import "reflect"
+
+// ItemHook is called by Parse for each parsed item, with its ancestors in the locale, from
+// the category down, after the body is assembled and before the item is added to the tree.
+// An error fails the parse of the item; ParseAll collects it with the others.
+type ItemHook func(path []Component, item *Item, locale string) error
+
+// BodyProcessor checks the body of an item once it's assembled from the rows of the resource,
+// and returns it, changed if needed, with the issues found. Issues are recorded as problems,
+// an error fails the parse of the item.
+type BodyProcessor interface {
+	ProcessBody(body string) (string, []BodyIssue, error)
+}
+
+// BodyIssue is a problem found by a BodyProcessor
+type BodyIssue struct {
+	Paragraph int    `json:"paragraph"` // numbered from 1, 0 for the whole body
+	Message   string `json:"message"`
+}
+
+// Finalizer checks the whole parsed tree, when the references can be resolved
+type Finalizer interface {
+	Finalize(r *ResourceParser) []Problem
+}
+
+// Listener is told when the content of the parser changes, to invalidate caches, rebuild
+// search indexes or notify people. OnComponentChanged is called for the components added or
+// modified by Parse, when the translation is new or its resource differs from the one before,
+// and for the ones deleted by Remove or PruneOrphans, without their descendants. Paths are tree
+// paths, see Change. OnParseComplete is called at the end of ParseAll, ParseStream and
+// ParseLocales, even if some requests failed, with a snapshot of the content.
+type Listener interface {
+	OnComponentChanged(path, locale string, kind ChangeKind)
+	OnParseComplete(s *Snapshot)
+}
+
+// AddListener adds a listener of the changes, listeners are called in the order added.
+// OnComponentChanged may be called concurrently by ParseLocales.
+func (r *ResourceParser) AddListener(l Listener) { r.listeners = append(r.listeners, l) }
+
+// AddItemHook adds a hook called for each parsed item, hooks are called in the order added.
+// Hooks may be called concurrently by ParseLocales.
+func (r *ResourceParser) AddItemHook(h ItemHook) { r.itemHooks = append(r.itemHooks, h) }
+
+// AddBodyProcessor adds a processor for the bodies of the parsed items, they are called in the
+// order added, each with the body returned by the previous one, before the item hooks.
+// Processors may be called concurrently by ParseLocales.
+func (r *ResourceParser) AddBodyProcessor(p BodyProcessor) {
+	r.bodyProcessors = append(r.bodyProcessors, p)
+}
+
+// AddFinalizer adds a check run by Finalize
+func (r *ResourceParser) AddFinalizer(f Finalizer) { r.finalizers = append(r.finalizers, f) }
+
+// Finalize runs the finalizers, once every resource is parsed, and returns the problems they
+// found, that are recorded with the others of the parser too, replacing the ones of the
+// previous Finalize.
+func (r *ResourceParser) Finalize() []Problem {
+	var list []Problem
+	for _, f := range r.finalizers {
+		list = append(list, f.Finalize(r)...)
+	}
+	r.finalized = list
+	return list
+}
+
+func (r *ResourceParser) runItemHooks(diff *Difficulty, item *Item, locale string) error {
+	if len(r.itemHooks) == 0 {
+		return nil
+	}
+	path := []Component{diff.parent.parent, diff.parent, diff}
+	for _, h := range r.itemHooks {
+		if err := h(path, item, locale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ResourceParser) processBody(item *Item, locale string) error {
+	for _, p := range r.bodyProcessors {
+		body, issues, err := p.ProcessBody(item.Body)
+		if err != nil {
+			return err
+		}
+		for _, i := range issues {
+			if i.Paragraph == 0 {
+				r.warn(item, locale, "%s", i.Message)
+			} else {
+				r.warn(item, locale, "paragraph %d: %s", i.Paragraph, i.Message)
+			}
+		}
+		item.Body = body
+	}
+	return nil
+}
+
+// current returns the resource of the translation of the component in the locale, nil if
+// missing or if no one listens to the changes
+func (r *ResourceParser) current(cmp Component, locale string) *Resource {
+	if len(r.listeners) == 0 {
+		return nil
+	}
+	c := r.lookup(locale, treePath(cmp))
+	if c == nil {
+		return nil
+	}
+	res := c.Resource()
+	return &res
+}
+
+// notifyParsed tells the listeners if the component parsed changed from the resource before,
+// see current
+func (r *ResourceParser) notifyParsed(cmp Component, locale string, before *Resource) {
+	if len(r.listeners) == 0 {
+		return
+	}
+	path := treePath(cmp)
+	switch after := r.current(cmp, locale); {
+	case after == nil:
+	case before == nil:
+		r.notify(path, locale, ChangeAdded)
+	case !reflect.DeepEqual(before, after):
+		r.notify(path, locale, ChangeModified)
+	}
+}
+
+func (r *ResourceParser) notify(path, locale string, kind ChangeKind) {
+	for _, l := range r.listeners {
+		l.OnComponentChanged(path, locale, kind)
+	}
+}
+
+func (r *ResourceParser) notifyComplete() {
+	if len(r.listeners) == 0 {
+		return
+	}
+	s := r.Snapshot()
+	for _, l := range r.listeners {
+		l.OnParseComplete(s)
+	}
+}
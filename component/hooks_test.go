@@ -0,0 +1,51 @@
+package component
+
+import (
+	"context"
+
+	. "gopkg.in/check.v1"
+)
+
+// changeRecorder is a Listener that records what it's told
+type changeRecorder struct {
+	changes   []string
+	snapshots []*Snapshot
+}
+
+func (l *changeRecorder) OnComponentChanged(path, locale string, kind ChangeKind) {
+	l.changes = append(l.changes, string(kind)+" "+locale+" "+path)
+}
+
+func (l *changeRecorder) OnParseComplete(s *Snapshot) { l.snapshots = append(l.snapshots, s) }
+
+func (CmpSuite) TestListener(c *C) {
+	p := bilingualParser()
+	var l changeRecorder
+	p.AddListener(&l)
+	en := p.category("cat", "en")
+	diff := en.Sub("sub").Difficulty("beginner")
+	item, other := diff.Item("item"), diff.Item("other")
+
+	_, errs := p.ParseAll([]ParseRequest{
+		{Component: en, Resource: &Resource{Content: []map[string]string{{KeyName: "IT Category"}}}, Locale: "it"},
+		{Component: item, Resource: &Resource{Content: []map[string]string{{KeyTitle: "Titolo"}, {KeyBody: "Uno"}, {KeyBody: "Due"}}}, Locale: "it"},
+		{Component: other, Resource: &Resource{Content: []map[string]string{{KeyTitle: "Altro"}, {KeyBody: "Tre"}}}, Locale: "it"},
+		{Component: other, Resource: &Resource{}, Locale: "it"},
+	})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(l.changes, DeepEquals, []string{
+		"modified it cat/sub/beginner/item",
+		"added it cat/sub/beginner/other",
+	})
+	c.Assert(l.snapshots, HasLen, 1)
+	c.Assert(l.snapshots[0].Category("cat", "it").Sub("sub").Difficulty("beginner").Item("other").Title, Equals, "Altro")
+
+	l.changes = nil
+	c.Assert(p.Remove(other, "it"), IsNil)
+	c.Assert(p.ParseLocales(context.Background(), map[string][]ParseRequest{
+		"it": {{Component: en, Resource: &Resource{Content: []map[string]string{{KeyName: "Categoria"}}}}},
+	}, 2), IsNil)
+	c.Assert(l.changes, DeepEquals, []string{"removed it cat/sub/beginner/other", "modified it cat"})
+	c.Assert(l.snapshots, HasLen, 2)
+	c.Assert(l.snapshots[1].Category("cat", "it").Name, Equals, "Categoria")
+}
@@ -0,0 +1,123 @@
+package component
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	// decoders of the supported assets
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+var imageRef = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^)\s]+)(?:\s+"[^"]*")?\s*\)`)
+
+// ImageRef is an image found in the body of an item
+type ImageRef struct {
+	Alt  string
+	Path string
+}
+
+// ImageInfo is the size of an image referenced by the items, see AnnotateImages
+type ImageInfo struct {
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// Images returns the images of the body, in order of appearance (notes excluded)
+func (i *Item) Images() []ImageRef {
+	var list []ImageRef
+	for _, m := range imageRef.FindAllStringSubmatch(stripBodyNotes(i.Body), -1) {
+		list = append(list, ImageRef{Alt: strings.TrimSpace(m[1]), Path: m[2]})
+	}
+	return list
+}
+
+// CheckImages returns a problem for each image of the items without alt text
+func (r *ResourceParser) CheckImages() []Problem {
+	var problems []Problem
+	r.walkItems(func(item *Item, locale string) {
+		for _, img := range item.Images() {
+			if img.Alt == "" {
+				problems = append(problems, Problem{Path: treePath(item), Locale: locale, Message: fmt.Sprintf("image %q has no alt text", img.Path)})
+			}
+		}
+	})
+	return problems
+}
+
+// AnnotateImages resolves the size of every image referenced by the items, replacing the
+// sizes of the previous call. Each path is resolved once, images that cannot be resolved
+// are returned as problems of the items using them.
+func (r *ResourceParser) AnnotateImages(resolver func(path string) (w, h int, err error)) []Problem {
+	var (
+		problems []Problem
+		failed   = make(map[string]error)
+	)
+	r.images = make(map[string]ImageInfo)
+	r.walkItems(func(item *Item, locale string) {
+		for _, img := range item.Images() {
+			if _, ok := r.images[img.Path]; ok {
+				continue
+			}
+			err, ok := failed[img.Path]
+			if !ok {
+				var w, h int
+				if w, h, err = resolver(img.Path); err == nil {
+					r.images[img.Path] = ImageInfo{Path: img.Path, Width: w, Height: h}
+					continue
+				}
+				failed[img.Path] = err
+			}
+			problems = append(problems, Problem{Path: treePath(item), Locale: locale, Message: fmt.Sprintf("image %q: %v", img.Path, err)})
+		}
+	})
+	return problems
+}
+
+// ImageTable returns the sizes found by AnnotateImages, sorted by path
+func (r *ResourceParser) ImageTable() []ImageInfo {
+	var list = make([]ImageInfo, 0, len(r.images))
+	for _, v := range r.images {
+		list = append(list, v)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	return list
+}
+
+// AssetResolver returns a resolver for AnnotateImages that decodes the assets,
+// an image path refers to the asset with the same file name.
+func AssetResolver(assets []*Asset) func(path string) (w, h int, err error) {
+	return func(p string) (int, int, error) {
+		for _, a := range assets {
+			if a.ID != path.Base(p) {
+				continue
+			}
+			cfg, _, err := image.DecodeConfig(bytes.NewReader([]byte(a.Content)))
+			if err != nil {
+				return 0, 0, err
+			}
+			return cfg.Width, cfg.Height, nil
+		}
+		return 0, 0, fmt.Errorf("No asset %q", path.Base(p))
+	}
+}
+
+// walkItems calls fn for every item of every locale, archived included, in tree order
+func (r *ResourceParser) walkItems(fn func(item *Item, locale string)) {
+	for _, l := range r.Locales(IncludeArchived()) {
+		for _, cat := range r.categories[l] {
+			walkCategory(cat, func(c Component) {
+				if item, ok := c.(*Item); ok {
+					fn(item, l)
+				}
+			})
+		}
+	}
+}
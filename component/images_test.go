@@ -0,0 +1,78 @@
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+
+	. "gopkg.in/check.v1"
+)
+
+func imagesParser() *ResourceParser {
+	p := NewResourceParser()
+	en := testCategory("en", "")
+	en.Sub("sub").Difficulty("beginner").AddItem(
+		&Item{ID: "one", Title: "One", Body: "![Map of the border](assets/map.png)\n\n![ ](assets/logo.png \"Logo\")"},
+		&Item{ID: "two", Title: "Two", Body: "![](../assets/map.png) and ![Lost](assets/lost.png)\n\n[[note: ![](assets/draft.png)]]"},
+	)
+	p.categories["en"] = []*Category{en}
+	return p
+}
+
+func (CmpSuite) TestItemImages(c *C) {
+	p := imagesParser()
+	item := p.category("cat", "en").Sub("sub").Difficulty("beginner").Item("one")
+	c.Assert(item.Images(), DeepEquals, []ImageRef{
+		{Alt: "Map of the border", Path: "assets/map.png"},
+		{Alt: "", Path: "assets/logo.png"},
+	})
+	c.Assert(p.CheckImages(), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/one", Locale: "en", Message: `image "assets/logo.png" has no alt text`},
+		{Path: "cat/sub/beginner/two", Locale: "en", Message: `image "../assets/map.png" has no alt text`},
+	})
+}
+
+func (CmpSuite) TestAnnotateImages(c *C) {
+	p := imagesParser()
+	var calls []string
+	problems := p.AnnotateImages(func(path string) (int, int, error) {
+		calls = append(calls, path)
+		switch path {
+		case "assets/map.png", "../assets/map.png":
+			return 640, 480, nil
+		case "assets/logo.png":
+			return 32, 32, nil
+		}
+		return 0, 0, errors.New("not found")
+	})
+	c.Assert(calls, DeepEquals, []string{"assets/map.png", "assets/logo.png", "../assets/map.png", "assets/lost.png"})
+	c.Assert(problems, DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/two", Locale: "en", Message: `image "assets/lost.png": not found`},
+	})
+	b, err := json.Marshal(p.ImageTable())
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `[{"path":"../assets/map.png","width":640,"height":480},`+
+		`{"path":"assets/logo.png","width":32,"height":32},{"path":"assets/map.png","width":640,"height":480}]`)
+
+	// a new call replaces the table
+	c.Assert(p.AnnotateImages(func(string) (int, int, error) { return 0, 0, errors.New("offline") }), HasLen, 4)
+	c.Assert(p.ImageTable(), HasLen, 0)
+}
+
+func (CmpSuite) TestAssetResolver(c *C) {
+	var buf bytes.Buffer
+	c.Assert(png.Encode(&buf, image.NewGray(image.Rect(0, 0, 3, 2))), IsNil)
+	resolve := AssetResolver([]*Asset{
+		{ID: "map.png", Content: buf.String()},
+		{ID: "broken.png", Content: "not an image"},
+	})
+	w, h, err := resolve("../assets/map.png")
+	c.Assert(err, IsNil)
+	c.Assert([]int{w, h}, DeepEquals, []int{3, 2})
+	_, _, err = resolve("assets/broken.png")
+	c.Assert(err, NotNil)
+	_, _, err = resolve("assets/missing.png")
+	c.Assert(err, ErrorMatches, `No asset "missing.png"`)
+}
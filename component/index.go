@@ -0,0 +1,67 @@
+package component
+
+// idIndex maps the IDs of the children of a component to their position.
+// The Add methods keep it up to date, and any other change to the children
+// must rebuild it, with reindex; lookups only read it, so they can run
+// concurrently. A copy of a parent must reset the index before adding
+// children to the copy, not to write in the map of the original.
+type idIndex struct {
+	size int
+	pos  map[string]int
+}
+
+// valid tells if the index was built for size children
+func (x *idIndex) valid(size int) bool {
+	return x.size == size && (size == 0 || x.pos != nil)
+}
+
+func (x *idIndex) build(size int, id func(int) string) {
+	x.size, x.pos = size, make(map[string]int, size)
+	for i := 0; i < size; i++ {
+		if _, ok := x.pos[id(i)]; !ok {
+			x.pos[id(i)] = i
+		}
+	}
+}
+
+// find returns the position of the child with the given ID, or -1.
+// The position found is checked, and a stale index falls back to a scan of
+// the children, so a missed rebuild causes a slower lookup, not a wrong result.
+func (x *idIndex) find(size int, id func(int) string, key string) int {
+	if x.valid(size) {
+		i, ok := x.pos[key]
+		if !ok {
+			return -1
+		}
+		if i < size && id(i) == key {
+			return i
+		}
+	}
+	for i := 0; i < size; i++ {
+		if id(i) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// add records the child appended at position size
+func (x *idIndex) add(size int, id func(int) string, key string) {
+	if !x.valid(size) || x.pos == nil {
+		x.build(size, id)
+	}
+	x.pos[key] = size
+	x.size++
+}
+
+// reset drops the index, for a copy of the parent before its children are added
+func (x *idIndex) reset() { x.size, x.pos = 0, nil }
+
+// reindex rebuilds the index of the subcategories, after a change other than Add
+func (c *Category) reindex() { c.subIndex.build(len(c.subcategories), c.subID) }
+
+// reindex rebuilds the index of the difficulties, after a change other than AddDifficulty
+func (s *Subcategory) reindex() { s.diffIndex.build(len(s.difficulties), s.diffID) }
+
+// reindex rebuilds the index of the items, after a change other than AddItem
+func (d *Difficulty) reindex() { d.itemIndex.build(len(d.items), d.itemID) }
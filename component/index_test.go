@@ -0,0 +1,115 @@
+package component
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestIndexDuplicates(c *C) {
+	cat := testCategory("en", "")
+	c.Assert(cat.Add(&Subcategory{ID: "sub"}), ErrorMatches, "Subcategory sub exists in cat")
+	c.Assert(cat.Add(&Subcategory{ID: "a"}, &Subcategory{ID: "a"}), ErrorMatches, "Subcategory a exists in cat")
+	c.Assert(cat.Subcategories(), DeepEquals, []string{"sub", "a"})
+
+	sub := cat.Sub("sub")
+	c.Assert(sub.AddDifficulty(&Difficulty{ID: "beginner"}), ErrorMatches, "Difficulty beginner exists in cat/sub")
+	diff := sub.Difficulty("beginner")
+	c.Assert(diff.AddItem(&Item{ID: "x"}, &Item{ID: "y"}), IsNil)
+	c.Assert(diff.AddItem(&Item{ID: "y"}), ErrorMatches, "item y exists in sub/beginner")
+	c.Assert(diff.ItemNames(), DeepEquals, []string{"x", "y"})
+}
+
+func (CmpSuite) TestIndexStale(c *C) {
+	p := bilingualParser()
+	en := p.categories["en"][0]
+	diff := en.Sub("sub").Difficulty("beginner")
+
+	// sorting moves the children behind the index
+	sort.Slice(diff.items, func(i, j int) bool { return diff.items[i].ID > diff.items[j].ID })
+	c.Assert(diff.Item("item").ID, Equals, "item")
+	c.Assert(diff.Item("other").ID, Equals, "other")
+
+	// removing and renaming are seen by the lookups
+	p.remove("en", diff.Item("other"))
+	c.Assert(diff.Item("other") == nil, Equals, true)
+	c.Assert(diff.AddItem(&Item{ID: "other"}), IsNil)
+	_, err := p.Rename("cat/sub/beginner/item", "renamed")
+	c.Assert(err, IsNil)
+	c.Assert(diff.Item("item") == nil, Equals, true)
+	c.Assert(diff.Item("renamed").Title, Equals, "Title")
+	_, err = p.Rename("cat/sub", "section")
+	c.Assert(err, IsNil)
+	c.Assert(en.Sub("sub") == nil, Equals, true)
+	c.Assert(en.Sub("section").Difficulty("beginner") == diff, Equals, true)
+
+	// a copy does not share the additions
+	cp := en.Copy()
+	c.Assert(cp.Add(&Subcategory{ID: "new"}), IsNil)
+	c.Assert(en.Sub("new") == nil, Equals, true)
+	c.Assert(en.Add(&Subcategory{ID: "new"}), IsNil)
+	c.Assert(cp.Sub("new") != en.Sub("new"), Equals, true)
+}
+
+func (CmpSuite) TestIndexConcurrentLookups(c *C) {
+	p := bilingualParser()
+	en := p.categories["en"][0]
+	diff := en.Sub("sub").Difficulty("beginner")
+	// a stale index is not rebuilt by the lookups, that only read it
+	sort.Slice(diff.items, func(i, j int) bool { return diff.items[i].ID > diff.items[j].ID })
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if en.Sub("sub").Difficulty("beginner").Item("item") == nil || p.lookup("en", "cat/sub/beginner/other") == nil {
+					panic("not found")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	c.Assert(diff.itemIndex.valid(len(diff.items)), Equals, true)
+}
+
+func BenchmarkParseLargeTree(b *testing.B) {
+	const subs, diffs, items = 100, 3, 100
+	cat := &Category{ID: "cat", Name: "Category"}
+	for s := 0; s < subs; s++ {
+		sub := &Subcategory{ID: fmt.Sprintf("sub%d", s), Name: "Sub"}
+		cat.Add(sub)
+		for d := 0; d < diffs; d++ {
+			diff := &Difficulty{ID: fmt.Sprintf("diff%d", d), Descr: "Difficulty"}
+			sub.AddDifficulty(diff)
+			for i := 0; i < items; i++ {
+				diff.AddItem(&Item{ID: fmt.Sprintf("item%d", i), Title: "Title", Body: "Body"})
+			}
+		}
+	}
+	var list []Component
+	for _, sub := range cat.subcategories {
+		for _, diff := range sub.difficulties {
+			for _, item := range diff.items {
+				list = append(list, item)
+			}
+		}
+	}
+	res := make([]Resource, len(list))
+	for i, cmp := range list {
+		res[i] = cmp.Resource()
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		p := NewResourceParser()
+		for i, cmp := range list {
+			r := res[i]
+			if err := p.Parse(cmp, &r, "it"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
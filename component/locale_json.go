@@ -0,0 +1,186 @@
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Tree is the content of a locale, returned by Export, in JSON:
+//
+//	{
+//	  "locale": "en",
+//	  "categories": [{"id", "name", "order", "subcategories": [{"id", "name", "order", "audience",
+//	    "difficulties": [{"id", "description", "items": [{"id", "title", "body", "order",
+//	    "summary", "audience"}], "checks": [{"text", "no_check", "style"}],
+//	    "quiz": [{"text", "options", "correct", "explanation"}]}]}]}],
+//	  "forms": [{"id", "name", "screens": [{"id", "name", "items": [inputs]}]}],
+//	  "glossaries": [{"id", "terms": [{"term", "definition"}]}]
+//	}
+//
+// Categories, subcategories and items are sorted by Order and ID, forms and glossaries by ID,
+// difficulties, checks, questions and terms keep their order. Lists are always present, empty if
+// there is nothing, but glossaries and quiz, omitted if there are none; audience, summary, style
+// and explanation are omitted when empty. Inputs are encoded like in Form.Tree.
+type Tree struct {
+	Locale     string         `json:"locale"`
+	Categories []TreeCategory `json:"categories"`
+	Forms      []TreeForm     `json:"forms"`
+	Glossaries []TreeGlossary `json:"glossaries,omitempty"`
+}
+
+// TreeCategory is a category of a Tree
+type TreeCategory struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Order         float64           `json:"order"`
+	Subcategories []TreeSubcategory `json:"subcategories"`
+}
+
+// TreeSubcategory is a subcategory of a Tree
+type TreeSubcategory struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Order        float64          `json:"order"`
+	Audience     []string         `json:"audience,omitempty"`
+	Difficulties []TreeDifficulty `json:"difficulties"`
+}
+
+// TreeDifficulty is a difficulty, with its checklist of a Tree
+type TreeDifficulty struct {
+	ID          string     `json:"id"`
+	Description string     `json:"description"`
+	Items       []TreeItem `json:"items"`
+	Checks      []Check    `json:"checks"`
+	Quiz        []Question `json:"quiz,omitempty"`
+}
+
+// TreeItem is an item of a Tree
+type TreeItem struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Order    float64  `json:"order"`
+	Summary  string   `json:"summary,omitempty"`
+	Audience []string `json:"audience,omitempty"`
+}
+
+// TreeForm is a form of a Tree
+type TreeForm struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Screens []TreeScreen `json:"screens"`
+}
+
+// TreeScreen is a screen of a form of a Tree
+type TreeScreen struct {
+	ID     string      `json:"id,omitempty"`
+	Name   string      `json:"name"`
+	Items  []FormInput `json:"items"`
+	ShowIf string      `json:"show_if,omitempty"`
+}
+
+// TreeGlossary is a glossary of a Tree
+type TreeGlossary struct {
+	ID    string         `json:"id"`
+	Terms []GlossaryTerm `json:"terms"`
+}
+
+// Export returns the categories and forms of the locale, as parsed, in a single value that
+// can be marshaled, see Tree. It fails for a locale without content.
+func (r *ResourceParser) Export(locale string) (*Tree, error) {
+	if r.emptyLocale(locale) {
+		return nil, fmt.Errorf("No content for locale %q", locale)
+	}
+	return r.tree(locale), nil
+}
+
+// MarshalLocale returns the JSON of the categories and forms of the locale, as parsed, in
+// the structure documented by Tree. The same content always gives the same bytes.
+func (r *ResourceParser) MarshalLocale(locale string) ([]byte, error) {
+	return json.MarshalIndent(r.tree(locale), "", "  ")
+}
+
+func (r *ResourceParser) tree(locale string) *Tree {
+	var doc = Tree{Locale: locale, Categories: []TreeCategory{}, Forms: []TreeForm{}}
+	for _, cat := range r.SortedCategories(locale) {
+		c := TreeCategory{ID: cat.ID, Name: cat.Name, Order: cat.Order, Subcategories: []TreeSubcategory{}}
+		for _, sub := range cat.subcategories {
+			s := TreeSubcategory{ID: sub.ID, Name: sub.Name, Order: sub.Order, Audience: sub.Audience, Difficulties: []TreeDifficulty{}}
+			for _, diff := range sub.difficulties {
+				d := TreeDifficulty{ID: diff.ID, Description: diff.Descr, Items: []TreeItem{}, Checks: []Check{}}
+				for _, i := range diff.items {
+					d.Items = append(d.Items, TreeItem{ID: i.ID, Title: i.Title, Body: i.Body, Order: i.Order, Summary: i.Abstract, Audience: i.Audience})
+				}
+				if diff.checklist != nil {
+					d.Checks = append(d.Checks, diff.checklist.Checks...)
+				}
+				if diff.quiz != nil && len(diff.quiz.Questions) != 0 {
+					d.Quiz = diff.quiz.Copy().Questions
+				}
+				s.Difficulties = append(s.Difficulties, d)
+			}
+			c.Subcategories = append(c.Subcategories, s)
+		}
+		doc.Categories = append(doc.Categories, c)
+	}
+	forms := append([]*Form(nil), r.forms[locale]...)
+	sort.Slice(forms, func(i, j int) bool { return forms[i].ID < forms[j].ID })
+	for _, form := range forms {
+		f := TreeForm{ID: form.ID, Name: form.Name, Screens: []TreeScreen{}}
+		for _, screen := range form.Screens {
+			s := TreeScreen{ID: screen.ID, Name: screen.Name, Items: append([]FormInput{}, screen.Items...), ShowIf: screen.ShowIf}
+			f.Screens = append(f.Screens, s)
+		}
+		doc.Forms = append(doc.Forms, f)
+	}
+	for _, g := range r.Glossaries(locale) {
+		doc.Glossaries = append(doc.Glossaries, TreeGlossary{ID: g.ID, Terms: append([]GlossaryTerm{}, g.Terms...)})
+	}
+	return &doc
+}
+
+// UnmarshalLocale replaces the categories, forms and glossaries of the locale with the ones of a document
+// of MarshalLocale.
+func (r *ResourceParser) UnmarshalLocale(b []byte) error {
+	var doc Tree
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	var cats []*Category
+	for _, c := range doc.Categories {
+		cat := &Category{ID: c.ID, Name: c.Name, Order: c.Order, Locale: doc.Locale}
+		for _, s := range c.Subcategories {
+			sub := &Subcategory{ID: s.ID, Name: s.Name, Order: s.Order, Audience: s.Audience}
+			for _, d := range s.Difficulties {
+				diff := &Difficulty{ID: d.ID, Descr: d.Description}
+				for _, i := range d.Items {
+					diff.AddItem(&Item{ID: i.ID, Title: i.Title, Body: i.Body, Order: i.Order, Abstract: i.Summary, Audience: i.Audience})
+				}
+				if len(d.Checks) != 0 {
+					diff.SetChecks(&Checklist{Checks: d.Checks})
+				}
+				if len(d.Quiz) != 0 {
+					diff.SetQuiz(&Quiz{Questions: d.Quiz})
+				}
+				sub.AddDifficulty(diff)
+			}
+			cat.Add(sub)
+		}
+		cats = append(cats, cat)
+	}
+	var forms []*Form
+	for _, f := range doc.Forms {
+		form := &Form{ID: f.ID, Name: f.Name, Locale: doc.Locale}
+		for _, s := range f.Screens {
+			form.Screens = append(form.Screens, FormScreen{ID: s.ID, Name: s.Name, Items: s.Items, ShowIf: s.ShowIf})
+		}
+		forms = append(forms, form)
+	}
+	var glossaries []*Glossary
+	for _, g := range doc.Glossaries {
+		glossaries = append(glossaries, &Glossary{ID: g.ID, Locale: doc.Locale, Terms: g.Terms})
+	}
+	r.categories[doc.Locale], r.forms[doc.Locale], r.glossaries[doc.Locale] = cats, forms, glossaries
+	return nil
+}
@@ -0,0 +1,107 @@
+package component
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func localeFixture() *ResourceParser {
+	p := bilingualParser()
+	cat := p.category("cat", "en")
+	cat.Order = 2
+	cat.Sub("sub").Audience = []string{"journalists"}
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.Item("item").Order, diff.Item("other").Order = 2, 1
+	diff.Item("item").Abstract = "Summary"
+	diff.AddChecks(Check{Text: "Read this", NoCheck: true, Style: StyleWarning})
+	cat.Sub("sub").AddDifficulty(&Difficulty{ID: "expert", Descr: "Empty"})
+	first := &Category{ID: "first", Name: "First", Order: 1, Locale: "en"}
+	first.Add(&Subcategory{ID: "empty", Name: "Empty"})
+	p.categories["en"] = append(p.categories["en"], first)
+	p.forms["en"] = []*Form{
+		{ID: "z", Name: "Last", Locale: "en"},
+		{ID: "a", Name: "Form", Locale: "en", Screens: []FormScreen{
+			{ID: "one", Name: "One", Items: []FormInput{
+				{Type: InputSingleChoice, Name: "choice", Label: "Choice", Options: []string{"Yes", "No"}},
+				{Type: InputText, Name: "text", Label: "Text", Hint: "Hint", Lines: 3},
+			}},
+			{ID: "two", Name: "Two"},
+		}},
+	}
+	return p
+}
+
+func (CmpSuite) TestMarshalLocale(c *C) {
+	b, err := localeFixture().MarshalLocale("en")
+	c.Assert(err, IsNil)
+	golden := filepath.Join("testdata", "locale_en.json")
+	if *update {
+		c.Assert(ioutil.WriteFile(golden, b, 0644), IsNil)
+	}
+	expected, err := ioutil.ReadFile(golden)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, string(expected))
+
+	// the order of the parser does not matter
+	p := localeFixture()
+	p.categories["en"][0], p.categories["en"][1] = p.categories["en"][1], p.categories["en"][0]
+	p.forms["en"][0], p.forms["en"][1] = p.forms["en"][1], p.forms["en"][0]
+	again, err := p.MarshalLocale("en")
+	c.Assert(err, IsNil)
+	c.Assert(string(again), Equals, string(b))
+
+	// empty locale
+	b, err = p.MarshalLocale("fr")
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "{\n  \"locale\": \"fr\",\n  \"categories\": [],\n  \"forms\": []\n}")
+}
+
+func (CmpSuite) TestExport(c *C) {
+	p := localeFixture()
+	t, err := p.Export("en")
+	c.Assert(err, IsNil)
+	c.Assert(t.Categories, HasLen, 2)
+	c.Assert(t.Categories[0].Subcategories[0].ID, Equals, "empty")
+	diff := t.Categories[1].Subcategories[0].Difficulties[0]
+	c.Assert([]string{diff.Items[0].ID, diff.Items[1].ID}, DeepEquals, []string{"other", "item"})
+	c.Assert(diff.Checks, HasLen, 2)
+	c.Assert(t.Forms[0].Screens[0].Items[1].Hint, Equals, "Hint")
+
+	// the tree marshals like MarshalLocale
+	b, err := json.MarshalIndent(t, "", "  ")
+	c.Assert(err, IsNil)
+	expected, err := p.MarshalLocale("en")
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, string(expected))
+
+	_, err = p.Export("fr")
+	c.Assert(err, ErrorMatches, `No content for locale "fr"`)
+}
+
+func (CmpSuite) TestUnmarshalLocale(c *C) {
+	rnd := rand.New(rand.NewSource(8))
+	for i := 0; i < 20; i++ {
+		src := randomTree(rnd, "en")
+		if i == 0 {
+			src = localeFixture()
+		}
+		b, err := src.MarshalLocale("en")
+		c.Assert(err, IsNil)
+		p := NewResourceParser()
+		c.Assert(p.UnmarshalLocale(b), IsNil)
+		again, err := p.MarshalLocale("en")
+		c.Assert(err, IsNil)
+		c.Assert(string(again), Equals, string(b))
+		for _, cmp := range src.localeComponents("en") {
+			c.Assert(textFields(p.lookup("en", treePath(cmp))), DeepEquals, textFields(cmp))
+		}
+	}
+	c.Assert(NewResourceParser().UnmarshalLocale([]byte("{")), NotNil)
+}
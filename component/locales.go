@@ -0,0 +1,93 @@
+package component
+
+import "sort"
+
+// An Option changes which content is included by reports and exports
+type Option func(*options)
+
+type options struct {
+	includeArchived bool
+	audience        []string
+	quarantine      QuarantineStore
+	ids             *NumericIDTable
+	roundTrip       bool
+	divergent       []string
+	assetRoot       string
+	includeEmpty    bool
+	since           *ResourceParser
+	progress        func(StreamProgress)
+	buffer          int
+	ready           *readyFilter
+}
+
+// readyFilter is the base locale and the thresholds of ReadyLocalesOnly
+type readyFilter struct {
+	base       string
+	thresholds Thresholds
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, fn := range opts {
+		fn(&o)
+	}
+	return o
+}
+
+// IncludeArchived includes archived locales
+func IncludeArchived() Option { return func(o *options) { o.includeArchived = true } }
+
+// IncludeEmptyLocales includes the locales that had content, all removed since
+func IncludeEmptyLocales() Option { return func(o *options) { o.includeEmpty = true } }
+
+// ReadyLocalesOnly excludes the locales that are not ready with the thresholds, compared to the
+// base locale, see LocaleReadiness. The base locale is always included.
+func ReadyLocalesOnly(base string, t Thresholds) Option {
+	return func(o *options) { o.ready = &readyFilter{base: base, thresholds: t} }
+}
+
+// Locales returns the sorted list of locales with content, archived and empty ones are excluded
+// by default
+func (r *ResourceParser) Locales(opts ...Option) []string {
+	var (
+		o    = newOptions(opts)
+		seen = make(map[string]bool)
+		list []string
+	)
+	add := func(l string) {
+		if seen[l] || r.archived[l] && !o.includeArchived || r.emptyLocale(l) && !o.includeEmpty {
+			return
+		}
+		if f := o.ready; f != nil && l != f.base && !r.readiness(f.base, l, f.thresholds).Ready {
+			return
+		}
+		seen[l] = true
+		list = append(list, l)
+	}
+	for l := range r.categories {
+		add(l)
+	}
+	for l := range r.forms {
+		add(l)
+	}
+	for l := range r.glossaries {
+		add(l)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// ArchiveLocale excludes a locale from reports and exports, its content is still available
+// when explicitly requested. It's Config.Archived.
+func (r *ResourceParser) ArchiveLocale(tag string) { r.archived[tag] = true }
+
+// UnarchiveLocale restores an archived locale
+func (r *ResourceParser) UnarchiveLocale(tag string) { delete(r.archived, tag) }
+
+// IsArchived tells if the locale is archived
+func (r *ResourceParser) IsArchived(tag string) bool { return r.archived[tag] }
+
+// emptyLocale tells if the locale has no content
+func (r *ResourceParser) emptyLocale(locale string) bool {
+	return len(r.categories[locale]) == 0 && len(r.forms[locale]) == 0 && len(r.glossaries[locale]) == 0
+}
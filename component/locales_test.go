@@ -0,0 +1,63 @@
+package component
+
+import (
+	"bytes"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestArchiveLocale(c *C) {
+	p := readinessParser(map[string]int{"en": 2, "it": 2, "la": 1})
+	c.Assert(p.Locales(), DeepEquals, []string{"en", "it", "la"})
+
+	p.ArchiveLocale("la")
+	c.Assert(p.IsArchived("la"), Equals, true)
+	c.Assert(p.Locales(), DeepEquals, []string{"en", "it"})
+	c.Assert(p.Locales(IncludeArchived()), DeepEquals, []string{"en", "it", "la"})
+	c.Assert(p.LocaleReadiness("en"), HasLen, 1)
+	c.Assert(p.LocaleReadiness("en", IncludeArchived()), HasLen, 2)
+	c.Assert(p.Categories()["la"], HasLen, 1)
+	c.Assert(p.category("cat", "la"), NotNil)
+
+	err := p.Parse(testCategory("en", ""), &Resource{Content: []map[string]string{{"name": "Categoria"}}}, "la")
+	c.Assert(err, IsNil)
+	c.Assert(p.Problems(), DeepEquals, []Problem{{Path: "cat", Locale: "la", Message: "locale is archived"}})
+
+	p.UnarchiveLocale("la")
+	c.Assert(p.Locales(), DeepEquals, []string{"en", "it", "la"})
+}
+
+func (CmpSuite) TestEmptyLocale(c *C) {
+	p := readinessParser(map[string]int{"en": 2, "it": 2, "la": 1})
+	old := readinessParser(map[string]int{"en": 2, "it": 2, "la": 1})
+	p.remove("la", p.category("cat", "la"))
+	c.Assert(p.Categories()["la"], HasLen, 0)
+
+	c.Assert(p.Locales(), DeepEquals, []string{"en", "it"})
+	c.Assert(p.Locales(IncludeEmptyLocales()), DeepEquals, []string{"en", "it", "la"})
+	c.Assert(p.LocaleReadiness("en"), HasLen, 1)
+	c.Assert(p.LocaleReadiness("en", IncludeEmptyLocales())["la"].Components, Equals, 0.0)
+
+	var b bytes.Buffer
+	c.Assert(p.ExportStringCatalog(&b, "en", FormatJSON), IsNil)
+	c.Assert(b.String(), Matches, `\{"source":"en","targets":\["it"\],.*\n`)
+	b.Reset()
+	c.Assert(p.ExportStringCatalog(&b, "en", FormatJSON, IncludeEmptyLocales()), IsNil)
+	c.Assert(b.String(), Matches, `\{"source":"en","targets":\["it","la"\],.*\n`)
+
+	// archived and empty are independent
+	p.ArchiveLocale("la")
+	c.Assert(p.Locales(IncludeEmptyLocales()), DeepEquals, []string{"en", "it"})
+	c.Assert(p.Locales(IncludeEmptyLocales(), IncludeArchived()), DeepEquals, []string{"en", "it", "la"})
+
+	log, err := GenerateChangelog(old, p, "la", FormatJSON)
+	c.Assert(err, IsNil)
+	c.Assert(string(log), Matches, `\{"locale":"la","locale_removed":true,.*`)
+	log, err = GenerateChangelog(old, p, "la", FormatMarkdown)
+	c.Assert(err, IsNil)
+	c.Assert(string(log), Equals, "# Changes (la)\n\nThe locale was removed, it has no content anymore.\n\n"+
+		"## la Category\n\n### Removed\n\n- Title (`cat/sub/beginner/item0`)\n")
+	log, err = GenerateChangelog(old, p, "it", FormatJSON)
+	c.Assert(err, IsNil)
+	c.Assert(string(log), Equals, `{"locale":"it","categories":[]}`)
+}
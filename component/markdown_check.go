@@ -0,0 +1,80 @@
+package component
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdLinkRef     = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)]*)\)`)
+	mdSpacedLink  = regexp.MustCompile(`\[([^\]]*)\]\s+\([^)]*\)`)
+	mdInlineCode  = regexp.MustCompile("`[^`]*`")
+	mdHeading     = regexp.MustCompile(`^(#+)(.*)$`)
+	mdLinkOpening = regexp.MustCompile(`!?\[[^\]]*\]\(`)
+)
+
+// MarkdownChecker is a BodyProcessor that reports malformed Markdown, that would be shown as
+// text by the apps: headings without a space or too deep, links and images that are not closed
+// or have no target, images without alternative text. Code and notes are not checked, and the
+// body is not changed: the HTML of a body is the SafeHTML encoding.
+type MarkdownChecker struct{}
+
+func (MarkdownChecker) ProcessBody(body string) (string, []BodyIssue, error) {
+	var (
+		issues []BodyIssue
+		fenced bool
+	)
+	for n, p := range strings.Split(body, paragraphSep) {
+		for _, line := range strings.Split(stripNotes(p), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				fenced = !fenced
+				continue
+			}
+			if fenced {
+				continue
+			}
+			for _, msg := range checkMarkdownLine(mdInlineCode.ReplaceAllString(line, "``")) {
+				issues = append(issues, BodyIssue{Paragraph: n + 1, Message: msg})
+			}
+		}
+	}
+	return body, issues, nil
+}
+
+// checkMarkdownLine returns the problems of a line of Markdown
+func checkMarkdownLine(line string) []string {
+	var list []string
+	if m := mdHeading.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+		switch text := strings.TrimSpace(m[2]); {
+		case len(m[1]) > 6:
+			list = append(list, fmt.Sprintf("heading of level %d, the deepest is 6", len(m[1])))
+		case text == "":
+			list = append(list, "empty heading")
+		case !strings.HasPrefix(m[2], " "):
+			list = append(list, fmt.Sprintf("heading %q without a space after #", text))
+		}
+	}
+	for _, m := range mdLinkRef.FindAllStringSubmatch(line, -1) {
+		image, text, target := m[1] != "", strings.TrimSpace(m[2]), strings.TrimSpace(m[3])
+		f := strings.Fields(target)
+		switch {
+		case image && target == "":
+			list = append(list, fmt.Sprintf("image %q without source", text))
+		case target == "":
+			list = append(list, fmt.Sprintf("link %q without target", text))
+		case len(f) > 1 && !strings.HasPrefix(f[1], `"`) && !strings.HasPrefix(target, "<"):
+			list = append(list, fmt.Sprintf("target %q of %q contains spaces", target, text))
+		case image && text == "":
+			list = append(list, fmt.Sprintf("image %s without alternative text", f[0]))
+		}
+	}
+	rest := mdLinkRef.ReplaceAllString(line, "")
+	for _, m := range mdSpacedLink.FindAllStringSubmatch(rest, -1) {
+		list = append(list, fmt.Sprintf("space between text and target of link %q", strings.TrimSpace(m[1])))
+	}
+	for _, m := range mdLinkOpening.FindAllString(mdSpacedLink.ReplaceAllString(rest, ""), -1) {
+		list = append(list, fmt.Sprintf("unterminated link %q", m))
+	}
+	return list
+}
@@ -0,0 +1,80 @@
+package component
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestMarkdownChecker(t *testing.T) {
+	for _, tc := range []struct {
+		body   string
+		issues []BodyIssue
+	}{
+		{"# Title\n\nText with [a link](https://example.com \"Title\") and ![a cat](cat.png)", nil},
+		{"##Title\n\n####### Deep\n\n#", []BodyIssue{
+			{1, `heading "Title" without a space after #`},
+			{2, "heading of level 7, the deepest is 6"},
+			{3, "empty heading"},
+		}},
+		{"See [here]() and ![cat]( ) and ![](cat.png)", []BodyIssue{
+			{1, `link "here" without target`},
+			{1, `image "cat" without source`},
+			{1, "image cat.png without alternative text"},
+		}},
+		{"One\n\nSee [the guide](some page) or [this] (https://example.com)\n[open](https://example.com", []BodyIssue{
+			{2, `target "some page" of "the guide" contains spaces`},
+			{2, `space between text and target of link "this"`},
+			{2, `unterminated link "[open]("`},
+		}},
+		{"```\n#include <stdio.h>\n\n[x]()\n```\n\nUse `[a]()` [[note: fix [b]() ]]", nil},
+	} {
+		body, issues, err := MarkdownChecker{}.ProcessBody(tc.body)
+		if err != nil || body != tc.body {
+			t.Errorf("%q: body %q, error %v", tc.body, body, err)
+		}
+		if !reflect.DeepEqual(issues, tc.issues) {
+			t.Errorf("%q: expected %v, got %v", tc.body, tc.issues, issues)
+		}
+	}
+}
+
+type bodyFunc func(string) (string, []BodyIssue, error)
+
+func (f bodyFunc) ProcessBody(body string) (string, []BodyIssue, error) { return f(body) }
+
+func (CmpSuite) TestBodyProcessors(c *C) {
+	batch := itemBatch("Voce")
+	batch[1].Resource.Content[1]["body"] = "##Uno"
+	p := NewResourceParser()
+	p.AddBodyProcessor(MarkdownChecker{})
+	p.AddBodyProcessor(bodyFunc(func(body string) (string, []BodyIssue, error) {
+		if strings.Contains(body, "<script") {
+			return "", nil, errors.New("script in body")
+		}
+		return strings.Replace(body, "##", "## ", 1), []BodyIssue{{0, "fixed"}}, nil
+	}))
+	var hooked string
+	p.AddItemHook(func(_ []Component, item *Item, _ string) error {
+		hooked = item.Body
+		return nil
+	})
+	_, errs := p.ParseAll(batch)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(hooked, Equals, "## Uno")
+	c.Assert(p.lookup("it", "cat/sub/beginner/item").(*Item).Body, Equals, "## Uno")
+	c.Assert(p.Problems(), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/item", Locale: "it", Message: `paragraph 1: heading "Uno" without a space after #`},
+		{Path: "cat/sub/beginner/item", Locale: "it", Message: "fixed"},
+	})
+
+	// an error fails the item, that is not changed
+	batch[1].Resource.Content[1]["body"] = "<script>"
+	_, errs = p.ParseAll(batch)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, ".*script in body")
+	c.Assert(p.lookup("it", "cat/sub/beginner/item").(*Item).Body, Equals, "## Uno")
+}
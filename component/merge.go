@@ -0,0 +1,94 @@
+package component
+
+import "fmt"
+
+// MergeStrategy is what Merge does with a component of the overlay that has the ID of one of
+// the base. Components with a new ID are always added, after the ones of the base.
+type MergeStrategy int
+
+const (
+	// MergeOverride replaces the item, checklist or quiz of the base with the one of the
+	// overlay, and the fields of a category, subcategory or difficulty with the ones of the
+	// overlay if it has a name, or a description
+	MergeOverride MergeStrategy = iota
+	// MergeAppend appends the paragraphs of the overlay item to the body of the base one, and
+	// the checks and questions of the overlay to the ones of the base, other fields are kept
+	MergeAppend
+	// MergeSkip keeps the component of the base
+	MergeSkip
+)
+
+// Merge returns a copy of the base category with the content of the overlay, a category with
+// the same ID: subcategories, difficulties and items are matched by ID, and the ones with the
+// same ID are merged with the strategy. An overlay component without a name, or a difficulty
+// without a description, only holds the components to merge into the base one.
+// The base and the overlay are not changed, and must have the same locale.
+func Merge(base, overlay *Category, strategy MergeStrategy) (*Category, error) {
+	if base.ID != overlay.ID {
+		return nil, fmt.Errorf("Cannot merge category %s into %s", overlay.ID, base.ID)
+	}
+	if base.Locale != overlay.Locale {
+		return nil, fmt.Errorf("Cannot merge locale %q into %q", overlay.Locale, base.Locale)
+	}
+	if strategy < MergeOverride || strategy > MergeSkip {
+		return nil, fmt.Errorf("Invalid merge strategy %d", strategy)
+	}
+	cat := base.Copy()
+	if strategy == MergeOverride && overlay.Name != "" {
+		cat.Name, cat.Order = overlay.Name, overlay.Order
+	}
+	for _, o := range overlay.subcategories {
+		sub := cat.Sub(o.ID)
+		if sub == nil {
+			cat.Add(o.Copy())
+			continue
+		}
+		if strategy == MergeOverride && o.Name != "" {
+			sub.Name, sub.Order, sub.Audience = o.Name, o.Order, copyStrings(o.Audience)
+		}
+		for _, od := range o.difficulties {
+			diff := sub.Difficulty(od.ID)
+			if diff == nil {
+				sub.AddDifficulty(od.Copy())
+				continue
+			}
+			mergeDifficulty(diff, od, strategy)
+		}
+	}
+	return cat, nil
+}
+
+// mergeDifficulty merges the overlay difficulty into the one of the copy of the base
+func mergeDifficulty(d, overlay *Difficulty, strategy MergeStrategy) {
+	if strategy == MergeOverride && overlay.Descr != "" {
+		d.Descr = overlay.Descr
+	}
+	for _, oi := range overlay.items {
+		i := d.itemIndex.find(len(d.items), d.itemID, oi.ID)
+		switch {
+		case i < 0:
+			d.AddItem(oi.Copy())
+		case strategy == MergeOverride:
+			item := oi.Copy()
+			item.parent = d
+			d.items[i] = item
+		case strategy == MergeAppend:
+			item := d.items[i]
+			item.Body = JoinBody(append(SplitBody(item.Body), SplitBody(oi.Body)...))
+		}
+	}
+	switch oc := overlay.checklist; {
+	case oc == nil:
+	case d.checklist == nil || strategy == MergeOverride:
+		d.SetChecks(oc.Copy())
+	case strategy == MergeAppend:
+		d.checklist.Add(oc.Copy().Checks...)
+	}
+	switch oq := overlay.quiz; {
+	case oq == nil:
+	case d.quiz == nil || strategy == MergeOverride:
+		d.SetQuiz(oq.Copy())
+	case strategy == MergeAppend:
+		d.quiz.Questions = append(d.quiz.Questions, oq.Copy().Questions...)
+	}
+}
@@ -0,0 +1,118 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+// mergeBase returns a category with a subcategory, a difficulty with two items and a checklist
+func mergeBase() *Category {
+	cat := &Category{ID: "cat", Name: "Category", Order: 1}
+	sub := &Subcategory{ID: "sub", Name: "Sub", Audience: []string{"journalist"}}
+	diff := &Difficulty{ID: "beginner", Descr: "Easy"}
+	cat.Add(sub)
+	sub.AddDifficulty(diff)
+	diff.AddItem(&Item{ID: "one", Title: "One", Body: "First"}, &Item{ID: "two", Title: "Two", Body: "Second"})
+	diff.AddChecks(Check{Text: "Check"})
+	return cat
+}
+
+// mergeOverlay returns an overlay of mergeBase, replacing an item and adding one, a check and
+// a subcategory
+func mergeOverlay() *Category {
+	cat := &Category{ID: "cat"}
+	sub := &Subcategory{ID: "sub"}
+	diff := &Difficulty{ID: "beginner"}
+	cat.Add(sub, &Subcategory{ID: "org", Name: "Our policies", Order: 5})
+	sub.AddDifficulty(diff)
+	diff.AddItem(&Item{ID: "two", Title: "Ours", Body: "Extra"}, &Item{ID: "three", Title: "Three", Body: "Third"})
+	diff.AddChecks(Check{Text: "Call us"})
+	return cat
+}
+
+func (CmpSuite) TestComponentCopy(c *C) {
+	cat := mergeBase()
+	diff := cat.Sub("sub").Difficulty("beginner")
+	sub := cat.Sub("sub").Copy()
+	c.Assert(sub.parent == nil, Equals, true)
+	sub.Audience[0] = "changed"
+	sub.Difficulty("beginner").Item("one").Title = "Changed"
+	sub.Difficulty("beginner").checklist.Checks[0].Text = "Changed"
+	c.Assert(cat.Sub("sub").Audience[0], Equals, "journalist")
+	c.Assert(diff.Item("one").Title, Equals, "One")
+	c.Assert(diff.checklist.Checks[0].Text, Equals, "Check")
+	c.Assert(sub.Difficulty("beginner").Item("one").parent == sub.Difficulty("beginner"), Equals, true)
+
+	// children added to a copy don't change the indexes of the original, even empty ones
+	empty, emptySub, emptyDiff := &Category{ID: "cat"}, &Subcategory{ID: "sub"}, &Difficulty{ID: "beginner"}
+	empty.reindex()
+	emptySub.reindex()
+	emptyDiff.reindex()
+	empty.Copy().Add(&Subcategory{ID: "new"})
+	emptySub.Copy().AddDifficulty(&Difficulty{ID: "new"})
+	emptyDiff.Copy().AddItem(&Item{ID: "new"})
+	_, sub1 := empty.subIndex.pos["new"]
+	_, diff1 := emptySub.diffIndex.pos["new"]
+	_, item1 := emptyDiff.itemIndex.pos["new"]
+	c.Assert([]bool{sub1, diff1, item1}, DeepEquals, []bool{false, false, false})
+
+	item := diff.Item("two").Copy()
+	c.Assert(item.parent == nil, Equals, true)
+	c.Assert(item.Title, Equals, "Two")
+	a := &Asset{ID: "logo.png", Content: "png"}
+	c.Assert(a.Copy() != a, Equals, true)
+	c.Assert(*a.Copy(), Equals, *a)
+}
+
+func (CmpSuite) TestMerge(c *C) {
+	base, overlay := mergeBase(), mergeOverlay()
+	items := func(cat *Category) (list []string) {
+		for _, i := range cat.Sub("sub").Difficulty("beginner").items {
+			list = append(list, i.ID+" "+i.Title+" "+i.Body)
+		}
+		return list
+	}
+	checks := func(cat *Category) (list []string) {
+		for _, v := range cat.Sub("sub").Difficulty("beginner").checklist.Checks {
+			list = append(list, v.Text)
+		}
+		return list
+	}
+
+	cat, err := Merge(base, overlay, MergeOverride)
+	c.Assert(err, IsNil)
+	c.Assert(cat.Name, Equals, "Category")
+	c.Assert(cat.Subcategories(), DeepEquals, []string{"sub", "org"})
+	c.Assert(cat.Sub("sub").Name, Equals, "Sub")
+	c.Assert(cat.Sub("org").parent == cat, Equals, true)
+	c.Assert(items(cat), DeepEquals, []string{"one One First", "two Ours Extra", "three Three Third"})
+	c.Assert(cat.Sub("sub").Difficulty("beginner").Item("two").parent == cat.Sub("sub").Difficulty("beginner"), Equals, true)
+	c.Assert(checks(cat), DeepEquals, []string{"Call us"})
+
+	cat, err = Merge(base, overlay, MergeAppend)
+	c.Assert(err, IsNil)
+	c.Assert(items(cat), DeepEquals, []string{"one One First", "two Two Second\n\nExtra", "three Three Third"})
+	c.Assert(checks(cat), DeepEquals, []string{"Check", "Call us"})
+
+	cat, err = Merge(base, overlay, MergeSkip)
+	c.Assert(err, IsNil)
+	c.Assert(items(cat), DeepEquals, []string{"one One First", "two Two Second", "three Three Third"})
+	c.Assert(checks(cat), DeepEquals, []string{"Check"})
+	c.Assert(cat.Subcategories(), DeepEquals, []string{"sub", "org"})
+
+	// the inputs don't change
+	c.Assert(base, DeepEquals, mergeBase())
+	c.Assert(overlay, DeepEquals, mergeOverlay())
+
+	overlay.Name, overlay.Order = "Our category", 2
+	cat, err = Merge(base, overlay, MergeOverride)
+	c.Assert(err, IsNil)
+	c.Assert(cat.Name, Equals, "Our category")
+	c.Assert(cat.Order, Equals, 2.0)
+
+	_, err = Merge(base, &Category{ID: "other"}, MergeOverride)
+	c.Assert(err, ErrorMatches, "Cannot merge category other into cat")
+	_, err = Merge(base, &Category{ID: "cat", Locale: "it"}, MergeOverride)
+	c.Assert(err, ErrorMatches, `Cannot merge locale "it" into ""`)
+	_, err = Merge(base, overlay, MergeStrategy(7))
+	c.Assert(err, ErrorMatches, "Invalid merge strategy 7")
+}
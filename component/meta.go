@@ -41,7 +41,7 @@ func setMeta(meta string, m meta) error {
 				break
 			}
 			if len(optionals) == 0 || order[i] != optionals[0] {
-				return fmt.Errorf("Expected %v, got %v", order[i], optionals[0])
+				return fmt.Errorf("Expected %v, got %v", order[i], m[1])
 			}
 			optionals = optionals[1:]
 			pointers = pointers[:i+copy(pointers[i:], pointers[i+1:])]
@@ -77,6 +77,18 @@ func setMetaValue(p interface{}, v string) error {
 			return nil
 		}
 		*pointer = strings.Split(v, ";")
+	case *[]int:
+		*pointer = nil
+		if strings.TrimSpace(v) == "" {
+			return nil
+		}
+		for _, part := range strings.Split(v, ";") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil {
+				return fmt.Errorf("Invalid int: %v", part)
+			}
+			*pointer = append(*pointer, n)
+		}
 	default:
 		return fmt.Errorf("unknown type %T", pointer)
 	}
@@ -94,10 +106,17 @@ func getMeta(m meta) string {
 		case int, float64:
 			isZero = t == 0
 		case bool:
-			isZero = t
+			isZero = !t
 		case []string:
 			isZero = len(t) == 0 || len(t) == 1 && t[0] == ""
 			v = strings.Join(t, ";")
+		case []int:
+			isZero = len(t) == 0
+			var parts = make([]string, len(t))
+			for i, n := range t {
+				parts[i] = strconv.Itoa(n)
+			}
+			v = strings.Join(parts, ";")
 		}
 		if len(optionals) != 0 && order[i] == optionals[0] {
 			optionals = optionals[1:]
@@ -23,6 +23,9 @@ func TestMeta(t *testing.T) {
 		{"[Type]: # (text_input)\n[Name]: # (name)\n[Label]: # (Name:)\n[Value]: # (some)\n[Options]: # (a;b)\n[Hint]: # (hint)\n[Lines]: # (10)", &FormInput{
 			Type: "text_input", Name: "name", Label: "Name:", Value: []string{"some"}, Options: []string{"a", "b"}, Hint: "hint", Lines: 10,
 		}},
+		{"[Type]: # (text_input)\n[Name]: # (name)\n[Label]: # (Name:)\n[Required]: # (true)", &FormInput{
+			Type: "text_input", Name: "name", Label: "Name:", Required: true,
+		}},
 	}
 	for _, tc := range testCases {
 		f := FormInput{}
@@ -0,0 +1,141 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// A Migration upgrades a resource written in an older format, before it's parsed
+type Migration struct {
+	Name   string
+	Detect func(res *Resource, c Component) bool
+	Apply  func(res *Resource, c Component) (*Resource, error)
+}
+
+// Applied records a migration applied to a resource
+type Applied struct {
+	Migration string `json:"migration"`
+	Path      string `json:"path"`
+	Locale    string `json:"locale"`
+}
+
+// MigrationLegacyBody turns a legacy item, with the whole body in the title row, into paragraph rows
+var MigrationLegacyBody = Migration{
+	Name: "legacy-item-body",
+	Detect: func(res *Resource, c Component) bool {
+		_, ok := c.(*Item)
+		return ok && len(res.Content) != 0 && res.Content[0][KeyBody] != ""
+	},
+	Apply: func(res *Resource, c Component) (*Resource, error) {
+		if len(res.Content) != 1 {
+			return nil, fmt.Errorf("Expected 1 legacy row, got %d", len(res.Content))
+		}
+		var content = []map[string]string{{KeyTitle: res.Content[0][KeyTitle]}}
+		for _, p := range strings.Split(res.Content[0][KeyBody], paragraphSep) {
+			if p = strings.TrimSpace(p); p != "" {
+				content = append(content, map[string]string{KeyBody: p})
+			}
+		}
+		return &Resource{Slug: res.Slug, Content: content}, nil
+	},
+}
+
+// MigrationChecklistIDs adds the IDs of the base checks, by position, to the rows of a checklist
+// translated before the checks had one, so that they keep matching if the checks are reordered
+var MigrationChecklistIDs = Migration{
+	Name: "checklist-ids",
+	Detect: func(res *Resource, c Component) bool {
+		list, ok := c.(*Checklist)
+		if !ok || len(list.Checks) == 0 {
+			return false
+		}
+		for _, check := range list.Checks {
+			if check.ID == "" {
+				return false
+			}
+		}
+		for _, row := range res.Content {
+			if row[KeyID] != "" {
+				return false
+			}
+		}
+		return true
+	},
+	Apply: func(res *Resource, c Component) (*Resource, error) {
+		list := c.(*Checklist)
+		var content []map[string]string
+		for _, row := range res.Content {
+			if row == nil {
+				continue
+			}
+			if len(content) == len(list.Checks) {
+				return nil, fmt.Errorf("Expected at most %d checks, got more", len(list.Checks))
+			}
+			var r = make(map[string]string, len(row)+1)
+			for k, v := range row {
+				r[k] = v
+			}
+			r[KeyID] = list.Checks[len(content)].ID
+			content = append(content, r)
+		}
+		return &Resource{Slug: res.Slug, Content: content}, nil
+	},
+}
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = []Migration{MigrationLegacyBody, MigrationChecklistIDs}
+)
+
+// RegisterMigration adds m to the migrations used by default in MigrateBatch. It's safe to call
+// while batches are migrated.
+func RegisterMigration(m Migration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	migrations = append(migrations, m)
+}
+
+func registeredMigrations() []Migration {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+	return append([]Migration(nil), migrations...)
+}
+
+// MigrateBatch upgrades the resources of the batch using the migrations, in order, or the registered
+// ones if none is specified. The input is not modified. A migration that fails leaves the resource
+// as it was and is reported as a Problem.
+func MigrateBatch(batch []ParseRequest, ms ...Migration) ([]ParseRequest, []Applied, []Problem) {
+	if len(ms) == 0 {
+		ms = registeredMigrations()
+	}
+	var (
+		result   = make([]ParseRequest, len(batch))
+		applied  []Applied
+		problems []Problem
+	)
+	for i, req := range batch {
+		result[i] = req
+		if req.Component == nil || req.Resource == nil {
+			continue
+		}
+		path := treePath(req.Component)
+		for _, m := range ms {
+			if !m.Detect(result[i].Resource, req.Component) {
+				continue
+			}
+			res, err := m.Apply(result[i].Resource, req.Component)
+			if err != nil {
+				problems = append(problems, Problem{
+					Path:    path,
+					Locale:  req.Locale,
+					Message: fmt.Sprintf("migration %s: %s", m.Name, err),
+				})
+				continue
+			}
+			result[i].Resource = res
+			applied = append(applied, Applied{Migration: m.Name, Path: path, Locale: req.Locale})
+		}
+	}
+	return result, applied, problems
+}
@@ -0,0 +1,94 @@
+package component
+
+import (
+	"errors"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestMigrateBatch(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	legacy := &Item{ID: "legacy", Title: "Legacy", Body: "a\n\nb"}
+	item := &Item{ID: "item", Title: "Item", Body: "c"}
+	diff.AddItem(legacy, item)
+
+	legacyRes := &Resource{Slug: "legacy", Content: []map[string]string{
+		{"title": "Vecchio", "body": "uno\n\n due \n\n"},
+	}}
+	batch := []ParseRequest{
+		{Component: cat, Resource: &Resource{Content: []map[string]string{{"name": "Categoria"}}}, Locale: "it"},
+		{Component: legacy, Resource: legacyRes, Locale: "it"},
+		{Component: item, Resource: &Resource{Content: []map[string]string{{"title": "Nuovo"}, {"body": "tre"}}}, Locale: "it"},
+	}
+
+	out, applied, problems := MigrateBatch(batch)
+	c.Assert(problems, HasLen, 0)
+	c.Assert(applied, DeepEquals, []Applied{{Migration: MigrationLegacyBody.Name, Path: "cat/sub/beginner/legacy", Locale: "it"}})
+	c.Assert(batch[1].Resource, Equals, legacyRes)
+	c.Assert(out[1].Resource.Content, DeepEquals, []map[string]string{
+		{"title": "Vecchio"}, {"body": "uno"}, {"body": "due"},
+	})
+	c.Assert(out[2].Resource, Equals, batch[2].Resource)
+
+	p := NewResourceParser()
+	for _, req := range out {
+		c.Assert(p.Parse(req.Component, req.Resource, req.Locale), IsNil)
+	}
+	c.Assert(p.lookup("it", "cat/sub/beginner/legacy").(*Item).Body, Equals, "uno\n\ndue")
+
+	failing := Migration{
+		Name:   "failing",
+		Detect: func(*Resource, Component) bool { return true },
+		Apply:  func(*Resource, Component) (*Resource, error) { return nil, errors.New("boom") },
+	}
+	out, applied, problems = MigrateBatch(batch[2:], failing)
+	c.Assert(applied, HasLen, 0)
+	c.Assert(problems, DeepEquals, []Problem{{Path: "cat/sub/beginner/item", Locale: "it", Message: "migration failing: boom"}})
+	c.Assert(out[0].Resource, Equals, batch[2].Resource)
+}
+
+func (CmpSuite) TestMigrateChecklistIDs(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.AddChecks(Check{Text: "One", ID: "one"}, Check{Text: "Two", ID: "two"})
+	list := diff.Checks()
+
+	res := &Resource{Content: []map[string]string{{"text": "Uno"}, nil, {"text": "Due"}}}
+	out, applied, problems := MigrateBatch([]ParseRequest{{Component: list, Resource: res, Locale: "it"}})
+	c.Assert(problems, HasLen, 0)
+	c.Assert(applied, DeepEquals, []Applied{{Migration: MigrationChecklistIDs.Name, Path: "cat/sub/beginner/.checks", Locale: "it"}})
+	c.Assert(out[0].Resource.Content, DeepEquals, []map[string]string{
+		{"text": "Uno", "id": "one"}, {"text": "Due", "id": "two"},
+	})
+	c.Assert(res.Content[0], DeepEquals, map[string]string{"text": "Uno"})
+
+	// rows with an ID are left alone, too many rows are a problem
+	c.Assert(MigrationChecklistIDs.Detect(out[0].Resource, list), Equals, false)
+	res = &Resource{Content: []map[string]string{{"text": "Uno"}, {"text": "Due"}, {"text": "Tre"}}}
+	out, applied, problems = MigrateBatch([]ParseRequest{{Component: list, Resource: res, Locale: "it"}})
+	c.Assert(applied, HasLen, 0)
+	c.Assert(problems, HasLen, 1)
+	c.Assert(out[0].Resource, Equals, res)
+}
+
+func (CmpSuite) TestRegisterMigrationConcurrent(c *C) {
+	defer func(ms []Migration) { migrations = ms }(registeredMigrations())
+	noop := Migration{
+		Name:   "noop",
+		Detect: func(*Resource, Component) bool { return false },
+		Apply:  func(res *Resource, _ Component) (*Resource, error) { return res, nil },
+	}
+	item := &Item{ID: "item", Title: "Item", Body: "a"}
+	testCategory("en", "").Sub("sub").Difficulty("beginner").AddItem(item)
+	batch := []ParseRequest{{Component: item, Resource: &Resource{}, Locale: "it"}}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); RegisterMigration(noop) }()
+		go func() { defer wg.Done(); MigrateBatch(batch) }()
+	}
+	wg.Wait()
+	c.Assert(len(registeredMigrations()), Equals, 12)
+}
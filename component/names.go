@@ -0,0 +1,57 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Default maximum lengths, in characters, of category and subcategory names and of
+// difficulty descriptions, that are free text
+const (
+	DefaultNameLength        = 100
+	DefaultDescriptionLength = 1000
+)
+
+// SetStrict makes the parser return an error for invalid names and descriptions,
+// instead of recording a problem and keeping them. It's Config.Strict.
+func (r *ResourceParser) SetStrict(strict bool) { r.strict = strict }
+
+// SetNameLength changes the maximum length of names, 0 is no limit. It's Config.NameLength.
+func (r *ResourceParser) SetNameLength(n int) { r.nameLength = n }
+
+// SetDescriptionLength changes the maximum length of difficulty descriptions, 0 is no limit.
+// It's Config.DescriptionLength.
+func (r *ResourceParser) SetDescriptionLength(n int) { r.descrLength = n }
+
+// checkName trims a category or subcategory name, as done for item titles, and checks it's
+// not empty, too long or with control characters.
+func (r *ResourceParser) checkName(c Component, locale, name string) (string, error) {
+	return r.checkText(c, locale, name, "name", r.nameLength)
+}
+
+// checkDescription checks a difficulty description like checkName, with its own length
+func (r *ResourceParser) checkDescription(c Component, locale, descr string) (string, error) {
+	return r.checkText(c, locale, descr, "description", r.descrLength)
+}
+
+func (r *ResourceParser) checkText(c Component, locale, text, kind string, length int) (string, error) {
+	text = strings.TrimSpace(text)
+	var msg string
+	switch {
+	case text == "":
+		msg = "empty " + kind
+	case strings.IndexFunc(text, unicode.IsControl) >= 0:
+		msg = kind + " contains control characters"
+	case length > 0 && utf8.RuneCountInString(text) > length:
+		msg = fmt.Sprintf("%s longer than %d characters", kind, length)
+	default:
+		return text, nil
+	}
+	if r.strict {
+		return "", rowError(c, locale, 1, ErrInvalidValue, "%s", msg)
+	}
+	r.warn(c, locale, "%s", msg)
+	return text, nil
+}
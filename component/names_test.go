@@ -0,0 +1,59 @@
+package component
+
+import (
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestNames(c *C) {
+	cat := testCategory("en", "")
+	sub, diff := cat.Sub("sub"), cat.Sub("sub").Difficulty("beginner")
+	row := func(k, v string) *Resource { return &Resource{Content: []map[string]string{{k: v}}} }
+
+	p := NewResourceParser()
+	c.Assert(p.Parse(cat, row("name", " Travel "), "en"), IsNil)
+	c.Assert(p.Parse(cat, row("name", "Viaggi\n"), "it"), IsNil)
+	c.Assert(p.Parse(sub, row("name", "\tSub "), "it"), IsNil)
+	c.Assert(p.Parse(diff, row("description", " Facile "), "it"), IsNil)
+	c.Assert(p.category("cat", "en").Name, Equals, "Travel")
+	c.Assert(p.category("cat", "it").Name, Equals, "Viaggi")
+	c.Assert(p.category("cat", "it").Sub("sub").Name, Equals, "Sub")
+	c.Assert(p.category("cat", "it").Sub("sub").Difficulty("beginner").Descr, Equals, "Facile")
+	c.Assert(p.Problems(), HasLen, 0)
+
+	// a description can be longer than a name
+	long := strings.Repeat("à", DefaultNameLength+1)
+	c.Assert(p.Parse(diff, row("description", long), "it"), IsNil)
+	c.Assert(p.Problems(), HasLen, 0)
+	c.Assert(p.Parse(cat, row("name", long), "it"), IsNil)
+	c.Assert(p.Problems(), HasLen, 1)
+
+	p.SetNameLength(10)
+	p.SetDescriptionLength(5)
+	for _, tc := range []struct {
+		cmp     Component
+		key     string
+		name    string
+		message string
+	}{
+		{cat, "name", "", "empty name"},
+		{cat, "name", "  \n ", "empty name"},
+		{sub, "name", "Sub\x00name", "name contains control characters"},
+		{sub, "name", strings.Repeat("à", 11), "name longer than 10 characters"},
+		{diff, "description", " ", "empty description"},
+		{diff, "description", strings.Repeat("à", 6), "description longer than 5 characters"},
+	} {
+		p.SetStrict(false)
+		p.problems = nil
+		c.Assert(p.Parse(tc.cmp, row(tc.key, tc.name), "it"), IsNil)
+		c.Assert(p.Problems(), DeepEquals, []Problem{{Path: treePath(tc.cmp), Locale: "it", Message: tc.message}})
+
+		p.SetStrict(true)
+		err := p.Parse(tc.cmp, row(tc.key, tc.name), "it")
+		c.Assert(err, DeepEquals, &ParseError{Path: treePath(tc.cmp), Kind: cmpType(tc.cmp), Locale: "it", Row: 1, Message: tc.message, Cause: ErrInvalidValue})
+		c.Assert(err.(*ParseError).Explain(), Equals, treePath(tc.cmp)+" (it) row 1: "+tc.message+"\n")
+	}
+	c.Assert(p.Parse(diff, row("description", strings.Repeat("à", 5)), "it"), IsNil)
+	c.Assert(p.Parse(sub, row("name", strings.Repeat("à", 10)), "it"), IsNil)
+}
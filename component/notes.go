@@ -0,0 +1,114 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	noteOpen  = "[[note:"
+	noteClose = "]]"
+)
+
+var ErrNote = errors.New("Unterminated note")
+
+// A Note is an editorial annotation, written as [[note: text]], that is never shown to users.
+// Offset is the position of the note in the original string.
+type Note struct {
+	Field  string `json:"field"`
+	Text   string `json:"text"`
+	Offset int    `json:"offset"`
+}
+
+// extractNotes returns s without notes and the notes found in it
+func extractNotes(field, s string) (string, []Note, error) {
+	var (
+		b     strings.Builder
+		notes []Note
+		start int
+	)
+	for {
+		i := strings.Index(s[start:], noteOpen)
+		if i < 0 {
+			break
+		}
+		i += start
+		j := strings.Index(s[i:], noteClose)
+		if j < 0 {
+			return s, notes, ErrNote
+		}
+		prefix := s[start:i]
+		start = i + j + len(noteClose)
+		if strings.HasSuffix(prefix, " ") && (start == len(s) || s[start] == ' ' || s[start] == '\n') {
+			prefix = prefix[:len(prefix)-1]
+		}
+		b.WriteString(prefix)
+		notes = append(notes, Note{Field: field, Text: strings.TrimSpace(s[i+len(noteOpen) : i+j]), Offset: i})
+	}
+	b.WriteString(s[start:])
+	return b.String(), notes, nil
+}
+
+// stripNotes removes notes from a string shown to users, unterminated notes are left untouched
+func stripNotes(s string) string {
+	if !strings.Contains(s, noteOpen) {
+		return s
+	}
+	clean, _, err := extractNotes("", s)
+	if err != nil {
+		return s
+	}
+	return strings.TrimSpace(clean)
+}
+
+// stripBodyNotes removes notes from a body, dropping paragraphs that contained only notes
+func stripBodyNotes(body string) string {
+	if !strings.Contains(body, noteOpen) {
+		return body
+	}
+	parts := strings.Split(body, paragraphSep)
+	var dst = parts[:0]
+	for _, p := range parts {
+		if p = stripNotes(p); p != "" {
+			dst = append(dst, p)
+		}
+	}
+	return strings.Join(dst, paragraphSep)
+}
+
+// checkNotes returns an error if s contains an unterminated note
+func checkNotes(s string) error {
+	_, _, err := extractNotes("", s)
+	return err
+}
+
+// Notes returns the editorial notes in the body of the item
+func (i *Item) Notes() []Note {
+	_, notes, _ := extractNotes(KeyBody, i.Body)
+	return notes
+}
+
+// Notes returns the editorial notes in the texts of the checks
+func (c *Checklist) Notes() []Note {
+	var list []Note
+	for i := range c.Checks {
+		_, notes, _ := extractNotes(fmt.Sprintf("checks.%d.text", i), c.Checks[i].Text)
+		list = append(list, notes...)
+	}
+	return list
+}
+
+// Notes returns the editorial notes in labels and hints of the form
+func (f *Form) Notes() []Note {
+	var list []Note
+	for i, s := range f.Screens {
+		for j, v := range s.Items {
+			_, notes, _ := extractNotes(fmt.Sprintf("screens.%d.items.%d.label", i, j), v.Label)
+			list = append(list, notes...)
+			_, notes, _ = extractNotes(fmt.Sprintf("screens.%d.items.%d.hint", i, j), v.Hint)
+			list = append(list, notes...)
+		}
+	}
+	return list
+}
@@ -0,0 +1,65 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestExtractNotes(c *C) {
+	for _, tc := range []struct {
+		in, out string
+		notes   []Note
+	}{
+		{"No notes", "No notes", nil},
+		{"[[note: start]]Text", "Text", []Note{{"f", "start", 0}}},
+		{"Text [[note: end]]", "Text", []Note{{"f", "end", 5}}},
+		{"A [[note: one]] B [[note:two]]", "A B", []Note{{"f", "one", 2}, {"f", "two", 18}}},
+		{"[[note: only]]", "", []Note{{"f", "only", 0}}},
+	} {
+		out, notes, err := extractNotes("f", tc.in)
+		c.Assert(err, IsNil)
+		c.Assert(out, Equals, tc.out, Commentf("%q", tc.in))
+		c.Assert(notes, DeepEquals, tc.notes, Commentf("%q", tc.in))
+	}
+	_, _, err := extractNotes("f", "A [[note: one]] B [[note: two")
+	c.Assert(err, Equals, ErrNote)
+	c.Assert(stripNotes("A [[note: unterminated"), Equals, "A [[note: unterminated")
+}
+
+func (CmpSuite) TestNotesStripped(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	var item Item
+	c.Assert(item.SetContents("[Title]: # (Title)\n[Order]: # (1)\n\nOne [[note: legal]]\n\n[[note: whole paragraph]]\n\nTwo"), IsNil)
+	item.ID = "item"
+	diff.AddItem(&item)
+	diff.AddChecks(Check{Text: "Check [[note: is this right?]]"})
+
+	c.Assert(item.Notes(), DeepEquals, []Note{{"body", "legal", 4}, {"body", "whole paragraph", 21}})
+	c.Assert(diff.Checks().Notes(), DeepEquals, []Note{{"checks.0.text", "is this right?", 6}})
+	c.Assert(item.Contents(), Matches, "(?s).*note: legal.*")
+
+	tree := diff.Tree(RawMarkdown).(map[string]interface{})
+	c.Assert(tree["items"].([]Item)[0].Body, Equals, "One\n\nTwo")
+	c.Assert(tree["checks"].([]Check)[0].Text, Equals, "Check")
+	c.Assert(diff.Tree(SafeHTML).(map[string]interface{})["items"].([]Item)[0].Body, Equals, "<p>One</p>\n\n<p>Two</p>\n")
+	c.Assert(item.Resource().Content[0]["body"], Equals, "One\n\nTwo")
+
+	form := Form{ID: "form", Screens: []FormScreen{{Name: "S", Items: []FormInput{{Label: "Label [[note: shorter]]", Hint: "Hint"}}}}}
+	c.Assert(form.Notes(), DeepEquals, []Note{{"screens.0.items.0.label", "shorter", 6}})
+	c.Assert(form.Tree(RawMarkdown).(*Form).Screens[0].Items[0].Label, Equals, "Label")
+	c.Assert(form.Screens[0].Items[0].Label, Equals, "Label [[note: shorter]]")
+}
+
+func (CmpSuite) TestNotesUnterminated(c *C) {
+	cat := testCategory("en", "")
+	r := NewResourceParser()
+	err := r.Parse(cat, &Resource{Content: []map[string]string{{"name": "Name [[note: open"}}}, "it")
+	c.Assert(err, IsNil)
+	c.Assert(r.Problems(), DeepEquals, []Problem{{Path: "cat", Locale: "it", Message: `unterminated note in "name"`}})
+
+	var p Parser
+	p.reset(false)
+	c.Assert(p.parseContents("contents_en/cat/.metadata.md", "[Name]: # (Category [[note: open)\n[Order]: # (1)", false), IsNil)
+	c.Assert(p.getCat("cat", "en").Name, Equals, "Category [[note: open")
+	c.Assert(p.Problems(), DeepEquals, []Problem{{Path: "contents_en/cat/.metadata.md", Locale: "en", Message: "unterminated note"}})
+}
@@ -0,0 +1,141 @@
+package component
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// NumericIDTable maps the tree paths of the components to compact IDs, see AssignNumericIDs
+type NumericIDTable struct {
+	Next uint32            `json:"next"` // the lowest ID never assigned
+	IDs  map[string]uint32 `json:"ids"`
+}
+
+// ID returns the numeric ID of the tree path
+func (t NumericIDTable) ID(path string) (uint32, bool) {
+	id, ok := t.IDs[path]
+	return id, ok
+}
+
+// WriteTo writes the table as JSON
+func (t NumericIDTable) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// ReadFrom replaces the table with the one read from r
+func (t *NumericIDTable) ReadFrom(r io.Reader) (int64, error) {
+	var c = countingReader{r: r}
+	var table NumericIDTable
+	if err := json.NewDecoder(&c).Decode(&table); err != nil {
+		return c.n, err
+	}
+	*t = table
+	return c.n, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// AssignNumericIDs returns a table with an ID for every component path in any locale.
+// Paths in prev keep their ID, also when renamed after prev was made; new paths get new IDs,
+// so an ID whose path is gone is never used again.
+func (r *ResourceParser) AssignNumericIDs(prev NumericIDTable) NumericIDTable {
+	var (
+		t     = NumericIDTable{Next: prev.Next, IDs: make(map[string]uint32)}
+		paths = r.componentPaths()
+		used  = make(map[uint32]bool)
+		old   = make([]string, 0, len(prev.IDs))
+	)
+	if t.Next == 0 {
+		t.Next = 1
+	}
+	for p, id := range prev.IDs {
+		if id >= t.Next {
+			t.Next = id + 1
+		}
+		old = append(old, p)
+	}
+	sort.Strings(old)
+	keep := func(path string, id uint32) {
+		if _, ok := t.IDs[path]; ok || used[id] {
+			return
+		}
+		t.IDs[path], used[id] = id, true
+	}
+	current := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		current[p] = true
+	}
+	// unchanged paths first, so a rename cannot take their ID
+	for _, p := range old {
+		if current[p] {
+			keep(p, prev.IDs[p])
+		}
+	}
+	for _, p := range old {
+		if n := r.renamedPath(p); n != p && current[n] {
+			keep(n, prev.IDs[p])
+		}
+	}
+	for _, p := range paths {
+		if _, ok := t.IDs[p]; !ok {
+			t.IDs[p] = t.Next
+			t.Next++
+		}
+	}
+	return t
+}
+
+// componentPaths returns the sorted tree paths of the components of every locale
+func (r *ResourceParser) componentPaths() []string {
+	var (
+		seen  = make(map[string]bool)
+		paths []string
+	)
+	add := func(c Component) {
+		if p := treePath(c); !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, l := range r.Locales(IncludeArchived()) {
+		for _, cat := range r.categories[l] {
+			walkCategory(cat, add)
+		}
+		for _, f := range r.forms[l] {
+			add(f)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// renamedPath returns the path after the renames made by the parser
+func (r *ResourceParser) renamedPath(path string) string {
+	for _, rn := range r.renames {
+		if path == rn[0] || strings.HasPrefix(path, rn[0]+"/") {
+			path = rn[1] + strings.TrimPrefix(path, rn[0])
+		}
+	}
+	return path
+}
+
+// NumericIDs adds the ID of the table to each component in exports
+func NumericIDs(t NumericIDTable) Option {
+	return func(o *options) { o.ids = &t }
+}
@@ -0,0 +1,72 @@
+package component
+
+import (
+	"bytes"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestAssignNumericIDs(c *C) {
+	p := bilingualParser()
+	t := p.AssignNumericIDs(NumericIDTable{})
+	c.Assert(t, DeepEquals, NumericIDTable{Next: 7, IDs: map[string]uint32{
+		"cat":                      1,
+		"cat/sub":                  2,
+		"cat/sub/beginner":         3,
+		"cat/sub/beginner/.checks": 4,
+		"cat/sub/beginner/item":    5,
+		"cat/sub/beginner/other":   6,
+	}})
+
+	// stable across imports
+	c.Assert(bilingualParser().AssignNumericIDs(t), DeepEquals, t)
+
+	var b bytes.Buffer
+	_, err := t.WriteTo(&b)
+	c.Assert(err, IsNil)
+	var read NumericIDTable
+	n, err := read.ReadFrom(&b)
+	c.Assert(err, IsNil)
+	c.Assert(n > 0, Equals, true)
+	c.Assert(read, DeepEquals, t)
+
+	// renamed paths keep the ID
+	_, err = p.Rename("cat/sub", "section")
+	c.Assert(err, IsNil)
+	_, err = p.Rename("cat/section/beginner/item", "renamed")
+	c.Assert(err, IsNil)
+	renamed := p.AssignNumericIDs(read)
+	c.Assert(renamed.Next, Equals, uint32(7))
+	c.Assert(renamed.IDs["cat/section"], Equals, uint32(2))
+	c.Assert(renamed.IDs["cat/section/beginner/.checks"], Equals, uint32(4))
+	c.Assert(renamed.IDs["cat/section/beginner/renamed"], Equals, uint32(5))
+	c.Assert(renamed.IDs["cat/section/beginner/other"], Equals, uint32(6))
+	_, ok := renamed.ID("cat/sub")
+	c.Assert(ok, Equals, false)
+
+	// retired IDs are not used again
+	p = bilingualParser()
+	diff := p.categories["en"][0].Sub("sub").Difficulty("beginner")
+	diff.items = diff.items[:1]
+	diff.AddItem(&Item{ID: "new", Title: "New"})
+	retired := p.AssignNumericIDs(t)
+	c.Assert(retired.Next, Equals, uint32(8))
+	c.Assert(retired.IDs["cat/sub/beginner/new"], Equals, uint32(7))
+	_, ok = retired.ID("cat/sub/beginner/other")
+	c.Assert(ok, Equals, false)
+	c.Assert(p.AssignNumericIDs(retired).IDs["cat/sub/beginner/new"], Equals, uint32(7))
+}
+
+func (CmpSuite) TestStringCatalogNumericIDs(c *C) {
+	p := bilingualParser()
+	var b bytes.Buffer
+	c.Assert(p.ExportStringCatalog(&b, "en", FormatCSV, NumericIDs(p.AssignNumericIDs(NumericIDTable{}))), IsNil)
+	lines := strings.Split(b.String(), "\n")
+	c.Assert(lines[:3], DeepEquals, []string{
+		"id,path,field,index,text,chars,words,it",
+		"1,cat,name,1,Category,8,1,true",
+		"2,cat/sub,name,1,Sub,3,1,true",
+	})
+	c.Assert(lines[9], Equals, "4,cat/sub/beginner/.checks,text,1,Check,5,1,true")
+}
@@ -0,0 +1,164 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ParseLocales parses the requests of each locale with up to workers locales at the same time.
+// The requests of a locale, whose Locale is ignored, are parsed in order, and the parsed locales, with
+// their problems, are merged in the parser in the order of the locale codes. The fallback
+// locales (see SetFallbackLocale and SetFallbackChain) in work are parsed before the others,
+// one at a time and each after its own fallbacks, since they read them.
+//
+// A locale stops at its first error, the components parsed until then are kept. The error
+// returned is the one of the first locale that failed, or the error of the context.
+func (r *ResourceParser) ParseLocales(ctx context.Context, work map[string][]ParseRequest, workers int) error {
+	var (
+		first   = r.fallbackOrder(work)
+		locales = make([]string, 0, len(work))
+		seen    = make(map[string]bool)
+	)
+	for _, l := range first {
+		seen[l] = true
+	}
+	for l := range work {
+		if !seen[l] {
+			locales = append(locales, l)
+		}
+	}
+	sort.Strings(locales)
+	if workers < 1 {
+		workers = 1
+	}
+	var errs = make(map[string]error)
+	for _, l := range first {
+		w := r.localeParser(l)
+		errs[l] = w.parseLocale(ctx, l, work[l])
+		r.merge(w, l)
+	}
+
+	var (
+		parsers = make([]*ResourceParser, len(locales))
+		results = make([]error, len(locales))
+		next    = make(chan int)
+		wg      sync.WaitGroup
+	)
+	for i := range locales {
+		parsers[i] = r.localeParser(locales[i])
+	}
+	for n := 0; n < workers && n < len(locales); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = parsers[i].parseLocale(ctx, locales[i], work[locales[i]])
+			}
+		}()
+	}
+	for i := range locales {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for i, l := range locales {
+		r.merge(parsers[i], l)
+		errs[l] = results[i]
+	}
+	r.notifyComplete()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, l := range append(first, locales...) {
+		if errs[l] != nil {
+			return fmt.Errorf("%s: %w", l, errs[l])
+		}
+	}
+	return nil
+}
+
+// fallbackOrder returns the locales of work that are fallbacks of a locale of work, each one
+// after its own fallbacks
+func (r *ResourceParser) fallbackOrder(work map[string][]ParseRequest) []string {
+	var (
+		fallbacks = make(map[string]bool)
+		done      = make(map[string]bool)
+		list      []string
+		visit     func(l string)
+	)
+	for l := range work {
+		for _, f := range r.fallbackChain(l) {
+			fallbacks[f] = true
+		}
+	}
+	visit = func(l string) {
+		if done[l] {
+			return
+		}
+		done[l] = true
+		for _, f := range r.fallbackChain(l) {
+			visit(f)
+		}
+		if _, ok := work[l]; ok && fallbacks[l] {
+			list = append(list, l)
+		}
+	}
+	var keys = make([]string, 0, len(fallbacks))
+	for l := range fallbacks {
+		keys = append(keys, l)
+	}
+	sort.Strings(keys)
+	for _, l := range keys {
+		visit(l)
+	}
+	return list
+}
+
+// localeParser returns a parser with the settings of r and the components of the locale,
+// that can parse it while r parses the others. The fallback locales are shared, read only.
+func (r *ResourceParser) localeParser(locale string) *ResourceParser {
+	w := NewResourceParser()
+	w.settings = r.settings.clone()
+	w.categories[locale] = r.categories[locale]
+	w.forms[locale] = r.forms[locale]
+	w.glossaries[locale] = r.glossaries[locale]
+	for _, l := range r.fallbackChain(locale) {
+		if l != locale {
+			w.categories[l] = r.categories[l]
+		}
+	}
+	if p, ok := r.pending[locale]; ok {
+		w.pending = map[string]map[string]bool{locale: p}
+	}
+	return w
+}
+
+func (r *ResourceParser) parseLocale(ctx context.Context, locale string, reqs []ParseRequest) error {
+	defer r.sortParsed(map[string]bool{locale: true})
+	for _, req := range reqs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.Parse(req.Component, req.Resource, locale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge takes the locale and the problems of a parser returned by localeParser
+func (r *ResourceParser) merge(w *ResourceParser, locale string) {
+	if list, ok := w.categories[locale]; ok {
+		r.categories[locale] = list
+	}
+	if list, ok := w.forms[locale]; ok {
+		r.forms[locale] = list
+	}
+	if list, ok := w.glossaries[locale]; ok {
+		r.glossaries[locale] = list
+	}
+	r.problems = append(r.problems, w.problems...)
+}
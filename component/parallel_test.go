@@ -0,0 +1,74 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	. "gopkg.in/check.v1"
+)
+
+// localeWork returns the requests to parse the components of src in several locales
+func localeWork(c *C, src *ResourceParser, locales int) map[string][]ParseRequest {
+	var work = make(map[string][]ParseRequest)
+	for _, cmp := range src.localeComponents("en") {
+		res, err := src.Encode(cmp, "en")
+		c.Assert(err, IsNil)
+		for i := 0; i < locales; i++ {
+			l := fmt.Sprint("l", i)
+			work[l] = append(work[l], ParseRequest{Component: cmp, Resource: res, Locale: l})
+		}
+	}
+	return work
+}
+
+func (CmpSuite) TestParseLocales(c *C) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 10; i++ {
+		src := randomTree(rnd, "en")
+		work := localeWork(c, src, 8)
+
+		serial := NewResourceParser()
+		for l := 0; l < 8; l++ {
+			for _, req := range work[fmt.Sprint("l", l)] {
+				c.Assert(serial.Parse(req.Component, req.Resource, req.Locale), IsNil)
+			}
+		}
+		parallel := NewResourceParser()
+		c.Assert(parallel.ParseLocales(context.Background(), work, 4), IsNil)
+		c.Assert(parallel.Categories(), DeepEquals, serial.Categories())
+		c.Assert(parallel.Forms(), DeepEquals, serial.Forms())
+	}
+}
+
+func (CmpSuite) TestParseLocalesErrors(c *C) {
+	src := randomTree(rand.New(rand.NewSource(4)), "en")
+	work := localeWork(c, src, 3)
+	work["l1"][1].Resource = &Resource{Content: []map[string]string{{"name": "a"}, {"name": "b"}}}
+
+	p := NewResourceParser()
+	err := p.ParseLocales(context.Background(), work, 2)
+	c.Assert(err, ErrorMatches, "l1: .*")
+	c.Assert(p.Categories()["l0"], HasLen, len(src.Categories()["en"]))
+	c.Assert(p.Categories()["l1"], HasLen, 1)
+	c.Assert(p.Categories()["l1"][0].Subcategories(), HasLen, 0)
+	c.Assert(p.Categories()["l2"], HasLen, len(src.Categories()["en"]))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Assert(NewResourceParser().ParseLocales(ctx, work, 2), Equals, context.Canceled)
+}
+
+func (CmpSuite) TestParseLocalesFallback(c *C) {
+	p := NewResourceParser()
+	p.SetFallbackLocale("en")
+	cat := testCategory("en", "")
+	sub := cat.Sub("sub")
+	work := map[string][]ParseRequest{
+		"en": {{Component: cat, Resource: &Resource{Content: []map[string]string{{"name": "Category"}}}}},
+		"it": {{Component: sub, Resource: &Resource{Content: []map[string]string{{"name": "Sotto"}}}}},
+	}
+	c.Assert(p.ParseLocales(context.Background(), work, 2), IsNil)
+	c.Assert(p.category("cat", "it").Name, Equals, "Category")
+	c.Assert(p.Problems(), DeepEquals, []Problem{{Path: "cat", Locale: "it", Message: "category missing, name of en used"}})
+}
@@ -0,0 +1,262 @@
+package component
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Causes of the errors of Parse, to be checked with errors.Is: the error types of the
+// package carry the details, like the path, the locale and the row.
+var (
+	ErrMissingParent    = errors.New("Missing parent")
+	ErrRowCountMismatch = errors.New("Row count mismatch")
+	ErrUnexpectedScreen = errors.New("Unexpected screen")
+	ErrUnexpectedInput  = errors.New("Unexpected input")
+	ErrOptionsMismatch  = errors.New("Options mismatch")
+	ErrInvalidValue     = errors.New("Invalid value")
+)
+
+// ParseError is an error in the resource of a component. Rows are numbered from 1, as in
+// the spreadsheets used by editors.
+type ParseError struct {
+	Path    string `json:"path"`
+	Kind    string `json:"kind"` // type of the component, like item or form
+	Locale  string `json:"locale"`
+	Row     int    `json:"row"`     // row where the error was found
+	Message string `json:"message"` // what went wrong
+	Cause   error  `json:"-"`       // one of the Err variables above, see Unwrap
+
+	LastRow    int      `json:"last_row"`            // last row consumed successfully
+	LastKind   string   `json:"last_kind"`           // what the last row was matched to
+	Divergence int      `json:"divergence"`          // first row that does not match the base, likely to be fixed
+	Expected   string   `json:"expected"`            // what was expected at Row
+	Remaining  []string `json:"remaining,omitempty"` // sequence expected from Row on, according to the base
+
+	// for forms, the screen and input of the base expected at Row, numbered from 1 as rows;
+	// Input is 0 for the screen row, both are 0 at the end of the form
+	Screen int `json:"screen,omitempty"`
+	Input  int `json:"input,omitempty"`
+}
+
+func (p *ParseError) Error() string {
+	return fmt.Sprintf("%s (%s) row %d: %s", p.Path, p.Locale, p.Row, p.Message)
+}
+
+func (p *ParseError) Unwrap() error { return p.Cause }
+
+// rowError returns a ParseError of the component at the row, caused by cause
+func rowError(c Component, locale string, row int, cause error, format string, args ...interface{}) *ParseError {
+	return &ParseError{Path: treePath(c), Kind: cmpType(c), Locale: locale, Row: row, Message: fmt.Sprintf(format, args...), Cause: cause}
+}
+
+// ContentMismatchError is returned when a resource has not the number of rows, or checks, of the base
+type ContentMismatchError struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"` // type of the component, see ParseError
+	Locale   string `json:"locale"`
+	Unit     string `json:"unit"` // rows or checks
+	Expected int    `json:"expected"`
+	Got      int    `json:"got"`
+	AtLeast  bool   `json:"at_least"` // Expected is a minimum
+}
+
+func contentMismatch(c Component, locale, unit string, expected, got int, atLeast bool) error {
+	return &ContentMismatchError{Path: treePath(c), Kind: cmpType(c), Locale: locale, Unit: unit, Expected: expected, Got: got, AtLeast: atLeast}
+}
+
+func (e *ContentMismatchError) Error() string {
+	if e.Unit == "checks" {
+		return fmt.Sprintf("%s (%s): %d checks, %d expected", e.Path, e.Locale, e.Got, e.Expected)
+	}
+	return ErrContent.Error()
+}
+
+// Is makes the error match ErrRowCountMismatch, and ErrContent, returned before it existed
+func (e *ContentMismatchError) Is(target error) bool {
+	return target == ErrContent || target == ErrRowCountMismatch
+}
+
+// LegacyFormatError is returned for an item resource with the whole body in the first row,
+// the old format, followed by other rows
+type LegacyFormatError struct {
+	Path   string `json:"path"`
+	Locale string `json:"locale"`
+	Rows   int    `json:"rows"`
+}
+
+func (e *LegacyFormatError) Error() string {
+	return fmt.Sprintf("Invalid Legacy %q (%s)", path.Base(path.Dir(e.Path)), e.Locale)
+}
+
+// Is makes the error match ErrContent
+func (e *LegacyFormatError) Is(target error) bool { return target == ErrContent }
+
+// Explain describes where the content stopped matching the base and what was expected
+func (p *ParseError) Explain() string {
+	b := bytes.NewBuffer(nil)
+	fmt.Fprintln(b, p.Error())
+	if p.LastKind != "" {
+		fmt.Fprintf(b, "last matching row: %d (%s)\n", p.LastRow, p.LastKind)
+	}
+	if p.Divergence != 0 && p.Divergence != p.Row {
+		fmt.Fprintf(b, "rows diverge from row %d, check it first\n", p.Divergence)
+	}
+	if p.Expected != "" {
+		fmt.Fprintf(b, "expected at row %d: %s\n", p.Row, p.Expected)
+	}
+	if len(p.Remaining) != 0 {
+		fmt.Fprintf(b, "expected sequence: %s\n", strings.Join(p.Remaining, ", "))
+	}
+	return b.String()
+}
+
+// SetResync makes form parsing tolerant: when a row does not match the base form, up to rows
+// rows are skipped or assumed missing (keeping the base text) to re-synchronize, and each one
+// is recorded as a problem. With 0, the default, any mismatch is an error. It's Config.Resync.
+func (r *ResourceParser) SetResync(rows int) { r.resync = rows }
+
+// formAligner tracks the rows of a form resource consumed while matching them to the base form
+type formAligner struct {
+	form       *Form
+	rows       []map[string]string
+	row        int // index of the next row
+	last       string
+	divergence int // index of the first row whose shape does not match, -1 if none
+
+	kinds  []bool // rows expected by the base, true for a screen and false for an input
+	pos    int    // index of the next expected row in kinds
+	budget int    // rows that can still be skipped or assumed missing, see SetResync
+	warn   func(format string, a ...interface{})
+}
+
+// formKinds returns the sequence of rows expected for the form, true for screens and false for inputs
+func formKinds(f *Form) []bool {
+	var kinds []bool
+	for _, s := range f.Screens {
+		if s.Name != "" {
+			kinds = append(kinds, true)
+		}
+		for _, v := range s.Items {
+			if v.Label != "" || v.Hint != "" || v.Options != nil {
+				kinds = append(kinds, false)
+			}
+		}
+	}
+	return kinds
+}
+
+func (a *formAligner) done() bool { return a.row >= len(a.rows) }
+
+func (a *formAligner) consume() {
+	if m := a.rows[a.row]; m[KeyScreen] != "" {
+		a.last = fmt.Sprintf("screen %q", m[KeyScreen])
+	} else {
+		a.last = fmt.Sprintf("input %q", m[KeyLabel])
+	}
+	a.row++
+	a.pos++
+}
+
+// expect tells if the current row is a screen (or an input), as described by what. If it's not and
+// the budget allows it, it re-synchronizes the rows either skipping the stray ones or assuming the
+// expected one is missing, choosing what matches the base for longer; ok is false if it can't.
+func (a *formAligner) expect(screen bool, what string) (ok, missing bool) {
+	if !a.done() && (a.rows[a.row][KeyScreen] != "") == screen {
+		return true, false
+	}
+	if a.budget == 0 {
+		return false, false
+	}
+	best, skip := a.matching(a.row, a.pos+1), 0
+	for k := 1; k <= a.budget && a.row+k < len(a.rows); k++ {
+		if (a.rows[a.row+k][KeyScreen] != "") != screen {
+			continue
+		}
+		if n := a.matching(a.row+k, a.pos); n > best {
+			best, skip = n, k
+		}
+	}
+	if skip == 0 {
+		a.warn("row %d: missing %s, base text kept", a.row+1, what)
+		a.budget--
+		a.pos++
+		return true, true
+	}
+	for ; skip > 0; skip-- {
+		a.warn("row %d skipped: expected %s", a.row+1, what)
+		a.budget--
+		a.row++
+	}
+	return true, false
+}
+
+// matching returns how many rows, from row, have the kinds expected from pos on
+func (a *formAligner) matching(row, pos int) int {
+	var n int
+	for ; row < len(a.rows) && pos < len(a.kinds); row, pos = row+1, pos+1 {
+		if (a.rows[row][KeyScreen] != "") != a.kinds[pos] {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// skipRest skips the rows left after the form, if the budget allows it
+func (a *formAligner) skipRest() bool {
+	if len(a.rows)-a.row > a.budget {
+		return false
+	}
+	for ; !a.done(); a.row++ {
+		a.warn("row %d skipped: expected end of the form", a.row+1)
+		a.budget--
+	}
+	return true
+}
+
+// check records a divergence if the row has not the same shape of the input
+func (a *formAligner) check(input *FormInput, m map[string]string) {
+	if a.divergence >= 0 {
+		return
+	}
+	sameOptions := input.Options == nil || len(input.Options) == len(strings.Split(m[KeyOptions], ";"))
+	if (input.Hint == "") != (m[KeyHint] == "") || !sameOptions {
+		a.divergence = a.row
+	}
+}
+
+// fail returns a ParseError at the current row, while expecting screen i and input j (-1 for the screen)
+func (a *formAligner) fail(locale string, i, j int, cause error, format string, args ...interface{}) error {
+	f := a.form
+	p := rowError(f, locale, a.row+1, cause, format, args...)
+	p.LastRow, p.LastKind, p.Divergence = a.row, a.last, a.row+1
+	if i < len(f.Screens) {
+		p.Screen, p.Input = i+1, j+1
+	}
+	if a.divergence >= 0 {
+		p.Divergence = a.divergence + 1
+	}
+	for ; i < len(f.Screens); i, j = i+1, -1 {
+		s := f.Screens[i]
+		if j < 0 && s.Name != "" {
+			p.Remaining = append(p.Remaining, fmt.Sprintf("screen %q (%d inputs)", s.Name, len(s.Items)))
+		}
+		if j < 0 {
+			j = 0
+		}
+		for ; j < len(s.Items); j++ {
+			if v := s.Items[j]; v.Label != "" || v.Hint != "" || v.Options != nil {
+				p.Remaining = append(p.Remaining, fmt.Sprintf("input %d/%d of screen %q", j+1, len(s.Items), s.Name))
+			}
+		}
+	}
+	if len(p.Remaining) != 0 {
+		p.Expected = p.Remaining[0]
+	} else {
+		p.Expected = "end of the form"
+	}
+	return p
+}
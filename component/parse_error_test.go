@@ -0,0 +1,189 @@
+package component
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+func errorForm() *Form {
+	return &Form{ID: "form", Name: "Form", Screens: []FormScreen{
+		{Name: "One", Items: []FormInput{{Label: "A", Options: []string{"x", "y"}}, {Label: "B", Hint: "Hint"}}},
+		{Name: "Two", Items: []FormInput{{Label: "C"}, {Label: "D"}}},
+	}}
+}
+
+func (CmpSuite) TestParseErrorExplain(c *C) {
+	var (
+		form  = errorForm()
+		name  = map[string]string{"form": "Modulo"}
+		one   = map[string]string{"screen": "Uno"}
+		two   = map[string]string{"screen": "Due"}
+		a     = map[string]string{"label": "A", "options": "x;y"}
+		b     = map[string]string{"label": "B", "hint": "Suggerimento"}
+		cc    = map[string]string{"label": "C"}
+		d     = map[string]string{"label": "D"}
+		extra = map[string]string{"label": "E", "hint": "Extra"}
+	)
+	c.Assert(NewResourceParser().Parse(form, &Resource{Content: []map[string]string{name, one, a, b, two, cc, d}}, "it"), IsNil)
+
+	for _, tc := range []struct {
+		rows                  []map[string]string
+		row, last, divergence int
+		expected, explain     string
+		remaining             int
+	}{
+		{ // deleted screen row
+			[]map[string]string{name, one, a, b, cc, d}, 5, 4, 5, `screen "Two" (2 inputs)`,
+			"forms/form (it) row 5: Expected screen 1, got item\nlast matching row: 4 (input \"B\")\nexpected at row 5: screen \"Two\" (2 inputs)\n" +
+				"expected sequence: screen \"Two\" (2 inputs), input 1/2 of screen \"Two\", input 2/2 of screen \"Two\"\n", 3,
+		},
+		{ // deleted item row
+			[]map[string]string{name, one, b, two, cc, d}, 4, 3, 3, `input 2/2 of screen "One"`,
+			"forms/form (it) row 4: Expected item 0/1, got screen \"Due\"\nlast matching row: 3 (input \"B\")\nrows diverge from row 3, check it first\n" +
+				"expected at row 4: input 2/2 of screen \"One\"\n" +
+				"expected sequence: input 2/2 of screen \"One\", screen \"Two\" (2 inputs), input 1/2 of screen \"Two\", input 2/2 of screen \"Two\"\n", 4,
+		},
+		{ // extra inserted row
+			[]map[string]string{name, one, a, b, two, extra, cc, d}, 8, 7, 6, "end of the form",
+			"forms/form (it) row 8: 1 unexpected rows\nlast matching row: 7 (input \"C\")\nrows diverge from row 6, check it first\nexpected at row 8: end of the form\n", 0,
+		},
+	} {
+		err := NewResourceParser().Parse(form, &Resource{Content: tc.rows}, "it")
+		c.Assert(err, FitsTypeOf, &ParseError{})
+		p := err.(*ParseError)
+		c.Assert(p.Row, Equals, tc.row)
+		c.Assert(p.LastRow, Equals, tc.last)
+		c.Assert(p.Divergence, Equals, tc.divergence)
+		c.Assert(p.Expected, Equals, tc.expected)
+		c.Assert(p.Remaining, HasLen, tc.remaining)
+		c.Assert(p.Explain(), Equals, tc.explain)
+	}
+}
+
+func (CmpSuite) TestFormResync(c *C) {
+	var (
+		form  = errorForm()
+		name  = map[string]string{"form": "Modulo"}
+		one   = map[string]string{"screen": "Uno"}
+		two   = map[string]string{"screen": "Due"}
+		a     = map[string]string{"label": "A1", "options": "x;y"}
+		b     = map[string]string{"label": "B1", "hint": "Suggerimento"}
+		cc    = map[string]string{"label": "C1"}
+		d     = map[string]string{"label": "D1"}
+		extra = map[string]string{"label": "E", "hint": "Extra"}
+	)
+	for _, tc := range []struct {
+		rows     []map[string]string
+		screens  []string
+		labels   []string
+		problems []string
+	}{
+		{ // extra row before a screen
+			[]map[string]string{name, one, a, b, extra, two, cc, d},
+			[]string{"Uno", "Due"}, []string{"A1", "B1", "C1", "D1"},
+			[]string{`row 5 skipped: expected screen "Two"`},
+		},
+		{ // missing screen row
+			[]map[string]string{name, one, a, b, cc, d},
+			[]string{"Uno", "Two"}, []string{"A1", "B1", "C1", "D1"},
+			[]string{`row 5: missing screen "Two", base text kept`},
+		},
+		{ // missing input row
+			[]map[string]string{name, one, a, two, cc, d},
+			[]string{"Uno", "Due"}, []string{"A1", "B", "C1", "D1"},
+			[]string{`row 4: missing input 2/2 of screen "One", base text kept`},
+		},
+		{ // extra rows at the end
+			[]map[string]string{name, one, a, b, two, cc, d, extra, extra},
+			[]string{"Uno", "Due"}, []string{"A1", "B1", "C1", "D1"},
+			[]string{"row 8 skipped: expected end of the form", "row 9 skipped: expected end of the form"},
+		},
+	} {
+		p := NewResourceParser()
+		p.SetResync(2)
+		c.Assert(p.Parse(form, &Resource{Content: tc.rows}, "it"), IsNil)
+		f := p.forms["it"][0]
+		var screens, labels, problems []string
+		for _, s := range f.Screens {
+			screens = append(screens, s.Name)
+			for _, i := range s.Items {
+				labels = append(labels, i.Label)
+			}
+		}
+		for _, pr := range p.Problems() {
+			c.Assert(pr.Path, Equals, "forms/form")
+			c.Assert(pr.Locale, Equals, "it")
+			problems = append(problems, pr.Message)
+		}
+		c.Assert(screens, DeepEquals, tc.screens)
+		c.Assert(labels, DeepEquals, tc.labels)
+		c.Assert(problems, DeepEquals, tc.problems)
+
+		// strict by default
+		c.Assert(NewResourceParser().Parse(form, &Resource{Content: tc.rows}, "it"), FitsTypeOf, &ParseError{})
+	}
+
+	// re-sync needs more rows than allowed
+	p := NewResourceParser()
+	p.SetResync(2)
+	err := p.Parse(form, &Resource{Content: []map[string]string{name, cc, d, cc, d, cc, d, a, b}}, "it")
+	c.Assert(err, FitsTypeOf, &ParseError{})
+	c.Assert(err, ErrorMatches, `forms/form \(it\) row 6: 4 unexpected rows`)
+	c.Assert(p.forms["it"], HasLen, 0)
+}
+
+func (CmpSuite) TestParseErrorTypes(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	item := &Item{ID: "item", Title: "Item", Body: "a"}
+	diff.AddItem(item)
+	diff.AddChecks(Check{Text: "One"}, Check{Text: "Two"})
+
+	for _, tc := range []struct {
+		cmp      Component
+		rows     []map[string]string
+		expected error
+		message  string
+	}{
+		{cat, nil, &ContentMismatchError{Path: "cat", Kind: "category", Locale: "it", Unit: "rows", Expected: 1}, "Invalid content"},
+		{cat.Sub("sub"), []map[string]string{{"name": "A"}, {"name": "B"}},
+			&ContentMismatchError{Path: "cat/sub", Kind: "subcategory", Locale: "it", Unit: "rows", Expected: 1, Got: 2}, "Invalid content"},
+		{diff, nil, &ContentMismatchError{Path: "cat/sub/beginner", Kind: "difficulty", Locale: "it", Unit: "rows", Expected: 1}, "Invalid content"},
+		{item, nil, &ContentMismatchError{Path: "cat/sub/beginner/item", Kind: "item", Locale: "it", Unit: "rows", Expected: 1, AtLeast: true}, "Invalid content"},
+		{diff.Checks(), []map[string]string{{"text": "Uno"}},
+			&ContentMismatchError{Path: "cat/sub/beginner/.checks", Kind: "checklist", Locale: "it", Unit: "checks", Expected: 2, Got: 1}, `cat/sub/beginner/\.checks \(it\): 1 checks, 2 expected`},
+		{item, []map[string]string{{"title": "Voce", "body": "Testo"}, {"body": "Altro"}},
+			&LegacyFormatError{Path: "cat/sub/beginner/item", Locale: "it", Rows: 2}, `Invalid Legacy "beginner" \(it\)`},
+	} {
+		err := NewResourceParser().Parse(tc.cmp, &Resource{Content: tc.rows}, "it")
+		c.Assert(err, DeepEquals, tc.expected)
+		c.Assert(err, ErrorMatches, tc.message)
+		c.Assert(errors.Is(err, ErrContent), Equals, true)
+		_, legacy := err.(*LegacyFormatError)
+		c.Assert(errors.Is(err, ErrRowCountMismatch), Equals, !legacy)
+	}
+
+	// forms tell the screen and input expected
+	form := errorForm()
+	for _, tc := range []struct {
+		rows          []map[string]string
+		screen, input int
+		cause         error
+	}{
+		{[]map[string]string{{"form": "Modulo"}, {"label": "A", "options": "x;y"}}, 1, 0, ErrUnexpectedInput},
+		{[]map[string]string{{"form": "Modulo"}, {"screen": "Uno"}, {"label": "A", "options": "x;y"}, {"screen": "Due"}}, 1, 2, ErrUnexpectedScreen},
+		{[]map[string]string{{"form": "Modulo"}, {"screen": "Uno"}}, 1, 1, ErrRowCountMismatch},
+		{[]map[string]string{{"form": "Modulo"}, {"screen": "Uno"}, {"label": "A", "options": "x;y"}, {"label": "B", "hint": "H"},
+			{"screen": "Due"}, {"label": "C"}, {"label": "D"}, {"label": "E"}}, 0, 0, ErrRowCountMismatch},
+		{[]map[string]string{{"form": "Modulo"}, {"screen": "Uno"}, {"label": "A", "options": "x"}, {"label": "B", "hint": "H"},
+			{"screen": "Due"}, {"label": "C"}, {"label": "D"}}, 1, 1, ErrOptionsMismatch},
+	} {
+		var p *ParseError
+		err := NewResourceParser().Parse(form, &Resource{Content: tc.rows}, "it")
+		c.Assert(errors.As(err, &p), Equals, true)
+		c.Assert([]int{p.Screen, p.Input}, DeepEquals, []int{tc.screen, tc.input})
+		c.Assert(p.Kind, Equals, "form")
+		c.Assert(errors.Is(err, tc.cause), Equals, true, Commentf("%v", err))
+	}
+}
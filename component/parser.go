@@ -1,7 +1,9 @@
 package component
 
 import (
+	"fmt"
 	"io"
+	"io/fs"
 	"sort"
 	"strings"
 
@@ -10,32 +12,214 @@ import (
 
 // Parser is an helper, creates a tree from the repo
 type Parser struct {
-	index      map[[2]string]int
+	index      map[[2]string]*Category // by ID and locale
+	implicit   map[*Subcategory]bool   // created for a difficulty before their own file
 	categories []*Category
 	assets     []*Asset
 	forms      []*Form
+	glossaries []*Glossary
+	failed     map[string]error
+	partial    bool // skip the locales that fail, see ParsePartial
+	problems   []Problem
 }
 
-// Parse executes the parsing on a repo
+// LoadReport tells which locales were loaded by a partial parse and which were skipped
+type LoadReport struct {
+	Loaded []string
+	Failed map[string]error // error that caused the locale to be skipped
+}
+
+// Parse executes the parsing on a repo, it fails at the first file that cannot be parsed
 func (p *Parser) Parse(t *object.Tree) error {
-	p.index = make(map[[2]string]int)
-	p.categories = make([]*Category, 0)
+	p.reset(false)
+	return p.parseTree(t)
+}
+
+// ParsePartial executes the parsing on a repo like Parse, but a file that cannot be parsed
+// skips its locale only. The report lists the locales loaded and the skipped ones; it fails
+// if a file outside of the locales is invalid or if no locale could be loaded.
+func (p *Parser) ParsePartial(t *object.Tree) (LoadReport, error) {
+	p.reset(true)
+	err := p.parseTree(t)
+	return p.report(), err
+}
+
+func (p *Parser) parseTree(t *object.Tree) error {
 	if err := p.parse(t, filterCat); err != nil {
 		return err
 	}
 	if err := p.parse(t, filterRes); err != nil {
 		return err
 	}
+	return p.finish()
+}
+
+// ParseFS executes the parsing on a file system with the same layout of the repo, like Parse
+func (p *Parser) ParseFS(fsys fs.FS) error {
+	p.reset(false)
+	return p.parseFS(fsys)
+}
+
+// ParseFSPartial executes the parsing on a file system like ParseFS, skipping the locales
+// that cannot be parsed like ParsePartial
+func (p *Parser) ParseFSPartial(fsys fs.FS) (LoadReport, error) {
+	p.reset(true)
+	err := p.parseFS(fsys)
+	return p.report(), err
+}
+
+func (p *Parser) parseFS(fsys fs.FS) error {
+	for _, fn := range []func(string) bool{filterCat, filterRes} {
+		err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !fn(name) {
+				return err
+			}
+			return p.load(name, func() error {
+				b, err := fs.ReadFile(fsys, name)
+				if err != nil {
+					return parseError{name, "read", err}
+				}
+				return p.parseContents(name, string(b), isImage(name))
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return p.finish()
+}
+
+func (p *Parser) finish() error {
+	p.checkSlugs()
+	if !p.partial && len(p.failed) != 0 {
+		return p.firstFailed()
+	}
+	if err := p.prune(); err != nil {
+		return err
+	}
+	p.sort()
+	return nil
+}
+
+// Problems returns the warnings of the last parse, like unterminated notes, that don't stop
+// the parsing of the file
+func (p *Parser) Problems() []Problem { return p.problems }
+
+func (p *Parser) report() LoadReport {
+	var (
+		r    = LoadReport{Failed: make(map[string]error)}
+		seen = make(map[string]bool)
+	)
+	for l, err := range p.failed {
+		r.Failed[l] = err
+	}
+	for _, c := range p.categories {
+		seen[c.Locale] = true
+	}
+	for _, f := range p.forms {
+		seen[f.Locale] = true
+	}
+	for _, g := range p.glossaries {
+		seen[g.Locale] = true
+	}
+	for l := range seen {
+		r.Loaded = append(r.Loaded, l)
+	}
+	sort.Strings(r.Loaded)
+	return r
+}
+
+func (p *Parser) reset(partial bool) {
+	p.partial = partial
+	p.index = make(map[[2]string]*Category)
+	p.implicit = make(map[*Subcategory]bool)
+	p.categories = make([]*Category, 0)
+	p.assets, p.forms, p.glossaries = nil, nil, nil
+	p.failed = make(map[string]error)
+	p.problems = nil
+}
+
+// load calls parse for the file. In a partial parse, if it fails the locale of the file is
+// marked as failed and its other files are ignored; errors of files without a locale, and
+// all errors of a strict parse, are returned.
+func (p *Parser) load(name string, parse func() error) error {
+	locale := fileLocale(name)
+	if _, ok := p.failed[locale]; ok {
+		return nil
+	}
+	err := parse()
+	if err == nil || locale == "" || !p.partial {
+		return err
+	}
+	p.failed[locale] = err
+	return nil
+}
+
+// prune removes the components of the failed locales, it returns the error of
+// the first failed locale if there is nothing left.
+func (p *Parser) prune() error {
+	if len(p.failed) == 0 {
+		return nil
+	}
+	for _, c := range append([]*Category(nil), p.categories...) {
+		if _, ok := p.failed[c.Locale]; ok {
+			p.removeCat(c.ID, c.Locale)
+		}
+	}
+	var forms []*Form
+	for _, f := range p.forms {
+		if _, ok := p.failed[f.Locale]; !ok {
+			forms = append(forms, f)
+		}
+	}
+	p.forms = forms
+	var glossaries []*Glossary
+	for _, g := range p.glossaries {
+		if _, ok := p.failed[g.Locale]; !ok {
+			glossaries = append(glossaries, g)
+		}
+	}
+	p.glossaries = glossaries
+	if len(p.categories) != 0 || len(forms) != 0 || len(glossaries) != 0 {
+		return nil
+	}
+	return p.firstFailed()
+}
+
+// firstFailed returns the error of the first failed locale, in alphabetical order
+func (p *Parser) firstFailed() error {
+	var locales []string
+	for l := range p.failed {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return p.failed[locales[0]]
+}
+
+// fileLocale returns the locale of a content, form or glossary file, empty for other files
+func fileLocale(name string) string {
+	dir := strings.SplitN(name, "/", 2)[0]
+	for _, prefix := range []string{"contents_", "forms_", "glossary_"} {
+		if strings.HasPrefix(dir, prefix) {
+			return dir[len(prefix):]
+		}
+	}
+	return ""
+}
+
+func (p *Parser) sort() {
 	sort.Sort(catSorter(p.categories))
 	for i := range p.categories {
 		sort.Sort(subSorter(p.categories[i].subcategories))
+		p.categories[i].reindex()
 		for j := range p.categories[i].subcategories {
 			for k := range p.categories[i].subcategories[j].difficulties {
-				sort.Sort(itemSorter(p.categories[i].subcategories[j].difficulties[k].items))
+				d := p.categories[i].subcategories[j].difficulties[k]
+				sort.Sort(itemSorter(d.items))
+				d.reindex()
 			}
 		}
 	}
-	return nil
 }
 
 func (p *Parser) parse(t *object.Tree, fn func(name string) bool) error {
@@ -50,7 +234,7 @@ func (p *Parser) parse(t *object.Tree, fn func(name string) bool) error {
 		if !fn(f.Name) {
 			continue
 		}
-		if err := p.parseFile(f); err != nil {
+		if err := p.load(f.Name, func() error { return p.parseFile(f) }); err != nil {
 			return err
 		}
 	}
@@ -62,18 +246,26 @@ func (p *Parser) parseFile(f *object.File) error {
 	if err != nil {
 		return parseError{f.Name, "read", err}
 	}
-	if ok, _ := f.IsBinary(); !ok {
+	binary, _ := f.IsBinary()
+	return p.parseContents(f.Name, contents, binary)
+}
+
+func (p *Parser) parseContents(name, contents string, binary bool) error {
+	if !binary {
 		contents = strings.Replace(strings.TrimSpace(contents), "\r\n", "\n", -1)
+		if checkNotes(contents) != nil {
+			p.problems = append(p.problems, Problem{Path: name, Locale: fileLocale(name), Message: "unterminated note"})
+		}
 	}
-	cmp, err := newCmp(f.Name)
+	cmp, err := newCmp(name)
 	if err != nil {
-		return parseError{f.Name, "cmp", err}
+		return parseError{name, "cmp", err}
 	}
-	if err := p.setPath(f.Name, cmp); err != nil {
+	if err := p.setPath(name, cmp); err != nil {
 		return err
 	}
 	if err := cmp.SetContents(contents); err != nil {
-		return parseError{f.Name, "contents", err}
+		return parseError{name, "contents", err}
 	}
 	return nil
 }
@@ -89,32 +281,48 @@ func (p *Parser) setPath(name string, cmp Component) error {
 	case *Form:
 		p.forms = append(p.forms, c)
 		return nil
+	case *Glossary:
+		p.glossaries = append(p.glossaries, c)
+		return nil
 	}
 
 	parts := strings.Split(name, "/")
-	idx := [2]string{parts[1], name[9:11]}
+	locale := name[9:11]
+	duplicate := func(path string) error {
+		return parseError{name, "path", &DuplicateError{Path: path, Locale: locale}}
+	}
 	if cat, ok := cmp.(*Category); ok {
-		p.index[idx] = len(p.categories)
-		p.categories = append(p.categories, cat)
+		if !p.addCat(cat) {
+			return duplicate(cat.ID)
+		}
 		return nil
 	}
-	cat := p.categories[p.index[idx]]
+	cat := p.getCat(parts[1], locale)
 	if cat == nil {
 		return parseError{name, "path", "Invalid cat"}
 	}
 
 	if sub, ok := cmp.(*Subcategory); ok {
-		cat.Add(sub)
+		if old := cat.Sub(sub.ID); old != nil && p.implicit[old] {
+			p.adoptSub(old, sub)
+			return nil
+		}
+		if cat.Add(sub) != nil {
+			return duplicate(cat.ID + "/" + sub.ID)
+		}
 		return nil
 	}
 	sub := cat.Sub(parts[2])
 	if sub == nil {
 		sub = &Subcategory{ID: parts[2]}
 		cat.Add(sub)
+		p.implicit[sub] = true
 	}
 
 	if dif, ok := cmp.(*Difficulty); ok {
-		sub.AddDifficulty(dif)
+		if sub.AddDifficulty(dif) != nil {
+			return duplicate(treePath(sub) + "/" + dif.ID)
+		}
 		return nil
 	}
 	dif := sub.Difficulty(parts[3])
@@ -125,15 +333,102 @@ func (p *Parser) setPath(name string, cmp Component) error {
 
 	switch c := cmp.(type) {
 	case *Item:
-		dif.AddItem(c)
+		if dif.AddItem(c) != nil {
+			return duplicate(treePath(dif) + "/" + c.ID)
+		}
 	case *Checklist:
 		dif.SetChecks(c)
+	case *Quiz:
+		dif.SetQuiz(c)
 	default:
 		return parseError{name, "type", "Invalid Path"}
 	}
 	return nil
 }
 
+// adoptSub replaces a subcategory created for its difficulties with the one of its file,
+// that takes its position and its difficulties
+func (p *Parser) adoptSub(old, sub *Subcategory) {
+	cat := old.parent
+	for i := range cat.subcategories {
+		if cat.subcategories[i] == old {
+			cat.subcategories[i] = sub
+		}
+	}
+	cat.reindex()
+	sub.parent = cat
+	sub.AddDifficulty(old.difficulties...)
+	delete(p.implicit, old)
+}
+
+// addCat adds the category, unless one with the same ID and locale exists
+func (p *Parser) addCat(cat *Category) bool {
+	key := [2]string{cat.ID, cat.Locale}
+	if _, ok := p.index[key]; ok {
+		return false
+	}
+	p.index[key] = cat
+	p.categories = append(p.categories, cat)
+	return true
+}
+
+func (p *Parser) getCat(id, locale string) *Category { return p.index[[2]string{id, locale}] }
+
+// removeCat removes the category with its descendants, it tells if it was found
+func (p *Parser) removeCat(id, locale string) bool {
+	cat := p.getCat(id, locale)
+	if cat == nil {
+		return false
+	}
+	delete(p.index, [2]string{id, locale})
+	for i := range p.categories {
+		if p.categories[i] == cat {
+			p.categories = append(p.categories[:i], p.categories[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// CheckInvariants verifies that the category index and list agree and that every component
+// has the right parent, it returns the first inconsistency found
+func (p *Parser) CheckInvariants() error {
+	if len(p.index) != len(p.categories) {
+		return fmt.Errorf("%d categories, %d in the index", len(p.categories), len(p.index))
+	}
+	for _, cat := range p.categories {
+		if p.index[[2]string{cat.ID, cat.Locale}] != cat {
+			return fmt.Errorf("category %s (%s) not in the index", cat.ID, cat.Locale)
+		}
+		// paths are built from the IDs, as the parents are not reliable
+		wrong := func(path ...string) error {
+			return fmt.Errorf("wrong parent for %s (%s)", strings.Join(path, "/"), cat.Locale)
+		}
+		for _, sub := range cat.subcategories {
+			if sub.parent != cat {
+				return wrong(cat.ID, sub.ID)
+			}
+			for _, diff := range sub.difficulties {
+				if diff.parent != sub {
+					return wrong(cat.ID, sub.ID, diff.ID)
+				}
+				for _, item := range diff.items {
+					if item.parent != diff {
+						return wrong(cat.ID, sub.ID, diff.ID, item.ID)
+					}
+				}
+				if diff.checklist != nil && diff.checklist.parent != diff {
+					return wrong(cat.ID, sub.ID, diff.ID, suffixChecks)
+				}
+				if diff.quiz != nil && diff.quiz.parent != diff {
+					return wrong(cat.ID, sub.ID, diff.ID, suffixQuiz)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (p *Parser) Categories() map[string][]*Category {
 	var res = make(map[string][]*Category)
 	for _, cat := range p.categories {
@@ -149,3 +444,35 @@ func (p *Parser) Assets() []*Asset {
 func (p *Parser) Forms() []*Form {
 	return p.forms
 }
+
+func (p *Parser) Glossaries() []*Glossary {
+	return p.glossaries
+}
+
+// ResourceParser returns a parser with the content of the locales, or of every locale if none
+// is given, to export, compare or count it. The components are the ones of p, not copies.
+func (p *Parser) ResourceParser(locales ...string) *ResourceParser {
+	var (
+		r    = NewResourceParser()
+		keep = make(map[string]bool, len(locales))
+	)
+	for _, l := range locales {
+		keep[l] = true
+	}
+	for _, cat := range p.categories {
+		if len(keep) == 0 || keep[cat.Locale] {
+			r.categories[cat.Locale] = append(r.categories[cat.Locale], cat)
+		}
+	}
+	for _, f := range p.forms {
+		if len(keep) == 0 || keep[f.Locale] {
+			r.forms[f.Locale] = append(r.forms[f.Locale], f)
+		}
+	}
+	for _, g := range p.glossaries {
+		if len(keep) == 0 || keep[g.Locale] {
+			r.glossaries[g.Locale] = append(r.glossaries[g.Locale], g)
+		}
+	}
+	return r
+}
@@ -0,0 +1,108 @@
+package component
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing/fstest"
+
+	. "gopkg.in/check.v1"
+)
+
+func mutationCategory(r *rand.Rand, id, locale string) *Category {
+	cat := &Category{ID: id, Locale: locale, Name: id, Order: float64(r.Intn(10))}
+	for i := r.Intn(3); i > 0; i-- {
+		sub := &Subcategory{ID: fmt.Sprint("sub", i)}
+		cat.Add(sub)
+		diff := &Difficulty{ID: "beginner"}
+		sub.AddDifficulty(diff)
+		diff.AddItem(&Item{ID: "item"})
+		diff.SetChecks(&Checklist{})
+	}
+	return cat
+}
+
+// TestParserMutations applies random additions, removals and sorts, checking
+// the invariants after every step
+func (CmpSuite) TestParserMutations(c *C) {
+	var (
+		p     Parser
+		r     = rand.New(rand.NewSource(42))
+		model = make(map[[2]string]bool)
+	)
+	p.reset(false)
+	for step := 0; step < 1000; step++ {
+		id, locale := fmt.Sprint("cat", r.Intn(8)), []string{"en", "it", "es"}[r.Intn(3)]
+		key := [2]string{id, locale}
+		switch op := r.Intn(3); op {
+		case 0:
+			c.Assert(p.addCat(mutationCategory(r, id, locale)), Equals, !model[key])
+			model[key] = true
+		case 1:
+			c.Assert(p.removeCat(id, locale), Equals, model[key])
+			delete(model, key)
+		case 2:
+			p.sort()
+		}
+		c.Assert(p.CheckInvariants(), IsNil, Commentf("step %d", step))
+
+		var keys, expected []string
+		for _, cat := range p.categories {
+			keys = append(keys, cat.Locale+"/"+cat.ID)
+		}
+		for k := range model {
+			expected = append(expected, k[1]+"/"+k[0])
+		}
+		sort.Strings(keys)
+		sort.Strings(expected)
+		c.Assert(keys, DeepEquals, expected, Commentf("step %d", step))
+	}
+}
+
+func (CmpSuite) TestParserCheckInvariants(c *C) {
+	var p Parser
+	p.reset(false)
+	r := rand.New(rand.NewSource(1))
+	p.addCat(mutationCategory(r, "a", "en"))
+	b := &Category{ID: "b", Locale: "en"}
+	p.addCat(b)
+	c.Assert(p.CheckInvariants(), IsNil)
+
+	p.categories = p.categories[1:]
+	c.Assert(p.CheckInvariants(), ErrorMatches, "1 categories, 2 in the index")
+	p.index[[2]string{"a", "en"}] = b
+	p.categories = append(p.categories, &Category{ID: "a", Locale: "en"})
+	c.Assert(p.CheckInvariants(), ErrorMatches, `category a \(en\) not in the index`)
+
+	p.reset(false)
+	cat := &Category{ID: "a", Locale: "en"}
+	sub := &Subcategory{ID: "sub"}
+	cat.Add(sub)
+	sub.AddDifficulty(&Difficulty{ID: "beginner"})
+	p.addCat(cat)
+	sub.difficulties[0].parent = &Subcategory{ID: "other"}
+	c.Assert(p.CheckInvariants(), ErrorMatches, `wrong parent for a/sub/beginner \(en\)`)
+}
+
+func (CmpSuite) TestParserResourceParser(c *C) {
+	var p Parser
+	c.Assert(p.ParseFS(fstest.MapFS{
+		"contents_en/cat/.metadata.md":              {Data: []byte("[Name]: # (Category)\n[Order]: # (1)")},
+		"contents_en/cat/sub/.metadata.md":          {Data: []byte("[Name]: # (Sub)\n[Order]: # (1)")},
+		"contents_en/cat/sub/beginner/.metadata.md": {Data: []byte("[Description]: # (Easy)")},
+		"contents_it/cat/.metadata.md":              {Data: []byte("[Name]: # (Categoria)\n[Order]: # (1)")},
+		"forms_en/form.md":                          {Data: []byte("[Name]: # (Form)")},
+		"glossary_it/terms.md":                      {Data: []byte(glossaryContents)},
+	}), IsNil)
+
+	r := p.ResourceParser()
+	c.Assert(r.Locales(), DeepEquals, []string{"en", "it"})
+	c.Assert(r.category("cat", "en").Sub("sub").Difficulty("beginner").Descr, Equals, "Easy")
+	c.Assert(r.Form("form", "en").Name, Equals, "Form")
+	c.Assert(r.glossaries["it"], HasLen, 1)
+
+	r = p.ResourceParser("it")
+	c.Assert(r.Locales(), DeepEquals, []string{"it"})
+	c.Assert(r.category("cat", "it").Name, Equals, "Categoria")
+	c.Assert(r.forms["en"], HasLen, 0)
+}
@@ -0,0 +1,219 @@
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Completion is the progress of a user in a part of the content: the checks done and the
+// ones to do, informational steps excluded
+type Completion struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Percent returns the percentage of checks done, 100 if there is nothing to check
+func (c Completion) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return 100 * float64(c.Done) / float64(c.Total)
+}
+
+func (c *Completion) add(o Completion) { c.Done, c.Total = c.Done+o.Done, c.Total+o.Total }
+
+// Progress keeps the checks done by each user in the checklists of a locale of the parser.
+// A check is the index, from 0, of a step of the checklist of a difficulty, identified by its
+// path, like cat/sub/beginner. Marks that don't match the content anymore, after an update,
+// are kept but not counted. It's safe for concurrent use, as long as the parser doesn't change:
+// the lookups of the checklists only read it.
+type Progress struct {
+	mu     sync.RWMutex
+	r      *ResourceParser
+	locale string
+	done   map[string]map[string]map[int]bool // by user and difficulty
+}
+
+// NewProgress returns the progress of the checklists of the locale
+func NewProgress(r *ResourceParser, locale string) *Progress {
+	return &Progress{r: r, locale: locale, done: make(map[string]map[string]map[int]bool)}
+}
+
+// checks returns the checks of the difficulty at the path
+func (p *Progress) checks(path string) ([]Check, error) {
+	d, ok := p.r.lookup(p.locale, path).(*Difficulty)
+	if !ok {
+		return nil, &NotFoundError{Path: path, Locale: p.locale}
+	}
+	if d.checklist == nil {
+		return nil, nil
+	}
+	return d.checklist.Checks, nil
+}
+
+// MarkDone marks the check of the difficulty done by the user. It fails if the check doesn't
+// exist or is an informational step.
+func (p *Progress) MarkDone(user, difficultyPath string, checkIndex int) error {
+	checks, err := p.checks(difficultyPath)
+	if err != nil {
+		return err
+	}
+	switch {
+	case checkIndex < 0 || checkIndex >= len(checks):
+		return fmt.Errorf("Check %d of %s: %d checks", checkIndex, difficultyPath, len(checks))
+	case checks[checkIndex].NoCheck:
+		return fmt.Errorf("Check %d of %s is informational", checkIndex, difficultyPath)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	diffs, ok := p.done[user]
+	if !ok {
+		diffs = make(map[string]map[int]bool)
+		p.done[user] = diffs
+	}
+	if diffs[difficultyPath] == nil {
+		diffs[difficultyPath] = make(map[int]bool)
+	}
+	diffs[difficultyPath][checkIndex] = true
+	return nil
+}
+
+// Unmark marks the check of the difficulty not done by the user
+func (p *Progress) Unmark(user, difficultyPath string, checkIndex int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.done[user][difficultyPath], checkIndex)
+	if len(p.done[user][difficultyPath]) == 0 {
+		delete(p.done[user], difficultyPath)
+	}
+	if len(p.done[user]) == 0 {
+		delete(p.done, user)
+	}
+}
+
+// Reset removes the progress of the user
+func (p *Progress) Reset(user string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.done, user)
+}
+
+// Completion returns the progress of the user in the category, subcategory or difficulty at
+// the path
+func (p *Progress) Completion(user, path string) (Completion, error) {
+	c := p.r.lookup(p.locale, path)
+	switch c.(type) {
+	case *Category, *Subcategory, *Difficulty:
+	default:
+		return Completion{}, &NotFoundError{Path: path, Locale: p.locale}
+	}
+	var total Completion
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	p.walk(user, c, func(path string, c Completion) { total.add(c) })
+	return total, nil
+}
+
+// Report returns the progress of the user in every category, subcategory and difficulty of
+// the locale, by path
+func (p *Progress) Report(user string) map[string]Completion {
+	var m = make(map[string]Completion)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, cat := range p.r.categories[p.locale] {
+		p.walk(user, cat, func(path string, c Completion) {
+			for {
+				v := m[path]
+				v.add(c)
+				m[path] = v
+				i := strings.LastIndex(path, "/")
+				if i < 0 {
+					break
+				}
+				path = path[:i]
+			}
+		})
+	}
+	return m
+}
+
+// walk calls fn with the progress of the user in each difficulty of the component
+func (p *Progress) walk(user string, c Component, fn func(path string, c Completion)) {
+	var diffs []*Difficulty
+	switch v := c.(type) {
+	case *Category:
+		for _, sub := range v.subcategories {
+			diffs = append(diffs, sub.difficulties...)
+		}
+	case *Subcategory:
+		diffs = v.difficulties
+	case *Difficulty:
+		diffs = []*Difficulty{v}
+	}
+	for _, d := range diffs {
+		path := treePath(d)
+		var c Completion
+		if d.checklist != nil {
+			for i, check := range d.checklist.Checks {
+				if check.NoCheck {
+					continue
+				}
+				c.Total++
+				if p.done[user][path][i] {
+					c.Done++
+				}
+			}
+		}
+		fn(path, c)
+	}
+}
+
+// progressJSON is the JSON of Progress: the checks done, sorted, by user and difficulty
+type progressJSON struct {
+	Locale string                      `json:"locale"`
+	Users  map[string]map[string][]int `json:"users"`
+}
+
+func (p *Progress) MarshalJSON() ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var v = progressJSON{Locale: p.locale, Users: make(map[string]map[string][]int, len(p.done))}
+	for user, diffs := range p.done {
+		v.Users[user] = make(map[string][]int, len(diffs))
+		for path, checks := range diffs {
+			var list = make([]int, 0, len(checks))
+			for i := range checks {
+				list = append(list, i)
+			}
+			sort.Ints(list)
+			v.Users[user][path] = list
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON replaces the progress with the one encoded by MarshalJSON. The parser stays
+// the same, and the locale is the one encoded.
+func (p *Progress) UnmarshalJSON(b []byte) error {
+	var v progressJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	var done = make(map[string]map[string]map[int]bool, len(v.Users))
+	for user, diffs := range v.Users {
+		done[user] = make(map[string]map[int]bool, len(diffs))
+		for path, checks := range diffs {
+			done[user][path] = make(map[int]bool, len(checks))
+			for _, i := range checks {
+				done[user][path][i] = true
+			}
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.locale, p.done = v.Locale, done
+	return nil
+}
@@ -0,0 +1,88 @@
+package component
+
+import (
+	"encoding/json"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestProgress(c *C) {
+	p := bilingualParser()
+	sub := p.categories["en"][0].Sub("sub")
+	sub.Difficulty("beginner").AddChecks(Check{Text: "Read", NoCheck: true}, Check{Text: "Second"})
+	sub.AddDifficulty(&Difficulty{ID: "advanced"})
+	sub.Difficulty("advanced").AddChecks(Check{Text: "Third"})
+
+	pr := NewProgress(p, "en")
+	c.Assert(pr.MarkDone("ann", "cat/sub/beginner", 0), IsNil)
+	c.Assert(pr.MarkDone("ann", "cat/sub/beginner", 0), IsNil)
+	c.Assert(pr.MarkDone("ann", "cat/sub/beginner", 1), ErrorMatches, "Check 1 of cat/sub/beginner is informational")
+	c.Assert(pr.MarkDone("ann", "cat/sub/beginner", 3), ErrorMatches, "Check 3 of cat/sub/beginner: 3 checks")
+	c.Assert(pr.MarkDone("ann", "cat/sub", 0), ErrorMatches, "cat/sub not found \\(en\\)")
+	c.Assert(pr.MarkDone("bob", "cat/sub/advanced", 0), IsNil)
+
+	comp, err := pr.Completion("ann", "cat/sub/beginner")
+	c.Assert(err, IsNil)
+	c.Assert(comp, Equals, Completion{Done: 1, Total: 2})
+	c.Assert(comp.Percent(), Equals, 50.0)
+	comp, err = pr.Completion("ann", "cat")
+	c.Assert(err, IsNil)
+	c.Assert(comp, Equals, Completion{Done: 1, Total: 3})
+	_, err = pr.Completion("ann", "cat/sub/beginner/item")
+	c.Assert(err, NotNil)
+	c.Assert(Completion{}.Percent(), Equals, 100.0)
+
+	c.Assert(pr.Report("bob"), DeepEquals, map[string]Completion{
+		"cat":              {Done: 1, Total: 3},
+		"cat/sub":          {Done: 1, Total: 3},
+		"cat/sub/beginner": {Done: 0, Total: 2},
+		"cat/sub/advanced": {Done: 1, Total: 1},
+	})
+
+	b, err := json.Marshal(pr)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"locale":"en","users":{"ann":{"cat/sub/beginner":[0]},"bob":{"cat/sub/advanced":[0]}}}`)
+	loaded := NewProgress(p, "it")
+	c.Assert(json.Unmarshal(b, loaded), IsNil)
+	c.Assert(loaded.Report("ann"), DeepEquals, pr.Report("ann"))
+
+	pr.Unmark("ann", "cat/sub/beginner", 0)
+	pr.Reset("bob")
+	b, err = json.Marshal(pr)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, `{"locale":"en","users":{}}`)
+}
+
+// TestProgressConcurrent is meant for go test -race: the lookups of MarkDone and Completion
+// only read the parser, even after a removal leaves its indexes to rebuild
+func (CmpSuite) TestProgressConcurrent(c *C) {
+	src := bilingualParser()
+	src.categories["en"][0].Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "One"}, Check{Text: "Two"})
+	b, err := src.MarshalLocale("en")
+	c.Assert(err, IsNil)
+	p := NewResourceParser()
+	c.Assert(p.UnmarshalLocale(b), IsNil)
+	c.Assert(p.Remove(p.lookup("en", "cat/sub/beginner/other"), "en"), IsNil)
+
+	pr := NewProgress(p, "en")
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			errs <- pr.MarkDone("user", "cat/sub/beginner", n%2)
+			_, err := pr.Completion("user", "cat")
+			errs <- err
+		}(n)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		c.Assert(err, IsNil)
+	}
+	comp, err := pr.Completion("user", "cat/sub/beginner")
+	c.Assert(err, IsNil)
+	c.Assert(comp.Done, Equals, 2)
+}
@@ -0,0 +1,160 @@
+package component
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PruneResult lists the components found by PruneOrphans
+type PruneResult struct {
+	Removed []ItemRef `json:"removed"` // every removed component, descendants included
+	Moved   []ItemRef `json:"moved"`   // components moved to their renamed path, see Rename
+}
+
+// AllowDivergence makes PruneOrphans skip the locales, that can have content missing in the base
+func AllowDivergence(locales ...string) Option {
+	return func(o *options) { o.divergent = append(o.divergent, locales...) }
+}
+
+// PruneOrphans finds the components of the other locales whose path does not exist in the base
+// locale, and removes them with their descendants. An orphan renamed in the base by Rename is
+// moved to the new path instead, unless the locale already has it. With dryRun nothing is changed.
+func (r *ResourceParser) PruneOrphans(baseLocale string, dryRun bool, opts ...Option) (*PruneResult, error) {
+	if r.emptyLocale(baseLocale) {
+		return nil, fmt.Errorf("No content for base locale %q", baseLocale)
+	}
+	var (
+		o         = newOptions(opts)
+		divergent = make(map[string]bool)
+		res       PruneResult
+	)
+	for _, l := range o.divergent {
+		divergent[l] = true
+	}
+	for _, l := range r.Locales(opts...) {
+		if l == baseLocale || divergent[l] {
+			continue
+		}
+		var orphans []Component
+		for _, c := range r.localeComponents(l) {
+			p := treePath(c)
+			if r.lookup(baseLocale, p) != nil || underOrphan(orphans, p) {
+				continue
+			}
+			orphans = append(orphans, c)
+		}
+		for _, c := range orphans {
+			p := treePath(c)
+			if n := r.renamedPath(p); n != p && path.Dir(n) == path.Dir(p) &&
+				r.lookup(baseLocale, n) != nil && r.lookup(l, n) == nil {
+				res.Moved = append(res.Moved, ItemRef{l, n})
+				if !dryRun {
+					setID(c, path.Base(n))
+					movePending(r.pending[l], p, n)
+				}
+				continue
+			}
+			for _, d := range r.localeComponents(l) {
+				if dp := treePath(d); dp == p || strings.HasPrefix(dp, p+"/") {
+					res.Removed = append(res.Removed, ItemRef{l, dp})
+				}
+			}
+			if !dryRun {
+				r.remove(l, c)
+			}
+		}
+	}
+	return &res, nil
+}
+
+// underOrphan tells if the path is a descendant of one of the orphans
+func underOrphan(orphans []Component, p string) bool {
+	for _, o := range orphans {
+		if strings.HasPrefix(p, treePath(o)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// localeComponents returns the components of the locale, in canonical order
+func (r *ResourceParser) localeComponents(locale string) []Component {
+	var list []Component
+	for _, cat := range r.categories[locale] {
+		walkCategory(cat, func(c Component) { list = append(list, c) })
+	}
+	for _, f := range r.forms[locale] {
+		list = append(list, f)
+	}
+	return list
+}
+
+// remove deletes the component of the locale from its parent, and the pending translations
+// of the component and its descendants
+func (r *ResourceParser) remove(locale string, c Component) {
+	p := treePath(c)
+	r.notify(p, locale, ChangeRemoved)
+	for pp := range r.pending[locale] {
+		if pp == p || strings.HasPrefix(pp, p+"/") {
+			delete(r.pending[locale], pp)
+		}
+	}
+	switch v := c.(type) {
+	case *Category:
+		list := r.categories[locale][:0]
+		for _, cat := range r.categories[locale] {
+			if cat != v {
+				list = append(list, cat)
+			}
+		}
+		r.categories[locale] = list
+	case *Subcategory:
+		list := v.parent.subcategories[:0]
+		for _, s := range v.parent.subcategories {
+			if s != v {
+				list = append(list, s)
+			}
+		}
+		v.parent.subcategories = list
+		v.parent.reindex()
+	case *Difficulty:
+		list := v.parent.difficulties[:0]
+		for _, d := range v.parent.difficulties {
+			if d != v {
+				list = append(list, d)
+			}
+		}
+		v.parent.difficulties = list
+		v.parent.reindex()
+	case *Item:
+		list := v.parent.items[:0]
+		for _, i := range v.parent.items {
+			if i != v {
+				list = append(list, i)
+			}
+		}
+		v.parent.items = list
+		v.parent.reindex()
+	case *Checklist:
+		v.parent.checklist = &Checklist{parent: v.parent}
+	case *Quiz:
+		v.parent.quiz = nil
+	case *Glossary:
+		list := r.glossaries[locale][:0]
+		for _, g := range r.glossaries[locale] {
+			if g != v {
+				list = append(list, g)
+			}
+		}
+		r.glossaries[locale] = list
+	case *Form:
+		list := r.forms[locale][:0]
+		for _, f := range r.forms[locale] {
+			if f != v {
+				list = append(list, f)
+			}
+		}
+		r.forms[locale] = list
+	}
+}
@@ -0,0 +1,91 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func orphanParser() *ResourceParser {
+	p := bilingualParser()
+	it := p.categories["it"][0]
+	it.Sub("sub").Difficulty("beginner").AddItem(&Item{ID: "gone", Title: "Andato"})
+	old := &Subcategory{ID: "old", Name: "Vecchia"}
+	it.Add(old)
+	diff := &Difficulty{ID: "beginner", Descr: "Facile"}
+	old.AddDifficulty(diff)
+	diff.AddItem(&Item{ID: "item", Title: "Voce"})
+	p.forms["it"] = []*Form{{ID: "form", Name: "Modulo", Locale: "it"}}
+	p.categories["sw"] = []*Category{testCategory("sw", "SW ")}
+	p.categories["sw"][0].ID = "legacy"
+	return p
+}
+
+func (CmpSuite) TestPruneOrphans(c *C) {
+	p := orphanParser()
+	removed := []ItemRef{
+		{"it", "cat/sub/beginner/gone"},
+		{"it", "cat/old"},
+		{"it", "cat/old/beginner"},
+		{"it", "cat/old/beginner/item"},
+		{"it", "forms/form"},
+		{"sw", "legacy"},
+		{"sw", "legacy/sub"},
+		{"sw", "legacy/sub/beginner"},
+	}
+
+	// dry run
+	res, err := p.PruneOrphans("en", true)
+	c.Assert(err, IsNil)
+	c.Assert(res.Removed, DeepEquals, removed)
+	c.Assert(res.Moved, HasLen, 0)
+	c.Assert(p.lookup("it", "cat/old/beginner/item"), NotNil)
+
+	// divergent locales are skipped
+	res, err = p.PruneOrphans("en", true, AllowDivergence("sw"))
+	c.Assert(err, IsNil)
+	c.Assert(res.Removed, DeepEquals, removed[:5])
+
+	res, err = p.PruneOrphans("en", false)
+	c.Assert(err, IsNil)
+	c.Assert(res.Removed, DeepEquals, removed)
+	for _, ref := range removed {
+		c.Assert(p.lookup(ref.Locale, ref.Path), IsNil)
+	}
+	c.Assert(p.lookup("it", "cat/sub/beginner/item"), NotNil)
+	c.Assert(p.lookup("it", "cat/sub/beginner/.checks"), NotNil)
+	c.Assert(p.Locales(), DeepEquals, []string{"en", "it"})
+
+	res, err = p.PruneOrphans("en", false)
+	c.Assert(err, IsNil)
+	c.Assert(res.Removed, HasLen, 0)
+
+	_, err = p.PruneOrphans("fr", true)
+	c.Assert(err, ErrorMatches, `No content for base locale "fr"`)
+}
+
+func (CmpSuite) TestPruneOrphansRenamed(c *C) {
+	p := bilingualParser()
+	_, err := p.Rename("cat/sub/beginner/item", "renamed")
+	c.Assert(err, IsNil)
+
+	// a locale added after the rename
+	fr := testCategory("fr", "FR ")
+	fr.Sub("sub").Difficulty("beginner").AddItem(&Item{ID: "item", Title: "Titre"}, &Item{ID: "other", Title: "Autre"})
+	p.categories["fr"] = []*Category{fr}
+	c.Assert(p.BootstrapLocale("en", "de"), IsNil)
+	p.categories["de"][0].Sub("sub").Difficulty("beginner").AddItem(&Item{ID: "item", Title: "Titel"})
+	p.pending["de"]["cat/sub/beginner/item"] = true
+
+	res, err := p.PruneOrphans("en", true)
+	c.Assert(err, IsNil)
+	c.Assert(res.Moved, DeepEquals, []ItemRef{{"fr", "cat/sub/beginner/renamed"}})
+	c.Assert(res.Removed, DeepEquals, []ItemRef{{"de", "cat/sub/beginner/item"}})
+	c.Assert(p.lookup("fr", "cat/sub/beginner/item"), NotNil)
+
+	_, err = p.PruneOrphans("en", false)
+	c.Assert(err, IsNil)
+	c.Assert(p.lookup("fr", "cat/sub/beginner/item"), IsNil)
+	c.Assert(p.lookup("fr", "cat/sub/beginner/renamed").(*Item).Title, Equals, "Titre")
+	c.Assert(p.lookup("de", "cat/sub/beginner/item"), IsNil)
+	c.Assert(p.NeedsTranslation("de", "cat/sub/beginner/item"), Equals, false)
+	c.Assert(p.NeedsTranslation("de", "cat/sub/beginner/renamed"), Equals, true)
+}
@@ -0,0 +1,87 @@
+package component
+
+import (
+	"encoding/json"
+
+	"github.com/securityfirst/tent/kvfile"
+)
+
+// QuarantineAfter is the number of consecutive failed runs that quarantine a component
+var QuarantineAfter = 3
+
+// QuarantineEntry is a component of a locale whose resource failed parsing
+type QuarantineEntry struct {
+	Path     string `json:"path"`
+	Locale   string `json:"locale"`
+	Hash     string `json:"hash"`     // hash of the request that failed
+	Failures int    `json:"failures"` // consecutive failed runs with the same hash
+	Error    string `json:"error"`    // last error
+}
+
+// A QuarantineStore keeps the components that failed parsing, see WithQuarantine
+type QuarantineStore interface {
+	Get(path, locale string) (QuarantineEntry, bool)
+	Put(e QuarantineEntry) error
+	Delete(path, locale string) error
+	List() []QuarantineEntry
+}
+
+// WithQuarantine makes ParseAll record the failures in q and skip the components that failed
+// QuarantineAfter consecutive runs, until their resource changes.
+func WithQuarantine(q QuarantineStore) Option {
+	return func(o *options) { o.quarantine = q }
+}
+
+// FileQuarantine is a QuarantineStore saved in a kvfile, every change is durable when it returns
+type FileQuarantine struct {
+	db *kvfile.DB
+}
+
+// OpenQuarantine loads the quarantine from the file, that is created when needed
+func OpenQuarantine(path string) (*FileQuarantine, error) {
+	db, err := kvfile.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileQuarantine{db: db}, nil
+}
+
+// Close closes the file
+func (q *FileQuarantine) Close() error { return q.db.Close() }
+
+func quarantineKey(path, locale string) string { return locale + "\x00" + path }
+
+func (q *FileQuarantine) Get(path, locale string) (QuarantineEntry, bool) {
+	var e QuarantineEntry
+	b, ok := q.db.Get(quarantineKey(path, locale))
+	if !ok || json.Unmarshal(b, &e) != nil {
+		return e, false
+	}
+	return e, true
+}
+
+func (q *FileQuarantine) Put(e QuarantineEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return q.db.Put(quarantineKey(e.Path, e.Locale), b)
+}
+
+// Delete removes the entry, clearing the quarantine of the component
+func (q *FileQuarantine) Delete(path, locale string) error {
+	return q.db.Delete(quarantineKey(path, locale))
+}
+
+// List returns the entries sorted by locale and path
+func (q *FileQuarantine) List() []QuarantineEntry {
+	var list = make([]QuarantineEntry, 0, q.db.Len())
+	q.db.Range(func(_ string, b []byte) bool {
+		var e QuarantineEntry
+		if json.Unmarshal(b, &e) == nil {
+			list = append(list, e)
+		}
+		return true
+	})
+	return list
+}
@@ -0,0 +1,65 @@
+package component
+
+import (
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestQuarantine(c *C) {
+	file := filepath.Join(c.MkDir(), "quarantine.json")
+	q, err := OpenQuarantine(file)
+	c.Assert(err, IsNil)
+	c.Assert(q.List(), HasLen, 0)
+
+	bad := itemBatch("Voce")
+	bad[1].Resource = &Resource{}
+	p := NewResourceParser()
+	for i := 1; i <= QuarantineAfter; i++ {
+		s, errs := p.ParseAll(bad, WithQuarantine(q))
+		c.Assert(s.Failed, Equals, 1)
+		c.Assert(s.Quarantined, Equals, 0)
+		c.Assert(errs, HasLen, 1)
+		e, ok := q.Get("cat/sub/beginner/item", "it")
+		c.Assert(ok, Equals, true)
+		c.Assert(e.Failures, Equals, i)
+		c.Assert(e.Error, Equals, "Invalid content")
+	}
+
+	// quarantined, and saved in the file
+	c.Assert(q.Close(), IsNil)
+	q, err = OpenQuarantine(file)
+	c.Assert(err, IsNil)
+	c.Assert(q.List(), HasLen, 1)
+	p = NewResourceParser()
+	s, errs := p.ParseAll(bad, WithQuarantine(q))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s.Applied, Equals, 1)
+	c.Assert(s.Failed, Equals, 0)
+	c.Assert(s.Quarantined, Equals, 1)
+	c.Assert(p.Problems(), DeepEquals, []Problem{{
+		Path: "cat/sub/beginner/item", Locale: "it", Message: "quarantined after 3 failures: Invalid content",
+	}})
+
+	// without the option it is parsed as usual
+	s, errs = NewResourceParser().ParseAll(bad)
+	c.Assert(s.Failed, Equals, 1)
+	c.Assert(errs, HasLen, 1)
+
+	// a changed resource is tried again, and a success clears the entry
+	s, errs = p.ParseAll(itemBatch("Voce"), WithQuarantine(q))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s.Applied, Equals, 2)
+	c.Assert(s.Quarantined, Equals, 0)
+	c.Assert(q.List(), HasLen, 0)
+
+	// manual clearing
+	p.ParseAll(bad, WithQuarantine(q))
+	c.Assert(q.List(), HasLen, 1)
+	c.Assert(q.Delete("cat/sub/beginner/item", "it"), IsNil)
+	c.Assert(q.Close(), IsNil)
+	q, err = OpenQuarantine(file)
+	c.Assert(err, IsNil)
+	c.Assert(q.List(), HasLen, 0)
+	c.Assert(q.Close(), IsNil)
+}
@@ -0,0 +1,308 @@
+package component
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// QueryError is a syntax error in a query, Pos is the byte offset where it was found
+type QueryError struct {
+	Pos int
+	Msg string
+}
+
+func (q *QueryError) Error() string { return fmt.Sprintf("query: %s at %d", q.Msg, q.Pos) }
+
+// Query returns the items matching the query, ordered by locale and then in tree order.
+//
+// A query is made of conditions, combined with AND, OR, NOT and parentheses; adjacent
+// conditions are joined by AND. A condition is a field, an operator and a value, that can be
+// quoted with double quotes:
+//
+//	locale, category, subcategory, difficulty, id
+//	    ":" or "=" match a glob (e.g. category:travel*), "~" a regular expression
+//	title, body
+//	    ":" contains (case insensitive), "=" equals, "~" matches a regular expression
+//	tag
+//	    ":" or "=" the item (or its subcategory) has the audience tag
+//	words
+//	    "=", "!=", "<", "<=", ">", ">=" compare the number of words of the body, code excluded
+//
+// For example: locale:es AND difficulty:advanced AND body:asylum AND words>100
+func (r *ResourceParser) Query(q string) ([]ItemRef, error) {
+	var p queryParser
+	if err := p.tokenize(q); err != nil {
+		return nil, err
+	}
+	match, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &QueryError{t.pos, fmt.Sprintf("unexpected %q", t.text)}
+	}
+	var refs []ItemRef
+	for _, l := range r.Locales(IncludeArchived()) {
+		for _, cat := range r.categories[l] {
+			walkCategory(cat, func(c Component) {
+				if item, ok := c.(*Item); ok && match(queryItem{l, item}) {
+					refs = append(refs, ItemRef{l, treePath(item)})
+				}
+			})
+		}
+	}
+	return refs, nil
+}
+
+type queryItem struct {
+	locale string
+	item   *Item
+}
+
+type queryFunc func(queryItem) bool
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type queryParser struct {
+	tokens []token
+	next   int
+}
+
+func (p *queryParser) tokenize(q string) error {
+	for i := 0; i < len(q); {
+		switch c := q[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			p.tokens = append(p.tokens, token{tokOpen, "(", i})
+			i++
+		case c == ')':
+			p.tokens = append(p.tokens, token{tokClose, ")", i})
+			i++
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(q) && q[j] != '"'; j++ {
+				if q[j] == '\\' && j+1 < len(q) {
+					j++
+				}
+				b.WriteByte(q[j])
+			}
+			if j == len(q) {
+				return &QueryError{i, "unterminated string"}
+			}
+			p.tokens = append(p.tokens, token{tokString, b.String(), i})
+			i = j + 1
+		case strings.IndexByte(":~=<>!", c) >= 0:
+			j := i + 1
+			if j < len(q) && q[j] == '=' && c != ':' && c != '~' && c != '=' {
+				j++
+			}
+			if q[i:j] == "!" {
+				return &QueryError{i, `expected "!="`}
+			}
+			p.tokens = append(p.tokens, token{tokOp, q[i:j], i})
+			i = j
+		default:
+			j := i
+			for j < len(q) && strings.IndexByte(" \t\n()\":~=<>!", q[j]) < 0 {
+				j++
+			}
+			p.tokens = append(p.tokens, token{tokWord, q[i:j], i})
+			i = j
+		}
+	}
+	p.tokens = append(p.tokens, token{tokEOF, "", len(q)})
+	return nil
+}
+
+func (p *queryParser) peek() token { return p.tokens[p.next] }
+
+func (p *queryParser) pop() token {
+	t := p.tokens[p.next]
+	if t.kind != tokEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *queryParser) keyword(t token, k string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, k)
+}
+
+// expr := term { OR term }
+func (p *queryParser) expr() (queryFunc, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword(p.peek(), "or") {
+		p.pop()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(i queryItem) bool { return l(i) || right(i) }
+	}
+	return left, nil
+}
+
+// term := factor { [AND] factor }
+func (p *queryParser) term() (queryFunc, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if p.keyword(t, "and") {
+			p.pop()
+		} else if t.kind == tokEOF || t.kind == tokClose || p.keyword(t, "or") {
+			return left, nil
+		}
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(i queryItem) bool { return l(i) && right(i) }
+	}
+}
+
+// factor := "(" expr ")" | NOT factor | condition
+func (p *queryParser) factor() (queryFunc, error) {
+	t := p.pop()
+	switch {
+	case t.kind == tokOpen:
+		fn, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.pop(); c.kind != tokClose {
+			return nil, &QueryError{c.pos, `expected ")"`}
+		}
+		return fn, nil
+	case p.keyword(t, "not"):
+		fn, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return func(i queryItem) bool { return !fn(i) }, nil
+	case t.kind == tokWord && !p.keyword(t, "and") && !p.keyword(t, "or"):
+		return p.condition(t)
+	case t.kind == tokEOF:
+		return nil, &QueryError{t.pos, "unexpected end of query"}
+	}
+	return nil, &QueryError{t.pos, fmt.Sprintf("unexpected %q", t.text)}
+}
+
+func (p *queryParser) condition(field token) (queryFunc, error) {
+	op := p.pop()
+	if op.kind != tokOp {
+		return nil, &QueryError{op.pos, fmt.Sprintf("expected operator after %q", field.text)}
+	}
+	v := p.pop()
+	if v.kind != tokWord && v.kind != tokString {
+		return nil, &QueryError{v.pos, fmt.Sprintf("expected value after %q", field.text+op.text)}
+	}
+	badOp := &QueryError{op.pos, fmt.Sprintf("invalid operator %q for %s", op.text, field.text)}
+	var re *regexp.Regexp
+	if op.text == "~" {
+		var err error
+		if re, err = regexp.Compile(v.text); err != nil {
+			return nil, &QueryError{v.pos, "invalid regular expression"}
+		}
+	}
+	switch f := strings.ToLower(field.text); f {
+	case "locale", "category", "subcategory", "difficulty", "id":
+		value := func(i queryItem) string {
+			switch f {
+			case "locale":
+				return i.locale
+			case "category":
+				return i.item.parent.parent.parent.ID
+			case "subcategory":
+				return i.item.parent.parent.ID
+			case "difficulty":
+				return i.item.parent.ID
+			}
+			return i.item.ID
+		}
+		switch op.text {
+		case "~":
+			return func(i queryItem) bool { return re.MatchString(value(i)) }, nil
+		case ":", "=":
+			if _, err := path.Match(v.text, ""); err != nil {
+				return nil, &QueryError{v.pos, "invalid pattern"}
+			}
+			return func(i queryItem) bool { ok, _ := path.Match(v.text, value(i)); return ok }, nil
+		}
+	case "title", "body":
+		value := func(i queryItem) string {
+			if f == "title" {
+				return i.item.Title
+			}
+			return stripBodyNotes(i.item.Body)
+		}
+		switch op.text {
+		case "~":
+			return func(i queryItem) bool { return re.MatchString(value(i)) }, nil
+		case "=":
+			return func(i queryItem) bool { return value(i) == v.text }, nil
+		case ":":
+			s := strings.ToLower(v.text)
+			return func(i queryItem) bool { return strings.Contains(strings.ToLower(value(i)), s) }, nil
+		}
+	case "tag":
+		if op.text == ":" || op.text == "=" {
+			return func(i queryItem) bool {
+				return hasTag(i.item.Audience, v.text) || hasTag(i.item.parent.parent.Audience, v.text)
+			}, nil
+		}
+	case "words":
+		n, err := strconv.Atoi(v.text)
+		if err != nil {
+			return nil, &QueryError{v.pos, fmt.Sprintf("invalid number %q", v.text)}
+		}
+		cmp, ok := map[string]func(a int) bool{
+			"=": func(a int) bool { return a == n }, "!=": func(a int) bool { return a != n },
+			"<": func(a int) bool { return a < n }, "<=": func(a int) bool { return a <= n },
+			">": func(a int) bool { return a > n }, ">=": func(a int) bool { return a >= n },
+		}[op.text]
+		if !ok {
+			return nil, badOp
+		}
+		return func(i queryItem) bool { return cmp(countWords(i.locale, i.item.Body)) }, nil
+	default:
+		return nil, &QueryError{field.pos, fmt.Sprintf("unknown field %q", field.text)}
+	}
+	return nil, badOp
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
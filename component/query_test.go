@@ -0,0 +1,88 @@
+package component
+
+import (
+	"regexp"
+
+	. "gopkg.in/check.v1"
+)
+
+func queryFixture() *ResourceParser {
+	p := NewResourceParser()
+	en := audienceCategory()
+	en.Sub("sub").Difficulty("beginner").Item("all").Body = "Ask for asylum at the border.\n\n[[note: hidden words]]"
+	en.Sub("mixed").Difficulty("expert").Item("kenya").Body = "One two three four five six"
+	p.categories["en"] = []*Category{en}
+	es := testCategory("es", "ES ")
+	es.Sub("sub").AddDifficulty(&Difficulty{ID: "advanced"})
+	es.Sub("sub").Difficulty("advanced").AddItem(&Item{ID: "asilo", Title: "Asilo", Body: "Pedir asylum en la frontera"})
+	es.Sub("sub").Difficulty("beginner").AddItem(&Item{ID: "all", Title: "Todo (1)", Body: "Nada"})
+	p.categories["es"] = []*Category{es}
+	return p
+}
+
+func (CmpSuite) TestQuery(c *C) {
+	p := queryFixture()
+	for _, tc := range []struct {
+		query string
+		paths []string
+	}{
+		{"locale:es", []string{"es:cat/sub/beginner/all", "es:cat/sub/advanced/asilo"}},
+		{"locale:e*", []string{"en:cat/sub/beginner/all", "en:cat/sub/beginner/both", "en:cat/local/beginner/item", "en:cat/mixed/beginner/uganda", "en:cat/mixed/expert/kenya", "es:cat/sub/beginner/all", "es:cat/sub/advanced/asilo"}},
+		{"locale=en AND subcategory:mixed", []string{"en:cat/mixed/beginner/uganda", "en:cat/mixed/expert/kenya"}},
+		{"locale:en subcategory:mixed", []string{"en:cat/mixed/beginner/uganda", "en:cat/mixed/expert/kenya"}},
+		{"category:cat difficulty:expert", []string{"en:cat/mixed/expert/kenya"}},
+		{"difficulty:adv* OR id:uganda", []string{"en:cat/mixed/beginner/uganda", "es:cat/sub/advanced/asilo"}},
+		{`id~"^(all|both)$" locale:en`, []string{"en:cat/sub/beginner/all", "en:cat/sub/beginner/both"}},
+		{"body:ASYLUM", []string{"en:cat/sub/beginner/all", "es:cat/sub/advanced/asilo"}},
+		{"body:asylum AND locale:es AND difficulty:advanced", []string{"es:cat/sub/advanced/asilo"}},
+		{"body:hidden", nil},
+		{`body:"at the border"`, []string{"en:cat/sub/beginner/all"}},
+		{"title=Both", []string{"en:cat/sub/beginner/both"}},
+		{`title="Todo (1)"`, []string{"es:cat/sub/beginner/all"}},
+		{`title~"^[A-Z][a-z]+$" locale:es`, []string{"es:cat/sub/advanced/asilo"}},
+		{"tag:kenya", []string{"en:cat/sub/beginner/both", "en:cat/local/beginner/item", "en:cat/mixed/expert/kenya"}},
+		{"tag:uganda AND NOT id:both", []string{"en:cat/mixed/beginner/uganda"}},
+		{"words>6", nil},
+		{"words>5", []string{"en:cat/sub/beginner/all", "en:cat/mixed/expert/kenya"}},
+		{"words>=5 locale:en", []string{"en:cat/sub/beginner/all", "en:cat/mixed/expert/kenya"}},
+		{"words=1", []string{"es:cat/sub/beginner/all"}},
+		{"words!=0 AND (locale:es OR tag:kenya) AND NOT difficulty:advanced", []string{"en:cat/mixed/expert/kenya", "es:cat/sub/beginner/all"}},
+		{"(locale:es)", []string{"es:cat/sub/beginner/all", "es:cat/sub/advanced/asilo"}},
+		{"locale:fr", nil},
+	} {
+		refs, err := p.Query(tc.query)
+		c.Assert(err, IsNil, Commentf(tc.query))
+		var paths []string
+		for _, r := range refs {
+			paths = append(paths, r.String())
+		}
+		c.Assert(paths, DeepEquals, tc.paths, Commentf(tc.query))
+	}
+}
+
+func (CmpSuite) TestQueryErrors(c *C) {
+	p := queryFixture()
+	for _, tc := range []struct {
+		query, err string
+	}{
+		{"", "query: unexpected end of query at 0"},
+		{"locale", `query: expected operator after "locale" at 6`},
+		{"locale:", `query: expected value after "locale:" at 7`},
+		{"updated<2023", `query: unknown field "updated" at 0`},
+		{"words:10", `query: invalid operator ":" for words at 5`},
+		{"words>ten", `query: invalid number "ten" at 6`},
+		{"tag~x", `query: invalid operator "~" for tag at 3`},
+		{`title~"("`, "query: invalid regular expression at 6"},
+		{"id:[", "query: invalid pattern at 3"},
+		{`body:"open`, "query: unterminated string at 5"},
+		{"(locale:en", `query: expected ")" at 10`},
+		{"locale:en)", `query: unexpected ")" at 9`},
+		{"locale:en AND", "query: unexpected end of query at 13"},
+		{"OR locale:en", `query: unexpected "OR" at 0`},
+		{"words!10", `query: expected "!=" at 5`},
+	} {
+		_, err := p.Query(tc.query)
+		c.Assert(err, ErrorMatches, regexp.QuoteMeta(tc.err), Commentf(tc.query))
+		c.Assert(err, FitsTypeOf, &QueryError{})
+	}
+}
@@ -0,0 +1,59 @@
+package component
+
+import "fmt"
+
+// QuizScore is the grade of the answers to a quiz, see Quiz.Score
+type QuizScore struct {
+	Correct   int             `json:"correct"`
+	Total     int             `json:"total"`
+	Questions []QuestionScore `json:"questions"`
+}
+
+// QuestionScore is the grade of the answer to a question of a quiz. The explanation is the
+// one of the question, for any answer.
+type QuestionScore struct {
+	Answered    bool   `json:"answered"`
+	Correct     bool   `json:"correct"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// Percent returns the share of correct answers, from 0 to 100
+func (s QuizScore) Percent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Correct) * 100 / float64(s.Total)
+}
+
+// Score grades the answers to the quiz: the positions in Options of the chosen options of each
+// question, in the order of the questions. Questions without an answer, missing or empty, are
+// wrong; an answer is right if it has all the correct options and no other, in any order.
+// Positions out of range, repeated, or answers to questions the quiz does not have are an error.
+func (q *Quiz) Score(answers [][]int) (QuizScore, error) {
+	if len(answers) > len(q.Questions) {
+		return QuizScore{}, fmt.Errorf("%d answers, %d questions", len(answers), len(q.Questions))
+	}
+	var score = QuizScore{Total: len(q.Questions), Questions: make([]QuestionScore, len(q.Questions))}
+	for i, question := range q.Questions {
+		s := QuestionScore{Explanation: question.Explanation}
+		if i < len(answers) && len(answers[i]) != 0 {
+			chosen := make(map[int]bool, len(answers[i]))
+			for _, n := range answers[i] {
+				if n < 0 || n >= len(question.Options) || chosen[n] {
+					return QuizScore{}, fmt.Errorf("Question %d: invalid option %d", i+1, n)
+				}
+				chosen[n] = true
+			}
+			s.Answered = true
+			s.Correct = len(chosen) == len(question.Correct)
+			for _, n := range question.Correct {
+				s.Correct = s.Correct && chosen[n]
+			}
+		}
+		if s.Correct {
+			score.Correct++
+		}
+		score.Questions[i] = s
+	}
+	return score, nil
+}
@@ -0,0 +1,101 @@
+package component
+
+import (
+	"testing/fstest"
+
+	. "gopkg.in/check.v1"
+)
+
+const quizContents = "[Question]: # (What does a VPN hide?)\n[Options]: # (Your traffic;Your screen;Your battery)\n" +
+	"[Correct]: # (0)\n[Explanation]: # (It encrypts the traffic)\n\n" +
+	"[Question]: # (Which are second factors?)\n[Options]: # (A code by SMS;A password;A security key)\n[Correct]: # (0;2)"
+
+func (CmpSuite) TestQuiz(c *C) {
+	fsys := fstest.MapFS{
+		"contents_en/cat/.metadata.md":              {Data: []byte("[Name]: # (Category)\n[Order]: # (1)")},
+		"contents_en/cat/sub/.metadata.md":          {Data: []byte("[Name]: # (Sub)\n[Order]: # (1)")},
+		"contents_en/cat/sub/beginner/.metadata.md": {Data: []byte("[Description]: # (Easy)")},
+		"contents_en/cat/sub/beginner/.quiz.md":     {Data: []byte(quizContents)},
+	}
+	var fp Parser
+	c.Assert(fp.ParseFS(fsys), IsNil)
+	c.Assert(fp.CheckInvariants(), IsNil)
+	diff := fp.Categories()["en"][0].Sub("sub").Difficulty("beginner")
+	q := diff.Quiz()
+	c.Assert(q, NotNil)
+	c.Assert(q.Path(), Equals, "contents_en/cat/sub/beginner/.quiz.md")
+	c.Assert(q.Contents(), Equals, quizContents)
+	c.Assert(q.Questions[1], DeepEquals, Question{
+		Text:    "Which are second factors?",
+		Options: []string{"A code by SMS", "A password", "A security key"},
+		Correct: []int{0, 2},
+	})
+	c.Assert(treePath(q), Equals, "cat/sub/beginner/.quiz")
+
+	for _, s := range []string{
+		"[Question]: # (Q)\n[Options]: # (A)\n[Correct]: # (0)",
+		"[Question]: # (Q)\n[Options]: # (A;B)\n[Correct]: # ()",
+		"[Question]: # (Q)\n[Options]: # (A;B)\n[Correct]: # (2)",
+		"[Question]: # (Q)\n[Options]: # (A;B)\n[Correct]: # (one)",
+	} {
+		c.Assert(new(Quiz).SetContents(s), NotNil, Commentf(s))
+	}
+
+	p := NewResourceParser()
+	for _, cmp := range []Component{fp.Categories()["en"][0], diff.parent, diff} {
+		c.Assert(p.Parse(cmp, encode(cmp, cmp), "en"), IsNil)
+	}
+	res := q.Resource()
+	c.Assert(Validate(q, &res), HasLen, 0)
+	c.Assert(p.Parse(q, &res, "en"), IsNil)
+	res.Content[0] = map[string]string{KeyQuestion: "Cosa nasconde una VPN?", KeyOptions: "Il traffico;Lo schermo;La batteria"}
+	c.Assert(p.Parse(q, &res, "it"), IsNil)
+	c.Assert(p.Problems(), DeepEquals, []Problem{{Path: "cat/sub/beginner/.quiz", Locale: "it", Message: "question 1: missing explanation"}})
+	it := p.lookup("it", "cat/sub/beginner/.quiz").(*Quiz)
+	c.Assert(it.Questions[0].Options, DeepEquals, []string{"Il traffico", "Lo schermo", "La batteria"})
+	c.Assert(it.Questions[0].Correct, DeepEquals, []int{0})
+
+	bad := map[string]string{KeyQuestion: "Q", KeyOptions: "A;B"}
+	c.Assert(p.Parse(q, &Resource{Content: []map[string]string{bad, res.Content[1]}}, "fr"), ErrorMatches, ".*: 2 options, 3 expected")
+	c.Assert(p.Parse(q, &Resource{Content: res.Content[:1]}, "fr"), ErrorMatches, ErrContent.Error())
+
+	tree, err := p.Export("it")
+	c.Assert(err, IsNil)
+	c.Assert(tree.Categories[0].Subcategories[0].Difficulties[0].Quiz, DeepEquals, it.Questions)
+	b, err := p.MarshalLocale("it")
+	c.Assert(err, IsNil)
+	copy := NewResourceParser()
+	c.Assert(copy.UnmarshalLocale(b), IsNil)
+	c.Assert(copy.lookup("it", "cat/sub/beginner/.quiz").(*Quiz).Questions, DeepEquals, it.Questions)
+
+	c.Assert(p.Remove(q, "it"), IsNil)
+	c.Assert(p.lookup("it", "cat/sub/beginner/.quiz"), IsNil)
+}
+
+func (CmpSuite) TestQuizScore(c *C) {
+	var q Quiz
+	c.Assert(q.SetContents(quizContents), IsNil)
+
+	s, err := q.Score([][]int{{0}, {2, 0}})
+	c.Assert(err, IsNil)
+	c.Assert(s.Correct, Equals, 2)
+	c.Assert(s.Percent(), Equals, 100.0)
+
+	s, err = q.Score([][]int{{1}})
+	c.Assert(err, IsNil)
+	c.Assert(s, DeepEquals, QuizScore{Correct: 0, Total: 2, Questions: []QuestionScore{
+		{Answered: true, Explanation: "It encrypts the traffic"},
+		{},
+	}})
+	s, err = q.Score([][]int{{0}, {0}})
+	c.Assert(err, IsNil)
+	c.Assert(s.Percent(), Equals, 50.0)
+	c.Assert(s.Questions[1], Equals, QuestionScore{Answered: true})
+
+	_, err = q.Score([][]int{{3}})
+	c.Assert(err, ErrorMatches, "Question 1: invalid option 3")
+	_, err = q.Score([][]int{{0, 0}})
+	c.Assert(err, ErrorMatches, "Question 1: invalid option 0")
+	_, err = q.Score([][]int{{0}, {0}, {0}})
+	c.Assert(err, ErrorMatches, "3 answers, 2 questions")
+}
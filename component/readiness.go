@@ -0,0 +1,111 @@
+package component
+
+// Readiness tells how much of a locale is translated, compared to the base locale
+type Readiness struct {
+	Components   float64 `json:"components"`    // percent of base components fully translated
+	Words        float64 `json:"words"`         // percent of base words translated
+	MissingNames int     `json:"missing_names"` // category and subcategory without a translated name
+	FormMismatch int     `json:"form_mismatch"` // forms with screens or inputs that differ from the base
+	Ready        bool    `json:"ready"`
+}
+
+// CriticalGaps returns the number of gaps that prevent a locale to be shown
+func (r Readiness) CriticalGaps() int { return r.MissingNames + r.FormMismatch }
+
+// Thresholds decides when a locale is ready to be published
+type Thresholds struct {
+	Components   float64 // minimum percent of components
+	Words        float64 // minimum percent of words
+	CriticalGaps int     // maximum number of critical gaps
+}
+
+var DefaultThresholds = Thresholds{Components: 80, Words: 80}
+
+// SetThresholds changes the thresholds used by LocaleReadiness. It's Config.Thresholds.
+func (r *ResourceParser) SetThresholds(t Thresholds) { r.thresholds = t }
+
+// LocaleReadiness returns the readiness of every locale, except the base one
+func (r *ResourceParser) LocaleReadiness(baseLocale string, opts ...Option) map[string]Readiness {
+	var res = make(map[string]Readiness)
+	for _, l := range r.Locales(opts...) {
+		if l == baseLocale {
+			continue
+		}
+		res[l] = r.readiness(baseLocale, l, r.thresholds)
+	}
+	return res
+}
+
+func (r *ResourceParser) readiness(base, locale string, t Thresholds) Readiness {
+	var (
+		rd                   Readiness
+		cmps, translated     int
+		words, wordsReceived int
+	)
+	check := func(c Component) {
+		target := r.translation(locale, treePath(c))
+		cmps++
+		var targets = make(map[string][]string)
+		if target != nil {
+			for _, f := range textFields(target) {
+				targets[f.Name] = f.Texts
+			}
+		}
+		complete := target != nil
+		for _, f := range textFields(c) {
+			t := targets[f.Name]
+			for i, s := range f.Texts {
+				n := countWords(base, s)
+				words += n
+				if i < len(t) && t[i] != "" {
+					wordsReceived += n
+				} else if s != "" {
+					complete = false
+				}
+			}
+		}
+		if complete {
+			translated++
+		}
+		switch c.(type) {
+		case *Category, *Subcategory:
+			if !complete {
+				rd.MissingNames++
+			}
+		case *Form:
+			if target != nil && !sameShape(c.(*Form), target.(*Form)) {
+				rd.FormMismatch++
+			}
+		}
+	}
+	for _, cat := range r.categories[base] {
+		walkCategory(cat, check)
+	}
+	for _, f := range r.forms[base] {
+		check(f)
+	}
+	rd.Components, rd.Words = percent(translated, cmps), percent(wordsReceived, words)
+	rd.Ready = rd.Components >= t.Components &&
+		rd.Words >= t.Words &&
+		rd.CriticalGaps() <= t.CriticalGaps
+	return rd
+}
+
+func sameShape(a, b *Form) bool {
+	if len(a.Screens) != len(b.Screens) {
+		return false
+	}
+	for i := range a.Screens {
+		if len(a.Screens[i].Items) != len(b.Screens[i].Items) {
+			return false
+		}
+	}
+	return true
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(n) * 100 / float64(total)
+}
@@ -0,0 +1,59 @@
+package component
+
+import (
+	"fmt"
+
+	. "gopkg.in/check.v1"
+)
+
+func readinessParser(items map[string]int) *ResourceParser {
+	p := NewResourceParser()
+	for locale, n := range items {
+		var prefix string
+		if locale != "en" {
+			prefix = locale + " "
+		}
+		cat := testCategory(locale, prefix)
+		diff := cat.Sub("sub").Difficulty("beginner")
+		for i := 0; i < n; i++ {
+			diff.AddItem(&Item{ID: fmt.Sprint("item", i), Title: "Title", Body: "one two\n\nthree four"})
+		}
+		p.categories[locale] = []*Category{cat}
+	}
+	return p
+}
+
+func (CmpSuite) TestLocaleReadiness(c *C) {
+	p := readinessParser(map[string]int{"en": 7, "it": 5, "es": 4, "fr": 7})
+	p.category("cat", "fr").Name = ""
+	p.SetThresholds(Thresholds{Components: 80, Words: 70})
+
+	// 39 words: 1 per name, 2 for the description ("&" is not a word), 5 per item
+	r := p.LocaleReadiness("en")
+	c.Assert(r, HasLen, 3)
+	c.Assert(r["it"], DeepEquals, Readiness{Components: 80, Words: percent(29, 39), Ready: true})
+	c.Assert(r["es"], DeepEquals, Readiness{Components: 70, Words: percent(24, 39)})
+	c.Assert(r["fr"], DeepEquals, Readiness{Components: 90, Words: percent(38, 39), MissingNames: 1})
+
+	p.SetThresholds(Thresholds{Components: 80, Words: 70, CriticalGaps: 1})
+	c.Assert(p.LocaleReadiness("en")["fr"].Ready, Equals, true)
+
+	// it is just above the cut, es just below it
+	c.Assert(p.Locales(ReadyLocalesOnly("en", Thresholds{Components: 80, Words: 70})), DeepEquals, []string{"en", "it"})
+	c.Assert(p.Locales(ReadyLocalesOnly("en", Thresholds{Components: 70, Words: 60, CriticalGaps: 1})), DeepEquals, []string{"en", "es", "fr", "it"})
+	c.Assert(p.Locales(ReadyLocalesOnly("en", Thresholds{Components: 81, CriticalGaps: 1})), DeepEquals, []string{"en", "fr"})
+}
+
+func (CmpSuite) TestLocaleReadinessForms(c *C) {
+	p := NewResourceParser()
+	p.forms["en"] = []*Form{{ID: "form", Name: "Form", Screens: []FormScreen{
+		{Name: "One", Items: []FormInput{{Label: "Label"}}},
+		{Name: "Two"},
+	}}}
+	p.forms["it"] = []*Form{{ID: "form", Name: "Modulo", Screens: []FormScreen{
+		{Name: "Uno", Items: []FormInput{{Label: "Etichetta"}}},
+	}}}
+	r := p.LocaleReadiness("en")["it"]
+	c.Assert(r.FormMismatch, Equals, 1)
+	c.Assert(r.Ready, Equals, false)
+}
@@ -0,0 +1,74 @@
+package component
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Rename changes the ID of the component at the tree path in every locale where it exists, and
+// returns the renamed components. It fails without changing anything if the path does not exist
+// or a sibling with the new ID exists in any locale.
+func (r *ResourceParser) Rename(oldPath, newID string) ([]ItemRef, error) {
+	if newID == "" || strings.Contains(newID, "/") || newID == suffixChecks {
+		return nil, fmt.Errorf("Invalid ID %q", newID)
+	}
+	if strings.HasSuffix(oldPath, "/"+suffixChecks) {
+		return nil, fmt.Errorf("Cannot rename %s", oldPath)
+	}
+	newPath := path.Join(path.Dir(oldPath), newID)
+	var (
+		refs     []ItemRef
+		renaming []Component
+	)
+	for _, l := range r.Locales(IncludeArchived()) {
+		c := r.lookup(l, oldPath)
+		if c == nil {
+			continue
+		}
+		if r.lookup(l, newPath) != nil {
+			return nil, fmt.Errorf("%s exists (%s)", newPath, l)
+		}
+		renaming = append(renaming, c)
+		refs = append(refs, ItemRef{l, newPath})
+	}
+	if len(renaming) == 0 {
+		return nil, fmt.Errorf("%s not found", oldPath)
+	}
+	for _, c := range renaming {
+		setID(c, newID)
+	}
+	for _, pending := range r.pending {
+		movePending(pending, oldPath, newPath)
+	}
+	r.renames = append(r.renames, [2]string{oldPath, newPath})
+	return refs, nil
+}
+
+func setID(c Component, id string) {
+	switch v := c.(type) {
+	case *Category:
+		v.ID = id
+	case *Subcategory:
+		v.ID = id
+		v.parent.reindex()
+	case *Difficulty:
+		v.ID = id
+		v.parent.reindex()
+	case *Item:
+		v.ID = id
+		v.parent.reindex()
+	case *Form:
+		v.ID = id
+	}
+}
+
+// movePending moves the pending paths under oldPath to newPath
+func movePending(pending map[string]bool, oldPath, newPath string) {
+	for p := range pending {
+		if p == oldPath || strings.HasPrefix(p, oldPath+"/") {
+			delete(pending, p)
+			pending[newPath+strings.TrimPrefix(p, oldPath)] = true
+		}
+	}
+}
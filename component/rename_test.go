@@ -0,0 +1,35 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestRename(c *C) {
+	p := bilingualParser()
+	c.Assert(p.BootstrapLocale("en", "sw"), IsNil)
+
+	refs, err := p.Rename("cat/sub/beginner/item", "renamed")
+	c.Assert(err, IsNil)
+	c.Assert(refs, DeepEquals, []ItemRef{{"en", "cat/sub/beginner/renamed"}, {"it", "cat/sub/beginner/renamed"}, {"sw", "cat/sub/beginner/renamed"}})
+	c.Assert(p.lookup("it", "cat/sub/beginner/item"), IsNil)
+	c.Assert(p.lookup("it", "cat/sub/beginner/renamed").(*Item).Title, Equals, "Titolo")
+	c.Assert(p.NeedsTranslation("sw", "cat/sub/beginner/renamed"), Equals, true)
+
+	refs, err = p.Rename("cat/sub", "section")
+	c.Assert(err, IsNil)
+	c.Assert(refs, HasLen, 3)
+	c.Assert(p.lookup("en", "cat/section/beginner/other"), NotNil)
+	c.Assert(p.NeedsTranslation("sw", "cat/section/beginner/other"), Equals, true)
+	c.Assert(p.NeedsTranslation("sw", "cat/sub/beginner/other"), Equals, false)
+
+	// other exists only in en and sw, renamed exists in all locales
+	_, err = p.Rename("cat/section/beginner/renamed", "other")
+	c.Assert(err, ErrorMatches, "cat/section/beginner/other exists .*")
+	c.Assert(p.lookup("it", "cat/section/beginner/renamed"), NotNil)
+	c.Assert(p.lookup("en", "cat/section/beginner/renamed"), NotNil)
+
+	_, err = p.Rename("cat/missing", "x")
+	c.Assert(err, ErrorMatches, "cat/missing not found")
+	_, err = p.Rename("cat/section", "a/b")
+	c.Assert(err, NotNil)
+}
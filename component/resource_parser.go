@@ -1,34 +1,135 @@
 package component
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"strings"
-)
 
-func splitSlug(s string) []string {
-	s = strings.Replace(s, "_", "|", -1)
-	s = strings.Replace(s, "|||", "|_|", -1)
-	return strings.Split(s, "|")
-}
+	"github.com/securityfirst/tent/kvfile"
+)
 
 func NewResourceParser() *ResourceParser {
 	return &ResourceParser{
 		categories: make(map[string][]*Category),
 		forms:      make(map[string][]*Form),
+		glossaries: make(map[string][]*Glossary),
+		settings: settings{
+			thresholds:  DefaultThresholds,
+			nameLength:  DefaultNameLength,
+			descrLength: DefaultDescriptionLength,
+			archived:    make(map[string]bool),
+		},
 	}
 }
 
+// ParseRequest is a component with the resource to parse for a locale
+type ParseRequest struct {
+	Component Component
+	Resource  *Resource
+	Locale    string
+}
+
 type ResourceParser struct {
-	buffer     bytes.Buffer
+	settings
 	categories map[string][]*Category
 	forms      map[string][]*Form
+	glossaries map[string][]*Glossary
+	problems   []Problem
+	finalized  []Problem                  // problems of the last Finalize
+	pending    map[string]map[string]bool // bootstrapped components by locale and path
+	images     map[string]ImageInfo       // image sizes by path, see AnnotateImages
+	batches    map[string]ImportSummary   // applied batches by key, see ParseAllIdempotent
+	batchKeys  []string                   // keys of the batches, least recent first
+	batchDB    *kvfile.DB                 // store of the batch keys, see SetBatchStore
+	batchSeq   uint64                     // last recency saved in batchDB
+	renames    [][2]string                // old and new tree paths, see Rename
 }
 
-func (r *ResourceParser) Categories() map[string][]*Category { return r.categories }
+// settings are what the Set and Add methods of a ResourceParser configure: the parsers of
+// ParseLocales and the one of a Snapshot take them all with clone, so a new field is never
+// left out of them
+type settings struct {
+	thresholds     Thresholds
+	strict         bool
+	nameLength     int
+	descrLength    int
+	archived       map[string]bool
+	resync         int                 // form rows that can be skipped or missing, see SetResync
+	fallback       string              // locale of missing categories, see SetFallbackLocale
+	chains         map[string][]string // fallback locales by locale, see SetFallbackChain
+	lenientChecks  bool                // missing checks keep the base text, see SetStrictChecklists
+	itemHooks      []ItemHook          // see AddItemHook
+	bodyProcessors []BodyProcessor     // see AddBodyProcessor
+	finalizers     []Finalizer         // see AddFinalizer
+	sorted         bool                // sort the parsed locales, see SetSorted
+	listeners      []Listener          // see AddListener
+}
+
+// clone returns a copy of the settings that doesn't share their maps and lists
+func (s settings) clone() settings {
+	archived := make(map[string]bool, len(s.archived))
+	for l, v := range s.archived {
+		archived[l] = v
+	}
+	s.archived = archived
+	if s.chains != nil {
+		chains := make(map[string][]string, len(s.chains))
+		for l, chain := range s.chains {
+			chains[l] = copyStrings(chain)
+		}
+		s.chains = chains
+	}
+	s.itemHooks = append([]ItemHook(nil), s.itemHooks...)
+	s.bodyProcessors = append([]BodyProcessor(nil), s.bodyProcessors...)
+	s.finalizers = append([]Finalizer(nil), s.finalizers...)
+	s.listeners = append([]Listener(nil), s.listeners...)
+	return s
+}
+
+// Problems returns the warnings collected while parsing
+func (r *ResourceParser) Problems() []Problem {
+	if len(r.finalized) == 0 {
+		return r.problems
+	}
+	return append(r.problems[:len(r.problems):len(r.problems)], r.finalized...)
+}
+
+func (r *ResourceParser) warn(cmp Component, locale, format string, a ...interface{}) {
+	r.problems = append(r.problems, Problem{Path: treePath(cmp), Locale: locale, Message: fmt.Sprintf(format, a...)})
+}
 
 func (r *ResourceParser) Parse(cmp Component, res *Resource, locale string) error {
+	var (
+		expected = make(map[string]bool)
+		unknown  = make(map[string]bool)
+	)
+	for _, k := range ExpectedKeys(cmp) {
+		expected[k] = true
+	}
+	for _, row := range res.Content {
+		for k, v := range row {
+			if checkNotes(v) != nil {
+				r.warn(cmp, locale, "unterminated note in %q", k)
+			}
+			if !expected[k] && !unknown[k] {
+				unknown[k] = true
+				r.warn(cmp, locale, "unknown key %q", k)
+			}
+		}
+	}
+	if r.archived[locale] {
+		r.warn(cmp, locale, "locale is archived")
+	}
+	before := r.current(cmp, locale)
+	if err := r.parse(cmp, res, locale); err != nil {
+		return err
+	}
+	delete(r.pending[locale], treePath(cmp))
+	r.notifyParsed(cmp, locale, before)
+	return nil
+}
+
+func (r *ResourceParser) parse(cmp Component, res *Resource, locale string) error {
 	switch v := cmp.(type) {
 	case *Form:
 		return r.parseForm(v, res, locale)
@@ -42,47 +143,107 @@ func (r *ResourceParser) Parse(cmp Component, res *Resource, locale string) erro
 		return r.parseItem(v, res, locale)
 	case *Checklist:
 		return r.parseChecklist(v, res, locale)
+	case *Glossary:
+		return r.parseGlossary(v, res, locale)
+	case *Quiz:
+		return r.parseQuiz(v, res, locale)
 	default:
 		return errors.New("Invalid Component")
 	}
 }
 
 func (r *ResourceParser) parseForm(f *Form, res *Resource, locale string) error {
+	if len(res.Content) == 0 {
+		return contentMismatch(f, locale, "rows", 1, 0, true)
+	}
 	var newForm = Form{
 		ID:      f.ID,
-		Name:    res.Content[0]["form"],
+		Name:    res.Content[0][KeyForm],
 		Locale:  locale,
 		Screens: make([]FormScreen, len(f.Screens)),
 	}
-	m := res.Content[1:]
+	a := formAligner{
+		form:       f,
+		rows:       res.Content,
+		row:        1,
+		last:       "form name",
+		divergence: -1,
+		kinds:      formKinds(f),
+		budget:     r.resync,
+		warn:       func(format string, args ...interface{}) { r.warn(f, locale, format, args...) },
+	}
+	// options are checked once the rows are aligned, a misaligned row has the wrong options
+	var optionsErr error
+	ids := f.ScreenIDs()
 	for i := range newForm.Screens {
 		screen := &newForm.Screens[i]
+		screen.ID, screen.ShowIf = ids[i], f.Screens[i].ShowIf
 		screen.Items = make([]FormInput, len(f.Screens[i].Items))
-		if f.Screens[i].Name != "" {
-			if len(m) == 0 {
-				return fmt.Errorf("No more at screen %d/%d", i+1, len(f.Screens))
-			}
-			if name := m[0]["screen"]; name != "" {
+		if name := f.Screens[i].Name; name != "" {
+			ok, missing := a.expect(true, fmt.Sprintf("screen %q", name))
+			switch {
+			case !ok && a.done():
+				return a.fail(locale, i, -1, ErrRowCountMismatch, "No more at screen %d/%d", i+1, len(f.Screens))
+			case !ok:
+				return a.fail(locale, i, -1, ErrUnexpectedInput, "Expected screen %d, got item", i)
+			case missing:
 				screen.Name = name
-				m = m[1:]
-			} else {
-				return fmt.Errorf("Expected screen %d, got item", i)
+			default:
+				m := a.rows[a.row]
+				screen.Name = m[KeyScreen]
+				if id := m[KeyID]; id != "" && id != screen.ID {
+					r.warn(f, locale, "screen %q: ID %q ignored, the source has %q", name, id, screen.ID)
+				}
+				a.consume()
 			}
 		}
 		for j := range screen.Items {
-			item := screen.Items[j]
+			item := &screen.Items[j]
+			*item = f.Screens[i].Items[j]
 			if item.Label == "" && item.Hint == "" && item.Options == nil {
 				continue
 			}
-			if s := m[0]["screen"]; s != "" {
-				return fmt.Errorf("Expected item %d/%d, got screen %q", i, j, s)
+			ok, missing := a.expect(false, fmt.Sprintf("input %d/%d of screen %q", j+1, len(screen.Items), f.Screens[i].Name))
+			switch {
+			case !ok && a.done():
+				return a.fail(locale, i, j, ErrRowCountMismatch, "No more at item %d/%d", i, j)
+			case !ok:
+				return a.fail(locale, i, j, ErrUnexpectedScreen, "Expected item %d/%d, got screen %q", i, j, a.rows[a.row][KeyScreen])
+			case missing:
+				continue
+			}
+			m := a.rows[a.row]
+			a.check(item, m)
+			item.Label, item.Hint = m[KeyLabel], m[KeyHint]
+			var options []string
+			if cell := m[KeyOptions]; cell != "" || item.Options != nil {
+				options = strings.Split(cell, ";")
+			}
+			if msg := item.checkOptions(options); msg != "" && optionsErr == nil {
+				optionsErr = a.fail(locale, i, j, ErrOptionsMismatch, "Form %q, screen %d, input %d: %s", f.ID, i+1, j+1, msg)
 			}
-			item.Label, item.Hint = m[0]["label"], m[0]["hint"]
-			item.Options = strings.Split(m[0]["options"], ";")
-			m = m[1:]
+			if item.Options != nil {
+				item.Options = options
+			}
+			a.consume()
+		}
+	}
+	if !a.done() && !a.skipRest() {
+		return a.fail(locale, len(f.Screens), -1, ErrRowCountMismatch, "%d unexpected rows", len(a.rows)-a.row)
+	}
+	if optionsErr != nil {
+		return optionsErr
+	}
+	for _, msg := range newForm.translateConditions(f) {
+		r.warn(f, locale, "%s", msg)
+	}
+	for i, old := range r.forms[locale] {
+		if old.ID == newForm.ID {
+			r.forms[locale][i] = &newForm
+			return nil
 		}
 	}
-	r.forms[newForm.Locale] = append(r.forms[newForm.Locale], &newForm)
+	r.forms[locale] = append(r.forms[locale], &newForm)
 	return nil
 }
 
@@ -99,107 +260,312 @@ func (r *ResourceParser) getCategory(cat *Category, locale string) *Category {
 
 func (r *ResourceParser) parseCategory(c *Category, res *Resource, locale string) error {
 	if len(res.Content) != 1 {
-		return ErrContent
+		return contentMismatch(c, locale, "rows", 1, len(res.Content), false)
 	}
-	if cat := r.getCategory(c, locale); cat != nil {
-		cat.Name = res.Content[0]["name"]
-		return nil
+	name, err := r.checkName(c, locale, res.Content[0][KeyName])
+	if err != nil {
+		return err
 	}
-	r.categories[locale] = append(r.categories[locale], &Category{
-		ID:     c.ID,
-		Order:  c.Order,
-		Name:   res.Content[0]["name"],
-		Locale: locale,
-	})
+	cat := r.getCategory(c, locale)
+	cat.Name, cat.SourceLocale = name, ""
 	return nil
 }
 
-func (r *ResourceParser) getSubcategory(sub *Subcategory, locale string) *Subcategory {
-	cat := r.getCategory(sub.parent, locale)
-	for _, s := range cat.subcategories {
-		if s.ID == sub.ID {
-			return s
-		}
+func (r *ResourceParser) getSubcategory(sub *Subcategory, locale string) (*Subcategory, error) {
+	cat, err := r.parentCategory(sub.parent, locale)
+	if err != nil {
+		return nil, err
+	}
+	if s := cat.Sub(sub.ID); s != nil {
+		return s, nil
 	}
 	s := Subcategory{ID: sub.ID, Order: sub.Order}
+	if base, l := r.fallbackParent(locale, treePath(sub)); base != nil {
+		b := base.(*Subcategory)
+		s.Name, s.Audience, s.SourceLocale = b.Name, copyStrings(b.Audience), l
+	}
 	cat.Add(&s)
-	return &s
+	return &s, nil
 }
 
 func (r *ResourceParser) parseSubcategory(s *Subcategory, res *Resource, locale string) error {
 	if len(res.Content) != 1 {
-		return ErrContent
+		return contentMismatch(s, locale, "rows", 1, len(res.Content), false)
 	}
-	sub := r.getSubcategory(s, locale)
-	sub.Name = res.Content[0]["name"]
+	name, err := r.checkName(s, locale, res.Content[0][KeyName])
+	if err != nil {
+		return err
+	}
+	sub, err := r.getSubcategory(s, locale)
+	if err != nil {
+		return err
+	}
+	sub.Name, sub.SourceLocale = name, ""
+	sub.Audience = copyStrings(s.Audience)
 	return nil
 }
 
-func (r *ResourceParser) getDifficulty(diff *Difficulty, locale string) *Difficulty {
-	sub := r.getSubcategory(diff.parent, locale)
-	for _, d := range sub.difficulties {
-		if d.ID == diff.ID {
-			return d
-		}
+func (r *ResourceParser) getDifficulty(diff *Difficulty, locale string) (*Difficulty, error) {
+	sub, err := r.getSubcategory(diff.parent, locale)
+	if err != nil {
+		return nil, err
+	}
+	if d := sub.Difficulty(diff.ID); d != nil {
+		return d, nil
 	}
 	d := Difficulty{ID: diff.ID}
+	if base, l := r.fallbackParent(locale, treePath(diff)); base != nil {
+		d.Descr, d.SourceLocale = base.(*Difficulty).Descr, l
+	}
 	sub.AddDifficulty(&d)
-	return &d
+	return &d, nil
 }
 
 func (r *ResourceParser) parseDifficulty(d *Difficulty, res *Resource, locale string) error {
 	if len(res.Content) != 1 {
-		return ErrContent
+		return contentMismatch(d, locale, "rows", 1, len(res.Content), false)
+	}
+	descr, err := r.checkDescription(d, locale, res.Content[0][KeyDescription])
+	if err != nil {
+		return err
+	}
+	diff, err := r.getDifficulty(d, locale)
+	if err != nil {
+		return err
 	}
-	diff := r.getDifficulty(d, locale)
-	diff.Descr = res.Content[0]["description"]
+	diff.Descr, diff.SourceLocale = descr, ""
 	return nil
 }
 
 func (r *ResourceParser) parseItem(i *Item, res *Resource, locale string) error {
 	if len(res.Content) == 0 {
-		return ErrContent
+		return contentMismatch(i, locale, "rows", 1, 0, true)
 	}
 	item := &Item{
-		ID:    i.ID,
-		Title: strings.TrimSpace(res.Content[0]["title"]),
-		Order: i.Order,
+		ID:       i.ID,
+		Title:    strings.TrimSpace(res.Content[0][KeyTitle]),
+		Order:    i.Order,
+		Audience: copyStrings(i.Audience),
+		Abstract: strings.TrimSpace(res.Content[0][KeySummary]),
 	}
-	r.buffer.Reset()
+	var body strings.Builder
 	// Old Verion Compatibility
-	if res.Content[0]["body"] != "" {
+	if res.Content[0][KeyBody] != "" {
 		if len(res.Content) != 1 {
-			return fmt.Errorf("Invalid Legacy %q (%s)", i.parent.ID, locale)
+			return &LegacyFormatError{Path: treePath(i), Locale: locale, Rows: len(res.Content)}
 		}
-		r.buffer.WriteString(strings.TrimSpace(res.Content[0]["body"]))
+		body.WriteString(strings.TrimSpace(res.Content[0][KeyBody]))
 	} else {
 		for _, v := range res.Content[1:] {
-			if r.buffer.Len() != 0 {
-				r.buffer.WriteString(paragraphSep)
+			if body.Len() != 0 {
+				body.WriteString(paragraphSep)
 			}
-			r.buffer.WriteString(strings.TrimSpace(v["body"]))
+			body.WriteString(strings.TrimSpace(v[KeyBody]))
 		}
 	}
-	item.Body = r.buffer.String()
-	r.getDifficulty(i.parent, locale).AddItem(item)
+	item.Body = body.String()
+	diff, err := r.getDifficulty(i.parent, locale)
+	if err != nil {
+		return err
+	}
+	item.parent = diff
+	if err := r.processBody(item, locale); err != nil {
+		return err
+	}
+	if err := r.runItemHooks(diff, item, locale); err != nil {
+		return err
+	}
+	if old := diff.Item(item.ID); old != nil {
+		*old = *item
+		return nil
+	}
+	diff.AddItem(item)
 	return nil
 }
 
+// SetStrictChecklists with false makes checklist parsing tolerant: checks missing at the end
+// of the resource, or with an empty text, keep the base text, are marked Untranslated and
+// recorded as problems. Strict, the default, fails for a resource with fewer checks than the
+// base. It's Config.LenientChecklists.
+func (r *ResourceParser) SetStrictChecklists(strict bool) { r.lenientChecks = !strict }
+
 func (r *ResourceParser) parseChecklist(c *Checklist, res *Resource, locale string) error {
-	for len(res.Content) > 0 && res.Content[0] == nil {
-		res.Content = res.Content[1:]
+	var rows []map[string]string
+	for _, row := range res.Content {
+		if row != nil {
+			rows = append(rows, row)
+		}
 	}
-	if l, e := len(res.Content), len(c.Checks); l != e {
-		return fmt.Errorf("%d checks, %d expected", l, e)
+	if l, e := len(rows), len(c.Checks); l > e || l < e && !r.lenientChecks {
+		return contentMismatch(c, locale, "checks", e, l, false)
+	}
+	rows, err := c.orderRows(rows, locale)
+	if err != nil {
+		return err
 	}
 
 	var checks Checklist
-	for i, r := range res.Content {
-		checks.Add(Check{
-			Text:    strings.TrimSpace(r["text"]),
-			NoCheck: c.Checks[i].NoCheck,
-		})
+	for i, base := range c.Checks {
+		var row map[string]string
+		if i < len(rows) {
+			row = rows[i]
+		}
+		check := base
+		check.Untranslated = false
+		switch text := strings.TrimSpace(row[KeyText]); {
+		case text == "" && r.lenientChecks:
+			check.Untranslated = true
+			r.warn(c, locale, "check %d: missing, base text kept", i+1)
+		default:
+			check.Text = text
+		}
+		if style := strings.TrimSpace(row[KeyStyle]); style != "" && style != base.Style {
+			r.warn(c, locale, "row %d: style %q ignored, the source has %q", i+1, style, base.Style)
+		}
+		checks.Add(check)
+	}
+	diff, err := r.getDifficulty(c.parent, locale)
+	if err != nil {
+		return err
+	}
+	diff.SetChecks(&checks)
+	return nil
+}
+
+func (r *ResourceParser) parseGlossary(g *Glossary, res *Resource, locale string) error {
+	if l, e := len(res.Content), len(g.Terms); l != e {
+		return contentMismatch(g, locale, "terms", e, l, false)
+	}
+	var glossary = Glossary{ID: g.ID, Locale: locale, Terms: make([]GlossaryTerm, len(g.Terms))}
+	for i, row := range res.Content {
+		t := GlossaryTerm{Term: strings.TrimSpace(row[KeyTerm]), Definition: strings.TrimSpace(row[KeyDefinition])}
+		if t.Term == "" || t.Definition == "" {
+			return rowError(g, locale, i+1, ErrInvalidValue, "empty term or definition")
+		}
+		glossary.Terms[i] = t
+	}
+	for i, old := range r.glossaries[locale] {
+		if old.ID == glossary.ID {
+			r.glossaries[locale][i] = &glossary
+			return nil
+		}
+	}
+	r.glossaries[locale] = append(r.glossaries[locale], &glossary)
+	return nil
+}
+
+func (r *ResourceParser) parseQuiz(q *Quiz, res *Resource, locale string) error {
+	if l, e := len(res.Content), len(q.Questions); l != e {
+		return contentMismatch(q, locale, "questions", e, l, false)
+	}
+	var quiz = Quiz{Questions: make([]Question, len(q.Questions))}
+	for i, row := range res.Content {
+		base := q.Questions[i]
+		question := Question{
+			Text:        strings.TrimSpace(row[KeyQuestion]),
+			Options:     strings.Split(row[KeyOptions], ";"),
+			Correct:     append([]int(nil), base.Correct...),
+			Explanation: strings.TrimSpace(row[KeyExplanation]),
+		}
+		for j := range question.Options {
+			question.Options[j] = strings.TrimSpace(question.Options[j])
+		}
+		switch {
+		case question.Text == "":
+			return rowError(q, locale, i+1, ErrInvalidValue, "empty question")
+		case len(question.Options) != len(base.Options):
+			return rowError(q, locale, i+1, ErrOptionsMismatch, "%d options, %d expected", len(question.Options), len(base.Options))
+		case question.Explanation == "" && base.Explanation != "":
+			r.warn(q, locale, "question %d: missing explanation", i+1)
+		}
+		quiz.Questions[i] = question
+	}
+	diff, err := r.getDifficulty(q.parent, locale)
+	if err != nil {
+		return err
+	}
+	diff.SetQuiz(&quiz)
+	return nil
+}
+
+func (r *ResourceParser) category(id, locale string) *Category {
+	for _, c := range r.categories[locale] {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// Remove deletes the translation of the component from the locale, with its descendants.
+// Parsing the component again after a change replaces it in place, Remove is needed only
+// when the component is gone from the base tree.
+func (r *ResourceParser) Remove(cmp Component, locale string) error {
+	path := treePath(cmp)
+	c := r.lookup(locale, path)
+	if c == nil {
+		return &NotFoundError{Path: path, Locale: locale}
+	}
+	r.remove(locale, c)
+	return nil
+}
+
+// lookup returns the component of locale found at the tree path, nil if missing
+func (r *ResourceParser) lookup(locale, path string) Component {
+	p := strings.Split(path, "/")
+	if len(p) == 2 && p[0] == "forms" {
+		if f := r.FormUnsafe(p[1], locale); f != nil {
+			return f
+		}
+		return nil
+	}
+	if len(p) == 2 && p[0] == "glossary" {
+		if g := r.glossary(p[1], locale); g != nil {
+			return g
+		}
+		return nil
+	}
+	cat := r.category(p[0], locale)
+	if cat == nil {
+		return nil
+	}
+	return lookupCategory(cat, p)
+}
+
+// lookupCategory returns the component of cat found at the tree path split in p, nil if missing
+func lookupCategory(cat *Category, p []string) Component {
+	if len(p) == 1 {
+		return cat
+	}
+	sub := cat.Sub(p[1])
+	if sub == nil {
+		return nil
+	}
+	if len(p) == 2 {
+		return sub
+	}
+	diff := sub.Difficulty(p[2])
+	if diff == nil {
+		return nil
+	}
+	switch {
+	case len(p) == 3:
+		return diff
+	case len(p) > 4:
+		return nil
+	case p[3] == suffixChecks:
+		if diff.checklist == nil {
+			return nil
+		}
+		return diff.checklist
+	case p[3] == suffixQuiz:
+		if diff.quiz == nil {
+			return nil
+		}
+		return diff.quiz
+	}
+	if item := diff.Item(p[3]); item != nil {
+		return item
 	}
-	r.getDifficulty(c.parent, locale).SetChecks(&checks)
 	return nil
 }
@@ -59,3 +59,204 @@ func (CmpSuite) TestParseResource(c *C) {
 	err = p.Parse(&legacyCmp, &legacyRes, "it")
 	c.Assert(err, NotNil)
 }
+
+func (CmpSuite) TestResourceKeys(c *C) {
+	cat := testCategory("en", "")
+	sub := cat.Sub("sub")
+	sub.Audience = []string{"kenya"}
+	diff := sub.Difficulty("beginner")
+	diff.AddItem(&Item{ID: "item", Title: "Title", Body: "One\n\nTwo", Abstract: "Summary", Audience: []string{"uganda"}})
+	diff.AddChecks(Check{Text: "First"}, Check{Text: "Second", NoCheck: true})
+	form := &Form{ID: "form", Name: "Form", Screens: []FormScreen{
+		{ID: "start", Name: "Start", Items: []FormInput{{Label: "Label", Hint: "Hint", Options: []string{"a", "b"}}}},
+	}}
+
+	var cmps []Component
+	walkCategory(cat, func(cmp Component) { cmps = append(cmps, cmp) })
+	cmps = append(cmps, form)
+	p := NewResourceParser()
+	for _, cmp := range cmps {
+		var keys = make(map[string]bool)
+		for _, k := range ExpectedKeys(cmp) {
+			keys[k] = true
+		}
+		res := cmp.Resource()
+		for _, row := range res.Content {
+			for k := range row {
+				c.Assert(keys[k], Equals, true, Commentf("%s: %s", treePath(cmp), k))
+			}
+		}
+		c.Assert(p.Parse(cmp, &res, "it"), IsNil)
+		got := p.lookup("it", treePath(cmp))
+		c.Assert(got, NotNil)
+		c.Assert(got.Resource().Content, DeepEquals, res.Content, Commentf(treePath(cmp)))
+	}
+	c.Assert(p.Problems(), HasLen, 0)
+
+	item := p.lookup("it", "cat/sub/beginner/item").(*Item)
+	c.Assert(item.Body, Equals, "One\n\nTwo")
+	c.Assert(item.Abstract, Equals, "Summary")
+	c.Assert(item.Audience, DeepEquals, []string{"uganda"})
+
+	res := &Resource{Content: []map[string]string{{"decription": "Facile"}}}
+	c.Assert(p.Parse(diff, res, "it"), IsNil)
+	c.Assert(p.Problems(), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner", Locale: "it", Message: `unknown key "decription"`},
+		{Path: "cat/sub/beginner", Locale: "it", Message: "empty description"},
+	})
+	c.Assert(ExpectedKeys(&Asset{}), HasLen, 0)
+}
+
+func (CmpSuite) TestResourceParserForms(c *C) {
+	p := NewResourceParser()
+	for _, id := range []string{"zeta", "alpha", "mid"} {
+		form := &Form{ID: id, Screens: []FormScreen{{Name: "One", Items: []FormInput{{Label: "A"}}}}}
+		res := &Resource{Content: []map[string]string{{"form": id}, {"screen": "Uno"}, {"label": "A " + id}}}
+		c.Assert(p.Parse(form, res, "it"), IsNil)
+	}
+	c.Assert(p.Parse(&Form{ID: "alpha"}, &Resource{Content: []map[string]string{{"form": "Alpha"}}}, "en"), IsNil)
+
+	forms := p.Forms()
+	c.Assert(forms, HasLen, 2)
+	var ids []string
+	for _, f := range forms["it"] {
+		ids = append(ids, f.ID)
+	}
+	c.Assert(ids, DeepEquals, []string{"alpha", "mid", "zeta"})
+	c.Assert(forms["it"][0].Screens[0].Items[0].Label, Equals, "A alpha")
+	c.Assert(forms["en"][0].Name, Equals, "Alpha")
+
+	// the order of the parser is kept
+	c.Assert(p.forms["it"][0].ID, Equals, "zeta")
+	forms["it"][0] = nil
+	c.Assert(p.Forms()["it"][0], NotNil)
+
+	c.Assert(p.Form("mid", "it"), DeepEquals, forms["it"][1])
+	c.Assert(p.Form("mid", "en"), IsNil)
+	c.Assert(p.Form("missing", "it"), IsNil)
+}
+
+func (CmpSuite) TestParseFormInputTypes(c *C) {
+	form := &Form{ID: "types", Screens: []FormScreen{{Name: "One", Items: []FormInput{
+		{Type: InputText, Name: "text", Label: "Text"},
+		{Type: InputSingleChoice, Name: "choice", Label: "Choice", Options: []string{"a", "b", "c"}},
+		{Name: "untyped", Label: "Untyped"},
+	}}}}
+	parse := func(text, choice, untyped string) error {
+		return NewResourceParser().Parse(form, &Resource{Content: []map[string]string{
+			{"form": "Tipi"}, {"screen": "Uno"},
+			{"label": "Testo", "options": text},
+			{"label": "Scelta", "options": choice},
+			{"label": "Senza tipo", "options": untyped},
+		}}, "it")
+	}
+	p := NewResourceParser()
+	c.Assert(p.Parse(form, &Resource{Content: []map[string]string{
+		{"form": "Tipi"}, {"screen": "Uno"}, {"label": "Testo"}, {"label": "Scelta", "options": "x;y;z"}, {"label": "Senza tipo", "options": "dropped"},
+	}}, "it"), IsNil)
+	inputs := p.forms["it"][0].Screens[0].Items
+	c.Assert(inputs, DeepEquals, []FormInput{
+		{Type: InputText, Name: "text", Label: "Testo"},
+		{Type: InputSingleChoice, Name: "choice", Label: "Scelta", Options: []string{"x", "y", "z"}},
+		{Name: "untyped", Label: "Senza tipo"},
+	})
+
+	for _, tc := range []struct {
+		text, choice, message string
+		input                 int
+	}{
+		{"p;q", "x;y;z", `Form "types", screen 1, input 1: 2 options, text_input has none`, 1},
+		{"", "x;y", `Form "types", screen 1, input 2: 2 options, 3 expected`, 2},
+		{"", "", `Form "types", screen 1, input 2: 1 options, 3 expected`, 2},
+		{"", "x;;", `Form "types", screen 1, input 2: 1 non-empty options, single_choice needs at least 2`, 2},
+	} {
+		err := parse(tc.text, tc.choice, "")
+		c.Assert(err, FitsTypeOf, &ParseError{})
+		c.Assert(err.(*ParseError).Message, Equals, tc.message)
+		c.Assert(err.(*ParseError).Screen, Equals, 1)
+		c.Assert(err.(*ParseError).Input, Equals, tc.input)
+	}
+}
+
+func (CmpSuite) TestResourceParserReparse(c *C) {
+	p := bilingualParser()
+	en := p.categories["en"][0]
+	diff := en.Sub("sub").Difficulty("beginner")
+	item, other := diff.Item("item"), diff.Item("other")
+	c.Assert(p.Parse(other, &Resource{Content: []map[string]string{{"title": "Altro", "body": "Tre"}}}, "it"), IsNil)
+
+	// an item parsed again is replaced, keeping its position
+	c.Assert(p.Parse(item, &Resource{Content: []map[string]string{{"title": "Titolo", "body": "Uno\n\nDue"}}}, "it"), IsNil)
+	it := p.categories["it"][0].Sub("sub").Difficulty("beginner")
+	c.Assert(it.ItemNames(), DeepEquals, []string{"item", "other"})
+	c.Assert(it.Item("item").Body, Equals, "Uno\n\nDue")
+
+	// so is a category with a new name
+	c.Assert(p.Parse(&Category{ID: "new"}, &Resource{Content: []map[string]string{{"name": "Nuova"}}}, "it"), IsNil)
+	for _, name := range []string{"Categoria", "Categoria rinominata"} {
+		c.Assert(p.Parse(en, &Resource{Content: []map[string]string{{"name": name}}}, "it"), IsNil)
+	}
+	cats := p.Categories()["it"]
+	c.Assert(cats, HasLen, 2)
+	c.Assert([]string{cats[0].ID, cats[0].Name, cats[1].ID}, DeepEquals, []string{"cat", "Categoria rinominata", "new"})
+	c.Assert(cats[0].Sub("sub").Difficulty("beginner").ItemNames(), DeepEquals, []string{"item", "other"})
+
+	// a component gone from the base is removed explicitly
+	c.Assert(p.Remove(other, "it"), IsNil)
+	c.Assert(it.ItemNames(), DeepEquals, []string{"item"})
+	c.Assert(p.Remove(other, "it"), DeepEquals, &NotFoundError{Path: "cat/sub/beginner/other", Locale: "it"})
+	c.Assert(p.Parse(other, &Resource{Content: []map[string]string{{"title": "Altro", "body": "Tre"}}}, "it"), IsNil)
+	c.Assert(it.ItemNames(), DeepEquals, []string{"item", "other"})
+}
+
+func (CmpSuite) TestParseChecklistLenient(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.AddChecks(Check{Text: "One"}, Check{Text: "Two", NoCheck: true, Style: StyleTip}, Check{Text: "Three"})
+	list := diff.Checks()
+	rows := []map[string]string{nil, {"text": "Uno"}, nil, {"text": " "}}
+
+	// strict: nil rows are skipped, missing rows are an error
+	err := NewResourceParser().Parse(list, &Resource{Content: rows}, "it")
+	c.Assert(err, ErrorMatches, `cat/sub/beginner/\.checks \(it\): 2 checks, 3 expected`)
+
+	p := NewResourceParser()
+	p.SetStrictChecklists(false)
+	c.Assert(p.Parse(list, &Resource{Content: rows}, "it"), IsNil)
+	checks := p.categories["it"][0].Sub("sub").Difficulty("beginner").checklist.Checks
+	c.Assert(checks, DeepEquals, []Check{
+		{Text: "Uno"},
+		{Text: "Two", NoCheck: true, Style: StyleTip, Untranslated: true},
+		{Text: "Three", Untranslated: true},
+	})
+	c.Assert(p.Problems(), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/.checks", Locale: "it", Message: "check 2: missing, base text kept"},
+		{Path: "cat/sub/beginner/.checks", Locale: "it", Message: "check 3: missing, base text kept"},
+	})
+	p.categories["en"] = []*Category{cat}
+	c.Assert(p.Coverage("en")["it"].Kinds["check"], DeepEquals, KindCoverage{3, 1, percent(1, 3)})
+	c.Assert(p.Parse(list, &Resource{Content: append(rows, map[string]string{"text": "A"}, map[string]string{"text": "B"})}, "it"),
+		ErrorMatches, `.* 4 checks, 3 expected`)
+}
+
+func (CmpSuite) TestParseChecklistIDs(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	diff.AddChecks(Check{Text: "One", ID: "one"}, Check{Text: "Two", ID: "two"})
+	list := diff.Checks()
+
+	rows := []map[string]string{{"text": "Due", "id": "two"}, {"text": "Uno", "id": "one"}}
+	p := NewResourceParser()
+	c.Assert(p.Parse(list, &Resource{Content: rows}, "it"), IsNil)
+	c.Assert(p.categories["it"][0].Sub("sub").Difficulty("beginner").checklist.Checks, DeepEquals, []Check{
+		{Text: "Uno", ID: "one"}, {Text: "Due", ID: "two"},
+	})
+
+	for _, rows := range [][]map[string]string{
+		{{"text": "Uno", "id": "one"}, {"text": "Due"}},
+		{{"text": "Uno", "id": "one"}, {"text": "Tre", "id": "three"}},
+		{{"text": "Uno", "id": "one"}, {"text": "Uno", "id": "one"}},
+	} {
+		c.Assert(NewResourceParser().Parse(list, &Resource{Content: rows}, "it"), ErrorMatches, `.*row 2.*check ID.*`)
+	}
+}
@@ -0,0 +1,115 @@
+package component
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// resourceExt is the extension of the files written by WriteResourcesCSV
+const resourceExt = ".csv"
+
+// ExportResources returns the resources of all the components of the locale, keyed by path,
+// as returned by Encode.
+func (r *ResourceParser) ExportResources(locale string) (map[string]*Resource, error) {
+	var m = make(map[string]*Resource)
+	for _, c := range r.localeComponents(locale) {
+		res, err := r.Encode(c, locale)
+		if err != nil {
+			return nil, err
+		}
+		m[treePath(c)] = res
+	}
+	return m, nil
+}
+
+// WriteResourcesCSV writes the resources of the locale in dir/locale, a CSV file for each
+// component at its path: the header has the keys of the rows and empty cells are missing keys.
+// The files can be read with ReadResourcesCSV.
+func (r *ResourceParser) WriteResourcesCSV(dir, locale string) error {
+	resources, err := r.ExportResources(locale)
+	if err != nil {
+		return err
+	}
+	var paths = make([]string, 0, len(resources))
+	for path := range resources {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		name := filepath.Join(dir, locale, filepath.FromSlash(path)+resourceExt)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		if err := writeResourceCSV(name, resources[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeResourceCSV(name string, res *Resource) error {
+	var keys = make(map[string]string)
+	for _, row := range res.Content {
+		for k := range row {
+			keys[k] = k
+		}
+	}
+	header := sortedKeys(keys)
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write(header)
+	for _, row := range res.Content {
+		var record = make([]string, len(header))
+		for i, k := range header {
+			record[i] = row[k]
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadResourcesCSV reads the resources of the locale written by WriteResourcesCSV in dir,
+// keyed by path.
+func ReadResourcesCSV(dir, locale string) (map[string]*Resource, error) {
+	var m = make(map[string]*Resource)
+	root := filepath.Join(dir, locale)
+	err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(name, resourceExt) {
+			return err
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		res, err := readResourceCSV(name)
+		if err != nil {
+			return err
+		}
+		m[strings.TrimSuffix(filepath.ToSlash(rel), resourceExt)] = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func readResourceCSV(name string) (*Resource, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return CSVDecoder{}.Decode(f)
+}
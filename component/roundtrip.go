@@ -0,0 +1,122 @@
+package component
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VerifyRoundTrip parses each request into a scratch parser, encodes the component it produces
+// with Resource and compares the result with the original content, returning a problem for
+// each value that is lost or changed. Before comparing, values are normalized: line endings
+// become "\n", notes are removed, spaces are trimmed, empty values are ignored and bodies are
+// compared paragraph by paragraph. Values the encoding adds, like screen IDs, are not losses.
+func (r *ResourceParser) VerifyRoundTrip(batch []ParseRequest) []Problem {
+	var problems []Problem
+	for _, req := range batch {
+		p, err := r.roundTrip(req)
+		if err != nil {
+			p = []Problem{{Path: treePath(req.Component), Locale: req.Locale, Message: err.Error()}}
+		}
+		problems = append(problems, p...)
+	}
+	return problems
+}
+
+// roundTrip returns the losses of the request, or the error of parsing it
+func (r *ResourceParser) roundTrip(req ParseRequest) ([]Problem, error) {
+	var (
+		path    = treePath(req.Component)
+		scratch = NewResourceParser()
+	)
+	problem := func(format string, a ...interface{}) Problem {
+		return Problem{Path: path, Locale: req.Locale, Message: fmt.Sprintf(format, a...)}
+	}
+	scratch.strict, scratch.nameLength, scratch.descrLength, scratch.resync = r.strict, r.nameLength, r.descrLength, r.resync
+	if err := scratch.Parse(req.Component, req.Resource, req.Locale); err != nil {
+		return nil, err
+	}
+	c := scratch.lookup(req.Locale, path)
+	if c == nil {
+		return []Problem{problem("nothing parsed")}, nil
+	}
+	var (
+		original = roundTripValues(req.Resource.Content)
+		encoded  = roundTripValues(c.Resource().Content)
+		problems []Problem
+	)
+	for _, f := range original.keys {
+		src, dst := original.values[f], encoded.values[f]
+		for i := range src {
+			switch {
+			case i >= len(dst):
+				problems = append(problems, problem("%s %d: %q lost", f, i+1, src[i]))
+			case src[i] != dst[i]:
+				problems = append(problems, problem("%s %d: %q encoded as %q", f, i+1, src[i], dst[i]))
+			}
+		}
+	}
+	return problems, nil
+}
+
+type fieldValues struct {
+	keys   []string // in order of appearance
+	values map[string][]string
+}
+
+// roundTripValues returns the normalized values of the rows, by key
+func roundTripValues(rows []map[string]string) fieldValues {
+	var f = fieldValues{values: make(map[string][]string)}
+	add := func(k, v string) {
+		if _, ok := f.values[k]; !ok {
+			f.keys = append(f.keys, k)
+		}
+		f.values[k] = append(f.values[k], v)
+	}
+	for _, row := range rows {
+		for _, k := range sortedKeys(row) {
+			v := stripNotes(strings.Replace(row[k], "\r\n", "\n", -1))
+			if k == KeyBody {
+				for _, p := range SplitBody(v) {
+					add(k, p)
+				}
+				continue
+			}
+			if v = strings.TrimSpace(v); v != "" {
+				add(k, v)
+			}
+		}
+	}
+	return f
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RoundTrip makes ParseAll verify each request with VerifyRoundTrip before parsing it.
+// In strict mode a loss fails the request, otherwise it's reported as a problem.
+func RoundTrip() Option { return func(o *options) { o.roundTrip = true } }
+
+// checkRoundTrip verifies the request for ParseAll, see RoundTrip. Parsing errors are left
+// to the parse that follows.
+func (r *ResourceParser) checkRoundTrip(req ParseRequest) error {
+	problems, err := r.roundTrip(req)
+	if err != nil || len(problems) == 0 {
+		return nil
+	}
+	if !r.strict {
+		r.problems = append(r.problems, problems...)
+		return nil
+	}
+	var msgs = make([]string, len(problems))
+	for i, p := range problems {
+		msgs[i] = p.Message
+	}
+	return fmt.Errorf("round trip: %s", strings.Join(msgs, "; "))
+}
@@ -0,0 +1,75 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func roundTripForm(bOptions string) []ParseRequest {
+	return []ParseRequest{{Component: errorForm(), Locale: "it", Resource: &Resource{Content: []map[string]string{
+		{"form": "Modulo"},
+		{"screen": "Uno"},
+		{"label": "A", "options": "x;y"},
+		{"label": "B", "hint": "Suggerimento", "options": bOptions},
+		{"screen": "Due"},
+		{"label": "C"},
+		{"label": "D"},
+	}}}}
+}
+
+func (CmpSuite) TestVerifyRoundTrip(c *C) {
+	p := NewResourceParser()
+
+	// clean, with the normalized differences
+	clean := itemBatch("Voce")
+	clean[1].Resource.Content = []map[string]string{
+		{"title": " Voce\r\n", "summary": "Riassunto"},
+		{"body": "uno\r\n\r\ndue [[note: check]]"},
+		{"body": "tre"},
+	}
+	c.Assert(p.VerifyRoundTrip(append(clean, roundTripForm("")...)), HasLen, 0)
+
+	// the options of an input without options are dropped
+	c.Assert(p.VerifyRoundTrip(roundTripForm("p;q")), DeepEquals, []Problem{
+		{Path: "forms/form", Locale: "it", Message: `options 2: "p;q" lost`},
+	})
+
+	// the summary is read only from the first row
+	lossy := itemBatch("Voce")
+	lossy[1].Resource.Content = []map[string]string{{"title": "Voce"}, {"body": "uno", "summary": "Riassunto"}}
+	c.Assert(p.VerifyRoundTrip(lossy), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/item", Locale: "it", Message: `summary 1: "Riassunto" lost`},
+	})
+
+	// parse errors are reported
+	lossy[1].Resource = &Resource{}
+	c.Assert(p.VerifyRoundTrip(lossy), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/item", Locale: "it", Message: "Invalid content"},
+	})
+	c.Assert(p.Problems(), HasLen, 0)
+}
+
+func (CmpSuite) TestParseAllRoundTrip(c *C) {
+	// lenient parsers report the loss and parse anyway
+	p := NewResourceParser()
+	s, errs := p.ParseAll(roundTripForm("p;q"), RoundTrip())
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s.Applied, Equals, 1)
+	c.Assert(p.Problems(), DeepEquals, []Problem{
+		{Path: "forms/form", Locale: "it", Message: `options 2: "p;q" lost`},
+	})
+
+	// strict parsers reject it
+	p = NewResourceParser()
+	p.SetStrict(true)
+	s, errs = p.ParseAll(append(roundTripForm("p;q"), itemBatch("Voce")...), RoundTrip())
+	c.Assert(s.Applied, Equals, 2)
+	c.Assert(s.Failed, Equals, 1)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `forms/form \(it\): round trip: options 2: "p;q" lost`)
+	c.Assert(p.forms["it"], HasLen, 0)
+
+	// without the option nothing is checked
+	s, errs = p.ParseAll(roundTripForm("p;q"))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s.Applied, Equals, 1)
+}
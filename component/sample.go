@@ -0,0 +1,120 @@
+package component
+
+import (
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// ItemRef points to a component of a locale
+type ItemRef struct {
+	Locale string `json:"locale"`
+	Path   string `json:"path"`
+}
+
+func (i ItemRef) String() string { return i.Locale + ":" + i.Path }
+
+// SampleSpec describes the sample returned by Sample
+type SampleSpec struct {
+	PerCategory int      // items per category, raised to cover every difficulty
+	Changed     []string // paths of recently changed components, preferred over the others
+}
+
+// Sample returns a deterministic selection of items for each category of the locale,
+// with at least one item per difficulty, one checklist per category and one form.
+// The selection prefers changed items and longer bodies, ties are broken using
+// a seed computed from the locale tree, so the same tree always yields the same sample.
+func (r *ResourceParser) Sample(locale string, spec SampleSpec) []ItemRef {
+	var (
+		rng     = rand.New(rand.NewSource(r.treeSeed(locale)))
+		changed = make(map[string]bool, len(spec.Changed))
+		refs    []ItemRef
+	)
+	for _, p := range spec.Changed {
+		changed[p] = true
+	}
+	for _, cat := range r.categories[locale] {
+		var (
+			items  []*Item
+			checks []*Checklist
+		)
+		walkCategory(cat, func(c Component) {
+			switch v := c.(type) {
+			case *Item:
+				items = append(items, v)
+			case *Checklist:
+				checks = append(checks, v)
+			}
+		})
+		for _, i := range sampleItems(items, spec.PerCategory, changed, rng) {
+			refs = append(refs, ItemRef{locale, treePath(i)})
+		}
+		if len(checks) != 0 {
+			refs = append(refs, ItemRef{locale, treePath(checks[rng.Intn(len(checks))])})
+		}
+	}
+	if forms := r.forms[locale]; len(forms) != 0 {
+		refs = append(refs, ItemRef{locale, treePath(forms[rng.Intn(len(forms))])})
+	}
+	return refs
+}
+
+// sampleItems picks n items, covering every difficulty, and returns them in tree order
+func sampleItems(items []*Item, n int, changed map[string]bool, rng *rand.Rand) []*Item {
+	var (
+		tie    = rng.Perm(len(items))
+		ranked = make([]int, len(items))
+	)
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.Slice(ranked, func(a, b int) bool {
+		x, y := items[ranked[a]], items[ranked[b]]
+		if cx, cy := changed[treePath(x)], changed[treePath(y)]; cx != cy {
+			return cx
+		}
+		if len(x.Body) != len(y.Body) {
+			return len(x.Body) > len(y.Body)
+		}
+		return tie[ranked[a]] < tie[ranked[b]]
+	})
+	var (
+		picked  = make(map[int]bool)
+		covered = make(map[*Difficulty]bool)
+	)
+	for _, i := range ranked {
+		if d := items[i].parent; !covered[d] {
+			covered[d], picked[i] = true, true
+		}
+	}
+	for _, i := range ranked {
+		if len(picked) >= n {
+			break
+		}
+		picked[i] = true
+	}
+	var list []*Item
+	for i := range items {
+		if picked[i] {
+			list = append(list, items[i])
+		}
+	}
+	return list
+}
+
+// treeSeed returns a seed computed from the contents of the locale
+func (r *ResourceParser) treeSeed(locale string) int64 {
+	h := fnv.New64a()
+	write := func(c Component) {
+		io.WriteString(h, treePath(c))
+		io.WriteString(h, c.Contents())
+	}
+	for _, cat := range r.categories[locale] {
+		walkCategory(cat, write)
+	}
+	for _, f := range r.forms[locale] {
+		write(f)
+	}
+	return int64(h.Sum64())
+}
@@ -0,0 +1,53 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func sampleParser() *ResourceParser {
+	p := NewResourceParser()
+	cat := testCategory("en", "")
+	sub := cat.Sub("sub")
+	sub.AddDifficulty(&Difficulty{ID: "expert", Descr: "Hard"})
+	for d, n := range map[string]int{"beginner": 4, "expert": 2} {
+		diff := sub.Difficulty(d)
+		for i := 0; i < n; i++ {
+			diff.AddItem(&Item{ID: fmt.Sprint("item", i), Order: float64(i), Title: "Title", Body: strings.Repeat("word ", i+1)})
+		}
+		diff.AddChecks(Check{Text: "Check " + d})
+	}
+	p.categories["en"] = []*Category{cat}
+	p.forms["en"] = []*Form{{ID: "form", Name: "Form"}}
+	return p
+}
+
+func (CmpSuite) TestSample(c *C) {
+	p := sampleParser()
+	spec := SampleSpec{PerCategory: 3}
+	sample := p.Sample("en", spec)
+	c.Assert(sample, DeepEquals, p.Sample("en", spec))
+	c.Assert(sample[:3], DeepEquals, []ItemRef{
+		{"en", "cat/sub/beginner/item2"},
+		{"en", "cat/sub/beginner/item3"},
+		{"en", "cat/sub/expert/item1"},
+	})
+	c.Assert(sample[3].Path, Matches, `cat/sub/(beginner|expert)/\.checks`)
+	c.Assert(sample[4], Equals, ItemRef{"en", "forms/form"})
+
+	spec.Changed = []string{"cat/sub/beginner/item0"}
+	changed := p.Sample("en", spec)
+	c.Assert(changed[:3], DeepEquals, []ItemRef{
+		{"en", "cat/sub/beginner/item0"},
+		{"en", "cat/sub/beginner/item3"},
+		{"en", "cat/sub/expert/item1"},
+	})
+
+	c.Assert(p.Sample("en", SampleSpec{PerCategory: 1})[:2], DeepEquals, []ItemRef{
+		{"en", "cat/sub/beginner/item3"},
+		{"en", "cat/sub/expert/item1"},
+	})
+	c.Assert(p.Sample("it", spec), HasLen, 0)
+}
@@ -0,0 +1,101 @@
+package component
+
+import "strings"
+
+const hexDigits = "0123456789ABCDEF"
+
+// slugMarker starts the slugs of EncodeSlug, and tells them from the legacy ones, that start
+// with the ID of a category. Category IDs never start with it: see checkSlugs.
+const slugMarker = "_v2_"
+
+// EncodeSlug returns slugMarker followed by the parts joined with "__". Letters, digits and "-"
+// are kept, any other byte of a part, including "_", is written as "_" and its uppercase hex
+// value, so the slug only has the characters allowed by Transifex and DecodeSlug returns the
+// same parts.
+func EncodeSlug(parts ...string) string {
+	if len(parts) == 0 {
+		return slugMarker
+	}
+	return appendSlug(slugMarker+escapeSlug(parts[0]), parts[1:]...)
+}
+
+// appendSlug returns the slug of EncodeSlug with more parts
+func appendSlug(slug string, parts ...string) string {
+	var b strings.Builder
+	b.WriteString(slug)
+	for _, p := range parts {
+		b.WriteString("__")
+		b.WriteString(escapeSlug(p))
+	}
+	return b.String()
+}
+
+func escapeSlug(p string) string {
+	var b strings.Builder
+	for j := 0; j < len(p); j++ {
+		if c := p[j]; slugChar(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xf])
+		}
+	}
+	return b.String()
+}
+
+// DecodeSlug returns the parts of a slug of EncodeSlug, ErrSlug if s is not one. Escapes of
+// bytes that need none are rejected, so every slug has a single encoding.
+func DecodeSlug(s string) ([]string, error) {
+	if !strings.HasPrefix(s, slugMarker) {
+		return nil, ErrSlug
+	}
+	s = s[len(slugMarker):]
+	var (
+		parts []string
+		b     []byte
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case slugChar(c):
+			b = append(b, c)
+		case c != '_':
+			return nil, ErrSlug
+		case i+1 < len(s) && s[i+1] == '_':
+			parts, b = append(parts, string(b)), nil
+			i++
+		case i+2 < len(s):
+			hi, lo := strings.IndexByte(hexDigits, s[i+1]), strings.IndexByte(hexDigits, s[i+2])
+			if hi < 0 || lo < 0 || slugChar(byte(hi<<4|lo)) {
+				return nil, ErrSlug
+			}
+			b = append(b, byte(hi<<4|lo))
+			i += 2
+		default:
+			return nil, ErrSlug
+		}
+	}
+	return append(parts, string(b)), nil
+}
+
+// SplitSlug returns the parts of a slug of EncodeSlug, or of a legacy slug without the
+// marker, whose parts are joined by "_" and "___". Only a slug with the marker can be invalid.
+// A component has the same parts in both formats, unless one of its IDs has a "_", so
+// EncodeSlug converts the legacy slug of a component to the current one.
+func SplitSlug(s string) ([]string, error) {
+	if strings.HasPrefix(s, slugMarker) {
+		return DecodeSlug(s)
+	}
+	return splitSlug(s), nil
+}
+
+func splitSlug(s string) []string {
+	s = strings.Replace(s, "_", "|", -1)
+	s = strings.Replace(s, "|||", "|_|", -1)
+	return strings.Split(s, "|")
+}
+
+func slugChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-'
+}
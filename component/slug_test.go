@@ -0,0 +1,138 @@
+package component
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"testing/quick"
+)
+
+func TestSlug(t *testing.T) {
+	for _, tc := range []struct {
+		parts []string
+		slug  string
+	}{
+		{[]string{"cat"}, "_v2_cat"},
+		{[]string{"cat", "sub", "two_factor_auth"}, "_v2_cat__sub__two_5Ffactor_5Fauth"},
+		{[]string{"a|b", "", "-"}, "_v2_a_7Cb____-"},
+		{[]string{"città"}, "_v2_citt_C3_A0"},
+		{[]string{"_", "__"}, "_v2__5F___5F_5F"},
+	} {
+		if s := EncodeSlug(tc.parts...); s != tc.slug {
+			t.Errorf("%q: expected %q, got %q", tc.parts, tc.slug, s)
+		}
+		if p, err := DecodeSlug(tc.slug); err != nil || !reflect.DeepEqual(p, tc.parts) {
+			t.Errorf("%q: expected %q, got %q %v", tc.slug, tc.parts, p, err)
+		}
+	}
+	for _, s := range []string{"cat", "_v2_cat_sub", "_v2_cat___sub", "_v2_a_", "_v2_a_5", "_v2_a_5f", "_v2_a_41", "_v2_a|b", "_v2_a b"} {
+		if p, err := DecodeSlug(s); err != ErrSlug {
+			t.Errorf("%q: expected %v, got %q %v", s, ErrSlug, p, err)
+		}
+	}
+	// legacy slugs
+	for s, parts := range map[string][]string{
+		"cat_sub_dif_item":    {"cat", "sub", "dif", "item"},
+		"cat___sub___dif":     {"cat", "_", "sub", "_", "dif"},
+		"cat__sub_two_factor": {"cat", "", "sub", "two", "factor"},
+		"cat__sub__dif":       {"cat", "", "sub", "", "dif"},
+		"cat_sub_2E":          {"cat", "sub", "2E"},
+	} {
+		if p, err := SplitSlug(s); err != nil || !reflect.DeepEqual(p, parts) {
+			t.Errorf("%q: expected %q, got %q %v", s, parts, p, err)
+		}
+	}
+	if _, err := SplitSlug("_v2_a_41"); err != ErrSlug {
+		t.Errorf("expected %v, got %v", ErrSlug, err)
+	}
+}
+
+// slugParts are random parts made of few characters, so separators and escapes are frequent
+type slugParts []string
+
+func (slugParts) Generate(r *rand.Rand, size int) reflect.Value {
+	chars := []string{"a", "Z", "0", "-", "_", "__", "|", "%", "à", "字", "\x00", "\xff"}
+	var parts = make(slugParts, 1+r.Intn(4))
+	for i := range parts {
+		for n := r.Intn(size + 1); n > 0; n-- {
+			parts[i] += chars[r.Intn(len(chars))]
+		}
+	}
+	return reflect.ValueOf(parts)
+}
+
+func TestSlugRoundTrip(t *testing.T) {
+	err := quick.Check(func(parts slugParts) bool {
+		s := EncodeSlug(parts...)
+		for i := 0; i < len(s); i++ {
+			if !slugChar(s[i]) && s[i] != '_' {
+				return false
+			}
+		}
+		p, err := DecodeSlug(s)
+		split, _ := SplitSlug(s)
+		return err == nil && reflect.DeepEqual(p, []string(parts)) && reflect.DeepEqual(split, []string(parts))
+	}, &quick.Config{MaxCount: 2000})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSlugTree checks the slugs of parsed components with "_" in their IDs, and that
+// translations keyed by slug go back to their components
+func TestSlugTree(t *testing.T) {
+	fsys := fstest.MapFS{
+		"contents_en/two_factor/.metadata.md":                        {Data: []byte("[Name]: # (Category)\n[Order]: # (1)")},
+		"contents_en/two_factor/a_b/.metadata.md":                    {Data: []byte("[Name]: # (Sub)\n[Order]: # (1)")},
+		"contents_en/two_factor/a_b/beginner/.metadata.md":           {Data: []byte("[Description]: # (Easy)")},
+		"contents_en/two_factor/a_b/beginner/.checks.md":             {Data: []byte("[Text]: # (Check)\n[NoCheck]: # (false)")},
+		"contents_en/two_factor/a_b/beginner/two_factor_auth.md":     {Data: []byte("[Title]: # (Auth)\n[Order]: # (1)\n\nBody")},
+		"contents_en/two_factor/a_b/beginner/two_5Ffactor_5Fauth.md": {Data: []byte("[Title]: # (Escaped)\n[Order]: # (2)\n\nBody")},
+		"forms_en/my_form.md":                                        {Data: []byte("[Name]: # (Form)")},
+	}
+	var p Parser
+	if err := p.ParseFS(fsys); err != nil {
+		t.Fatal(err)
+	}
+	var cmps []Component
+	walkCategory(p.Categories()["en"][0], func(c Component) { cmps = append(cmps, c) })
+	cmps = append(cmps, p.Forms()[0])
+	expected := [][]string{
+		{"two_factor"},
+		{"two_factor", "a_b"},
+		{"two_factor", "a_b", "beginner"},
+		{"two_factor", "a_b", "beginner", "two_factor_auth"},
+		{"two_factor", "a_b", "beginner", "two_5Ffactor_5Fauth"},
+		{"two_factor", "a_b", "beginner", "", "checks"},
+		{"forms", "_", "my_form"},
+	}
+	if len(cmps) != len(expected) {
+		t.Fatalf("expected %d components, got %d", len(expected), len(cmps))
+	}
+	translations := make(map[string][]map[string]string)
+	for i, c := range cmps {
+		slug := c.Resource().Slug
+		if parts, err := SplitSlug(slug); err != nil || !reflect.DeepEqual(parts, expected[i]) {
+			t.Errorf("%s: expected %q, got %q %v", slug, expected[i], parts, err)
+		}
+		if _, ok := translations[slug]; ok {
+			t.Errorf("%s: duplicate slug", slug)
+		}
+		translations[slug] = c.Resource().Content
+	}
+	translations[EncodeSlug("two_factor", "a_b", "beginner", "two_factor_auth")][0][KeyTitle] = "Autenticazione"
+
+	r := NewResourceParser()
+	for _, c := range cmps {
+		res := c.Resource()
+		res.Content = translations[res.Slug]
+		if err := r.Parse(c, &res, "it"); err != nil {
+			t.Fatalf("%s: %s", res.Slug, err)
+		}
+	}
+	diff := r.Categories()["it"][0].Sub("a_b").Difficulty("beginner")
+	if a, b := diff.Item("two_factor_auth").Title, diff.Item("two_5Ffactor_5Fauth").Title; a != "Autenticazione" || b != "Escaped" {
+		t.Errorf("unexpected titles %q and %q", a, b)
+	}
+}
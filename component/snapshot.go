@@ -0,0 +1,132 @@
+package component
+
+import "strings"
+
+// Snapshot is a copy of the content of a parser that does not change: it can be read from
+// any number of goroutines while the parser goes on parsing, and replaced by a new one when
+// the parsing is done. Every method returns copies, so changing them does not change it.
+type Snapshot struct {
+	r *ResourceParser
+}
+
+// Snapshot returns a copy of the categories and forms of the parser, with the components still
+// to be translated and the settings of the parser, except its listeners, so that Reparse
+// parses like it.
+func (r *ResourceParser) Snapshot() *Snapshot {
+	s := NewResourceParser()
+	for l, cats := range r.categories {
+		list := make([]*Category, len(cats))
+		for i, c := range cats {
+			list[i] = c.Copy()
+		}
+		s.categories[l] = list
+	}
+	for l, forms := range r.forms {
+		list := make([]*Form, len(forms))
+		for i, f := range forms {
+			list[i] = f.Copy()
+		}
+		s.forms[l] = list
+	}
+	for l, glossaries := range r.glossaries {
+		list := make([]*Glossary, len(glossaries))
+		for i, g := range glossaries {
+			list[i] = g.Copy()
+		}
+		s.glossaries[l] = list
+	}
+	s.settings = r.settings.clone()
+	s.listeners = nil
+	if r.images != nil {
+		s.images = make(map[string]ImageInfo, len(r.images))
+		for k, v := range r.images {
+			s.images[k] = v
+		}
+	}
+	s.renames = append([][2]string(nil), r.renames...)
+	for l, pending := range r.pending {
+		if s.pending == nil {
+			s.pending = make(map[string]map[string]bool)
+		}
+		s.pending[l] = make(map[string]bool, len(pending))
+		for p := range pending {
+			s.pending[l][p] = true
+		}
+	}
+	return &Snapshot{r: s}
+}
+
+// Locales returns the locales with content, see ResourceParser.Locales
+func (s *Snapshot) Locales(opts ...Option) []string { return s.r.Locales(opts...) }
+
+// Categories returns the categories of the locale sorted, see ResourceParser.SortedCategories
+func (s *Snapshot) Categories(locale string) []*Category { return s.r.SortedCategories(locale) }
+
+// Category returns the category of the locale, nil if missing
+func (s *Snapshot) Category(id, locale string) *Category {
+	if c := s.r.category(id, locale); c != nil {
+		return c.Copy()
+	}
+	return nil
+}
+
+// Form returns the form of the locale, nil if missing
+func (s *Snapshot) Form(id, locale string) *Form { return s.r.Form(id, locale) }
+
+// Export returns the content of the locale, see ResourceParser.Export
+func (s *Snapshot) Export(locale string) (*Tree, error) { return s.r.Export(locale) }
+
+// Resolve returns the component at the path, see ResourceParser.Resolve
+func (s *Snapshot) Resolve(locale, path string) (Component, error) { return s.r.Resolve(locale, path) }
+
+// Encode returns the resource of a component, see ResourceParser.Encode
+func (s *Snapshot) Encode(cmp Component, locale string) (*Resource, error) {
+	return s.r.Encode(cmp, locale)
+}
+
+// Problems returns the warnings of the Reparse that made the snapshot, nil for the one of a
+// parser
+func (s *Snapshot) Problems() []Problem { return append([]Problem(nil), s.r.Problems()...) }
+
+// Reparse returns a new snapshot with the translation of the component in the locale parsed
+// from the resource, like Parse, leaving s as it is. Only the category, form or glossary of
+// the component is copied, the rest is shared with s, so the cost does not depend on the size
+// of the content: a preview can show a change without parsing everything again.
+func (s *Snapshot) Reparse(cmp Component, res *Resource, locale string) (*Snapshot, error) {
+	n := *s.r
+	n.categories = make(map[string][]*Category, len(s.r.categories))
+	for l, list := range s.r.categories {
+		n.categories[l] = append([]*Category(nil), list...)
+	}
+	n.forms = make(map[string][]*Form, len(s.r.forms))
+	for l, list := range s.r.forms {
+		n.forms[l] = append([]*Form(nil), list...)
+	}
+	n.glossaries = make(map[string][]*Glossary, len(s.r.glossaries))
+	for l, list := range s.r.glossaries {
+		n.glossaries[l] = append([]*Glossary(nil), list...)
+	}
+	n.problems, n.finalized = nil, nil
+	if pending, ok := s.r.pending[locale]; ok {
+		n.pending = make(map[string]map[string]bool, len(s.r.pending))
+		for l, m := range s.r.pending {
+			n.pending[l] = m
+		}
+		n.pending[locale] = make(map[string]bool, len(pending))
+		for p := range pending {
+			n.pending[locale][p] = true
+		}
+	}
+	// forms and glossaries are replaced by Parse, categories are changed in place
+	if p := strings.Split(treePath(cmp), "/"); len(p) != 2 || p[0] != "forms" && p[0] != "glossary" {
+		for i, c := range n.categories[locale] {
+			if c.ID == p[0] {
+				n.categories[locale][i] = c.Copy()
+			}
+		}
+	}
+	if err := n.Parse(cmp, res, locale); err != nil {
+		return nil, err
+	}
+	return &Snapshot{r: &n}, nil
+}
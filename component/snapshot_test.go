@@ -0,0 +1,88 @@
+package component
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestSnapshot(c *C) {
+	p := localeFixture()
+	p.SetFallbackLocale("en")
+	s := p.Snapshot()
+	expected, err := p.Export("en")
+	c.Assert(err, IsNil)
+
+	// the snapshot does not follow the parser, and can be read while it parses
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				t, err := s.Export("en")
+				c.Check(err, IsNil)
+				c.Check(t, DeepEquals, expected)
+				item, err := s.Resolve("it", "cat/sub/beginner/other")
+				c.Check(err, IsNil)
+				c.Check(item.(*Item).SourceLocale, Equals, "en")
+				c.Check(s.Category("cat", "en").Sub("sub").Difficulty("beginner").Item("item").Title, Equals, "Title")
+			}
+		}()
+	}
+	rnd := rand.New(rand.NewSource(1))
+	base := p.category("cat", "en")
+	for i := 0; i < 50; i++ {
+		c.Assert(p.Parse(base, &Resource{Content: []map[string]string{{"name": fmt.Sprint("Name ", rnd.Int())}}}, "en"), IsNil)
+		item := base.Sub("sub").Difficulty("beginner").Item("item")
+		c.Assert(p.Parse(item, &Resource{Content: []map[string]string{{"title": fmt.Sprint("T", i)}, {"body": "B"}}}, "en"), IsNil)
+	}
+	wg.Wait()
+
+	c.Assert(s.Locales(), DeepEquals, []string{"en", "it"})
+	c.Assert(s.Categories("en")[1].Name, Equals, "Category")
+	c.Assert(s.Form("a", "en").Name, Equals, "Form")
+	s.Category("cat", "en").Name = "Changed"
+	c.Assert(s.Category("cat", "en").Name, Equals, "Category")
+	res, err := s.Encode(base, "en")
+	c.Assert(err, IsNil)
+	c.Assert(res.Content, DeepEquals, []map[string]string{{"name": "Category"}})
+}
+
+func (CmpSuite) TestSnapshotReparse(c *C) {
+	p := localeFixture()
+	s := p.Snapshot()
+	base := p.category("cat", "en")
+	item := base.Sub("sub").Difficulty("beginner").Item("item")
+
+	n, err := s.Reparse(item, &Resource{Content: []map[string]string{{"title": "Nuovo"}, {"body": "Uno"}}}, "it")
+	c.Assert(err, IsNil)
+	c.Assert(n.Category("cat", "it").Sub("sub").Difficulty("beginner").Item("item").Title, Equals, "Nuovo")
+	c.Assert(s.Category("cat", "it").Sub("sub").Difficulty("beginner").Item("item").Title, Equals, "Titolo")
+	c.Assert(n.r.category("cat", "it") != s.r.category("cat", "it"), Equals, true)
+	c.Assert(n.r.category("cat", "en") == s.r.category("cat", "en"), Equals, true)
+
+	form := p.FormUnsafe("a", "en")
+	n, err = n.Reparse(form, encode(form, form), "it")
+	c.Assert(err, IsNil)
+	c.Assert(n.Form("a", "it").Name, Equals, "Form")
+	c.Assert(s.Form("a", "it"), IsNil)
+	c.Assert(n.Category("cat", "it").Sub("sub").Difficulty("beginner").Item("item").Title, Equals, "Nuovo")
+
+	_, err = s.Reparse(base, &Resource{}, "it")
+	c.Assert(err, ErrorMatches, ErrContent.Error())
+
+	// the settings of the parser apply
+	checks := base.Sub("sub").Difficulty("beginner").Checks()
+	res := &Resource{Content: []map[string]string{{KeyText: "Leggi"}}}
+	_, err = p.Snapshot().Reparse(checks, res, "it")
+	c.Assert(err, ErrorMatches, ".*1 checks, 2 expected")
+	p.SetStrictChecklists(false)
+	n, err = p.Snapshot().Reparse(checks, res, "it")
+	c.Assert(err, IsNil)
+	c.Assert(n.Category("cat", "it").Sub("sub").Difficulty("beginner").Checks().Checks[1].Text, Equals, checks.Checks[1].Text)
+	c.Assert(n.Problems(), HasLen, 1)
+	c.Assert(n.Problems()[0].Path, Equals, "cat/sub/beginner/.checks")
+}
@@ -0,0 +1,228 @@
+package component
+
+import "strings"
+
+// Snippet is code found in the body of an item, to copy exactly.
+// Language is set only for fenced blocks with an info string.
+type Snippet struct {
+	Language string `json:"language,omitempty"`
+	Text     string `json:"text"`
+	Offset   int    `json:"offset"` // position in the body where the code starts
+}
+
+type snippetCache struct {
+	body string
+	list []Snippet
+}
+
+// CodeSnippets returns the code blocks and inline code spans of the body, following the
+// CommonMark rules for fenced blocks, indented blocks and backtick strings.
+func (i *Item) CodeSnippets() []Snippet {
+	if i.snippets.body != i.Body {
+		list, _ := extractCode(i.Body)
+		i.snippets = snippetCache{body: i.Body, list: list}
+	}
+	return i.snippets.list
+}
+
+type codeLine struct {
+	start int // offset of the line
+	text  string
+}
+
+// extractCode returns the code of the Markdown text and the text with each code replaced by a space
+func extractCode(s string) ([]Snippet, string) {
+	var (
+		lines    []codeLine
+		snippets []Snippet
+		text     strings.Builder
+		para     []codeLine // lines of the current paragraph
+	)
+	for start := 0; start <= len(s); {
+		end := strings.IndexByte(s[start:], '\n')
+		if end < 0 {
+			lines = append(lines, codeLine{start, s[start:]})
+			break
+		}
+		lines = append(lines, codeLine{start, s[start : start+end]})
+		start += end + 1
+	}
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		last := para[len(para)-1]
+		spans, rest := inlineCode(s[para[0].start:last.start+len(last.text)], para[0].start)
+		snippets = append(snippets, spans...)
+		text.WriteString(rest)
+		text.WriteByte('\n')
+		para = para[:0]
+	}
+	for i := 0; i < len(lines); {
+		l := lines[i]
+		if fence, indent, lang := openingFence(l.text); fence != "" {
+			flush()
+			var code []string
+			for i++; i < len(lines) && !closingFence(lines[i].text, fence); i++ {
+				code = append(code, trimIndent(lines[i].text, indent))
+			}
+			i++ // the closing fence, if any
+			snippets = append(snippets, Snippet{Language: lang, Text: strings.Join(code, "\n"), Offset: l.start})
+			text.WriteString(" \n")
+			continue
+		}
+		if len(para) == 0 && codeIndent(l.text) >= 0 {
+			var code []string
+			for ; i < len(lines); i++ {
+				if n := codeIndent(lines[i].text); n >= 0 {
+					code = append(code, lines[i].text[n:])
+				} else if strings.TrimSpace(lines[i].text) == "" {
+					code = append(code, "")
+				} else {
+					break
+				}
+			}
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			snippets = append(snippets, Snippet{Text: strings.Join(code, "\n"), Offset: l.start})
+			text.WriteString(" \n")
+			continue
+		}
+		if strings.TrimSpace(l.text) == "" {
+			flush()
+		} else {
+			para = append(para, l)
+		}
+		i++
+	}
+	flush()
+	return snippets, text.String()
+}
+
+// openingFence returns the fence of a fenced code block opening line, its indentation and the
+// first word of its info string
+func openingFence(line string) (fence string, indent int, lang string) {
+	indent = len(line) - len(strings.TrimLeft(line, " "))
+	rest := line[indent:]
+	if indent > 3 || !strings.HasPrefix(rest, "```") && !strings.HasPrefix(rest, "~~~") {
+		return "", 0, ""
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+	info := strings.TrimSpace(rest[n:])
+	if rest[0] == '`' && strings.Contains(info, "`") {
+		return "", 0, ""
+	}
+	if f := strings.Fields(info); len(f) != 0 {
+		lang = f[0]
+	}
+	return rest[:n], indent, lang
+}
+
+// closingFence tells if the line closes a block opened by fence
+func closingFence(line, fence string) bool {
+	rest := strings.TrimLeft(line, " ")
+	if len(line)-len(rest) > 3 {
+		return false
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, fence[:1]))
+	return n >= len(fence) && strings.TrimSpace(rest[n:]) == ""
+}
+
+// trimIndent removes up to n leading spaces
+func trimIndent(line string, n int) string {
+	for ; n > 0 && strings.HasPrefix(line, " "); n-- {
+		line = line[1:]
+	}
+	return line
+}
+
+// codeIndent returns the length of the indentation of an indented code line, -1 if it's not one
+func codeIndent(line string) int {
+	if strings.TrimSpace(line) == "" {
+		return -1
+	}
+	for i, width := 0, 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return -1
+		}
+		if width >= 4 {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// inlineCode returns the code spans of a paragraph starting at offset, and the paragraph with each
+// span replaced by a space. A backtick string opens a span closed by the next one of the same
+// length; if there is none, it's literal text.
+func inlineCode(p string, offset int) ([]Snippet, string) {
+	var (
+		spans []Snippet
+		rest  strings.Builder
+		pos   int
+	)
+	run := func(i int) int {
+		n := 0
+		for i+n < len(p) && p[i+n] == '`' {
+			n++
+		}
+		return n
+	}
+	for i := 0; i < len(p); {
+		j := strings.IndexByte(p[i:], '`')
+		if j < 0 {
+			break
+		}
+		start := i + j
+		n := run(start)
+		if escaped(p, start) { // the first backtick is literal
+			start, n = start+1, n-1
+			if n == 0 {
+				i = start
+				continue
+			}
+		}
+		end := -1
+		for k := start + n; k < len(p); {
+			m := strings.IndexByte(p[k:], '`')
+			if m < 0 {
+				break
+			}
+			if cn := run(k + m); cn == n {
+				end = k + m
+				break
+			} else {
+				k += m + cn
+			}
+		}
+		if end < 0 {
+			i = start + n
+			continue
+		}
+		code := strings.Replace(p[start+n:end], "\n", " ", -1)
+		if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+			code = code[1 : len(code)-1]
+		}
+		spans = append(spans, Snippet{Text: code, Offset: offset + start})
+		rest.WriteString(p[pos:start])
+		rest.WriteByte(' ')
+		i, pos = end+n, end+n
+	}
+	rest.WriteString(p[pos:])
+	return spans, rest.String()
+}
+
+// escaped tells if the byte at i follows an odd number of backslashes
+func escaped(s string, i int) bool {
+	n := 0
+	for i > 0 && s[i-1] == '\\' {
+		n, i = n+1, i-1
+	}
+	return n%2 == 1
+}
@@ -0,0 +1,81 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeSnippets(t *testing.T) {
+	for _, tc := range []struct {
+		body     string
+		snippets []Snippet
+		words    int
+	}{
+		{"No code here, just words.", nil, 5},
+		{"", nil, 0},
+		{ // fenced block with language
+			"Run this:\n\n```sh\ngpg --gen-key\ngpg --list-keys\n```\n\nDone.",
+			[]Snippet{{Language: "sh", Text: "gpg --gen-key\ngpg --list-keys", Offset: 11}}, 3,
+		},
+		{ // tildes, longer closing fence, indented opening fence
+			"  ~~~ ini extra\n  key = value\n more\n~~~~~\ntext",
+			[]Snippet{{Language: "ini", Text: "key = value\nmore", Offset: 0}}, 1,
+		},
+		{ // a shorter fence does not close, an unclosed block ends the body
+			"````\n```\nx\n",
+			[]Snippet{{Text: "```\nx\n", Offset: 0}}, 0,
+		},
+		{ // backticks in the info string: inline code, not a fence
+			"``` a`b ```",
+			[]Snippet{{Text: "a`b", Offset: 0}}, 0,
+		},
+		{ // inline spans
+			"Use `tor --verify` or ``a ` b`` now",
+			[]Snippet{{Text: "tor --verify", Offset: 4}, {Text: "a ` b", Offset: 22}}, 3,
+		},
+		{ // unmatched and escaped backticks are literal
+			"one ``two three",
+			nil, 3,
+		},
+		{"\\`a` b", nil, 2},
+		{ // backslashes do not escape inside spans
+			"`C:\\`",
+			[]Snippet{{Text: "C:\\", Offset: 0}}, 0,
+		},
+		{ // spans across lines of a paragraph, padding is stripped
+			"Set `` `x`\nand y ``!",
+			[]Snippet{{Text: "`x` and y", Offset: 4}}, 1,
+		},
+		{ // indented block
+			"Keys:\n\n    ssh-keygen\n\n    ssh-add\n\nEnd",
+			[]Snippet{{Text: "ssh-keygen\n\nssh-add", Offset: 7}}, 2,
+		},
+		{ // indentation does not interrupt a paragraph
+			"Keys:\n    ssh-keygen",
+			nil, 2,
+		},
+		{ // tab indentation
+			"\tcode `not inline`",
+			[]Snippet{{Text: "code `not inline`", Offset: 0}}, 0,
+		},
+	} {
+		item := Item{Body: tc.body}
+		if s := item.CodeSnippets(); !reflect.DeepEqual(s, tc.snippets) {
+			t.Errorf("%q: expected %+v, got %+v", tc.body, tc.snippets, s)
+		}
+		if n := countWords("en", tc.body); n != tc.words {
+			t.Errorf("%q: expected %d words, got %d", tc.body, tc.words, n)
+		}
+	}
+}
+
+func TestCodeSnippetsCache(t *testing.T) {
+	item := Item{Body: "`a`"}
+	if s := item.CodeSnippets(); len(s) != 1 || s[0].Text != "a" {
+		t.Fatalf("unexpected %+v", s)
+	}
+	item.Body = "`b`"
+	if s := item.CodeSnippets(); len(s) != 1 || s[0].Text != "b" {
+		t.Fatalf("stale %+v", s)
+	}
+}
@@ -1,19 +1,184 @@
 package component
 
+import (
+	"fmt"
+	"sort"
+)
+
+// catSorter and the other sorters order by Order, then by ID for the same Order
 type catSorter []*Category
 
-func (s catSorter) Len() int           { return len(s) }
-func (s catSorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-func (s catSorter) Less(i, j int) bool { return s[i].Order < s[j].Order }
+func (s catSorter) Len() int      { return len(s) }
+func (s catSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s catSorter) Less(i, j int) bool {
+	if s[i].Order != s[j].Order {
+		return s[i].Order < s[j].Order
+	}
+	return s[i].ID < s[j].ID
+}
 
 type subSorter []*Subcategory
 
-func (s subSorter) Len() int           { return len(s) }
-func (s subSorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-func (s subSorter) Less(i, j int) bool { return s[i].Order < s[j].Order }
+func (s subSorter) Len() int      { return len(s) }
+func (s subSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s subSorter) Less(i, j int) bool {
+	if s[i].Order != s[j].Order {
+		return s[i].Order < s[j].Order
+	}
+	return s[i].ID < s[j].ID
+}
 
 type itemSorter []*Item
 
-func (s itemSorter) Len() int           { return len(s) }
-func (s itemSorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-func (s itemSorter) Less(i, j int) bool { return s[i].Order < s[j].Order }
+func (s itemSorter) Len() int      { return len(s) }
+func (s itemSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s itemSorter) Less(i, j int) bool {
+	if s[i].Order != s[j].Order {
+		return s[i].Order < s[j].Order
+	}
+	return s[i].ID < s[j].ID
+}
+
+// SortedCategories returns a copy of the categories of the locale sorted by Order and ID, with
+// subcategories and items sorted the same way. Difficulties and checks keep their order.
+func (r *ResourceParser) SortedCategories(locale string) []*Category {
+	var list = make(catSorter, len(r.categories[locale]))
+	for i, c := range r.categories[locale] {
+		list[i] = c.Copy()
+		sort.Sort(subSorter(list[i].subcategories))
+		list[i].reindex()
+		for _, sub := range list[i].subcategories {
+			for _, d := range sub.difficulties {
+				sort.Sort(itemSorter(d.items))
+				d.reindex()
+			}
+		}
+	}
+	sort.Sort(list)
+	return list
+}
+
+// sibling is the ID and Order of a component, see checkOrders
+type sibling struct {
+	id    string
+	order float64
+}
+
+// checkOrders reports the siblings under parent, in the given order, without an Order (zero)
+// or with the Order of a previous one
+func checkOrders(locale, parent string, list []sibling) []Problem {
+	var (
+		problems []Problem
+		seen     = make(map[float64]string)
+	)
+	for _, s := range list {
+		path := s.id
+		if parent != "" {
+			path = parent + "/" + s.id
+		}
+		prev, dup := seen[s.order]
+		switch {
+		case s.order == 0:
+			problems = append(problems, Problem{Path: path, Locale: locale, Message: "missing order"})
+		case dup:
+			problems = append(problems, Problem{Path: path, Locale: locale, Message: fmt.Sprintf("order %v same as %s", s.order, prev)})
+		default:
+			seen[s.order] = s.id
+		}
+	}
+	return problems
+}
+
+// Sort orders, in place, the categories of the locale, their subcategories and items by Order,
+// then by ID, so the same content always comes in the same sequence. Difficulties and checks keep
+// their order. It returns the components without an Order or with the Order of a sibling.
+func (r *ResourceParser) Sort(locale string) []Problem {
+	cats := r.categories[locale]
+	sort.Stable(catSorter(cats))
+	var list = make([]sibling, len(cats))
+	for i, c := range cats {
+		list[i] = sibling{c.ID, c.Order}
+	}
+	problems := checkOrders(locale, "", list)
+	for _, c := range cats {
+		sort.Stable(subSorter(c.subcategories))
+		c.reindex()
+		list = make([]sibling, len(c.subcategories))
+		for i, s := range c.subcategories {
+			list[i] = sibling{s.ID, s.Order}
+		}
+		problems = append(problems, checkOrders(locale, c.ID, list)...)
+		for _, s := range c.subcategories {
+			for _, d := range s.difficulties {
+				sort.Stable(itemSorter(d.items))
+				d.reindex()
+				list = make([]sibling, len(d.items))
+				for i, item := range d.items {
+					list[i] = sibling{item.ID, item.Order}
+				}
+				problems = append(problems, checkOrders(locale, treePath(d), list)...)
+			}
+		}
+	}
+	return problems
+}
+
+// SetSorted with true makes the parser Sort each locale it parses, after ParseAll, ParseStream
+// and ParseLocales, recording the order problems. It's Config.Sorted.
+func (r *ResourceParser) SetSorted(sorted bool) { r.sorted = sorted }
+
+// sortParsed sorts the locales if the parser is set to, see SetSorted
+func (r *ResourceParser) sortParsed(locales map[string]bool) {
+	if !r.sorted {
+		return
+	}
+	var list = make([]string, 0, len(locales))
+	for l := range locales {
+		list = append(list, l)
+	}
+	sort.Strings(list)
+	for _, l := range list {
+		r.problems = append(r.problems, r.Sort(l)...)
+	}
+}
+
+// Sort orders the tree like ResourceParser.Sort, and forms and glossaries by ID. It returns
+// the categories, subcategories and items without an Order or with the Order of a sibling.
+func (t *Tree) Sort() []Problem {
+	less := func(o1, o2 float64, id1, id2 string) bool {
+		if o1 != o2 {
+			return o1 < o2
+		}
+		return id1 < id2
+	}
+	cats := t.Categories
+	sort.SliceStable(cats, func(i, j int) bool { return less(cats[i].Order, cats[j].Order, cats[i].ID, cats[j].ID) })
+	var list = make([]sibling, len(cats))
+	for i, c := range cats {
+		list[i] = sibling{c.ID, c.Order}
+	}
+	problems := checkOrders(t.Locale, "", list)
+	for _, c := range cats {
+		subs := c.Subcategories
+		sort.SliceStable(subs, func(i, j int) bool { return less(subs[i].Order, subs[j].Order, subs[i].ID, subs[j].ID) })
+		list = make([]sibling, len(subs))
+		for i, s := range subs {
+			list[i] = sibling{s.ID, s.Order}
+		}
+		problems = append(problems, checkOrders(t.Locale, c.ID, list)...)
+		for _, s := range subs {
+			for _, d := range s.Difficulties {
+				items := d.Items
+				sort.SliceStable(items, func(i, j int) bool { return less(items[i].Order, items[j].Order, items[i].ID, items[j].ID) })
+				list = make([]sibling, len(items))
+				for i, item := range items {
+					list[i] = sibling{item.ID, item.Order}
+				}
+				problems = append(problems, checkOrders(t.Locale, c.ID+"/"+s.ID+"/"+d.ID, list)...)
+			}
+		}
+	}
+	sort.SliceStable(t.Forms, func(i, j int) bool { return t.Forms[i].ID < t.Forms[j].ID })
+	sort.SliceStable(t.Glossaries, func(i, j int) bool { return t.Glossaries[i].ID < t.Glossaries[j].ID })
+	return problems
+}
@@ -0,0 +1,130 @@
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+
+	. "gopkg.in/check.v1"
+)
+
+// orderedBatch returns the requests to parse categories, subcategories and items with the
+// same Order, in a random sequence after their parents
+func orderedBatch(rnd *rand.Rand) []ParseRequest {
+	var cats, subs, items []ParseRequest
+	for _, id := range []string{"c", "a", "b"} {
+		cat := testCategory("en", "")
+		cat.ID, cat.Order = id, 1
+		cats = append(cats, ParseRequest{Component: cat, Resource: &Resource{Content: []map[string]string{{"name": id}}}, Locale: "it"})
+		cat.Add(&Subcategory{ID: "a", Order: 1})
+		cat.Sub("a").AddDifficulty(&Difficulty{ID: "beginner"})
+		for _, sub := range cat.subcategories {
+			sub.Order = 1
+			subs = append(subs, ParseRequest{Component: sub, Resource: &Resource{Content: []map[string]string{{"name": sub.ID}}}, Locale: "it"})
+			d := sub.Difficulty("beginner")
+			items = append(items, ParseRequest{Component: d, Resource: &Resource{Content: []map[string]string{{"description": "d"}}}, Locale: "it"})
+			for i := 0; i < 4; i++ {
+				item := &Item{ID: fmt.Sprint("item", 3-i), Order: float64(i % 2)}
+				d.AddItem(item)
+				items = append(items, ParseRequest{Component: item, Resource: &Resource{Content: []map[string]string{{"title": item.ID}, {"body": "b"}}}, Locale: "it"})
+			}
+		}
+	}
+	for _, list := range [][]ParseRequest{cats, subs, items} {
+		rnd.Shuffle(len(list), func(i, j int) { list[i], list[j] = list[j], list[i] })
+	}
+	// difficulties before their items
+	sortDifficultiesFirst(items)
+	return append(append(cats, subs...), items...)
+}
+
+func sortDifficultiesFirst(reqs []ParseRequest) {
+	var n int
+	for i, r := range reqs {
+		if _, ok := r.Component.(*Difficulty); ok {
+			reqs[n], reqs[i] = reqs[i], reqs[n]
+			n++
+		}
+	}
+}
+
+func (CmpSuite) TestSortedCategories(c *C) {
+	rnd := rand.New(rand.NewSource(7))
+	var expected string
+	for i := 0; i < 10; i++ {
+		p := NewResourceParser()
+		_, errs := p.ParseAll(orderedBatch(rnd))
+		c.Assert(errs, HasLen, 0)
+
+		cats := p.SortedCategories("it")
+		var ids []string
+		for _, cat := range cats {
+			ids = append(ids, cat.ID)
+		}
+		c.Assert(ids, DeepEquals, []string{"a", "b", "c"})
+		c.Assert(cats[0].Subcategories(), DeepEquals, []string{"a", "sub"})
+		c.Assert(cats[0].Sub("a").Difficulty("beginner").ItemNames(), DeepEquals, []string{"item1", "item3", "item0", "item2"})
+
+		var trees []interface{}
+		for _, cat := range cats {
+			trees = append(trees, cat.Tree(RawMarkdown))
+		}
+		b, err := json.Marshal(trees)
+		c.Assert(err, IsNil)
+		if i == 0 {
+			expected = string(b)
+		}
+		c.Assert(string(b), Equals, expected)
+	}
+}
+
+func (CmpSuite) TestSort(c *C) {
+	rnd := rand.New(rand.NewSource(3))
+	var expected []Problem
+	for i := 0; i < 10; i++ {
+		p := NewResourceParser()
+		p.SetSorted(true)
+		_, errs := p.ParseAll(orderedBatch(rnd))
+		c.Assert(errs, HasLen, 0)
+
+		cats := p.CategoriesUnsafe()["it"]
+		c.Assert([]string{cats[0].ID, cats[1].ID, cats[2].ID}, DeepEquals, []string{"a", "b", "c"})
+		c.Assert(cats[0].Subcategories(), DeepEquals, []string{"a", "sub"})
+		c.Assert(cats[0].Sub("a").Difficulty("beginner").ItemNames(), DeepEquals, []string{"item1", "item3", "item0", "item2"})
+		c.Assert(cats[0].Sub("a").Difficulty("beginner").Item("item0"), NotNil)
+
+		problems := p.Problems()
+		c.Assert(problems[:3], DeepEquals, []Problem{
+			{Path: "b", Locale: "it", Message: "order 1 same as a"},
+			{Path: "c", Locale: "it", Message: "order 1 same as a"},
+			{Path: "a/sub", Locale: "it", Message: "order 1 same as a"},
+		})
+		c.Assert(problems[3:5], DeepEquals, []Problem{
+			{Path: "a/a/beginner/item1", Locale: "it", Message: "missing order"},
+			{Path: "a/a/beginner/item3", Locale: "it", Message: "missing order"},
+		})
+		if i == 0 {
+			expected = problems
+		}
+		c.Assert(problems, DeepEquals, expected)
+
+		tree, err := p.Export("it")
+		c.Assert(err, IsNil)
+		sorted, err := json.Marshal(tree)
+		c.Assert(err, IsNil)
+		rnd.Shuffle(len(tree.Categories), func(i, j int) {
+			tree.Categories[i], tree.Categories[j] = tree.Categories[j], tree.Categories[i]
+		})
+		items := tree.Categories[0].Subcategories[0].Difficulties[0].Items
+		rnd.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+		c.Assert(tree.Sort(), DeepEquals, expected)
+		b, err := json.Marshal(tree)
+		c.Assert(err, IsNil)
+		c.Assert(string(b), Equals, string(sorted))
+	}
+
+	p := NewResourceParser()
+	_, errs := p.ParseAll(orderedBatch(rnd))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(p.Problems(), HasLen, 0)
+}
@@ -0,0 +1,92 @@
+package component
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// Stats counts the parsed content of a locale
+type Stats struct {
+	Locale        string  `json:"locale"`
+	Categories    int     `json:"categories"`
+	Subcategories int     `json:"subcategories"`
+	Difficulties  int     `json:"difficulties"`
+	Items         int     `json:"items"`
+	Checks        int     `json:"checks"`
+	Forms         int     `json:"forms"`
+	Words         int     `json:"words"`
+	Translated    float64 `json:"translated"` // percent of the words of the base locale, see Readiness.Words
+}
+
+// Stats returns the counts of the components and the words of the locale. Translated is 100,
+// see StatsReport for the percent relative to a base locale.
+func (r *ResourceParser) Stats(locale string) Stats {
+	var s = Stats{Locale: locale, Forms: len(r.forms[locale]), Translated: 100}
+	count := func(c Component) {
+		switch v := c.(type) {
+		case *Category:
+			s.Categories++
+		case *Subcategory:
+			s.Subcategories++
+		case *Difficulty:
+			s.Difficulties++
+		case *Item:
+			s.Items++
+		case *Checklist:
+			s.Checks += len(v.Checks)
+		}
+		for _, f := range textFields(c) {
+			for _, t := range f.Texts {
+				s.Words += countWords(locale, t)
+			}
+		}
+	}
+	for _, cat := range r.categories[locale] {
+		walkCategory(cat, count)
+	}
+	for _, f := range r.forms[locale] {
+		count(f)
+	}
+	return s
+}
+
+// StatsReport returns the Stats of every locale, sorted by locale, with the percent of the
+// words of the base locale that each one translates
+func (r *ResourceParser) StatsReport(baseLocale string, opts ...Option) []Stats {
+	var list []Stats
+	for _, l := range r.Locales(opts...) {
+		s := r.Stats(l)
+		if l != baseLocale {
+			s.Translated = r.readiness(baseLocale, l, r.thresholds).Words
+		}
+		list = append(list, s)
+	}
+	return list
+}
+
+// WriteStats writes the stats as a JSON list or as CSV, with a header and a row for each locale
+func WriteStats(w io.Writer, stats []Stats, format Format) error {
+	switch format {
+	case FormatJSON:
+		if stats == nil {
+			stats = []Stats{}
+		}
+		return json.NewEncoder(w).Encode(stats)
+	case FormatCSV:
+	default:
+		return ErrFormat
+	}
+	c := csv.NewWriter(w)
+	c.Write([]string{"locale", "categories", "subcategories", "difficulties", "items", "checks", "forms", "words", "translated"})
+	for _, s := range stats {
+		c.Write([]string{
+			s.Locale, strconv.Itoa(s.Categories), strconv.Itoa(s.Subcategories), strconv.Itoa(s.Difficulties),
+			strconv.Itoa(s.Items), strconv.Itoa(s.Checks), strconv.Itoa(s.Forms), strconv.Itoa(s.Words),
+			strconv.FormatFloat(s.Translated, 'f', 1, 64),
+		})
+	}
+	c.Flush()
+	return c.Error()
+}
@@ -0,0 +1,39 @@
+package component
+
+import (
+	"bytes"
+
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestStats(c *C) {
+	p := readinessParser(map[string]int{"en": 7, "it": 5})
+	p.category("cat", "en").Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "Lock it"}, Check{Text: "Back it up"})
+	p.forms["en"] = []*Form{{ID: "form", Name: "Incident report", Locale: "en"}}
+
+	// 1 per name, 2 for the description, 5 per item, 5 for the checks and 2 for the form
+	c.Assert(p.Stats("en"), DeepEquals, Stats{
+		Locale: "en", Categories: 1, Subcategories: 1, Difficulties: 1, Items: 7, Checks: 2, Forms: 1,
+		Words: 46, Translated: 100,
+	})
+	c.Assert(p.Stats("fr"), DeepEquals, Stats{Locale: "fr", Translated: 100})
+
+	report := p.StatsReport("en")
+	c.Assert(report, HasLen, 2)
+	c.Assert(report[0].Locale, Equals, "en")
+	c.Assert(report[1], DeepEquals, Stats{
+		Locale: "it", Categories: 1, Subcategories: 1, Difficulties: 1, Items: 5,
+		Words: 32, Translated: percent(29, 46),
+	})
+
+	var b bytes.Buffer
+	c.Assert(WriteStats(&b, report, FormatCSV), IsNil)
+	c.Assert(b.String(), Equals, `locale,categories,subcategories,difficulties,items,checks,forms,words,translated
+en,1,1,1,7,2,1,46,100.0
+it,1,1,1,5,0,0,32,63.0
+`)
+	b.Reset()
+	c.Assert(WriteStats(&b, report[:1], FormatJSON), IsNil)
+	c.Assert(b.String(), Equals, `[{"locale":"en","categories":1,"subcategories":1,"difficulties":1,"items":7,"checks":2,"forms":1,"words":46,"translated":100}]`+"\n")
+	c.Assert(WriteStats(&b, report, FormatHTML), Equals, ErrFormat)
+}
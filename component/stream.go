@@ -0,0 +1,107 @@
+package component
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+)
+
+// DefaultStreamBuffer is the number of requests ParseIterator reads ahead, see WithBuffer
+const DefaultStreamBuffer = 16
+
+// StreamProgress is reported after each request of ParseAll, ParseStream or ParseIterator,
+// see WithProgress
+type StreamProgress struct {
+	Parsed int    // requests handled so far, including this one
+	Path   string // path of the component
+	Locale string
+	Err    error // the *ImportError of the request, if any
+}
+
+// WithProgress calls fn after each request of the batch, in the goroutine that parses it
+func WithProgress(fn func(StreamProgress)) Option {
+	return func(o *options) { o.progress = fn }
+}
+
+// WithBuffer sets how many requests ParseIterator reads ahead of the parser
+func WithBuffer(n int) Option {
+	return func(o *options) { o.buffer = n }
+}
+
+// ParseStream parses the requests received from the channel until it's closed or the context
+// is done, like ParseAll does with a batch, and returns the context error last if it stopped
+// early. Requests aren't kept after they are parsed, so the caller can produce them one at a
+// time and the memory used doesn't grow with the size of the content.
+func (r *ResourceParser) ParseStream(ctx context.Context, reqs <-chan ParseRequest, opts ...Option) (ImportSummary, []error) {
+	var (
+		b = newBatchState(opts)
+		h = sha1.New()
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			r.sortParsed(b.locales)
+			r.notifyComplete()
+			b.summary.Fingerprint = fmt.Sprintf("%x", h.Sum(nil))
+			return b.summary, append(b.errs, ctx.Err())
+		case req, ok := <-reqs:
+			if !ok {
+				r.sortParsed(b.locales)
+				r.notifyComplete()
+				b.summary.Fingerprint = fmt.Sprintf("%x", h.Sum(nil))
+				return b.summary, b.errs
+			}
+			writeFingerprint(h, req)
+			b.parse(r, req)
+		}
+	}
+}
+
+// A RequestIterator returns the requests to parse one at a time, and io.EOF after the last one
+type RequestIterator interface {
+	Next() (ParseRequest, error)
+}
+
+// ParseIterator parses the requests of the iterator with ParseStream, reading at most
+// DefaultStreamBuffer requests, or the ones set with WithBuffer, ahead of the parser. An error
+// of the iterator other than io.EOF stops it, and is returned last.
+func (r *ResourceParser) ParseIterator(ctx context.Context, it RequestIterator, opts ...Option) (ImportSummary, []error) {
+	n := newOptions(opts).buffer
+	if n <= 0 {
+		n = DefaultStreamBuffer
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		reqs = make(chan ParseRequest, n)
+		errc = make(chan error, 1)
+	)
+	go func() {
+		defer close(reqs)
+		for {
+			req, err := it.Next()
+			if err != nil {
+				if err != io.EOF {
+					errc <- err
+				}
+				return
+			}
+			select {
+			case reqs <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	s, errs := r.ParseStream(ctx, reqs, opts...)
+	cancel()
+	for range reqs {
+	}
+	select {
+	case err := <-errc:
+		errs = append(errs, err)
+	default:
+	}
+	return s, errs
+}
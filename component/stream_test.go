@@ -0,0 +1,70 @@
+package component
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	. "gopkg.in/check.v1"
+)
+
+type sliceIterator struct {
+	reqs []ParseRequest
+	err  error
+}
+
+func (s *sliceIterator) Next() (ParseRequest, error) {
+	if len(s.reqs) == 0 {
+		if s.err != nil {
+			return ParseRequest{}, s.err
+		}
+		return ParseRequest{}, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (CmpSuite) TestParseStream(c *C) {
+	batch := itemBatch("Voce")
+	expected, errs := NewResourceParser().ParseAll(batch)
+	c.Assert(errs, HasLen, 0)
+
+	var progress []StreamProgress
+	p := NewResourceParser()
+	reqs := make(chan ParseRequest, len(batch))
+	for _, req := range batch {
+		reqs <- req
+	}
+	close(reqs)
+	s, errs := p.ParseStream(context.Background(), reqs, WithProgress(func(sp StreamProgress) { progress = append(progress, sp) }))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(s, Equals, expected)
+	c.Assert(p.lookup("it", "cat/sub/beginner/item").(*Item).Title, Equals, "Voce")
+	c.Assert(progress, DeepEquals, []StreamProgress{
+		{Parsed: 1, Path: "cat", Locale: "it"},
+		{Parsed: 2, Path: "cat/sub/beginner/item", Locale: "it"},
+	})
+
+	// a failed parent skips the children
+	bad := itemBatch("Voce")
+	bad[0].Resource = &Resource{}
+	s, errs = NewResourceParser().ParseIterator(context.Background(), &sliceIterator{reqs: bad}, WithBuffer(1))
+	c.Assert(s.Failed, Equals, 1)
+	c.Assert(s.Skipped, Equals, 1)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errors.Is(errs[1], ErrParentFailed), Equals, true)
+
+	// the iterator error is returned last
+	fail := errors.New("read failed")
+	s, errs = NewResourceParser().ParseIterator(context.Background(), &sliceIterator{reqs: itemBatch("Voce"), err: fail})
+	c.Assert(s.Applied, Equals, 2)
+	c.Assert(errs, DeepEquals, []error{fail})
+
+	// a cancelled context stops the stream
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s, errs = NewResourceParser().ParseStream(ctx, make(chan ParseRequest))
+	c.Assert(s.Applied, Equals, 0)
+	c.Assert(errs, DeepEquals, []error{context.Canceled})
+}
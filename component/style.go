@@ -0,0 +1,248 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Punctuation is the policy for the punctuation at the end of a string
+type Punctuation int
+
+const (
+	PunctuationAny    Punctuation = iota
+	PunctuationAlways             // a sentence terminal, like "." or "。", is required
+	PunctuationNever              // no sentence terminal except "?" and "!"
+)
+
+// Capitalization is the policy for the case of the words of a string. It's not applied to
+// strings without cased letters, i.e. in scripts without case.
+type Capitalization int
+
+const (
+	CaseAny      Capitalization = iota
+	CaseSentence                // first word capitalized, not all words in title case
+	CaseTitle                   // words of 4 or more letters capitalized
+)
+
+// FieldStyle is the style of a class of strings
+type FieldStyle struct {
+	Punctuation Punctuation
+	Case        Capitalization
+}
+
+// StyleRules are the rules of CheckStyle
+type StyleRules struct {
+	Checks  FieldStyle // check texts
+	Options FieldStyle // each option of form inputs
+	Titles  FieldStyle // item titles
+	Labels  FieldStyle // form input labels
+	// NoDoubleSpaces forbids consecutive spaces in all the strings above
+	NoDoubleSpaces bool
+	// NoLabelColons forbids colons at the end of the labels
+	NoLabelColons bool
+	// Terminal is appended to fix missing punctuation, "." if empty
+	Terminal string
+	// Locales replace the rules for some locales
+	Locales map[string]StyleRules
+}
+
+func (s StyleRules) locale(l string) StyleRules {
+	if rules, ok := s.Locales[l]; ok {
+		return rules
+	}
+	return s
+}
+
+// CheckStyle returns a problem for each string that breaks the rules in the locales, all the
+// locales if none is given. The message lists the broken rules and Fix has the corrected string.
+// Problems use the units of the exports: options are reported for the whole cell of an input.
+func (r *ResourceParser) CheckStyle(rules StyleRules, locales ...string) []Problem {
+	if len(locales) == 0 {
+		locales = r.Locales()
+	}
+	var problems []Problem
+	for _, l := range locales {
+		rules := rules.locale(l)
+		for _, c := range r.localeComponents(l) {
+			for _, f := range textFields(c) {
+				for i, s := range f.Texts {
+					fixed, issues := rules.apply(f.Name, s)
+					if len(issues) == 0 {
+						continue
+					}
+					problems = append(problems, Problem{
+						Path:    treePath(c),
+						Locale:  l,
+						Message: fmt.Sprintf("%s %d: %s", f.Name, i+1, strings.Join(issues, ", ")),
+						Fix:     fixed,
+					})
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// apply returns the string fixed and the broken rules of the field
+func (s StyleRules) apply(field, text string) (string, []string) {
+	var (
+		style  FieldStyle
+		issues []string
+	)
+	switch field {
+	case KeyText:
+		style = s.Checks
+	case KeyTitle:
+		style = s.Titles
+	case KeyLabel:
+		style = s.Labels
+	case KeyOptions:
+		if text == "" {
+			return text, nil
+		}
+		options := strings.Split(text, ";")
+		for i := range options {
+			var list []string
+			options[i], list = s.applyStyle(s.Options, options[i])
+			issues = appendIssues(issues, list...)
+		}
+		return strings.Join(options, ";"), issues
+	default:
+		return text, nil
+	}
+	if text == "" {
+		return text, nil
+	}
+	text, issues = s.applyStyle(style, text)
+	if field == KeyLabel && s.NoLabelColons {
+		if t := strings.TrimRight(text, ":：  "); t != strings.TrimRight(text, " ") {
+			text, issues = t, appendIssues(issues, "trailing colon")
+		}
+	}
+	return text, issues
+}
+
+func (s StyleRules) applyStyle(style FieldStyle, text string) (string, []string) {
+	var issues []string
+	if s.NoDoubleSpaces && strings.Contains(text, "  ") {
+		for strings.Contains(text, "  ") {
+			text = strings.Replace(text, "  ", " ", -1)
+		}
+		issues = append(issues, "double space")
+	}
+	if hasCase(text) {
+		var issue string
+		switch style.Case {
+		case CaseSentence:
+			text, issue = sentenceCase(text)
+		case CaseTitle:
+			text, issue = titleCase(text)
+		}
+		issues = appendIssues(issues, issue)
+	}
+	body, tail := splitTail(text)
+	last, _ := utf8.DecodeLastRuneInString(body)
+	terminal := body != "" && unicode.Is(unicode.Sentence_Terminal, last)
+	switch {
+	case style.Punctuation == PunctuationAlways && !terminal && body != "":
+		t := s.Terminal
+		if t == "" {
+			t = "."
+		}
+		text, issues = body+t+tail, appendIssues(issues, "missing terminal punctuation")
+	case style.Punctuation == PunctuationNever && terminal && last != '?' && last != '!':
+		body = strings.TrimRightFunc(body, func(r rune) bool {
+			return unicode.Is(unicode.Sentence_Terminal, r) && r != '?' && r != '!'
+		})
+		text, issues = body+tail, appendIssues(issues, "terminal punctuation")
+	}
+	return text, issues
+}
+
+func appendIssues(list []string, issues ...string) []string {
+	for _, issue := range issues {
+		if issue == "" {
+			continue
+		}
+		var found bool
+		for _, v := range list {
+			found = found || v == issue
+		}
+		if !found {
+			list = append(list, issue)
+		}
+	}
+	return list
+}
+
+// splitTail separates closing quotes, brackets and spaces at the end of s
+func splitTail(s string) (string, string) {
+	body := strings.TrimRight(s, " \"')]”’»")
+	return body, s[len(body):]
+}
+
+// hasCase tells if s has letters with case
+func hasCase(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) || unicode.IsLower(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// sentenceCase capitalizes the first word, and lowercases the others if they are all
+// capitalized like in a title
+func sentenceCase(s string) (string, string) {
+	words := strings.Split(s, " ")
+	var issue string
+	if w := words[0]; startsLower(w) {
+		words[0], issue = capitalize(w), "not capitalized"
+	}
+	var long, capital int
+	for _, w := range words[1:] {
+		if utf8.RuneCountInString(w) < 4 || !hasCase(w) {
+			continue
+		}
+		long++
+		if startsUpper(w) && strings.ToUpper(w) != w {
+			capital++
+		}
+	}
+	if long >= 2 && capital == long {
+		for i, w := range words[1:] {
+			if utf8.RuneCountInString(w) >= 4 && strings.ToUpper(w) != w {
+				words[i+1] = uncapitalize(w)
+			}
+		}
+		issue = "title case"
+	}
+	return strings.Join(words, " "), issue
+}
+
+// titleCase capitalizes the words of 4 or more letters
+func titleCase(s string) (string, string) {
+	words := strings.Split(s, " ")
+	var issue string
+	for i, w := range words {
+		if (i == 0 || utf8.RuneCountInString(w) >= 4) && startsLower(w) {
+			words[i], issue = capitalize(w), "not in title case"
+		}
+	}
+	return strings.Join(words, " "), issue
+}
+
+func startsLower(w string) bool { r, _ := utf8.DecodeRuneInString(w); return unicode.IsLower(r) }
+func startsUpper(w string) bool { r, _ := utf8.DecodeRuneInString(w); return unicode.IsUpper(r) }
+
+func capitalize(w string) string {
+	r, n := utf8.DecodeRuneInString(w)
+	return string(unicode.ToTitle(r)) + w[n:]
+}
+
+func uncapitalize(w string) string {
+	r, n := utf8.DecodeRuneInString(w)
+	return string(unicode.ToLower(r)) + w[n:]
+}
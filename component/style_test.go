@@ -0,0 +1,68 @@
+package component
+
+import (
+	"reflect"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestStyleRules(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		rules  StyleRules
+		field  string
+		text   string
+		fixed  string
+		issues []string
+	}{
+		// punctuation
+		{"period required", StyleRules{Checks: FieldStyle{Punctuation: PunctuationAlways}}, KeyText, "Use a VPN", "Use a VPN.", []string{"missing terminal punctuation"}},
+		{"period present", StyleRules{Checks: FieldStyle{Punctuation: PunctuationAlways}}, KeyText, "Use a VPN.", "Use a VPN.", nil},
+		{"question", StyleRules{Checks: FieldStyle{Punctuation: PunctuationAlways}}, KeyText, "Is it safe?", "Is it safe?", nil},
+		{"quoted", StyleRules{Checks: FieldStyle{Punctuation: PunctuationAlways}}, KeyText, `Say "no"`, `Say "no."`, []string{"missing terminal punctuation"}},
+		{"terminal", StyleRules{Checks: FieldStyle{Punctuation: PunctuationAlways}, Terminal: "。"}, KeyText, "使用する", "使用する。", []string{"missing terminal punctuation"}},
+		{"period forbidden", StyleRules{Checks: FieldStyle{Punctuation: PunctuationNever}}, KeyText, "Use a VPN...", "Use a VPN", []string{"terminal punctuation"}},
+		{"question allowed", StyleRules{Checks: FieldStyle{Punctuation: PunctuationNever}}, KeyText, "Is it safe?", "Is it safe?", nil},
+		{"options", StyleRules{Options: FieldStyle{Punctuation: PunctuationNever, Case: CaseSentence}}, KeyOptions, "Yes;no.;Maybe", "Yes;No;Maybe", []string{"not capitalized", "terminal punctuation"}},
+		{"no options", StyleRules{Options: FieldStyle{Case: CaseSentence}}, KeyOptions, "", "", nil},
+		// capitalization
+		{"sentence", StyleRules{Titles: FieldStyle{Case: CaseSentence}}, KeyTitle, "protect your phone", "Protect your phone", []string{"not capitalized"}},
+		{"sentence with names", StyleRules{Titles: FieldStyle{Case: CaseSentence}}, KeyTitle, "Install Tor on your phone", "Install Tor on your phone", nil},
+		{"title case", StyleRules{Titles: FieldStyle{Case: CaseSentence}}, KeyTitle, "Protect Your Phone With PIN", "Protect your phone with PIN", []string{"title case"}},
+		{"title", StyleRules{Titles: FieldStyle{Case: CaseTitle}}, KeyTitle, "protect your phone", "Protect Your Phone", []string{"not in title case"}},
+		{"labels", StyleRules{Labels: FieldStyle{Case: CaseSentence}, NoLabelColons: true}, KeyLabel, "name:", "Name", []string{"not capitalized", "trailing colon"}},
+		{"wide colon", StyleRules{NoLabelColons: true}, KeyLabel, "名前：", "名前", []string{"trailing colon"}},
+		{"other fields", StyleRules{Titles: FieldStyle{Case: CaseSentence}}, KeyName, "name", "name", nil},
+		// spaces
+		{"double spaces", StyleRules{NoDoubleSpaces: true}, KeyText, "Use   a VPN", "Use a VPN", []string{"double space"}},
+		// scripts
+		{"cyrillic", StyleRules{Titles: FieldStyle{Case: CaseSentence}}, KeyTitle, "защитите телефон", "Защитите телефон", []string{"not capitalized"}},
+		{"greek", StyleRules{Titles: FieldStyle{Case: CaseSentence}}, KeyTitle, "ασφάλεια", "Ασφάλεια", []string{"not capitalized"}},
+		{"arabic", StyleRules{Titles: FieldStyle{Case: CaseTitle, Punctuation: PunctuationAlways}}, KeyTitle, "احمِ هاتفك؟", "احمِ هاتفك؟", nil},
+		{"devanagari", StyleRules{Checks: FieldStyle{Case: CaseSentence, Punctuation: PunctuationAlways}}, KeyText, "फ़ोन सुरक्षित करें।", "फ़ोन सुरक्षित करें।", nil},
+		{"chinese", StyleRules{Checks: FieldStyle{Case: CaseSentence, Punctuation: PunctuationNever}}, KeyText, "保护手机。", "保护手机", []string{"terminal punctuation"}},
+	} {
+		fixed, issues := tc.rules.apply(tc.field, tc.text)
+		if fixed != tc.fixed || !reflect.DeepEqual(issues, tc.issues) {
+			t.Errorf("%s: expected %q %q, got %q %q", tc.name, tc.fixed, tc.issues, fixed, issues)
+		}
+	}
+}
+
+func (CmpSuite) TestCheckStyle(c *C) {
+	p := bilingualParser()
+	p.lookup("en", "cat/sub/beginner/.checks").(*Checklist).Checks = []Check{{Text: "First."}, {Text: "Second"}}
+	p.forms["ja"] = []*Form{{ID: "form", Screens: []FormScreen{{Items: []FormInput{{Label: "名前："}}}}}}
+	rules := StyleRules{
+		Checks:        FieldStyle{Punctuation: PunctuationAlways},
+		Titles:        FieldStyle{Case: CaseTitle},
+		NoLabelColons: true,
+		Locales:       map[string]StyleRules{"it": {}},
+	}
+	c.Assert(p.CheckStyle(rules), DeepEquals, []Problem{
+		{Path: "cat/sub/beginner/.checks", Locale: "en", Message: "text 2: missing terminal punctuation", Fix: "Second."},
+		{Path: "forms/form", Locale: "ja", Message: "label 1: trailing colon", Fix: "名前"},
+	})
+	c.Assert(p.CheckStyle(rules, "it"), HasLen, 0)
+}
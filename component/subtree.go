@@ -0,0 +1,236 @@
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// NotFoundError is returned when a path has no component in a locale
+type NotFoundError struct {
+	Path   string
+	Locale string
+	Cause  error // optional, e.g. ErrMissingTranslation
+	Parent bool  // the path is the parent of the component parsed, see ErrMissingParent
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%v: %s not found (%s)", e.Cause, e.Path, e.Locale)
+	}
+	return fmt.Sprintf("%s not found (%s)", e.Path, e.Locale)
+}
+
+func (e *NotFoundError) Unwrap() error { return e.Cause }
+
+// Is makes the error of a missing parent match ErrMissingParent
+func (e *NotFoundError) Is(target error) bool { return e.Parent && target == ErrMissingParent }
+
+// ParentMismatchError is returned by ImportSubtree when a parent of the subtree
+// has a different name in the parser, i.e. the subtree comes from another tree.
+type ParentMismatchError struct {
+	Path     string
+	Locale   string
+	Expected string // name in the subtree
+	Found    string // name in the parser
+}
+
+func (e *ParentMismatchError) Error() string {
+	return fmt.Sprintf("%s (%s) is %q, expected %q", e.Path, e.Locale, e.Found, e.Expected)
+}
+
+// subtree is the document written by ExportSubtree
+type subtree struct {
+	Locale  string       `json:"locale"`
+	Path    string       `json:"path"`
+	Parents []subtreeRef `json:"parents,omitempty"` // from the category down
+	Node    subtreeNode  `json:"node"`
+}
+
+type subtreeRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type subtreeNode struct {
+	Type     string        `json:"type"`
+	ID       string        `json:"id"`
+	Name     string        `json:"name"` // title of items, description of difficulties
+	Body     string        `json:"body,omitempty"`
+	Summary  string        `json:"summary,omitempty"`
+	Order    float64       `json:"order,omitempty"`
+	Audience []string      `json:"audience,omitempty"`
+	Checks   []Check       `json:"checks,omitempty"`
+	Children []subtreeNode `json:"children,omitempty"`
+}
+
+// ExportSubtree writes the category, subcategory or difficulty at the path, with all its
+// descendants and the IDs and names of its parents.
+func (r *ResourceParser) ExportSubtree(w io.Writer, path, locale string, format Format) error {
+	if format != FormatJSON {
+		return ErrFormat
+	}
+	c := r.lookup(locale, path)
+	if c == nil {
+		return &NotFoundError{Path: path, Locale: locale}
+	}
+	var doc = subtree{Locale: locale, Path: path}
+	switch v := c.(type) {
+	case *Category:
+	case *Subcategory:
+		doc.Parents = []subtreeRef{{v.parent.ID, v.parent.Name}}
+	case *Difficulty:
+		doc.Parents = []subtreeRef{{v.parent.parent.ID, v.parent.parent.Name}, {v.parent.ID, v.parent.Name}}
+	default:
+		return fmt.Errorf("Cannot export %s %s", cmpType(c), path)
+	}
+	doc.Node = newSubtreeNode(c)
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(doc)
+}
+
+func newSubtreeNode(c Component) subtreeNode {
+	var n = subtreeNode{Type: cmpType(c)}
+	switch v := c.(type) {
+	case *Category:
+		n.ID, n.Name, n.Order = v.ID, v.Name, v.Order
+		for _, s := range v.subcategories {
+			n.Children = append(n.Children, newSubtreeNode(s))
+		}
+	case *Subcategory:
+		n.ID, n.Name, n.Order, n.Audience = v.ID, v.Name, v.Order, v.Audience
+		for _, d := range v.difficulties {
+			n.Children = append(n.Children, newSubtreeNode(d))
+		}
+	case *Difficulty:
+		n.ID, n.Name = v.ID, v.Descr
+		if v.checklist != nil {
+			n.Checks = v.checklist.Checks
+		}
+		for _, i := range v.items {
+			n.Children = append(n.Children, newSubtreeNode(i))
+		}
+	case *Item:
+		n.ID, n.Name, n.Body, n.Summary, n.Order, n.Audience = v.ID, v.Title, v.Body, v.Abstract, v.Order, v.Audience
+	}
+	return n
+}
+
+// ImportSubtree reads a subtree written by ExportSubtree and merges it into its locale, at the
+// same path: components are added or updated, the ones missing from the subtree are kept.
+// The parents must exist with the same names. It returns the components added or changed.
+func (r *ResourceParser) ImportSubtree(rd io.Reader, format Format) ([]ItemRef, error) {
+	if format != FormatJSON {
+		return nil, ErrFormat
+	}
+	var doc subtree
+	if err := json.NewDecoder(rd).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var (
+		ids  = strings.Split(doc.Path, "/")
+		want = map[string]int{"category": 1, "subcategory": 2, "difficulty": 3}[doc.Node.Type]
+	)
+	if want == 0 || len(ids) != want || len(doc.Parents) != want-1 || ids[want-1] != doc.Node.ID {
+		return nil, fmt.Errorf("Invalid subtree %s", doc.Path)
+	}
+	var parents []Component
+	for i, p := range doc.Parents {
+		path := strings.Join(ids[:i+1], "/")
+		c := r.lookup(doc.Locale, path)
+		if c == nil || ids[i] != p.ID {
+			return nil, &NotFoundError{Path: path, Locale: doc.Locale}
+		}
+		if name := textFields(c)[0].Texts[0]; name != p.Name {
+			return nil, &ParentMismatchError{Path: path, Locale: doc.Locale, Expected: p.Name, Found: name}
+		}
+		parents = append(parents, c)
+	}
+	m := subtreeMerge{locale: doc.Locale}
+	switch len(parents) {
+	case 0:
+		m.category(r, doc.Node)
+	case 1:
+		m.subcategory(parents[0].(*Category), doc.Node)
+	case 2:
+		m.difficulty(parents[1].(*Subcategory), doc.Node)
+	}
+	return m.changed, nil
+}
+
+// subtreeMerge upserts the nodes of a subtree, recording what changes
+type subtreeMerge struct {
+	locale  string
+	changed []ItemRef
+}
+
+// track records c if it was created or its fields changed
+func (m *subtreeMerge) track(c Component, created bool, before, after []interface{}) {
+	if created || !reflect.DeepEqual(before, after) {
+		m.changed = append(m.changed, ItemRef{m.locale, treePath(c)})
+	}
+}
+
+func (m *subtreeMerge) category(r *ResourceParser, n subtreeNode) {
+	cat := r.category(n.ID, m.locale)
+	created := cat == nil
+	if created {
+		cat = &Category{ID: n.ID, Locale: m.locale}
+		r.categories[m.locale] = append(r.categories[m.locale], cat)
+	}
+	before := []interface{}{cat.Name, cat.Order}
+	cat.Name, cat.Order = n.Name, n.Order
+	m.track(cat, created, before, []interface{}{cat.Name, cat.Order})
+	for _, c := range n.Children {
+		m.subcategory(cat, c)
+	}
+}
+
+func (m *subtreeMerge) subcategory(cat *Category, n subtreeNode) {
+	sub := cat.Sub(n.ID)
+	created := sub == nil
+	if created {
+		sub = &Subcategory{ID: n.ID}
+		cat.Add(sub)
+	}
+	before := []interface{}{sub.Name, sub.Order, sub.Audience}
+	sub.Name, sub.Order, sub.Audience = n.Name, n.Order, n.Audience
+	m.track(sub, created, before, []interface{}{sub.Name, sub.Order, sub.Audience})
+	for _, c := range n.Children {
+		m.difficulty(sub, c)
+	}
+}
+
+func (m *subtreeMerge) difficulty(sub *Subcategory, n subtreeNode) {
+	diff := sub.Difficulty(n.ID)
+	created := diff == nil
+	if created {
+		diff = &Difficulty{ID: n.ID}
+		sub.AddDifficulty(diff)
+	}
+	var checks []Check
+	if diff.checklist != nil {
+		checks = diff.checklist.Checks
+	}
+	before := []interface{}{diff.Descr, checks}
+	diff.Descr = n.Name
+	if n.Checks != nil {
+		diff.SetChecks(&Checklist{Checks: n.Checks})
+		checks = n.Checks
+	}
+	m.track(diff, created, before, []interface{}{diff.Descr, checks})
+	for _, c := range n.Children {
+		item := diff.Item(c.ID)
+		created := item == nil
+		if created {
+			item = &Item{ID: c.ID}
+			diff.AddItem(item)
+		}
+		before := []interface{}{item.Title, item.Body, item.Abstract, item.Order, item.Audience}
+		item.Title, item.Body, item.Abstract, item.Order, item.Audience = c.Name, c.Body, c.Summary, c.Order, c.Audience
+		m.track(item, created, before, []interface{}{item.Title, item.Body, item.Abstract, item.Order, item.Audience})
+	}
+}
@@ -0,0 +1,107 @@
+package component
+
+import (
+	"bytes"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func subtreeParser() *ResourceParser {
+	p := NewResourceParser()
+	cat := audienceCategory()
+	cat.Sub("sub").Difficulty("beginner").AddChecks(Check{Text: "Check"}, Check{Text: "Read", NoCheck: true})
+	cat.Sub("sub").Difficulty("beginner").Item("all").Abstract = "Summary"
+	p.categories["en"] = []*Category{cat}
+	return p
+}
+
+func (CmpSuite) TestSubtreeRoundtrip(c *C) {
+	src := subtreeParser()
+	for _, tc := range []struct {
+		path  string
+		setup func(p *ResourceParser)
+		added int
+	}{
+		{"cat", func(p *ResourceParser) {}, 13},
+		{"cat/mixed", func(p *ResourceParser) {
+			p.categories["en"] = []*Category{{ID: "cat", Name: "Category", Locale: "en"}}
+		}, 5},
+		{"cat/sub/beginner", func(p *ResourceParser) {
+			p.categories["en"] = []*Category{testCategory("en", "")}
+			p.categories["en"][0].Sub("sub").Difficulty("beginner").AddItem(&Item{ID: "old", Title: "Old"})
+		}, 3},
+	} {
+		var exported bytes.Buffer
+		c.Assert(src.ExportSubtree(&exported, tc.path, "en", FormatJSON), IsNil)
+
+		// importing in the same tree changes nothing
+		refs, err := src.ImportSubtree(bytes.NewReader(exported.Bytes()), FormatJSON)
+		c.Assert(err, IsNil)
+		c.Assert(refs, HasLen, 0, Commentf(tc.path))
+
+		dst := NewResourceParser()
+		tc.setup(dst)
+		refs, err = dst.ImportSubtree(bytes.NewReader(exported.Bytes()), FormatJSON)
+		c.Assert(err, IsNil)
+		c.Assert(refs, HasLen, tc.added, Commentf(tc.path))
+		c.Assert(refs[0], Equals, ItemRef{"en", tc.path})
+		var again bytes.Buffer
+		c.Assert(dst.ExportSubtree(&again, tc.path, "en", FormatJSON), IsNil)
+		if tc.path == "cat/sub/beginner" {
+			// upsert keeps what was already there
+			c.Assert(dst.lookup("en", "cat/sub/beginner/old"), NotNil)
+			c.Assert(again.String(), Matches, `(?s).*"id": "old".*`)
+			continue
+		}
+		c.Assert(again.String(), Equals, exported.String(), Commentf(tc.path))
+	}
+	item := src.lookup("en", "cat/sub/beginner/all").(*Item)
+	c.Assert(item.Abstract, Equals, "Summary")
+}
+
+func (CmpSuite) TestSubtreeUpdate(c *C) {
+	p := subtreeParser()
+	var b bytes.Buffer
+	c.Assert(p.ExportSubtree(&b, "cat/sub", "en", FormatJSON), IsNil)
+	c.Assert(b.String(), Matches, `(?s)\{\n  "locale": "en",\n  "path": "cat/sub",\n  "parents": \[\n    \{\n      "id": "cat",\n      "name": "Category"\n.*`)
+	doc := strings.Replace(b.String(), `"name": "Both"`, `"name": "Both, again"`, 1)
+	doc = strings.Replace(doc, `"Read"`, `"Read it"`, 1)
+	refs, err := p.ImportSubtree(strings.NewReader(doc), FormatJSON)
+	c.Assert(err, IsNil)
+	c.Assert(refs, DeepEquals, []ItemRef{{"en", "cat/sub/beginner"}, {"en", "cat/sub/beginner/both"}})
+	c.Assert(p.lookup("en", "cat/sub/beginner/both").(*Item).Title, Equals, "Both, again")
+	c.Assert(p.lookup("en", "cat/sub/beginner").(*Difficulty).Checks().Checks, DeepEquals, []Check{
+		{Text: "Check"}, {Text: "Read it", NoCheck: true},
+	})
+}
+
+func (CmpSuite) TestSubtreeErrors(c *C) {
+	p := subtreeParser()
+	var b bytes.Buffer
+	c.Assert(p.ExportSubtree(&b, "cat/sub", "en", FormatCSV), Equals, ErrFormat)
+	c.Assert(p.ExportSubtree(&b, "cat/nope", "en", FormatJSON), DeepEquals, &NotFoundError{Path: "cat/nope", Locale: "en"})
+	c.Assert(p.ExportSubtree(&b, "cat/sub", "it", FormatJSON), FitsTypeOf, &NotFoundError{})
+	c.Assert(p.ExportSubtree(&b, "cat/sub/beginner/all", "en", FormatJSON), ErrorMatches, "Cannot export item .*")
+	c.Assert(p.ExportSubtree(&b, "cat/sub/beginner", "en", FormatJSON), IsNil)
+	doc := b.String()
+
+	_, err := NewResourceParser().ImportSubtree(strings.NewReader(doc), FormatJSON)
+	c.Assert(err, DeepEquals, &NotFoundError{Path: "cat", Locale: "en"})
+
+	dst := NewResourceParser()
+	dst.categories["en"] = []*Category{{ID: "cat", Name: "Category", Locale: "en"}}
+	_, err = dst.ImportSubtree(strings.NewReader(doc), FormatJSON)
+	c.Assert(err, DeepEquals, &NotFoundError{Path: "cat/sub", Locale: "en"})
+
+	dst.categories["en"] = []*Category{testCategory("en", "Other ")}
+	_, err = dst.ImportSubtree(strings.NewReader(doc), FormatJSON)
+	c.Assert(err, DeepEquals, &ParentMismatchError{Path: "cat", Locale: "en", Expected: "Category", Found: "Other Category"})
+	c.Assert(err, ErrorMatches, `cat \(en\) is "Other Category", expected "Category"`)
+	c.Assert(dst.lookup("en", "cat/sub/beginner").(*Difficulty).items, HasLen, 0)
+
+	_, err = p.ImportSubtree(strings.NewReader(strings.Replace(doc, `"path": "cat/sub/beginner"`, `"path": "cat/beginner"`, 1)), FormatJSON)
+	c.Assert(err, ErrorMatches, "Invalid subtree cat/beginner")
+	_, err = p.ImportSubtree(strings.NewReader(doc), FormatHTML)
+	c.Assert(err, Equals, ErrFormat)
+}
@@ -0,0 +1,81 @@
+package component
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SummaryLength is the maximum number of runes of a derived item summary
+var SummaryLength = 140
+
+const ellipsis = "…"
+
+type summaryCache struct {
+	body, text string
+	length     int
+}
+
+var (
+	mdImage    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	mdLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdTag      = regexp.MustCompile(`<[^>]+>`)
+	mdListItem = regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+\.)\s+`)
+	mdQuote    = regexp.MustCompile(`(?m)^\s*>\s?`)
+	mdEmphasis = regexp.MustCompile("[*_`~]+")
+)
+
+// Summary returns the explicit summary of the item if set. Otherwise it derives one from the
+// first paragraph of the body with text that is not a heading, stripped of Markdown and
+// truncated to SummaryLength runes on a word boundary.
+func (i *Item) Summary() string {
+	if i.Abstract != "" {
+		return i.Abstract
+	}
+	if c := i.summary; c.body != i.Body || c.length != SummaryLength {
+		i.summary = summaryCache{body: i.Body, length: SummaryLength, text: deriveSummary(i.Body, SummaryLength)}
+	}
+	return i.summary.text
+}
+
+func deriveSummary(body string, length int) string {
+	for _, p := range strings.Split(stripBodyNotes(body), paragraphSep) {
+		if p = strings.TrimSpace(p); p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		if s := stripMarkdown(p); s != "" {
+			return truncate(s, length)
+		}
+	}
+	return ""
+}
+
+func stripMarkdown(s string) string {
+	s = mdImage.ReplaceAllString(s, "")
+	s = mdLink.ReplaceAllString(s, "$1")
+	s = mdTag.ReplaceAllString(s, "")
+	s = mdListItem.ReplaceAllString(s, "")
+	s = mdQuote.ReplaceAllString(s, "")
+	s = mdEmphasis.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// truncate cuts s at the last word boundary, so that with the ellipsis it fits in length runes
+func truncate(s string, length int) string {
+	if utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	var cut, n int
+	for i := range s {
+		if n == length-1 {
+			cut = i
+			break
+		}
+		n++
+	}
+	if i := strings.LastIndex(s[:cut+1], " "); i > 0 {
+		cut = i
+	}
+	return strings.TrimRight(s[:cut], " ,;:.") + ellipsis
+}
@@ -0,0 +1,63 @@
+package component
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSummary(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	var testCases = []struct {
+		body, summary string
+	}{
+		{"Plain text.\n\nSecond", "Plain text."},
+		{"# Heading\n\nAfter **the** heading", "After the heading"},
+		{"![image](cover.png)\n\nText with a [link](http://example.com) &amp; more", "Text with a link & more"},
+		{"![image](cover.png) Caption", "Caption"},
+		{"- first\n- second\n\nText", "first second"},
+		{"1. one\n2. two", "one two"},
+		{"[[note: skip me]]\n\n> quoted <b>text</b>", "quoted text"},
+		{"## Only heading", ""},
+		{long, strings.Repeat("word ", 27) + "word…"},
+	}
+	for _, tc := range testCases {
+		item := Item{Body: tc.body}
+		if s := item.Summary(); s != tc.summary {
+			t.Errorf("%q: expected %q, got %q", tc.body, tc.summary, s)
+		}
+	}
+	item := Item{Body: "àèìòù " + long}
+	s := item.Summary()
+	if n := utf8.RuneCountInString(s); n > SummaryLength || !strings.HasSuffix(s, ellipsis) {
+		t.Errorf("summary too long (%d): %q", n, s)
+	}
+	item.Body = "Changed"
+	if s := item.Summary(); s != "Changed" {
+		t.Errorf("summary not updated: %q", s)
+	}
+}
+
+func TestSummaryOverride(t *testing.T) {
+	var item Item
+	const contents = "[Title]: # (Title)\n[Order]: # (1)\n[Summary]: # (Short one)\n\n# Heading\n\nBody"
+	if err := item.SetContents(contents); err != nil {
+		t.Fatal(err)
+	}
+	if s := item.Summary(); s != "Short one" {
+		t.Errorf("expected explicit summary, got %q", s)
+	}
+	if c := item.Contents(); c != contents {
+		t.Errorf("expected %q, got %q", contents, c)
+	}
+	d := testCategory("en", "").Sub("sub").Difficulty("beginner")
+	d.AddItem(&item)
+	d.AddChecks(Check{Text: "Check"})
+	if r := item.Resource(); r.Content[0]["summary"] != "Short one" {
+		t.Errorf("summary missing from resource %v", r.Content)
+	}
+	item.Abstract = ""
+	if items := d.Tree(RawMarkdown).(map[string]interface{})["items"].([]Item); items[0].Abstract != "Body" {
+		t.Errorf("expected derived summary in tree, got %q", items[0].Abstract)
+	}
+}
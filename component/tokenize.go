@@ -0,0 +1,95 @@
+package component
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TokenKind is the class of a Token
+type TokenKind int
+
+const (
+	TokenWord   TokenKind = iota // letters, with digits and inner apostrophes or hyphens
+	TokenNumber                  // digits, with inner dots or commas
+	TokenCJK                     // a single Han, Hiragana or Katakana character
+	TokenPunct                   // a single punctuation or symbol character
+)
+
+// Token is a unit of text. Every token but punctuation counts as a word.
+type Token struct {
+	Kind TokenKind
+	Text string
+}
+
+// IsWord tells if the token counts as a word
+func (t Token) IsWord() bool { return t.Kind != TokenPunct }
+
+// Tokenize splits the text into tokens. It's used by every feature that counts words, so
+// they always agree. The rules are currently the same for every locale.
+func Tokenize(locale, text string) []Token {
+	var (
+		tokens []Token
+		runes  = []rune(text)
+	)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			tokens = append(tokens, Token{TokenCJK, string(r)})
+			i++
+		case unicode.IsLetter(r):
+			j := i + 1
+			for j < len(runes) && (isWordRune(runes[j]) || isJoiner(runes[j]) && j+1 < len(runes) && isWordRune(runes[j+1])) {
+				j++
+			}
+			tokens = append(tokens, Token{TokenWord, string(runes[i:j])})
+			i = j
+		case unicode.IsDigit(r):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || strings.ContainsRune(".,", runes[j]) && j+1 < len(runes) && unicode.IsDigit(runes[j+1])) {
+				j++
+			}
+			kind := TokenNumber
+			if j < len(runes) && isWordRune(runes[j]) { // like 3g or 2fa
+				for kind = TokenWord; j < len(runes) && isWordRune(runes[j]); j++ {
+				}
+			}
+			tokens = append(tokens, Token{kind, string(runes[i:j])})
+			i = j
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			tokens = append(tokens, Token{TokenPunct, string(r)})
+			i++
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// TokenizeMarkdown is Tokenize for Markdown text: notes, code, images, link targets and
+// HTML tags are not tokenized.
+func TokenizeMarkdown(locale, text string) []Token {
+	_, text = extractCode(stripBodyNotes(text))
+	return Tokenize(locale, stripMarkdown(text))
+}
+
+// countWords returns the number of words of Markdown text, see TokenizeMarkdown
+func countWords(locale, s string) int {
+	var n int
+	for _, t := range TokenizeMarkdown(locale, s) {
+		if t.IsWord() {
+			n++
+		}
+	}
+	return n
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+func isWordRune(r rune) bool {
+	return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r))
+}
+
+func isJoiner(r rune) bool { return r == '\'' || r == '’' || r == '-' }
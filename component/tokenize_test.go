@@ -0,0 +1,98 @@
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, tc := range []struct {
+		text   string
+		tokens []Token
+	}{
+		{"", nil},
+		{"Don't re-use e-mail 2fa codes", []Token{
+			{TokenWord, "Don't"}, {TokenWord, "re-use"}, {TokenWord, "e-mail"}, {TokenWord, "2fa"}, {TokenWord, "codes"},
+		}},
+		{"Pay 1,000.50 - now!", []Token{
+			{TokenWord, "Pay"}, {TokenNumber, "1,000.50"}, {TokenPunct, "-"}, {TokenWord, "now"}, {TokenPunct, "!"},
+		}},
+		{"end. 'quoted'", []Token{
+			{TokenWord, "end"}, {TokenPunct, "."}, {TokenPunct, "'"}, {TokenWord, "quoted"}, {TokenPunct, "'"},
+		}},
+		{"使用VPN。", []Token{{TokenCJK, "使"}, {TokenCJK, "用"}, {TokenWord, "VPN"}, {TokenPunct, "。"}}},
+		{"café naïve", []Token{{TokenWord, "café"}, {TokenWord, "naïve"}}},
+	} {
+		if tokens := Tokenize("en", tc.text); !reflect.DeepEqual(tokens, tc.tokens) {
+			t.Errorf("%q: expected %v, got %v", tc.text, tc.tokens, tokens)
+		}
+	}
+}
+
+func TestTokenizeMarkdown(t *testing.T) {
+	tokens := TokenizeMarkdown("en", "**Read** [the guide](http://example.com/a) ![logo](logo.png)\n\n"+
+		"Run `gpg --verify` <b>now</b> [[note: not this]]\n\n```\ncode block\n```")
+	var words []string
+	for _, t := range tokens {
+		if t.IsWord() {
+			words = append(words, t.Text)
+		}
+	}
+	if expected := []string{"Read", "the", "guide", "Run", "now"}; !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected %q, got %q", expected, words)
+	}
+}
+
+// TestWordCounts checks that every feature counting words agrees with Tokenize
+func (CmpSuite) TestWordCounts(c *C) {
+	p := bilingualParser()
+	en := p.categories["en"][0].Sub("sub").Difficulty("beginner")
+	en.Item("item").Body = "Use `tor` & a VPN, **always**.\n\n安全な通信を使う\n\n```\nignored words\n```"
+	en.Item("other").Body = "1,000 [links](http://a.b/c) aren't e-mail!"
+
+	var expected = make(map[string]int)
+	tokens := 0
+	for _, cmp := range p.localeComponents("en") {
+		for _, f := range textFields(cmp) {
+			for _, s := range f.Texts {
+				for _, t := range TokenizeMarkdown("en", s) {
+					if t.IsWord() {
+						expected[treePath(cmp)]++
+						tokens++
+					}
+				}
+			}
+		}
+	}
+	c.Assert(expected["cat/sub/beginner/item"], Equals, 1+4+8)
+	c.Assert(expected["cat/sub/beginner/other"], Equals, 1+4)
+
+	// string catalog
+	var b bytes.Buffer
+	c.Assert(p.ExportStringCatalog(&b, "en", FormatJSON), IsNil)
+	var catalog stringCatalog
+	c.Assert(json.Unmarshal(b.Bytes(), &catalog), IsNil)
+	var units = make(map[string]int)
+	for _, u := range catalog.Units {
+		units[u.Path] += u.Words
+	}
+	c.Assert(units, DeepEquals, expected)
+
+	// query
+	for _, path := range []string{"cat/sub/beginner/item", "cat/sub/beginner/other"} {
+		item := p.lookup("en", path).(*Item)
+		n := expected[path] - countWords("en", item.Title)
+		refs, err := p.Query(fmt.Sprintf("locale:en AND id:%s AND words=%d", item.ID, n))
+		c.Assert(err, IsNil)
+		c.Assert(refs, DeepEquals, []ItemRef{{"en", path}})
+	}
+
+	// readiness, "it" translates everything but the body of item and other
+	translated := tokens - expected["cat/sub/beginner/other"] - (expected["cat/sub/beginner/item"] - 1 - 4)
+	c.Assert(p.LocaleReadiness("en")["it"].Words, Equals, percent(translated, tokens))
+}
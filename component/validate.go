@@ -0,0 +1,176 @@
+package component
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError is a problem of the rows of a resource found by Validate
+type ValidationError struct {
+	Row     int    `json:"row"` // numbered from 1, 0 for the whole resource
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	switch {
+	case e.Row == 0:
+		return e.Message
+	case e.Key == "":
+		return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+	}
+	return fmt.Sprintf("row %d, %s: %s", e.Row, e.Key, e.Message)
+}
+
+// rowSchema describes a row: the keys it needs with a value, and the ones it may have
+type rowSchema struct {
+	required []string
+	optional []string
+}
+
+// Validate checks the rows of the resource against the schema of the component, the base of
+// Parse, before parsing it: the number of rows, the keys each row needs with a value and the
+// unknown keys. It returns every problem found, in order of row, and nothing if the resource
+// is well formed; Parse can still fail for what depends on the content, like a name too long.
+func Validate(cmp Component, res *Resource) []ValidationError {
+	var v validator
+	switch c := cmp.(type) {
+	case *Category:
+		v.rows(res, 1, false, func(int) rowSchema { return rowSchema{required: []string{KeyName}} })
+	case *Subcategory:
+		v.rows(res, 1, false, func(int) rowSchema {
+			return rowSchema{required: []string{KeyName}, optional: []string{KeyAudience}}
+		})
+	case *Difficulty:
+		v.rows(res, 1, false, func(int) rowSchema { return rowSchema{required: []string{KeyDescription}} })
+	case *Item:
+		v.item(res)
+	case *Checklist:
+		v.rows(res, len(c.Checks), false, func(int) rowSchema {
+			return rowSchema{required: []string{KeyText}, optional: []string{KeyStyle}}
+		})
+	case *Form:
+		v.form(c, res)
+	case *Glossary:
+		v.rows(res, len(c.Terms), false, func(int) rowSchema {
+			return rowSchema{required: []string{KeyTerm, KeyDefinition}}
+		})
+	case *Quiz:
+		v.rows(res, len(c.Questions), false, func(int) rowSchema {
+			return rowSchema{required: []string{KeyQuestion, KeyOptions}, optional: []string{KeyExplanation}}
+		})
+	default:
+		v.add(0, "", "Invalid Component")
+	}
+	sort.SliceStable(v.errors, func(i, j int) bool { return v.errors[i].Row < v.errors[j].Row })
+	return v.errors
+}
+
+type validator struct {
+	errors []ValidationError
+}
+
+func (v *validator) add(row int, key, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{Row: row, Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// rows checks the number of rows, at least n if atLeast, and each row against its schema
+func (v *validator) rows(res *Resource, n int, atLeast bool, schema func(i int) rowSchema) {
+	switch l := len(res.Content); {
+	case atLeast && l < n:
+		v.add(0, "", "%d rows, at least %d expected", l, n)
+	case !atLeast && l != n:
+		v.add(0, "", "%d rows, %d expected", l, n)
+	}
+	for i, row := range res.Content {
+		if !atLeast && i >= n {
+			break
+		}
+		v.row(i+1, row, schema(i))
+	}
+}
+
+// row checks the keys of a row, numbered from 1
+func (v *validator) row(n int, row map[string]string, s rowSchema) {
+	if row == nil {
+		v.add(n, "", "empty row")
+		return
+	}
+	known := make(map[string]bool, len(s.required)+len(s.optional))
+	for _, k := range s.required {
+		known[k] = true
+		switch value, ok := row[k]; {
+		case !ok:
+			v.add(n, k, "missing")
+		case strings.TrimSpace(value) == "":
+			v.add(n, k, "empty value")
+		}
+	}
+	for _, k := range s.optional {
+		known[k] = true
+	}
+	for _, k := range sortedKeys(row) {
+		if !known[k] {
+			v.add(n, k, "unknown key")
+		}
+	}
+}
+
+// item checks the title row, with the whole body in the legacy format, and a row for each paragraph
+func (v *validator) item(res *Resource) {
+	legacy := len(res.Content) != 0 && res.Content[0][KeyBody] != ""
+	if legacy && len(res.Content) > 1 {
+		v.add(0, "", "%d rows, the legacy format with the body in the first row has 1", len(res.Content))
+	}
+	v.rows(res, 1, true, func(i int) rowSchema {
+		switch {
+		case i != 0:
+			return rowSchema{required: []string{KeyBody}}
+		case legacy:
+			return rowSchema{required: []string{KeyTitle, KeyBody}, optional: []string{KeySummary, KeyAudience}}
+		}
+		return rowSchema{required: []string{KeyTitle}, optional: []string{KeyBody, KeySummary, KeyAudience}}
+	})
+}
+
+// form checks the rows in the order of the screens and inputs of the base form
+func (v *validator) form(f *Form, res *Resource) {
+	type formRow struct {
+		schema rowSchema
+		input  *FormInput
+	}
+	var expected = []formRow{{schema: rowSchema{required: []string{KeyForm}}}}
+	for i, s := range f.Screens {
+		if s.Name != "" {
+			expected = append(expected, formRow{schema: rowSchema{required: []string{KeyScreen}, optional: []string{KeyID}}})
+		}
+		for j := range s.Items {
+			in := &f.Screens[i].Items[j]
+			if in.Label == "" && in.Hint == "" && in.Options == nil {
+				continue
+			}
+			var s rowSchema
+			for _, k := range []struct {
+				key   string
+				value string
+			}{{KeyLabel, in.Label}, {KeyHint, in.Hint}, {KeyOptions, strings.Join(in.Options, ";")}} {
+				if k.value != "" {
+					s.required = append(s.required, k.key)
+				} else {
+					s.optional = append(s.optional, k.key)
+				}
+			}
+			expected = append(expected, formRow{schema: s, input: in})
+		}
+	}
+	v.rows(res, len(expected), false, func(i int) rowSchema { return expected[i].schema })
+	for i, row := range res.Content {
+		if i >= len(expected) || expected[i].input == nil || row[KeyOptions] == "" {
+			continue
+		}
+		if msg := expected[i].input.checkOptions(strings.Split(row[KeyOptions], ";")); msg != "" {
+			v.add(i+1, KeyOptions, "%s", msg)
+		}
+	}
+}
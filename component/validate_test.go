@@ -0,0 +1,55 @@
+package component
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (CmpSuite) TestValidate(c *C) {
+	cat := testCategory("en", "")
+	diff := cat.Sub("sub").Difficulty("beginner")
+	item := &Item{ID: "item", Title: "Item", Body: "a"}
+	diff.AddItem(item)
+	diff.AddChecks(Check{Text: "One"}, Check{Text: "Two"})
+	form := errorForm()
+
+	for _, tc := range []struct {
+		cmp      Component
+		rows     []map[string]string
+		expected []string
+	}{
+		{cat, []map[string]string{{"name": "Categoria"}}, nil},
+		{cat, nil, []string{"0 rows, 1 expected"}},
+		{cat.Sub("sub"), []map[string]string{{"name": " ", "audience": "x", "title": "?"}, {"name": "B"}},
+			[]string{"2 rows, 1 expected", "row 1, name: empty value", "row 1, title: unknown key"}},
+		{diff, []map[string]string{{}}, []string{"row 1, description: missing"}},
+		{item, []map[string]string{{"title": "Voce"}, {"body": "Uno"}, {"text": "Due"}},
+			[]string{"row 3, body: missing", "row 3, text: unknown key"}},
+		{item, []map[string]string{{"title": "Voce", "body": "Testo"}}, nil},
+		{item, []map[string]string{{"title": "Voce", "body": "Testo"}, {"body": "Altro"}},
+			[]string{"2 rows, the legacy format with the body in the first row has 1"}},
+		{item, nil, []string{"0 rows, at least 1 expected"}},
+		{diff.Checks(), []map[string]string{{"text": "Uno", "style": "warning"}, nil},
+			[]string{"row 2: empty row"}},
+		{form, []map[string]string{{"form": "Modulo"}, {"screen": "Uno", "id": "one"}, {"label": "A", "options": "x;y"},
+			{"label": "B", "hint": "H"}, {"screen": "Due"}, {"label": "C"}, {"label": "D"}}, nil},
+		{form, []map[string]string{{"form": "Modulo"}, {"screen": "Uno"}, {"label": "A", "options": "x"},
+			{"label": "B"}, {"label": "C"}, {"label": " ", "hint": "H"}}, []string{
+			"6 rows, 7 expected",
+			"row 3, options: 1 options, 2 expected",
+			"row 4, hint: missing",
+			"row 5, screen: missing", "row 5, label: unknown key",
+			"row 6, label: empty value",
+		}},
+	} {
+		var errs []string
+		for _, e := range Validate(tc.cmp, &Resource{Content: tc.rows}) {
+			errs = append(errs, e.Error())
+		}
+		c.Assert(errs, DeepEquals, tc.expected)
+	}
+	c.Assert(Validate(new(Asset), &Resource{}), DeepEquals, []ValidationError{{Message: "Invalid Component"}})
+
+	// a form resource without rows is an error, not a panic
+	c.Assert(NewResourceParser().Parse(form, &Resource{}, "it"), DeepEquals,
+		&ContentMismatchError{Path: "forms/form", Kind: "form", Locale: "it", Unit: "rows", Expected: 1, AtLeast: true})
+}
@@ -0,0 +1,98 @@
+package component
+
+import (
+	"errors"
+	"sort"
+)
+
+// SkipChildren is returned by the function of Walk to skip the descendants of a component
+var SkipChildren = errors.New("skip children")
+
+// WalkFunc is called by Walk for each component, with its ancestors from the category down
+type WalkFunc func(path []Component, c Component) error
+
+// Walk visits the categories of the locale depth-first, categories, subcategories and items
+// sorted by Order and ID, then the difficulties of each subcategory with their items, checklist and quiz.
+// It stops at the first error returned by fn, and returns it, except for SkipChildren.
+// The components are copies, like the ones of Categories: changing them does not change the
+// parser.
+func (r *ResourceParser) Walk(locale string, fn WalkFunc) error {
+	for _, cat := range r.SortedCategories(locale) {
+		if err := cat.Walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkForms calls fn for a copy of each form of the locale, it stops at the first error and
+// returns it
+func (r *ResourceParser) WalkForms(locale string, fn func(f *Form) error) error {
+	for _, f := range r.forms[locale] {
+		if err := fn(f.Copy()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Walk visits the category and its descendants like ResourceParser.Walk, the components are
+// the ones of the category
+func (c *Category) Walk(fn WalkFunc) error {
+	return visit(nil, c, fn, func(path []Component) error {
+		subs := append(subSorter(nil), c.subcategories...)
+		sort.Stable(subs)
+		for _, sub := range subs {
+			if err := sub.walk(path, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Subcategory) walk(path []Component, fn WalkFunc) error {
+	return visit(path, s, fn, func(path []Component) error {
+		for _, d := range s.difficulties {
+			if err := d.walk(path, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *Difficulty) walk(path []Component, fn WalkFunc) error {
+	return visit(path, d, fn, func(path []Component) error {
+		items := append(itemSorter(nil), d.items...)
+		sort.Stable(items)
+		for _, item := range items {
+			if err := visit(path, item, fn, nil); err != nil {
+				return err
+			}
+		}
+		if d.checklist != nil && len(d.checklist.Checks) != 0 {
+			if err := visit(path, d.checklist, fn, nil); err != nil {
+				return err
+			}
+		}
+		if d.quiz != nil && len(d.quiz.Questions) != 0 {
+			return visit(path, d.quiz, fn, nil)
+		}
+		return nil
+	})
+}
+
+// visit calls fn for c and then children, with c added to the path, unless fn skips them
+func visit(path []Component, c Component, fn WalkFunc, children func(path []Component) error) error {
+	switch err := fn(path, c); {
+	case err == SkipChildren:
+		return nil
+	case err != nil:
+		return err
+	}
+	if children == nil {
+		return nil
+	}
+	return children(append(path[:len(path):len(path)], c))
+}
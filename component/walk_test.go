@@ -0,0 +1,81 @@
+package component
+
+import (
+	"errors"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func walkParser() *ResourceParser {
+	p := NewResourceParser()
+	second, first := testCategory("en", ""), testCategory("en", "")
+	second.ID, second.Order = "second", 2
+	first.ID, first.Order = "first", 1
+	first.Add(&Subcategory{ID: "before", Order: -1})
+	diff := first.Sub("sub").Difficulty("beginner")
+	diff.AddItem(&Item{ID: "b", Order: 2}, &Item{ID: "a", Order: 1})
+	diff.AddChecks(Check{Text: "Check"})
+	p.categories["en"] = []*Category{second, first}
+	p.forms["en"] = []*Form{{ID: "form"}}
+	return p
+}
+
+// walkLog returns a WalkFunc that logs the visits as "ancestors > path", the ancestors are
+// the last part of their path
+func walkLog(log *[]string, result func(c Component) error) WalkFunc {
+	return func(path []Component, c Component) error {
+		var ids []string
+		for _, p := range path {
+			s := treePath(p)
+			ids = append(ids, s[strings.LastIndex(s, "/")+1:])
+		}
+		*log = append(*log, strings.Join(ids, "/")+" > "+treePath(c))
+		return result(c)
+	}
+}
+
+func (CmpSuite) TestWalk(c *C) {
+	p := walkParser()
+	var log []string
+	c.Assert(p.Walk("en", walkLog(&log, func(Component) error { return nil })), IsNil)
+	c.Assert(log, DeepEquals, []string{
+		" > first",
+		"first > first/before",
+		"first > first/sub",
+		"first/sub > first/sub/beginner",
+		"first/sub/beginner > first/sub/beginner/a",
+		"first/sub/beginner > first/sub/beginner/b",
+		"first/sub/beginner > first/sub/beginner/.checks",
+		" > second",
+		"second > second/sub",
+		"second/sub > second/sub/beginner",
+	})
+
+	// pruning
+	log = nil
+	c.Assert(p.Walk("en", walkLog(&log, func(c Component) error {
+		if _, ok := c.(*Subcategory); ok {
+			return SkipChildren
+		}
+		return nil
+	})), IsNil)
+	c.Assert(log, DeepEquals, []string{" > first", "first > first/before", "first > first/sub", " > second", "second > second/sub"})
+
+	// early termination
+	log = nil
+	stop := errors.New("stop")
+	c.Assert(p.Walk("en", walkLog(&log, func(c Component) error {
+		if _, ok := c.(*Item); ok {
+			return stop
+		}
+		return nil
+	})), Equals, stop)
+	c.Assert(log, HasLen, 5)
+
+	var forms []string
+	c.Assert(p.WalkForms("en", func(f *Form) error { forms = append(forms, f.ID); return nil }), IsNil)
+	c.Assert(forms, DeepEquals, []string{"form"})
+	c.Assert(p.WalkForms("en", func(f *Form) error { return stop }), Equals, stop)
+	c.Assert(p.Walk("it", walkLog(&log, nil)), IsNil)
+}
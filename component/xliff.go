@@ -0,0 +1,330 @@
+package component
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// XLIFFVersion is a version of the XLIFF format supported by WriteXLIFF and ReadXLIFF
+type XLIFFVersion string
+
+const (
+	XLIFF12 XLIFFVersion = "1.2"
+	XLIFF20 XLIFFVersion = "2.0"
+)
+
+var ErrXLIFF = errors.New("Invalid XLIFF")
+
+// xliffFixed are the keys of the rows that are copied as they are, not translated
+var xliffFixed = map[string]bool{KeyID: true, KeyStyle: true, KeyAudience: true}
+
+// xliffGroup is the resource of a component, with the target rows when translated
+type xliffGroup struct {
+	path           string
+	source, target *Resource
+}
+
+// WriteXLIFF writes the strings of the source locale that the target locale still needs,
+// the components missing in the target or bootstrapped and not translated yet, as a XLIFF
+// document. Each component is a group named after its path, with a unit for each key of its
+// rows in the layout of Encode. The translated file can be read with ReadXLIFF.
+func (r *ResourceParser) WriteXLIFF(w io.Writer, source, target string, version XLIFFVersion) error {
+	var groups []xliffGroup
+	for _, c := range r.localeComponents(source) {
+		path := treePath(c)
+		if r.translation(target, path) != nil {
+			continue
+		}
+		res, err := r.Encode(c, source)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, xliffGroup{path: path, source: res})
+	}
+	var doc interface{}
+	switch version {
+	case XLIFF12:
+		doc = newXLIFF12(source, target, groups)
+	case XLIFF20:
+		doc = newXLIFF20(source, target, groups)
+	default:
+		return fmt.Errorf("%w: version %q", ErrXLIFF, version)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadXLIFF reads a document written by WriteXLIFF, in any version, and returns a request
+// for each component with all its units translated, with the source component as base.
+// Components with missing targets are left out, so they are still pending.
+func (r *ResourceParser) ReadXLIFF(rd io.Reader) ([]ParseRequest, error) {
+	b, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	var head struct {
+		Version string `xml:"version,attr"`
+	}
+	if err := xml.Unmarshal(b, &head); err != nil {
+		return nil, err
+	}
+	var (
+		source, target string
+		groups         []xliffGroup
+	)
+	// size returns the number of rows of the source component, the bound of the row of a unit
+	size := func(locale, path string) (int, error) {
+		c := r.lookup(locale, path)
+		if c == nil {
+			return 0, &NotFoundError{Path: path, Locale: locale}
+		}
+		res, err := r.Encode(c, locale)
+		if err != nil {
+			return 0, err
+		}
+		return len(res.Content), nil
+	}
+	switch XLIFFVersion(head.Version) {
+	case XLIFF12:
+		var doc xliff12
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		source, target, groups, err = doc.groups(size)
+	case XLIFF20:
+		var doc xliff20
+		if err := xml.Unmarshal(b, &doc); err != nil {
+			return nil, err
+		}
+		source, target, groups, err = doc.groups(size)
+	default:
+		return nil, fmt.Errorf("%w: version %q", ErrXLIFF, head.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []ParseRequest
+	for _, g := range groups {
+		if g.target == nil {
+			continue
+		}
+		c := r.lookup(source, g.path)
+		if c == nil {
+			return nil, &NotFoundError{Path: g.path, Locale: source}
+		}
+		list = append(list, ParseRequest{Component: c, Resource: g.target, Locale: target})
+	}
+	return list, nil
+}
+
+// xliffUnits calls fn for each key of the rows of res, in order, with the ID of its unit
+func xliffUnits(group int, res *Resource, fn func(id, key, value string)) {
+	for i, row := range res.Content {
+		for _, k := range sortedKeys(row) {
+			fn(fmt.Sprintf("%d.%d.%s", group, i, k), k, row[k])
+		}
+	}
+}
+
+// xliffRows collects the units of a group into rows, target is nil once a unit is missing.
+// Units must be in the rows of the source resource, size is their number.
+type xliffRows struct {
+	path   string
+	size   int
+	target *Resource
+}
+
+func newXLIFFRows(path string, size int) *xliffRows {
+	return &xliffRows{path: path, size: size, target: new(Resource)}
+}
+
+// set adds the target of the unit, nil if missing, or its source if it's not translated
+func (x *xliffRows) set(id, source string, target *string) error {
+	p := strings.SplitN(id, ".", 3)
+	if len(p) != 3 {
+		return fmt.Errorf("%w: unit %q of %s", ErrXLIFF, id, x.path)
+	}
+	row, err := strconv.Atoi(p[1])
+	if err != nil || row < 0 || row >= x.size {
+		return fmt.Errorf("%w: unit %q of %s", ErrXLIFF, id, x.path)
+	}
+	if xliffFixed[p[2]] && target == nil {
+		target = &source
+	}
+	if x.target == nil {
+		return nil
+	}
+	if target == nil {
+		x.target = nil
+		return nil
+	}
+	for len(x.target.Content) <= row {
+		x.target.Content = append(x.target.Content, make(map[string]string))
+	}
+	x.target.Content[row][p[2]] = *target
+	return nil
+}
+
+func (x *xliffRows) group() xliffGroup { return xliffGroup{path: x.path, target: x.target} }
+
+// xliffTranslate returns the translate attribute of the units of the key
+func xliffTranslate(key string) string {
+	if xliffFixed[key] {
+		return "no"
+	}
+	return ""
+}
+
+type xliff12 struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string      `xml:"version,attr"`
+	File    xliff12File `xml:"file"`
+}
+
+type xliff12File struct {
+	Original       string         `xml:"original,attr"`
+	SourceLanguage string         `xml:"source-language,attr"`
+	TargetLanguage string         `xml:"target-language,attr"`
+	Datatype       string         `xml:"datatype,attr"`
+	Groups         []xliff12Group `xml:"body>group"`
+}
+
+type xliff12Group struct {
+	ID      string        `xml:"id,attr"`
+	Resname string        `xml:"resname,attr"`
+	Units   []xliff12Unit `xml:"trans-unit"`
+}
+
+type xliff12Unit struct {
+	ID        string         `xml:"id,attr"`
+	Translate string         `xml:"translate,attr,omitempty"`
+	Space     string         `xml:"http://www.w3.org/XML/1998/namespace space,attr"`
+	Source    string         `xml:"source"`
+	Target    *xliff12Target `xml:"target"`
+}
+
+type xliff12Target struct {
+	State string `xml:"state,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+func newXLIFF12(source, target string, groups []xliffGroup) *xliff12 {
+	doc := &xliff12{Version: string(XLIFF12), File: xliff12File{
+		Original:       "tent",
+		SourceLanguage: source,
+		TargetLanguage: target,
+		Datatype:       "plaintext",
+	}}
+	for i, g := range groups {
+		group := xliff12Group{ID: fmt.Sprintf("g%d", i), Resname: g.path}
+		xliffUnits(i, g.source, func(id, key, value string) {
+			group.Units = append(group.Units, xliff12Unit{
+				ID:        id,
+				Translate: xliffTranslate(key),
+				Space:     "preserve",
+				Source:    value,
+			})
+		})
+		doc.File.Groups = append(doc.File.Groups, group)
+	}
+	return doc
+}
+
+func (doc *xliff12) groups(size func(locale, path string) (int, error)) (source, target string, groups []xliffGroup, err error) {
+	for _, g := range doc.File.Groups {
+		n, err := size(doc.File.SourceLanguage, g.Resname)
+		if err != nil {
+			return "", "", nil, err
+		}
+		rows := newXLIFFRows(g.Resname, n)
+		for _, u := range g.Units {
+			var value *string
+			if u.Target != nil && u.Target.State != "needs-translation" && u.Target.State != "new" {
+				value = &u.Target.Text
+			}
+			if err := rows.set(u.ID, u.Source, value); err != nil {
+				return "", "", nil, err
+			}
+		}
+		groups = append(groups, rows.group())
+	}
+	return doc.File.SourceLanguage, doc.File.TargetLanguage, groups, nil
+}
+
+type xliff20 struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string      `xml:"version,attr"`
+	SrcLang string      `xml:"srcLang,attr"`
+	TrgLang string      `xml:"trgLang,attr"`
+	File    xliff20File `xml:"file"`
+}
+
+type xliff20File struct {
+	ID     string         `xml:"id,attr"`
+	Groups []xliff20Group `xml:"group"`
+}
+
+type xliff20Group struct {
+	ID    string        `xml:"id,attr"`
+	Name  string        `xml:"name,attr"`
+	Units []xliff20Unit `xml:"unit"`
+}
+
+type xliff20Unit struct {
+	ID        string         `xml:"id,attr"`
+	Translate string         `xml:"translate,attr,omitempty"`
+	Space     string         `xml:"http://www.w3.org/XML/1998/namespace space,attr"`
+	Segment   xliff20Segment `xml:"segment"`
+}
+
+type xliff20Segment struct {
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+}
+
+func newXLIFF20(source, target string, groups []xliffGroup) *xliff20 {
+	doc := &xliff20{Version: string(XLIFF20), SrcLang: source, TrgLang: target, File: xliff20File{ID: "tent"}}
+	for i, g := range groups {
+		group := xliff20Group{ID: fmt.Sprintf("g%d", i), Name: g.path}
+		xliffUnits(i, g.source, func(id, key, value string) {
+			group.Units = append(group.Units, xliff20Unit{
+				ID:        id,
+				Translate: xliffTranslate(key),
+				Space:     "preserve",
+				Segment:   xliff20Segment{Source: value},
+			})
+		})
+		doc.File.Groups = append(doc.File.Groups, group)
+	}
+	return doc
+}
+
+func (doc *xliff20) groups(size func(locale, path string) (int, error)) (source, target string, groups []xliffGroup, err error) {
+	for _, g := range doc.File.Groups {
+		n, err := size(doc.SrcLang, g.Name)
+		if err != nil {
+			return "", "", nil, err
+		}
+		rows := newXLIFFRows(g.Name, n)
+		for _, u := range g.Units {
+			if err := rows.set(u.ID, u.Segment.Source, u.Segment.Target); err != nil {
+				return "", "", nil, err
+			}
+		}
+		groups = append(groups, rows.group())
+	}
+	return doc.SrcLang, doc.TrgLang, groups, nil
+}
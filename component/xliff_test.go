@@ -0,0 +1,100 @@
+package component
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// translateXLIFF sets the target of every unit of the document, but the ones of skip
+func translateXLIFF(c *C, b []byte, version XLIFFVersion, skip string) []byte {
+	var doc interface{}
+	switch version {
+	case XLIFF12:
+		var d xliff12
+		c.Assert(xml.Unmarshal(b, &d), IsNil)
+		for _, g := range d.File.Groups {
+			for i, u := range g.Units {
+				if u.Translate == "" && g.Resname != skip {
+					g.Units[i].Target = &xliff12Target{State: "translated", Text: "IT " + u.Source}
+				}
+			}
+		}
+		doc = &d
+	case XLIFF20:
+		var d xliff20
+		c.Assert(xml.Unmarshal(b, &d), IsNil)
+		for _, g := range d.File.Groups {
+			for i, u := range g.Units {
+				if u.Translate == "" && g.Name != skip {
+					text := "IT " + u.Segment.Source
+					g.Units[i].Segment.Target = &text
+				}
+			}
+		}
+		doc = &d
+	}
+	out, err := xml.Marshal(doc)
+	c.Assert(err, IsNil)
+	return out
+}
+
+func (CmpSuite) TestXLIFF(c *C) {
+	for _, version := range []XLIFFVersion{XLIFF12, XLIFF20} {
+		p := bilingualParser()
+		form := errorForm()
+		form.Locale = "en"
+		form.Screens[0].ID = "first"
+		p.forms["en"] = []*Form{form}
+
+		// only what's missing in the target is exported
+		var b bytes.Buffer
+		c.Assert(p.WriteXLIFF(&b, "en", "it", version), IsNil)
+		c.Assert(strings.HasPrefix(b.String(), xml.Header+`<xliff xmlns="urn:oasis:names:tc:xliff:document:`+string(version)), Equals, true)
+		c.Assert(strings.Contains(b.String(), "cat/sub/beginner/other"), Equals, true)
+		c.Assert(strings.Contains(b.String(), "cat/sub/beginner/item"), Equals, false)
+		c.Assert(strings.Contains(b.String(), `translate="no"`), Equals, true)
+
+		// a document without targets has nothing to import
+		list, err := p.ReadXLIFF(bytes.NewReader(b.Bytes()))
+		c.Assert(err, IsNil)
+		c.Assert(list, HasLen, 0)
+
+		// incomplete components are left out
+		list, err = p.ReadXLIFF(bytes.NewReader(translateXLIFF(c, b.Bytes(), version, "forms/form")))
+		c.Assert(err, IsNil)
+		c.Assert(list, HasLen, 1)
+		c.Assert(list[0].Locale, Equals, "it")
+		c.Assert(list[0].Resource.Content, DeepEquals, []map[string]string{{"title": "IT Other"}, {"body": "IT <b>Three</b>"}})
+
+		list, err = p.ReadXLIFF(bytes.NewReader(translateXLIFF(c, b.Bytes(), version, "")))
+		c.Assert(err, IsNil)
+		c.Assert(list, HasLen, 2)
+		_, errs := p.ParseAll(list)
+		c.Assert(errs, HasLen, 0)
+		c.Assert(p.category("cat", "it").Sub("sub").Difficulty("beginner").Item("other").Title, Equals, "IT Other")
+		f := p.FormUnsafe("form", "it")
+		c.Assert(f.Name, Equals, "IT Form")
+		c.Assert(f.Screens[0].ID, Equals, "first")
+		c.Assert(f.Screens[0].Items[0].Options, DeepEquals, []string{"IT x", "y"})
+		c.Assert(f.Screens[0].Items[1].Hint, Equals, "IT Hint")
+
+		// nothing is left to translate
+		b.Reset()
+		c.Assert(p.WriteXLIFF(&b, "en", "it", version), IsNil)
+		c.Assert(strings.Contains(b.String(), "<group"), Equals, false)
+	}
+
+	p := bilingualParser()
+	c.Assert(p.WriteXLIFF(new(bytes.Buffer), "en", "it", "1.0"), ErrorMatches, `Invalid XLIFF: version "1.0"`)
+	_, err := p.ReadXLIFF(strings.NewReader(`<xliff version="1.0"></xliff>`))
+	c.Assert(err, ErrorMatches, `Invalid XLIFF: version "1.0"`)
+	_, err = p.ReadXLIFF(strings.NewReader(`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en"><file><group name="cat"><unit id="x"><segment><source>A</source></segment></unit></group></file></xliff>`))
+	c.Assert(err, ErrorMatches, `Invalid XLIFF: unit "x" of cat`)
+	_, err = p.ReadXLIFF(strings.NewReader(`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en"><file><group name="cat"><unit id="0.999999999.name"><segment><source>A</source><target>B</target></segment></unit></group></file></xliff>`))
+	c.Assert(err, ErrorMatches, `Invalid XLIFF: unit "0.999999999.name" of cat`)
+	_, err = p.ReadXLIFF(strings.NewReader(`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0"><file><group name="missing"><unit id="0.0.name"><segment><source>A</source><target>B</target></segment></unit></group></file></xliff>`))
+	c.Assert(err, FitsTypeOf, &NotFoundError{})
+}
@@ -0,0 +1,357 @@
+// Package kvfile is a small key-value store kept in a single append-only file.
+//
+// Every change is a record with a checksum, written and synced before Put or Delete return.
+// When the file is opened, a truncated or corrupt tail, left by a crash during a write, is
+// discarded. The file is rewritten without the obsolete records when they are the majority,
+// keeping its permissions; a failed rewrite is sent to the logger set with SetLogger, if any,
+// as the records are already durable.
+package kvfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrClosed   = errors.New("kvfile: closed")
+	ErrFormat   = errors.New("kvfile: not a kvfile")
+	ErrTooLarge = errors.New("kvfile: key or value too large")
+)
+
+// CompactAfter is the minimum number of obsolete records that make the file compacted
+var CompactAfter = 256
+
+const (
+	magic     = "kvfile1\n"
+	headerLen = 13 // checksum, operation, key length, value length
+
+	opPut    = 1
+	opDelete = 2
+
+	maxKeyLen   = 1 << 20
+	maxValueLen = 1 << 30
+)
+
+// DB is a store opened from a file, it's safe to use from multiple goroutines
+type DB struct {
+	mu      sync.RWMutex
+	path    string
+	f       *os.File
+	size    int64 // end of the last valid record
+	data    map[string][]byte
+	garbage int         // obsolete records in the file
+	logger  *log.Logger // errors of the automatic compaction, see SetLogger
+}
+
+// Open loads the store from the file, that is created if missing
+func Open(path string) (*DB, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	db := DB{path: path, f: f, data: make(map[string][]byte)}
+	if err := db.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &db, nil
+}
+
+// load reads the records until the first invalid one, and truncates the file there
+func (db *DB) load() error {
+	info, err := db.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		if _, err := db.f.Write([]byte(magic)); err != nil {
+			return err
+		}
+		db.size = int64(len(magic))
+		return db.f.Sync()
+	}
+	r := bufio.NewReader(db.f)
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, head); err != nil || string(head) != magic {
+		return ErrFormat
+	}
+	db.size = int64(len(magic))
+	for {
+		op, key, value, n, err := readRecord(r, info.Size()-db.size)
+		if err != nil {
+			break
+		}
+		db.size += n
+		if _, ok := db.data[key]; ok {
+			db.garbage++
+		}
+		switch op {
+		case opPut:
+			db.data[key] = value
+		case opDelete:
+			delete(db.data, key)
+			db.garbage++
+		}
+	}
+	if db.size == info.Size() {
+		_, err = db.f.Seek(db.size, io.SeekStart)
+		return err
+	}
+	return db.truncate()
+}
+
+// truncate drops what follows the last valid record
+func (db *DB) truncate() error {
+	if err := db.f.Truncate(db.size); err != nil {
+		return err
+	}
+	if _, err := db.f.Seek(db.size, io.SeekStart); err != nil {
+		return err
+	}
+	return db.f.Sync()
+}
+
+// readRecord reads a record of at most max bytes, header included. The lengths are checked
+// before allocating, so a corrupt header cannot make it allocate more than the file has.
+func readRecord(r io.Reader, max int64) (op byte, key string, value []byte, n int64, err error) {
+	var head [headerLen]byte
+	if _, err = io.ReadFull(r, head[:]); err != nil {
+		return
+	}
+	op = head[4]
+	klen, vlen := binary.BigEndian.Uint32(head[5:9]), binary.BigEndian.Uint32(head[9:13])
+	if op != opPut && op != opDelete || klen > maxKeyLen || vlen > maxValueLen ||
+		headerLen+int64(klen)+int64(vlen) > max {
+		err = ErrFormat
+		return
+	}
+	body := make([]byte, int(klen)+int(vlen))
+	if _, err = io.ReadFull(r, body); err != nil {
+		return
+	}
+	h := crc32.NewIEEE()
+	h.Write(head[4:])
+	h.Write(body)
+	if h.Sum32() != binary.BigEndian.Uint32(head[:4]) {
+		err = ErrFormat
+		return
+	}
+	return op, string(body[:klen]), body[klen:], int64(headerLen + len(body)), nil
+}
+
+func appendRecord(b []byte, op byte, key string, value []byte) []byte {
+	var head [headerLen]byte
+	head[4] = op
+	binary.BigEndian.PutUint32(head[5:9], uint32(len(key)))
+	binary.BigEndian.PutUint32(head[9:13], uint32(len(value)))
+	h := crc32.NewIEEE()
+	h.Write(head[4:])
+	io.WriteString(h, key)
+	h.Write(value)
+	binary.BigEndian.PutUint32(head[:4], h.Sum32())
+	b = append(b, head[:]...)
+	b = append(b, key...)
+	return append(b, value...)
+}
+
+// Get returns a copy of the value of the key
+func (db *DB) Get(key string) ([]byte, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	v, ok := db.data[key]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte{}, v...), true
+}
+
+// Put saves the value of the key, it's durable when Put returns.
+// Keys are up to 1MB and values up to 1GB, larger ones return ErrTooLarge.
+func (db *DB) Put(key string, value []byte) error {
+	if len(key) > maxKeyLen || len(value) > maxValueLen {
+		return ErrTooLarge
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	value = append([]byte{}, value...)
+	if err := db.write(opPut, key, value); err != nil {
+		return err
+	}
+	if _, ok := db.data[key]; ok {
+		db.garbage++
+	}
+	db.data[key] = value
+	db.compactIfNeeded()
+	return nil
+}
+
+// Delete removes the key, a missing key is not an error
+func (db *DB) Delete(key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if _, ok := db.data[key]; !ok {
+		return nil
+	}
+	if err := db.write(opDelete, key, nil); err != nil {
+		return err
+	}
+	delete(db.data, key)
+	db.garbage += 2
+	db.compactIfNeeded()
+	return nil
+}
+
+// write appends and syncs a record; on failure the file is truncated to the previous record
+func (db *DB) write(op byte, key string, value []byte) error {
+	if db.f == nil {
+		return ErrClosed
+	}
+	b := appendRecord(nil, op, key, value)
+	_, err := db.f.Write(b)
+	if err == nil {
+		err = db.f.Sync()
+	}
+	if err != nil {
+		db.truncate()
+		return err
+	}
+	db.size += int64(len(b))
+	return nil
+}
+
+// Range calls fn for every key, sorted, until it returns false. It works on a copy of the
+// store, so fn can change it.
+func (db *DB) Range(fn func(key string, value []byte) bool) {
+	db.mu.RLock()
+	var keys = make([]string, 0, len(db.data))
+	for k := range db.data {
+		keys = append(keys, k)
+	}
+	var values = make(map[string][]byte, len(db.data))
+	for k, v := range db.data {
+		values[k] = append([]byte{}, v...)
+	}
+	db.mu.RUnlock()
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !fn(k, values[k]) {
+			return
+		}
+	}
+}
+
+// Len returns the number of keys
+func (db *DB) Len() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.data)
+}
+
+// SetLogger sets the logger of the errors of the compaction that follows Put and Delete,
+// nil, the default, discards them. Compact returns its error.
+func (db *DB) SetLogger(l *log.Logger) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.logger = l
+}
+
+// compactIfNeeded compacts the file after a change, that is durable even if compaction fails
+func (db *DB) compactIfNeeded() {
+	if db.garbage < CompactAfter || db.garbage <= len(db.data) {
+		return
+	}
+	if err := db.compact(); err != nil && db.logger != nil {
+		db.logger.Printf("kvfile: compacting %s: %s", db.path, err)
+	}
+}
+
+// Compact rewrites the file with the live keys only
+func (db *DB) Compact() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.compact()
+}
+
+// compact writes a new file and renames it over the old one, so a crash leaves one of the two
+func (db *DB) compact() error {
+	if db.f == nil {
+		return ErrClosed
+	}
+	var keys = make([]string, 0, len(db.data))
+	for k := range db.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b := []byte(magic)
+	for _, k := range keys {
+		b = appendRecord(b, opPut, k, db.data[k])
+	}
+	info, err := db.f.Stat()
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(db.path), filepath.Base(db.path))
+	if err != nil {
+		return err
+	}
+	// TempFile creates the file readable by the owner only
+	if err = tmp.Chmod(info.Mode().Perm()); err == nil {
+		_, err = tmp.Write(b)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), db.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	syncDir(filepath.Dir(db.path))
+	f, err := os.OpenFile(db.path, os.O_RDWR, 0644)
+	if err != nil {
+		db.f.Close()
+		db.f = nil
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return err
+	}
+	db.f.Close()
+	db.f, db.size, db.garbage = f, int64(len(b)), 0
+	return nil
+}
+
+// syncDir makes a rename durable, where the platform supports it
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// Close closes the file, the store cannot be used anymore
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.f == nil {
+		return ErrClosed
+	}
+	err := db.f.Close()
+	db.f = nil
+	return err
+}
@@ -0,0 +1,258 @@
+package kvfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func open(t *testing.T, path string) *DB {
+	t.Helper()
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func contents(db *DB) map[string]string {
+	var m = make(map[string]string)
+	db.Range(func(k string, v []byte) bool {
+		m[k] = string(v)
+		return true
+	})
+	return m
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	db := open(t, path)
+	for _, kv := range [][2]string{{"a", "1"}, {"b", "2"}, {"c", ""}, {"a", "3"}} {
+		if err := db.Put(kv[0], []byte(kv[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete("missing"); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"a": "3", "c": ""}
+	if m := contents(db); !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %v, got %v", expected, m)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put("d", nil); err != ErrClosed {
+		t.Fatalf("expected %v, got %v", ErrClosed, err)
+	}
+
+	db = open(t, path)
+	defer db.Close()
+	if m := contents(db); !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %v, got %v", expected, m)
+	}
+	if v, ok := db.Get("a"); !ok || string(v) != "3" {
+		t.Fatalf("expected 3, got %q %v", v, ok)
+	}
+	if _, ok := db.Get("b"); ok {
+		t.Fatal("deleted key found")
+	}
+	// values are copies
+	v, _ := db.Get("a")
+	v[0] = 'x'
+	if v, _ := db.Get("a"); string(v) != "3" {
+		t.Fatalf("value changed: %q", v)
+	}
+}
+
+func TestTruncatedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	db := open(t, path)
+	db.Put("a", []byte("one"))
+	db.Put("b", []byte("two"))
+	db.Close()
+
+	// the file is the same after each recovery, the damage is always to b
+	for _, damage := range []struct {
+		name string
+		fn   func(b []byte) []byte
+	}{
+		{"partial record", func(b []byte) []byte { return b[:len(b)-2] }},
+		{"partial header", func(b []byte) []byte { return b[:len(b)-len("b")-len("two")-headerLen+3] }},
+		{"bad checksum", func(b []byte) []byte { b[len(b)-1] ^= 0xff; return b }},
+		{"huge length", func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[len(b)-len("b")-len("two")-4:], maxValueLen)
+			return b
+		}},
+		{"garbage tail", func(b []byte) []byte { return append(b, 1, 2, 3) }},
+	} {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, damage.fn(b), 0644); err != nil {
+			t.Fatal(err)
+		}
+		db = open(t, path)
+		expected := map[string]string{"a": "one"}
+		if damage.name == "garbage tail" {
+			expected["b"] = "two"
+		}
+		if m := contents(db); !reflect.DeepEqual(m, expected) {
+			t.Fatalf("%s: expected %v, got %v", damage.name, expected, m)
+		}
+		// the store keeps working after the recovery
+		if err := db.Put("b", []byte("two")); err != nil {
+			t.Fatal(err)
+		}
+		db.Close()
+		db = open(t, path)
+		if m := contents(db); !reflect.DeepEqual(m, map[string]string{"a": "one", "b": "two"}) {
+			t.Fatalf("%s: got %v after recovery", damage.name, m)
+		}
+		db.Close()
+	}
+}
+
+func TestReadRecordLimit(t *testing.T) {
+	b := appendRecord(nil, opPut, "key", []byte("value"))
+	if _, key, _, n, err := readRecord(bytes.NewReader(b), int64(len(b))); err != nil || key != "key" || n != int64(len(b)) {
+		t.Fatalf("got %q %d %v", key, n, err)
+	}
+	// a length past the end of the file is rejected before allocating the record
+	binary.BigEndian.PutUint32(b[9:13], maxValueLen)
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, _, _, _, err := readRecord(bytes.NewReader(b), int64(len(b))); err != ErrFormat {
+			t.Fatalf("expected %v, got %v", ErrFormat, err)
+		}
+	})
+	if allocs > 2 {
+		t.Fatalf("%v allocations", allocs)
+	}
+}
+
+func TestNotKVFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	if err := ioutil.WriteFile(path, []byte(`[{"path":"a"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err != ErrFormat {
+		t.Fatalf("expected %v, got %v", ErrFormat, err)
+	}
+	if b, _ := ioutil.ReadFile(path); string(b) != `[{"path":"a"}]` {
+		t.Fatalf("file changed: %s", b)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	defer func(n int) { CompactAfter = n }(CompactAfter)
+	CompactAfter = 10
+
+	path := filepath.Join(t.TempDir(), "db")
+	db := open(t, path)
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	expected := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		k := fmt.Sprint("key", i%5)
+		v := fmt.Sprint(i)
+		if err := db.Put(k, []byte(v)); err != nil {
+			t.Fatal(err)
+		}
+		expected[k] = v
+	}
+	db.Put("gone", []byte("x"))
+	db.Delete("gone")
+	if db.garbage >= CompactAfter {
+		t.Fatalf("not compacted, %d obsolete records", db.garbage)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if max := int64(len(magic) + 20*(headerLen+10)); info.Size() > max {
+		t.Fatalf("file has %d bytes, expected at most %d", info.Size(), max)
+	}
+	if mode := info.Mode().Perm(); mode != 0640 {
+		t.Fatalf("compaction changed the mode to %v", mode)
+	}
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Put("last", []byte("y")); err != nil {
+		t.Fatal(err)
+	}
+	expected["last"] = "y"
+	db.Close()
+
+	db = open(t, path)
+	defer db.Close()
+	if m := contents(db); !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %v, got %v", expected, m)
+	}
+	if db.garbage != 0 {
+		t.Fatalf("expected no obsolete records, got %d", db.garbage)
+	}
+}
+
+func TestTooLarge(t *testing.T) {
+	db := open(t, filepath.Join(t.TempDir(), "db"))
+	defer db.Close()
+	if err := db.Put(string(make([]byte, maxKeyLen+1)), nil); err != ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if err := db.Put(string(make([]byte, maxKeyLen)), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	defer func(n int) { CompactAfter = n }(CompactAfter)
+	CompactAfter = 8
+
+	path := filepath.Join(t.TempDir(), "db")
+	db := open(t, path)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				k := fmt.Sprintf("%d-%d", g, i%10)
+				if err := db.Put(k, []byte(fmt.Sprint(i))); err != nil {
+					t.Error(err)
+					return
+				}
+				if v, ok := db.Get(k); !ok || len(v) == 0 {
+					t.Errorf("%s: got %q", k, v)
+				}
+				db.Range(func(string, []byte) bool { return false })
+				if i%7 == 0 {
+					db.Delete(k)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	expected := contents(db)
+	db.Close()
+
+	db = open(t, path)
+	defer db.Close()
+	if m := contents(db); !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %v, got %v", expected, m)
+	}
+	if db.Len() != len(expected) || len(expected) == 0 {
+		t.Fatalf("expected %d keys, got %d", len(expected), db.Len())
+	}
+}
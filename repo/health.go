@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/securityfirst/tent/component"
+)
+
+// Health describes the state of the content served by the repo
+type Health struct {
+	Loaded    bool                `json:"loaded"`
+	Hash      string              `json:"hash,omitempty"`
+	Updated   time.Time           `json:"updated,omitempty"` // last successful update, even without changes
+	Age       time.Duration       `json:"age"`               // time since Updated
+	Locales   int                 `json:"locales"`
+	LastError string              `json:"last_error,omitempty"` // error of the last update, if it failed
+	Failed    map[string]string   `json:"failed_locales,omitempty"`
+	Blocking  []component.Problem `json:"blocking,omitempty"` // problems of the parse that block readiness
+	Ready     bool                `json:"ready"`              // loaded, not older than the max age and without blocking problems
+	Reason    string              `json:"reason,omitempty"`   // why it's not ready
+}
+
+// status keeps the outcome of the updates, with its own lock so that it can be read during one
+type status struct {
+	sync.Mutex
+	hash     string
+	updated  time.Time
+	locales  int
+	err      error
+	failed   map[string]error
+	problems []component.Problem
+	maxAge   time.Duration
+	blocking func(component.Problem) bool
+}
+
+func (s *status) record(hash string, locales int, failed map[string]error, problems []component.Problem, err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.hash, s.locales, s.failed, s.problems, s.err = hash, locales, failed, problems, err
+	if err == nil {
+		s.updated = time.Now()
+	}
+}
+
+// SetMaxAge sets how old the last update can be for the repo to be ready, 0 means no limit
+func (r *Repo) SetMaxAge(d time.Duration) {
+	r.status.Lock()
+	defer r.status.Unlock()
+	r.status.maxAge = d
+}
+
+// SetBlocking sets which problems of the parse, see Parser.Problems, keep the repo from being
+// ready. nil, the default, makes every problem blocking.
+func (r *Repo) SetBlocking(fn func(component.Problem) bool) {
+	r.status.Lock()
+	defer r.status.Unlock()
+	r.status.blocking = fn
+}
+
+// Health returns the state of the repo, it does not wait for an update in progress
+func (r *Repo) Health() Health {
+	s := &r.status
+	s.Lock()
+	defer s.Unlock()
+	h := Health{
+		Loaded:  s.hash != "",
+		Hash:    s.hash,
+		Updated: s.updated,
+		Locales: s.locales,
+	}
+	if !s.updated.IsZero() {
+		h.Age = time.Since(s.updated)
+	}
+	if s.err != nil {
+		h.LastError = s.err.Error()
+	}
+	if len(s.failed) != 0 {
+		h.Failed = make(map[string]string, len(s.failed))
+		for l, err := range s.failed {
+			h.Failed[l] = err.Error()
+		}
+	}
+	for _, p := range s.problems {
+		if s.blocking == nil || s.blocking(p) {
+			h.Blocking = append(h.Blocking, p)
+		}
+	}
+	switch {
+	case !h.Loaded:
+		h.Reason = "not loaded"
+	case s.maxAge != 0 && h.Age > s.maxAge:
+		h.Reason = fmt.Sprintf("last update %s ago, max age %s", h.Age.Round(time.Second), s.maxAge)
+	case len(h.Blocking) != 0:
+		h.Reason = fmt.Sprintf("blocking problems (%d), the first is %s", len(h.Blocking), h.Blocking[0])
+	default:
+		h.Ready = true
+	}
+	return h
+}
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+)
+
+// MIMEMsgPack is the content type of MessagePack responses
+const MIMEMsgPack = binding.MIMEMSGPACK2
+
+var ErrNotAcceptable = errors.New("unsupported accept type, use application/json or application/msgpack")
+
+// negotiate returns the content type for the Accept header: the supported type with the
+// highest weight, the q parameter, of the most specific range that matches it, the first
+// listed for equal weights. It's JSON if the header is empty, or an empty string if no type
+// is supported or they all have weight 0.
+func negotiate(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return gin.MIMEJSON
+	}
+	var (
+		best     string
+		bestQ    float64
+		bestPos  int
+		accepted = parseAccept(accept)
+	)
+	for _, mime := range []string{gin.MIMEJSON, MIMEMsgPack} {
+		q, pos := acceptWeight(accepted, mime)
+		if q > bestQ || q == bestQ && q > 0 && pos < bestPos {
+			best, bestQ, bestPos = mime, q, pos
+		}
+	}
+	return best
+}
+
+// mediaRange is a range of the Accept header, with its weight
+type mediaRange struct {
+	mime string
+	q    float64
+}
+
+// parseAccept returns the ranges of the Accept header, an invalid q is 0
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{mime: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		if r.mime == "" {
+			continue
+		}
+		for _, p := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) != 2 || !strings.EqualFold(strings.TrimSpace(kv[0]), "q") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			r.q = q
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// acceptWeight returns the weight of the type and the position of the range that gives it,
+// the most specific that matches: the type, then its main type with /*, then */*
+func acceptWeight(ranges []mediaRange, mime string) (q float64, pos int) {
+	var names = []string{mime, strings.SplitN(mime, "/", 2)[0] + "/*", "*/*"}
+	if mime == MIMEMsgPack {
+		names = append([]string{binding.MIMEMSGPACK}, names...)
+	}
+	for _, name := range names {
+		for i, r := range ranges {
+			if r.mime == name {
+				return r.q, i
+			}
+		}
+	}
+	return 0, len(ranges)
+}
+
+// render writes obj in the type accepted by the client, answering 406 if none is supported
+func (r *RepoHandler) render(c *gin.Context, status int, obj interface{}) {
+	mime := negotiate(c.GetHeader("Accept"))
+	c.Writer.Header().Add("Vary", "Accept")
+	if mime == "" {
+		r.err(c, http.StatusNotAcceptable, ErrNotAcceptable)
+		return
+	}
+	if mime == MIMEMsgPack {
+		c.Status(status)
+		render.WriteMsgPack(c.Writer, obj)
+		return
+	}
+	writeJSON(c, status, obj)
+}
+
+// renderContent renders content of the current commit, with an ETag that is the same for every
+// type and a 304 answer when it matches If-None-Match. The content is built only when needed.
+func (r *RepoHandler) renderContent(c *gin.Context, obj func() interface{}) {
+	c.Writer.Header().Add("Vary", "X-Tent-Language")
+	if negotiate(c.GetHeader("Accept")) != "" {
+		if etag := r.repo.etag(r.locale(c), c.Request.URL.RequestURI()); etag != "" {
+			c.Header("ETag", etag)
+			if c.GetHeader("If-None-Match") == etag {
+				c.Writer.Header().Add("Vary", "Accept")
+				c.Status(http.StatusNotModified)
+				c.Writer.WriteHeaderNow()
+				return
+			}
+		}
+	}
+	r.render(c, http.StatusOK, obj())
+}
+
+func writeJSON(c *gin.Context, status int, obj interface{}) {
+	header := c.Writer.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{"application/json; charset=utf-8"}
+	}
+	c.Status(status)
+	e := json.NewEncoder(c.Writer)
+	e.SetEscapeHTML(false)
+	e.Encode(obj)
+}
@@ -2,12 +2,15 @@ package repo
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/securityfirst/tent/component"
@@ -66,6 +69,25 @@ func New(owner, name, branch string) (*Repo, error) {
 	return &Repo{repo: r, name: name, owner: owner, branch: branch}, nil
 }
 
+// Load clones the repository at url, any address supported by git, and parses the content of
+// the branch, "master" if empty. Unlike Local and New, the content is ready when it returns;
+// Refresh updates it.
+func Load(ctx context.Context, url, branch string) (*Repo, error) {
+	logger.Printf("Using %q", url)
+	r, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{URL: url})
+	if err != nil {
+		return nil, err
+	}
+	if branch == "" {
+		branch = "master"
+	}
+	repo := &Repo{repo: r, name: strings.TrimSuffix(path.Base(url), ".git"), branch: branch}
+	if err := repo.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
 type Repo struct {
 	sync.RWMutex
 	owner      string
@@ -77,17 +99,22 @@ type Repo struct {
 	categories map[string][]*component.Category
 	assets     []*component.Asset
 	forms      []*component.Form
+	failed     map[string]error // locales skipped by the last parse
+	problems   []component.Problem
+	status     status
 }
 
 func (r *Repo) SetConf(c *oauth2.Config) { r.conf = c }
 
-func (r *Repo) Tree(locale string, html bool) interface{} {
+func (r *Repo) Tree(locale string, enc component.Encoding, opts ...component.Option) interface{} {
 	r.RLock()
 	defer r.RUnlock()
 
 	var cats = make([]interface{}, 0, len(r.categories))
 	for _, i := range r.Categories(locale) {
-		cats = append(cats, r.Category(i, locale).Tree(html))
+		if cat := r.Category(i, locale).Filter(opts...); cat != nil {
+			cats = append(cats, cat.Tree(enc))
+		}
 	}
 
 	var ass = make([]string, len(r.assets))
@@ -95,12 +122,12 @@ func (r *Repo) Tree(locale string, html bool) interface{} {
 		ass[i] = r.assets[i].ID
 	}
 
-	var forms = make([]*component.Form, 0)
+	var forms = make([]interface{}, 0)
 	for i := range r.forms {
 		if r.forms[i].Locale != locale {
 			continue
 		}
-		forms = append(forms, r.forms[i])
+		forms = append(forms, r.forms[i].Tree(enc))
 	}
 
 	return map[string]interface{}{
@@ -157,12 +184,25 @@ func (r *Repo) hash() string {
 	return "n/a"
 }
 
-func (r *Repo) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+func (r *Repo) MarshalJSON() ([]byte, error) { return json.Marshal(r.info()) }
+
+func (r *Repo) info() map[string]interface{} {
+	return map[string]interface{}{
 		"owner":  r.owner,
 		"name":   r.name,
 		"commit": r.hash(),
-	})
+	}
+}
+
+// etag identifies the content of the current commit returned for a locale and request URI
+func (r *Repo) etag(locale, uri string) string {
+	r.RLock()
+	defer r.RUnlock()
+	if r.commit == nil {
+		return ""
+	}
+	h := sha1.Sum([]byte(r.commit.Hash.String() + "\n" + locale + "\n" + uri))
+	return `"` + hex.EncodeToString(h[:]) + `"`
 }
 
 func (r *Repo) String() string {
@@ -170,46 +210,68 @@ func (r *Repo) String() string {
 }
 
 func (r *Repo) Pull() {
+	if err := r.Refresh(context.Background()); err != nil {
+		logger.Println(err)
+	}
+}
+
+// Refresh fetches the branch and, if its commit changed, parses the content again. Only the
+// objects missing from the clone are fetched. The error is returned and recorded in Health.
+func (r *Repo) Refresh(ctx context.Context) error {
 	r.Lock()
 	defer r.Unlock()
 
-	err := r.repo.Fetch(&git.FetchOptions{})
+	err := r.pull(ctx)
+	var hash string
+	if r.commit != nil {
+		hash = r.commit.Hash.String()
+	}
+	r.status.record(hash, len(r.categories), r.failed, r.problems, err)
+	return err
+}
+
+func (r *Repo) pull(ctx context.Context) error {
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
-		logger.Println("Pull failed:", err)
-		return
+		return fmt.Errorf("Pull failed: %v", err)
 	}
 	branch := plumbing.ReferenceName("refs/remotes/origin/" + r.branch)
 	hash, err := r.repo.Reference(branch, false)
 	if err != nil {
-		logger.Printf("Reference %q failed:", branch, err)
-		return
+		return fmt.Errorf("Reference %q failed: %v", branch, err)
 	}
 	if r.commit != nil && r.commit.Hash == hash.Hash() {
-		return
+		return nil
 	}
 	if r.commit != nil {
 		logger.Println("Changing commit from", r.commit.Hash, "to", hash)
 	} else {
 		logger.Println("Checkout with", hash)
 	}
-	r.commit, err = r.repo.CommitObject(hash.Hash())
+	// the commit is changed only if parsing succeeds, so a failed one is tried again
+	commit, err := r.repo.CommitObject(hash.Hash())
 	if err != nil {
-		logger.Println("Commit failed:", err)
-		return
+		return fmt.Errorf("Commit failed: %v", err)
 	}
 	var parser component.Parser
-	tree, err := r.commit.Tree()
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("Tree failed: %v", err)
+	}
+	report, err := parser.ParsePartial(tree)
 	if err != nil {
-		logger.Println("Tree failed:", err)
-		return
+		return fmt.Errorf("Parsing failed: %v", err)
 	}
-	if err := parser.Parse(tree); err != nil {
-		logger.Println("Parsing failed:", err)
-		return
+	for locale, err := range report.Failed {
+		logger.Printf("Locale %q skipped: %s", locale, err)
 	}
+	r.commit = commit
 	r.categories = parser.Categories()
 	r.assets = parser.Assets()
 	r.forms = parser.Forms()
+	r.failed = report.Failed
+	r.problems = parser.Problems()
+	return nil
 }
 
 func (r *Repo) file(c component.Component) (*object.File, error) {
@@ -241,6 +303,23 @@ func (r *Repo) Asset(id string) *component.Asset {
 	return nil
 }
 
+// CategoryAssets returns the assets referenced by the category, see Category.Assets, and the
+// IDs of the referenced assets that are missing
+func (r *Repo) CategoryAssets(cat *component.Category) ([]*component.Asset, []string) {
+	var (
+		assets  []*component.Asset
+		missing []string
+	)
+	for _, id := range cat.Assets() {
+		if a := r.Asset(id); a != nil {
+			assets = append(assets, a)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return assets, missing
+}
+
 func (r *Repo) Forms(locale string) []string {
 	r.RLock()
 	defer r.RUnlock()
@@ -2,14 +2,15 @@ package repo
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/github"
@@ -321,17 +322,32 @@ func (r *RepoHandler) ParseForm(c *gin.Context) {
 }
 
 func (r *RepoHandler) Info(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	r.render(c, http.StatusOK, gin.H{
 		"user": r.user(c),
-		"repo": r.repo,
+		"repo": r.repo.info(),
 	})
 }
 
+// Healthz reports the state of the repo, it always succeeds
+func (r *RepoHandler) Healthz(c *gin.Context) {
+	r.render(c, http.StatusOK, r.repo.Health())
+}
+
+// Readyz reports the state of the repo, failing if it's not ready to serve content
+func (r *RepoHandler) Readyz(c *gin.Context) {
+	h := r.repo.Health()
+	status := http.StatusOK
+	if !h.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	r.render(c, status, h)
+}
+
 func (r *RepoHandler) Root(c *gin.Context) {
-	cats := r.repo.Categories(r.locale(c))
-	sort.Strings(cats)
-	c.JSON(http.StatusOK, gin.H{
-		"categories": cats,
+	r.renderContent(c, func() interface{} {
+		cats := r.repo.Categories(r.locale(c))
+		sort.Strings(cats)
+		return gin.H{"categories": cats}
 	})
 }
 
@@ -343,7 +359,7 @@ func (r *RepoHandler) ShowChecks(c *gin.Context) {
 		return
 	}
 	cmp.(*component.Checklist).Hash = hash
-	c.JSON(http.StatusOK, cmp)
+	r.renderContent(c, func() interface{} { return cmp })
 }
 
 func (r *RepoHandler) UpdateChecks(c *gin.Context) {
@@ -373,15 +389,15 @@ func (r *RepoHandler) Show(c *gin.Context) {
 	case *component.Category:
 		v := *t
 		v.Hash = hash
-		out = &v
+		out = v.Fields()
 	case *component.Subcategory:
 		v := *t
 		v.Hash = hash
-		out = &v
+		out = v.Fields()
 	case *component.Difficulty:
 		v := *t
 		v.Hash = hash
-		out = &v
+		out = v.Fields()
 	case *component.Item:
 		v := *t
 		v.Hash = hash
@@ -395,7 +411,7 @@ func (r *RepoHandler) Show(c *gin.Context) {
 		v.Hash = hash
 		out = &v
 	}
-	c.JSON(http.StatusOK, out)
+	r.renderContent(c, func() interface{} { return out })
 }
 
 func (r *RepoHandler) Create(c *gin.Context) {
@@ -422,19 +438,39 @@ func (r *RepoHandler) Delete(c *gin.Context) {
 	c.Writer.WriteHeader(http.StatusNoContent)
 }
 
+// inlineAssets are the content types of the assets served inline, other assets, like HTML or
+// SVG that could run scripts from the origin of the API, are downloaded as attachments
+var inlineAssets = map[string]bool{
+	"image/png": true, "image/jpeg": true, "image/gif": true, "image/webp": true, "image/bmp": true,
+}
+
 func (r *RepoHandler) AssetShow(c *gin.Context) {
 	a := r.asset(c)
-	var ct string
-	switch path.Ext(a.ID) {
-	case ".png":
-		ct = "image/png"
-	case ".jpg", "jpeg":
-		ct = "image/jpeg"
-	default:
-		ct = "application/octet-stream"
+	h := c.Writer.Header()
+	h.Set("X-Content-Type-Options", "nosniff")
+	if t := a.ContentType(); inlineAssets[t] {
+		h.Set("Content-Type", t)
+	} else {
+		h.Set("Content-Type", "application/octet-stream")
+		h.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(a.ID)}))
 	}
-	c.Writer.Header().Set("content-type", ct)
-	c.Writer.WriteString(r.asset(c).Contents())
+	c.Writer.WriteString(a.Contents())
+}
+
+// CategoryAssets lists the assets referenced by the category, without their content, so a
+// client can fetch them before they are needed
+func (r *RepoHandler) CategoryAssets(c *gin.Context) {
+	r.renderContent(c, func() interface{} {
+		assets, missing := r.repo.CategoryAssets(r.cat(c))
+		var list = make([]gin.H, len(assets))
+		for i, a := range assets {
+			list[i] = gin.H{"id": a.ID, "hash": a.Hash, "content_type": a.ContentType()}
+		}
+		if missing == nil {
+			missing = []string{}
+		}
+		return gin.H{"assets": list, "missing": missing}
+	})
 }
 
 func (r *RepoHandler) AssetCreate(c *gin.Context) {
@@ -447,16 +483,14 @@ func (r *RepoHandler) AssetCreate(c *gin.Context) {
 }
 
 func (r *RepoHandler) Tree(c *gin.Context) {
-	writeJSON(c, http.StatusOK, r.repo.Tree(r.locale(c), c.Query("content") == "html"))
-}
-
-func writeJSON(c *gin.Context, status int, obj interface{}) {
-	header := c.Writer.Header()
-	if val := header["Content-Type"]; len(val) == 0 {
-		header["Content-Type"] = []string{"application/json; charset=utf-8"}
+	var opts []component.Option
+	if a := c.Query("audience"); a != "" {
+		opts = append(opts, component.Audience(strings.Split(a, ",")...))
+	}
+	enc, err := component.ParseEncoding(c.Query("content"))
+	if err != nil {
+		r.err(c, http.StatusBadRequest, err)
+		return
 	}
-	c.Status(status)
-	e := json.NewEncoder(c.Writer)
-	e.SetEscapeHTML(false)
-	e.Encode(obj)
+	r.renderContent(c, func() interface{} { return r.repo.Tree(r.locale(c), enc, opts...) })
 }
@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/securityfirst/tent/component"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func init() { gin.SetMode(gin.TestMode) }
+
+// serve answers the request with the handlers of h registered for the method and path
+func serve(req *http.Request, method, path string, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	e := gin.New()
+	e.Handle(method, path, handlers...)
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestAssetShow(t *testing.T) {
+	h := (&Repo{assets: []*component.Asset{
+		{ID: "map.png", Content: "\x89PNG\r\n\x1a\n"},
+		{ID: "page.html", Content: "<script>alert(1)</script>"},
+		{ID: "logo.svg", Content: "<svg/>"},
+	}}).Handler()
+	for _, tc := range []struct{ id, contentType, disposition string }{
+		{"map.png", "image/png", ""},
+		{"page.html", "application/octet-stream", `attachment; filename=page.html`},
+		{"logo.svg", "application/octet-stream", `attachment; filename=logo.svg`},
+	} {
+		req := httptest.NewRequest("GET", "/asset/"+tc.id, nil)
+		w := serve(req, "GET", "/asset/:asset", h.SetAsset, h.AssetShow)
+		if ct := w.Header().Get("Content-Type"); ct != tc.contentType {
+			t.Errorf("%s: content type %q", tc.id, ct)
+		}
+		if d := w.Header().Get("Content-Disposition"); d != tc.disposition {
+			t.Errorf("%s: disposition %q", tc.id, d)
+		}
+		if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("%s: sniffing allowed", tc.id)
+		}
+	}
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	var r Repo
+	h := r.Handler()
+	check := func(state string, code int, ready bool, lastError string) {
+		t.Helper()
+		for _, tc := range []struct {
+			path    string
+			handler gin.HandlerFunc
+			code    int
+		}{{"/healthz", h.Healthz, http.StatusOK}, {"/readyz", h.Readyz, code}} {
+			w := serve(httptest.NewRequest("GET", tc.path, nil), "GET", tc.path, tc.handler)
+			if w.Code != tc.code {
+				t.Errorf("%s %s: status %d, expected %d", state, tc.path, w.Code, tc.code)
+			}
+			var health Health
+			if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+				t.Fatalf("%s %s: %v", state, tc.path, err)
+			}
+			if health.Ready != ready || health.LastError != lastError {
+				t.Errorf("%s %s: unexpected health %+v", state, tc.path, health)
+			}
+		}
+	}
+
+	check("not loaded", http.StatusServiceUnavailable, false, "")
+	r.status.record("abc", 2, nil, nil, nil)
+	check("ok", http.StatusOK, true, "")
+	// an update failed, the previous content is still served
+	r.status.record("abc", 1, map[string]error{"it": errors.New("broken")}, nil, errors.New("pull failed"))
+	check("degraded", http.StatusOK, true, "pull failed")
+	// the last successful update is too old
+	r.SetMaxAge(time.Millisecond)
+	r.status.updated = time.Now().Add(-time.Second)
+	check("stale", http.StatusServiceUnavailable, false, "pull failed")
+	if reason := r.Health().Reason; reason != "last update 1s ago, max age 1ms" {
+		t.Errorf("stale: reason %q", reason)
+	}
+
+	// the parse has problems, blocking unless SetBlocking excludes them
+	r.SetMaxAge(0)
+	note := component.Problem{Path: "contents_en/cat/.metadata.md", Locale: "en", Message: "unterminated note"}
+	r.status.record("abc", 2, nil, []component.Problem{note}, nil)
+	check("blocked", http.StatusServiceUnavailable, false, "")
+	if h := r.Health(); !reflect.DeepEqual(h.Blocking, []component.Problem{note}) ||
+		h.Reason != "blocking problems (1), the first is contents_en/cat/.metadata.md (en): unterminated note" {
+		t.Errorf("blocked: unexpected health %+v", h)
+	}
+	r.SetBlocking(func(p component.Problem) bool { return p.Message != "unterminated note" })
+	check("not blocking", http.StatusOK, true, "")
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct{ accept, mime string }{
+		{"", gin.MIMEJSON},
+		{"application/json", gin.MIMEJSON},
+		{"application/msgpack, application/json", MIMEMsgPack},
+		{"application/json;q=0.5, application/msgpack", MIMEMsgPack},
+		{"application/x-msgpack;q=0.9, application/json;q=0.8", MIMEMsgPack},
+		{"application/json; q=0, */*", MIMEMsgPack},
+		{"*/*;q=0.1, application/json;q=0.2", gin.MIMEJSON},
+		{"application/*", gin.MIMEJSON},
+		{"application/*;q=1, application/json;q=0.3", MIMEMsgPack},
+		{"text/html, */*;q=0", ""},
+		{"application/json;q=0, application/msgpack;q=0", ""},
+		{"application/json;q=x", ""},
+		{"text/html", ""},
+	} {
+		if mime := negotiate(tc.accept); mime != tc.mime {
+			t.Errorf("%q: got %q, expected %q", tc.accept, mime, tc.mime)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	r := Repo{
+		commit:     &object.Commit{Hash: plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")},
+		categories: map[string][]*component.Category{"en": {{ID: "cat"}}},
+	}
+	h := r.Handler()
+	get := func(header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		return serve(req, "GET", "/", h.ParseLocale, h.Root)
+	}
+
+	w := get()
+	var root struct {
+		Categories []string `json:"categories" codec:"categories"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &root); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("json: %d %v", w.Code, err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" || len(root.Categories) != 1 {
+		t.Errorf("json: unexpected %q %v", ct, root)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if vary := w.Header().Values("Vary"); !reflect.DeepEqual(vary, []string{"X-Tent-Language", "Accept"}) {
+		t.Errorf("json: vary %v", vary)
+	}
+
+	w = get("Accept", MIMEMsgPack)
+	root.Categories = nil
+	if err := codec.NewDecoderBytes(w.Body.Bytes(), new(codec.MsgpackHandle)).Decode(&root); err != nil {
+		t.Fatalf("msgpack: %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != MIMEMsgPack+"; charset=utf-8" || len(root.Categories) != 1 {
+		t.Errorf("msgpack: unexpected %q %v", ct, root)
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("msgpack: ETag %q, expected %q", w.Header().Get("ETag"), etag)
+	}
+
+	w = get("If-None-Match", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("not modified: %d %q", w.Code, w.Body.String())
+	}
+	if vary := w.Header().Values("Vary"); !reflect.DeepEqual(vary, []string{"X-Tent-Language", "Accept"}) {
+		t.Errorf("not modified: vary %v", vary)
+	}
+	if w = get("If-None-Match", etag, "X-Tent-Language", "it"); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("other locale: %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	if w = get("Accept", "text/html"); w.Code != http.StatusNotAcceptable {
+		t.Errorf("not acceptable: %d", w.Code)
+	}
+}
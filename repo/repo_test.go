@@ -0,0 +1,118 @@
+package repo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// origin is a local repository to load, commit adds a commit with the files to its master
+type origin struct {
+	t   *testing.T
+	dir string
+	wt  *git.Worktree
+}
+
+func newOrigin(t *testing.T) *origin {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &origin{t: t, dir: dir, wt: wt}
+}
+
+func (o *origin) commit(files map[string]string) string {
+	o.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(o.dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			o.t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			o.t.Fatal(err)
+		}
+		if _, err := o.wt.Add(name); err != nil {
+			o.t.Fatal(err)
+		}
+	}
+	hash, err := o.wt.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func TestLoadRefresh(t *testing.T) {
+	o := newOrigin(t)
+	first := o.commit(map[string]string{"contents_en/cat/.metadata.md": "[Name]: # (Category)\n[Order]: # (1)"})
+	ctx := context.Background()
+	r, err := Load(ctx, o.dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := r.Health(); h.Hash != first || !h.Ready || r.Category("cat", "en").Name != "Category" {
+		t.Fatalf("unexpected load %+v", h)
+	}
+
+	// readers see either the old content or the new one, never a mix
+	second := o.commit(map[string]string{"contents_en/cat/.metadata.md": "[Name]: # (Renamed)\n[Order]: # (1)"})
+	var (
+		wg   sync.WaitGroup
+		done = make(chan struct{})
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			r.RLock()
+			name, hash := r.categories["en"][0].Name, r.commit.Hash.String()
+			r.RUnlock()
+			if !(name == "Category" && hash == first) && !(name == "Renamed" && hash == second) {
+				t.Errorf("mixed content: %q at %s", name, hash)
+				return
+			}
+		}
+	}()
+	err = r.Refresh(ctx)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := r.Health(); h.Hash != second || r.Category("cat", "en").Name != "Renamed" {
+		t.Fatalf("unexpected refresh %+v", h)
+	}
+
+	// a commit that cannot be parsed keeps the previous content
+	o.commit(map[string]string{"contents_en/cat/.metadata.md": "broken"})
+	if err := r.Refresh(ctx); err == nil {
+		t.Fatal("no error for a broken commit")
+	}
+	h := r.Health()
+	if h.Hash != second || !h.Ready || h.LastError == "" || r.Category("cat", "en").Name != "Renamed" {
+		t.Fatalf("unexpected failed refresh %+v", h)
+	}
+
+	if _, err := Load(ctx, filepath.Join(o.dir, "missing"), ""); err == nil {
+		t.Fatal("no error for a missing repository")
+	}
+}
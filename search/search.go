@@ -0,0 +1,303 @@
+// Package search keeps a full-text index of the items and checks of a ResourceParser, by
+// locale, so clients can search the content without downloading the whole tree.
+//
+// Titles, bodies and check texts, as plain text without notes, are split into words with
+// component.Tokenize, the same words counted everywhere else, and matched ignoring case.
+// Every word of a query must match; the last one also matches as a prefix, so a query can be
+// searched while it's typed. Words of a title weigh three times the ones of a body.
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/securityfirst/tent/component"
+)
+
+// Kinds of Result
+const (
+	KindItem  = "item"
+	KindCheck = "check"
+)
+
+// weights of the fields of a document in the score
+const (
+	titleWeight = 3
+	textWeight  = 1
+)
+
+// Result is an item or a check matching a query
+type Result struct {
+	Kind        string  `json:"kind"`
+	Path        string  `json:"path"` // tree path of the item or of the checklist
+	Category    string  `json:"category"`
+	Subcategory string  `json:"subcategory"`
+	Difficulty  string  `json:"difficulty"`
+	Item        string  `json:"item,omitempty"`
+	Check       int     `json:"check,omitempty"` // position in the checklist, from 1
+	Title       string  `json:"title"`           // title of the item or text of the check
+	Score       float64 `json:"score"`
+}
+
+// Facets counts results by category, subcategory and difficulty
+type Facets struct {
+	Category    map[string]int `json:"category"`
+	Subcategory map[string]int `json:"subcategory"` // by category/subcategory
+	Difficulty  map[string]int `json:"difficulty"`
+}
+
+// Count returns the facets of the results
+func Count(results []Result) Facets {
+	f := Facets{Category: map[string]int{}, Subcategory: map[string]int{}, Difficulty: map[string]int{}}
+	for _, r := range results {
+		f.Category[r.Category]++
+		f.Subcategory[r.Category+"/"+r.Subcategory]++
+		f.Difficulty[r.Difficulty]++
+	}
+	return f
+}
+
+// Option filters and limits the results of Search
+type Option func(*options)
+
+type options struct {
+	category, subcategory, difficulty string
+	kind                              string
+	limit                             int
+}
+
+// Category keeps the results of the category
+func Category(id string) Option { return func(o *options) { o.category = id } }
+
+// Subcategory keeps the results of the subcategory, of any category if not set with Category
+func Subcategory(id string) Option { return func(o *options) { o.subcategory = id } }
+
+// Difficulty keeps the results of the difficulty
+func Difficulty(id string) Option { return func(o *options) { o.difficulty = id } }
+
+// Kind keeps the results of a kind, KindItem or KindCheck
+func Kind(kind string) Option { return func(o *options) { o.kind = kind } }
+
+// Limit returns at most n results, the best ones
+func Limit(n int) Option { return func(o *options) { o.limit = n } }
+
+func (o *options) match(r *Result) bool {
+	return (o.category == "" || o.category == r.Category) &&
+		(o.subcategory == "" || o.subcategory == r.Subcategory) &&
+		(o.difficulty == "" || o.difficulty == r.Difficulty) &&
+		(o.kind == "" || o.kind == r.Kind)
+}
+
+// Index is the index of the content of a parser. It's safe for concurrent use, and a search
+// never waits for a build: the new index replaces the old one when it's complete.
+type Index struct {
+	mu      sync.RWMutex
+	locales map[string]*localeIndex
+}
+
+// localeIndex is the index of a locale, documents by path and their score by word
+type localeIndex struct {
+	docs  map[string]*Result
+	words map[string]map[string]float64
+}
+
+// New returns an empty index, fill it with Build or add it to a parser with AddFinalizer,
+// so it's rebuilt every time the parser is finalized
+func New() *Index { return &Index{locales: make(map[string]*localeIndex)} }
+
+// Finalize rebuilds the index, it's a component.Finalizer and never reports problems
+func (x *Index) Finalize(p *component.ResourceParser) []component.Problem {
+	x.Build(p)
+	return nil
+}
+
+// Build replaces the index with the content of the parser, archived locales excluded
+func (x *Index) Build(p *component.ResourceParser) {
+	var locales = make(map[string]*localeIndex)
+	for _, l := range p.Locales() {
+		idx := newLocaleIndex()
+		for _, cat := range p.SortedCategories(l) {
+			idx.addCategory(l, cat)
+		}
+		locales[l] = idx
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.locales = locales
+}
+
+// Update replaces the documents of the category of the locale with the ones of cat, after a
+// change of the category alone, like Snapshot.Reparse; a nil cat removes them. The rest of
+// the index is kept, and searches see the new documents once they are all in. Updates must not
+// run at the same time as each other or as Build.
+func (x *Index) Update(locale, id string, cat *component.Category) {
+	x.mu.RLock()
+	old := x.locales[locale]
+	x.mu.RUnlock()
+	var (
+		idx    = newLocaleIndex()
+		prefix = id + "/"
+	)
+	if old != nil {
+		for key, r := range old.docs {
+			if !strings.HasPrefix(key, prefix) {
+				idx.docs[key] = r
+			}
+		}
+		for w, docs := range old.words {
+			var kept = make(map[string]float64, len(docs))
+			for key, s := range docs {
+				if !strings.HasPrefix(key, prefix) {
+					kept[key] = s
+				}
+			}
+			if len(kept) != 0 {
+				idx.words[w] = kept
+			}
+		}
+	}
+	if cat != nil {
+		idx.addCategory(locale, cat)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.locales[locale] = idx
+}
+
+// Search returns the items and checks of the locale matching the query, best first
+func (x *Index) Search(locale, query string, opts ...Option) []Result {
+	var o options
+	for _, fn := range opts {
+		fn(&o)
+	}
+	terms := words(locale, query)
+	if len(terms) == 0 {
+		return nil
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	idx, ok := x.locales[locale]
+	if !ok {
+		return nil
+	}
+	var scores map[string]float64
+	for i, t := range terms {
+		found := idx.find(t, i == len(terms)-1)
+		if scores == nil {
+			scores = found
+			continue
+		}
+		for path, s := range scores {
+			if f, ok := found[path]; ok {
+				scores[path] = s + f
+			} else {
+				delete(scores, path)
+			}
+		}
+	}
+	var list []Result
+	for path, s := range scores {
+		r := idx.docs[path]
+		if !o.match(r) {
+			continue
+		}
+		res := *r
+		res.Score = s
+		list = append(list, res)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].Path < list[j].Path
+	})
+	if o.limit > 0 && len(list) > o.limit {
+		list = list[:o.limit]
+	}
+	return list
+}
+
+func newLocaleIndex() *localeIndex {
+	return &localeIndex{docs: make(map[string]*Result), words: make(map[string]map[string]float64)}
+}
+
+func (idx *localeIndex) addCategory(locale string, cat *component.Category) {
+	for _, s := range cat.Subcategories() {
+		sub := cat.Sub(s)
+		for _, d := range sub.DifficultyNames() {
+			diff := sub.Difficulty(d)
+			tree := diff.Tree(component.PlainText).(map[string]interface{})
+			doc := Result{Category: cat.ID, Subcategory: sub.ID, Difficulty: diff.ID}
+			for _, item := range tree["items"].([]component.Item) {
+				r := doc
+				r.Kind, r.Item, r.Title = KindItem, item.ID, item.Title
+				r.Path = strings.Join([]string{cat.ID, sub.ID, diff.ID, item.ID}, "/")
+				idx.add(locale, &r, field{item.Title, titleWeight}, field{item.Body, textWeight})
+			}
+			for i, c := range tree["checks"].([]component.Check) {
+				r := doc
+				r.Kind, r.Check, r.Title = KindCheck, i+1, c.Text
+				r.Path = strings.Join([]string{cat.ID, sub.ID, diff.ID, ".checks"}, "/")
+				idx.add(locale, &r, field{c.Text, textWeight})
+			}
+		}
+	}
+}
+
+// field is a text of a document, with its weight in the score
+type field struct {
+	text   string
+	weight float64
+}
+
+// add indexes the document with its fields
+func (idx *localeIndex) add(locale string, r *Result, fields ...field) {
+	key := r.Path
+	if r.Kind == KindCheck {
+		key = fmt.Sprintf("%s/%d", r.Path, r.Check)
+	}
+	idx.docs[key] = r
+	for _, f := range fields {
+		for _, w := range words(locale, f.text) {
+			docs, ok := idx.words[w]
+			if !ok {
+				docs = make(map[string]float64)
+				idx.words[w] = docs
+			}
+			docs[key] += f.weight
+		}
+	}
+}
+
+// find returns the score of the documents with the word, or a word starting with it
+func (idx *localeIndex) find(word string, prefix bool) map[string]float64 {
+	var m = make(map[string]float64)
+	for path, s := range idx.words[word] {
+		m[path] += s
+	}
+	if !prefix {
+		return m
+	}
+	for w, docs := range idx.words {
+		if w == word || !strings.HasPrefix(w, word) {
+			continue
+		}
+		for path, s := range docs {
+			m[path] += s / 2
+		}
+	}
+	return m
+}
+
+// words returns the words of the text in lower case
+func words(locale, text string) []string {
+	var list []string
+	for _, t := range component.TokenizeMarkdown(locale, text) {
+		if t.IsWord() {
+			list = append(list, strings.ToLower(t.Text))
+		}
+	}
+	return list
+}
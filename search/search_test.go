@@ -0,0 +1,119 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/securityfirst/tent/component"
+)
+
+const locale = `{"locale": "en", "categories": [{"id": "travel", "name": "Travel", "order": 0,
+	"subcategories": [{"id": "borders", "name": "Borders", "order": 0, "difficulties": [{"id": "beginner",
+	"description": "Easy", "items": [
+		{"id": "phone", "title": "Your phone", "body": "Back up the **phone** before the border [[note: check wording]]", "order": 0},
+		{"id": "laptop", "title": "Laptop", "body": "Encrypt the laptop, and the phone too.", "order": 1}],
+	"checks": [{"text": "Phone backed up", "no_check": false}, {"text": "Passport", "no_check": false}]}]}]},
+	{"id": "digital", "name": "Digital", "order": 1, "subcategories": [{"id": "mobile", "name": "Mobile", "order": 0,
+	"difficulties": [{"id": "advanced", "description": "Hard", "items": [
+		{"id": "apps", "title": "Apps", "body": "Check the permissions of the apps on your phone.", "order": 0}],
+	"checks": []}]}]}]}`
+
+func testIndex(t *testing.T) (*Index, *component.ResourceParser) {
+	p := component.NewResourceParser()
+	if err := p.UnmarshalLocale([]byte(locale)); err != nil {
+		t.Fatal(err)
+	}
+	x := New()
+	p.AddFinalizer(x)
+	p.Finalize()
+	return x, p
+}
+
+func paths(results []Result) []string {
+	var list []string
+	for _, r := range results {
+		if r.Kind == KindCheck {
+			list = append(list, r.Path+" "+r.Title)
+		} else {
+			list = append(list, r.Path)
+		}
+	}
+	return list
+}
+
+func TestSearch(t *testing.T) {
+	x, _ := testIndex(t)
+	for _, tc := range []struct {
+		query    string
+		opts     []Option
+		expected []string
+	}{
+		{"phone", nil, []string{"travel/borders/beginner/phone", "digital/mobile/advanced/apps",
+			"travel/borders/beginner/.checks Phone backed up", "travel/borders/beginner/laptop"}},
+		{"PHONE encrypt", nil, []string{"travel/borders/beginner/laptop"}},
+		{"the lap", nil, []string{"travel/borders/beginner/laptop"}},
+		{"pass", nil, []string{"travel/borders/beginner/.checks Passport"}},
+		{"phone", []Option{Category("digital")}, []string{"digital/mobile/advanced/apps"}},
+		{"phone", []Option{Subcategory("borders"), Kind(KindItem)}, []string{"travel/borders/beginner/phone", "travel/borders/beginner/laptop"}},
+		{"phone", []Option{Difficulty("beginner"), Limit(1)}, []string{"travel/borders/beginner/phone"}},
+		{"wording", nil, nil}, // notes are not indexed
+		{"ph one", nil, nil},
+		{"", nil, nil},
+	} {
+		if got := paths(x.Search("en", tc.query, tc.opts...)); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q: expected %q, got %q", tc.query, tc.expected, got)
+		}
+	}
+	if r := x.Search("it", "phone"); r != nil {
+		t.Errorf("other locale: %v", r)
+	}
+
+	r := x.Search("en", "phone")
+	if r[0].Score != 4 || r[0].Item != "phone" || r[0].Title != "Your phone" || r[2].Check != 1 {
+		t.Errorf("unexpected results %+v", r[:3])
+	}
+	f := Count(r)
+	if !reflect.DeepEqual(f.Category, map[string]int{"travel": 3, "digital": 1}) ||
+		!reflect.DeepEqual(f.Subcategory, map[string]int{"travel/borders": 3, "digital/mobile": 1}) ||
+		!reflect.DeepEqual(f.Difficulty, map[string]int{"beginner": 3, "advanced": 1}) {
+		t.Errorf("unexpected facets %+v", f)
+	}
+}
+
+func TestSearchRebuild(t *testing.T) {
+	x, p := testIndex(t)
+	p.ArchiveLocale("en")
+	if r := x.Search("en", "phone"); len(r) != 4 {
+		t.Errorf("not finalized: %d results", len(r))
+	}
+	p.Finalize()
+	if r := x.Search("en", "phone"); r != nil {
+		t.Errorf("archived: %v", r)
+	}
+}
+
+func TestSearchUpdate(t *testing.T) {
+	x, p := testIndex(t)
+	s := p.Snapshot()
+	item := s.Category("digital", "en").Sub("mobile").Difficulty("advanced").Item("apps")
+	s, err := s.Reparse(item, &component.Resource{Content: []map[string]string{
+		{component.KeyTitle: "Apps"}, {component.KeyBody: "Review the permissions."},
+	}}, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Update("en", "digital", s.Category("digital", "en"))
+	if got := paths(x.Search("en", "review")); !reflect.DeepEqual(got, []string{"digital/mobile/advanced/apps"}) {
+		t.Errorf("updated: %q", got)
+	}
+	if r := x.Search("en", "phone"); len(r) != 3 {
+		t.Errorf("old body: %d results", len(r))
+	}
+	x.Update("en", "travel", nil)
+	if r := x.Search("en", "phone"); r != nil {
+		t.Errorf("removed: %v", r)
+	}
+	if got := paths(x.Search("en", "permissions")); !reflect.DeepEqual(got, []string{"digital/mobile/advanced/apps"}) {
+		t.Errorf("kept: %q", got)
+	}
+}
@@ -12,6 +12,8 @@ import (
 
 const (
 	pathInfo        = "/"
+	pathHealth      = "/healthz"
+	pathReady       = "/readyz"
 	pathTree        = "/api/tree"
 	pathRepo        = "/api/repo"
 	pathUpdate      = "/api/repo/update"
@@ -22,6 +24,7 @@ const (
 	pathCheck       = "/api/repo/category/:cat/:sub/:diff/checks"
 	pathAsset       = "/api/repo/asset"
 	pathAssetID     = "/api/repo/asset/:asset"
+	pathCatAssets   = "/api/repo/assets/:cat"
 	pathForm        = "/api/repo/form/:form"
 )
 
@@ -48,6 +51,8 @@ func (o *Tent) Register(root *gin.RouterGroup, c auth.Config) {
 		default: // discard
 		}
 	})
+	root.GET(pathHealth, h.Healthz)
+	root.GET(pathReady, h.Readyz)
 	locale := root.Use(h.ParseLocale)
 	locale.GET(pathTree, h.Tree)
 	locale.GET(pathInfo, h.Info)
@@ -58,6 +63,7 @@ func (o *Tent) Register(root *gin.RouterGroup, c auth.Config) {
 	locale.GET(pathItem, h.SetItem, h.Show)
 	locale.GET(pathCheck, h.SetCheck, h.ShowChecks)
 	locale.GET(pathAssetID, h.SetAsset, h.AssetShow)
+	locale.GET(pathCatAssets, h.SetCat, h.CategoryAssets)
 	locale.GET(pathForm, h.SetForm, h.Show)
 
 	// Locale and Authorized handlers
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/securityfirst/tent/component"
+)
+
+// parseDir parses the contents of the directory, the current one if empty
+func parseDir(dir string) (*component.Parser, error) {
+	if dir == "" {
+		dir = "."
+	}
+	var p component.Parser
+	if err := p.ParseFS(os.DirFS(dir)); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// parseDirPartial parses the contents of the directory like parseDir, skipping the locales
+// that cannot be parsed
+func parseDirPartial(dir string) (*component.Parser, component.LoadReport, error) {
+	if dir == "" {
+		dir = "."
+	}
+	var p component.Parser
+	report, err := p.ParseFSPartial(os.DirFS(dir))
+	if err != nil {
+		return nil, report, err
+	}
+	return &p, report, nil
+}
+
+// dirArg returns the first argument, the directory of the contents, if any
+func dirArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
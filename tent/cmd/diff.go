@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/spf13/cobra"
+)
+
+var diffJSON bool
+
+var diffCmd = &cobra.Command{
+	Use:   "diff old new",
+	Short: "Compares the contents of two directories",
+	Long: `Parses the contents of two directories, like two versions of the repo, and prints the
+components added, removed and modified, by locale. It exits with 1 if there are changes.`,
+	Args: cobra.ExactArgs(2),
+	Run:  diffRun,
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "print the changes as JSON")
+}
+
+func diffRun(cmd *cobra.Command, args []string) {
+	var parsers [2]*component.ResourceParser
+	for i, dir := range args {
+		p, err := parseDir(dir)
+		if err != nil {
+			log.Fatalf("Parse error (%s): %s", dir, err)
+		}
+		parsers[i] = p.ResourceParser()
+	}
+	cs := component.Diff(parsers[0], parsers[1])
+	if diffJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(cs); err != nil {
+			log.Fatalf("Output error: %s", err)
+		}
+	} else {
+		var locales = make([]string, 0, len(cs.Locales))
+		for l := range cs.Locales {
+			locales = append(locales, l)
+		}
+		sort.Strings(locales)
+		for _, l := range locales {
+			for _, c := range cs.Locales[l] {
+				fmt.Printf("%s %s %s\n", l, c.Kind, c.Path)
+			}
+		}
+	}
+	if !cs.Empty() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3" // sqlite3 driver of the sqlite format
+	"github.com/securityfirst/tent/bundle"
+	"github.com/spf13/cobra"
+)
+
+var exportFlags struct {
+	Locale, Format, Output, Driver string
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [dir]",
+	Short: "Exports the contents of a directory",
+	Long: `Parses the contents of a directory and writes a locale as JSON, to the standard output
+or to a file, or the locales as a SQLite database, every one if the locale is not set.
+SQLite uses the sqlite3 driver built in the binary, or another registered one named by --driver.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  exportRun,
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	f := exportCmd.Flags()
+	f.StringVar(&exportFlags.Locale, "locale", "", "locale to export (default en for json, every one for sqlite)")
+	f.StringVar(&exportFlags.Format, "format", "json", "output format, json or sqlite")
+	f.StringVarP(&exportFlags.Output, "output", "o", "", "output file (default stdout, required for sqlite)")
+	f.StringVar(&exportFlags.Driver, "driver", "sqlite3", "database/sql driver of the sqlite format")
+}
+
+func exportRun(cmd *cobra.Command, args []string) {
+	p, err := parseDir(dirArg(args))
+	if err != nil {
+		log.Fatalf("Parse error: %s", err)
+	}
+	switch exportFlags.Format {
+	case "json":
+		locale := exportFlags.Locale
+		if locale == "" {
+			locale = "en"
+		}
+		b, err := p.ResourceParser(locale).MarshalLocale(locale)
+		if err != nil {
+			log.Fatalf("Export error: %s", err)
+		}
+		w := os.Stdout
+		if exportFlags.Output != "" {
+			if w, err = os.Create(exportFlags.Output); err != nil {
+				log.Fatalf("Output error: %s", err)
+			}
+		}
+		if _, err := w.Write(b); err != nil {
+			log.Fatalf("Output error: %s", err)
+		}
+		if err := w.Close(); err != nil {
+			log.Fatalf("Output error: %s", err)
+		}
+	case "sqlite":
+		if exportFlags.Output == "" {
+			log.Fatalf("Output file required")
+		}
+		var locales []string
+		if exportFlags.Locale != "" {
+			locales = append(locales, exportFlags.Locale)
+		}
+		if err := bundle.WriteFile(exportFlags.Driver, exportFlags.Output, p.ResourceParser(locales...)); err != nil {
+			log.Fatalf("Export error: %s", err)
+		}
+	default:
+		log.Fatalf("Invalid format: %s", exportFlags.Format)
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/securityfirst/tent/component"
+	"github.com/spf13/cobra"
+)
+
+var statsFlags struct {
+	Base, Format string
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [dir]",
+	Short: "Counts the contents of a directory",
+	Long: `Parses the contents of a directory and prints, for each locale, the number of
+components and words, and the percent of the words of the base locale it translates.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  statsRun,
+}
+
+func init() {
+	RootCmd.AddCommand(statsCmd)
+	f := statsCmd.Flags()
+	f.StringVar(&statsFlags.Base, "base", "en", "base locale of the translations")
+	f.StringVar(&statsFlags.Format, "format", "csv", "output format, csv or json")
+}
+
+func statsRun(cmd *cobra.Command, args []string) {
+	p, err := parseDir(dirArg(args))
+	if err != nil {
+		log.Fatalf("Parse error: %s", err)
+	}
+	stats := p.ResourceParser().StatsReport(statsFlags.Base)
+	if err := component.WriteStats(os.Stdout, stats, component.Format(statsFlags.Format)); err != nil {
+		log.Fatalf("Stats error: %s", err)
+	}
+}
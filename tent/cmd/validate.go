@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [dir]",
+	Short: "Checks the contents of a directory",
+	Long: `Parses the contents of a directory and prints the locales that cannot be loaded,
+with the file that failed, and the ordering problems of the others. It exits with 1 if a
+locale fails.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  validateRun,
+}
+
+func init() {
+	RootCmd.AddCommand(validateCmd)
+}
+
+func validateRun(cmd *cobra.Command, args []string) {
+	p, report, err := parseDirPartial(dirArg(args))
+	if err != nil {
+		log.Fatalf("Parse error: %s", err)
+	}
+	var failed = make([]string, 0, len(report.Failed))
+	for l := range report.Failed {
+		failed = append(failed, l)
+	}
+	sort.Strings(failed)
+	for _, l := range failed {
+		fmt.Printf("%s: %s\n", l, report.Failed[l])
+	}
+	if err := p.CheckInvariants(); err != nil {
+		log.Fatalf("Invariant error: %s", err)
+	}
+	r := p.ResourceParser()
+	for _, l := range r.Locales() {
+		for _, problem := range r.Sort(l) {
+			fmt.Println(problem)
+		}
+	}
+	if len(failed) != 0 {
+		os.Exit(1)
+	}
+}